@@ -2,25 +2,56 @@ package di
 
 import (
 	"database/sql"
+	"net/http"
+	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/domain/ports"
+	"inmo-backend/internal/infrastructure/config"
 	"inmo-backend/internal/infrastructure/db"
+	"inmo-backend/internal/infrastructure/mail"
+	"inmo-backend/internal/infrastructure/oidc"
 	"inmo-backend/internal/infrastructure/repository"
 	"inmo-backend/internal/interface/api/handler"
 	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
 )
 
 type Container struct {
 	SqlDB      			*sql.DB
+	tokenManager 		*middleware.TokenManager
+	sessionRepo 		ports.SessionRepository
+	passwordResetRepo 	ports.PasswordResetRepository
+	twoFactorRepo 		ports.TwoFactorRepository
+	emailVerificationRepo 	ports.EmailVerificationRepository
+	tenantRepo 			ports.TenantRepository
+	mailer 				ports.Mailer
+	loginGuard 			*usecase.LoginGuard
+	authConfig 			config.AuthConfig
+	tenantConfig 		config.TenantConfig
+	tenantResolver 		*middleware.TenantResolver
+	defaultTenant 		*models.Tenant
+	tenantUsecase 		ports.TenantUseCase
+	tenantHandler 		*handler.TenantHandler
+	healthHandler 		*handler.HealthHandler
+}
+
+// tenantScope holds the repositories, use cases and handlers bound to one
+// tenant. Nothing built from it can reach the data of another tenant.
+type tenantScope struct {
 	userRepo   			ports.UserRepository
 	propertyRepo    	ports.PropertyRepository
+	apiKeyRepo 			ports.APIKeyRepository
+	ssoStateRepo 		ports.SSOStateRepository
+	userIdentityRepo 	ports.UserIdentityRepository
+	invitationRepo 		ports.InvitationRepository
+	branchRepo 			ports.BranchRepository
 	userUsecase 		ports.UserUseCase
-	propertyUsecase  	ports.PropertyUseCase
-	userHandler 		*handler.UserHandler
-	propertyHandler 	*handler.PropertyHandler
-	healthHandler 		*handler.HealthHandler
+	invitationUsecase 	ports.InvitationUseCase
+	handlers 			*Handlers
 }
 
 func NewContainer() *Container {
@@ -35,28 +66,208 @@ func NewContainer() *Container {
 		logrus.Fatal("Failed to initialize database connection")
 	}
 
-	container.userRepo = repository.NewUserRepository(container.SqlDB)
-	container.propertyRepo = repository.NewPropertyRepository(container.SqlDB)
-	container.userUsecase = usecase.NewUserUseCase(container.userRepo)
-	container.propertyUsecase = usecase.NewPropertyUseCase(container.propertyRepo)
-	container.userHandler = handler.NewUserHandler(container.userUsecase)
-	container.propertyHandler = handler.NewPropertyHandler(container.propertyUsecase)
+	middleware.SetPasswordHasher(newPasswordHasher(config.LoadPasswordHashConfig()))
+
+	container.authConfig = config.LoadAuthConfig()
+	authConfig := container.authConfig
+	container.tokenManager = middleware.NewTokenManager(authConfig.JWTSecret, authConfig.JWTIssuer, authConfig.AccessTokenTTL, authConfig.RefreshTokenTTL)
+
+	container.sessionRepo = repository.NewSessionRepository(container.SqlDB)
+	container.passwordResetRepo = repository.NewPasswordResetRepository(container.SqlDB)
+	container.twoFactorRepo = repository.NewTwoFactorRepository(container.SqlDB)
+	container.emailVerificationRepo = repository.NewEmailVerificationRepository(container.SqlDB)
+	container.tenantRepo = repository.NewTenantRepository(container.SqlDB)
+	container.mailer = newMailer(config.LoadMailConfig())
+	container.loginGuard = newLoginGuard(container.SqlDB)
 	container.healthHandler = handler.NewHealthHandler()
 
+	container.tenantConfig = config.LoadTenantConfig()
+	container.tenantResolver = middleware.NewTenantResolver(container.tenantRepo, container.tokenManager, container.tenantConfig.BaseDomain)
+	defaultTenant, err := container.tenantRepo.GetBySlug(models.DefaultTenantSlug)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to load the default tenant")
+	}
+	container.defaultTenant = defaultTenant
+	if container.tenantConfig.MultiTenant {
+		logrus.Info("Multi-tenant mode, requests are routed by X-Tenant header, subdomain or access token")
+	}
+
+	if authConfig.AdminEmail != "" {
+		admin := &models.User{
+			Username: authConfig.AdminUsername,
+			Email:    authConfig.AdminEmail,
+			Password: authConfig.AdminPassword,
+		}
+		if err := container.newTenantScope(defaultTenant).userUsecase.EnsureAdmin(admin); err != nil {
+			logrus.WithError(err).Error("Failed to bootstrap admin user")
+		}
+	}
+
+	container.tenantUsecase = usecase.NewTenantUseCase(container.tenantRepo, func(tenant *models.Tenant) usecase.TenantServices {
+		scope := container.newTenantScope(tenant)
+		return usecase.TenantServices{Branches: scope.branchRepo, Invitations: scope.invitationUsecase}
+	})
+	container.tenantHandler = handler.NewTenantHandler(container.tenantUsecase)
+
 	logrus.Info("DI container initialized successfully")
 	return container
 }
 
+// newTenantScope wires the repositories, use cases and handlers of a tenant.
+// Links mailed to users replace {tenant} in their URL with the tenant's slug.
+func (c *Container) newTenantScope(tenant *models.Tenant) *tenantScope {
+	scope := &tenantScope{}
+
+	scope.userRepo = repository.NewUserRepository(c.SqlDB, tenant.ID)
+	scope.propertyRepo = repository.NewPropertyRepository(c.SqlDB, tenant.ID)
+	scope.apiKeyRepo = repository.NewAPIKeyRepository(c.SqlDB, tenant.ID)
+	scope.ssoStateRepo = repository.NewSSOStateRepository(c.SqlDB, tenant.ID)
+	scope.userIdentityRepo = repository.NewUserIdentityRepository(c.SqlDB, tenant.ID)
+	scope.invitationRepo = repository.NewInvitationRepository(c.SqlDB, tenant.ID)
+	scope.branchRepo = repository.NewBranchRepository(c.SqlDB, tenant.ID)
+	verificationConfig := config.LoadEmailVerificationConfig()
+	emailVerifier := usecase.NewEmailVerifier(
+		c.emailVerificationRepo, scope.userRepo,
+		c.mailer, verificationConfig.TokenTTL, tenantURL(verificationConfig.VerifyURL, tenant),
+	)
+	userUsecase := usecase.NewUserUseCase(scope.userRepo, c.sessionRepo, c.twoFactorRepo, c.tokenManager, c.loginGuard.ForTenant(tenant.ID), emailVerifier)
+	scope.userUsecase = userUsecase
+	oidcConfig := config.LoadOIDCConfig()
+	oidcConfig.RedirectURL = tenantURL(oidcConfig.RedirectURL, tenant)
+	ssoUsecase := usecase.NewSSOUseCase(
+		newIdentityProvider(oidcConfig), scope.ssoStateRepo, scope.userIdentityRepo,
+		scope.userRepo, userUsecase, oidcConfig.StateTTL,
+	)
+	propertyUsecase := usecase.NewPropertyUseCase(scope.propertyRepo, scope.userRepo)
+	twoFactorUsecase := usecase.NewTwoFactorUseCase(scope.userRepo, c.twoFactorRepo, c.sessionRepo, c.authConfig.TOTPIssuer)
+	apiKeyUsecase := usecase.NewAPIKeyUseCase(scope.apiKeyRepo)
+	resetConfig := config.LoadPasswordResetConfig()
+	passwordUsecase := usecase.NewPasswordUseCase(
+		scope.userRepo, c.passwordResetRepo, c.sessionRepo,
+		c.mailer, resetConfig.TokenTTL, tenantURL(resetConfig.ResetURL, tenant),
+	)
+	invitationConfig := config.LoadInvitationConfig()
+	scope.invitationUsecase = usecase.NewInvitationUseCase(
+		scope.invitationRepo, scope.userRepo, scope.branchRepo, c.tokenManager,
+		c.mailer, invitationConfig.TokenTTL, tenantURL(invitationConfig.AcceptURL, tenant),
+	)
+	branchUsecase := usecase.NewBranchUseCase(scope.branchRepo, scope.userRepo)
+
+	scope.handlers = &Handlers{
+		Tenant: tenant,
+		PropertyHandler: handler.NewPropertyHandler(propertyUsecase),
+		UserHandler:  handler.NewUserHandler(userUsecase),
+		PasswordHandler: handler.NewPasswordHandler(passwordUsecase),
+		TwoFactorHandler: handler.NewTwoFactorHandler(twoFactorUsecase),
+		APIKeyHandler: handler.NewAPIKeyHandler(apiKeyUsecase),
+		InvitationHandler: handler.NewInvitationHandler(scope.invitationUsecase),
+		SSOHandler: handler.NewSSOHandler(ssoUsecase),
+		BranchHandler: handler.NewBranchHandler(branchUsecase),
+		HealthHandler: c.healthHandler,
+		AuthMiddleware: middleware.AuthMiddleware(c.tokenManager, c.sessionRepo, scope.apiKeyRepo),
+	}
+	return scope
+}
+
+func tenantURL(template string, tenant *models.Tenant) string {
+	return strings.ReplaceAll(template, "{tenant}", tenant.Slug)
+}
+
+func newPasswordHasher(hashConfig config.PasswordHashConfig) ports.PasswordHasher {
+	switch hashConfig.Algorithm {
+	case "bcrypt":
+		return middleware.NewBcryptHasher(hashConfig.BcryptCost)
+	case "argon2id":
+		params := middleware.DefaultArgon2idParams
+		params.Memory = uint32(hashConfig.Argon2Memory)
+		params.Iterations = uint32(hashConfig.Argon2Iterations)
+		params.Parallelism = uint8(hashConfig.Argon2Parallelism)
+		return middleware.NewArgon2idHasher(params)
+	default:
+		logrus.Fatalf("Unsupported PASSWORD_HASH_ALGORITHM %q, use argon2id or bcrypt", hashConfig.Algorithm)
+		return nil
+	}
+}
+
+func newLoginGuard(sqlDB *sql.DB) *usecase.LoginGuard {
+	loginConfig := config.LoadLoginProtectionConfig()
+	policy := models.LoginPolicy{
+		MaxAttempts:     loginConfig.MaxAttempts,
+		IPMaxAttempts:   loginConfig.IPMaxAttempts,
+		LockoutDuration: loginConfig.LockoutDuration,
+		FreeAttempts:    loginConfig.FreeAttempts,
+		BackoffBase:     loginConfig.BackoffBase,
+		BackoffMax:      loginConfig.BackoffMax,
+		Window:          loginConfig.Window,
+	}
+	if loginConfig.Store == "memory" {
+		logrus.Warn("Failed login attempts are tracked in memory and are not shared between instances")
+		return usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), policy)
+	}
+	return usecase.NewLoginGuard(repository.NewLoginAttemptRepository(sqlDB), policy)
+}
+
+func newMailer(mailConfig config.MailConfig) ports.Mailer {
+	if mailConfig.Driver == "smtp" {
+		logrus.Infof("Sending emails through SMTP server %s", mailConfig.SMTPHost)
+		return mail.NewSMTPMailer(mailConfig.SMTPHost, mailConfig.SMTPPort, mailConfig.SMTPUsername, mailConfig.SMTPPassword, mailConfig.From)
+	}
+	logrus.Warnf("Emails are written to the outbox directory %s instead of being sent", mailConfig.OutboxDir)
+	return mail.NewOutboxMailer(mailConfig.OutboxDir, mailConfig.From)
+}
+
+// newIdentityProvider returns nil when OIDC_ISSUER is not set, which leaves
+// single sign-on disabled.
+func newIdentityProvider(oidcConfig config.OIDCConfig) ports.IdentityProvider {
+	if oidcConfig.Issuer == "" {
+		logrus.Info("Single sign-on is disabled, set OIDC_ISSUER to enable it")
+		return nil
+	}
+	if oidcConfig.ClientID == "" {
+		logrus.Fatal("OIDC_CLIENT_ID must be set when OIDC_ISSUER is")
+	}
+	logrus.Infof("Single sign-on through %s", oidcConfig.Issuer)
+	return oidc.NewProvider(oidcConfig.Issuer, oidcConfig.ClientID, oidcConfig.ClientSecret, oidcConfig.RedirectURL, oidcConfig.Scopes, nil)
+}
+
 type Handlers struct {
+	Tenant 				*models.Tenant
 	PropertyHandler 	*handler.PropertyHandler
 	UserHandler   		*handler.UserHandler
+	PasswordHandler 	*handler.PasswordHandler
+	TwoFactorHandler 	*handler.TwoFactorHandler
+	APIKeyHandler 		*handler.APIKeyHandler
+	InvitationHandler 	*handler.InvitationHandler
+	SSOHandler 			*handler.SSOHandler
+	BranchHandler 		*handler.BranchHandler
+	HealthHandler 		*handler.HealthHandler
+	AuthMiddleware 		gin.HandlerFunc
+}
+
+// Platform is what the tenant router needs besides the handlers of each tenant
+type Platform struct {
+	TenantHandler 		*handler.TenantHandler
 	HealthHandler 		*handler.HealthHandler
+	PlatformAdminToken 	string
+	// ResolveTenant always returns the default tenant unless MULTI_TENANT is set
+	ResolveTenant 		func(r *http.Request) (*models.Tenant, error)
+	HandlersFor 		func(tenant *models.Tenant) *Handlers
 }
 
-func (c *Container) GetHandlers() *Handlers {
-	return &Handlers{
-		PropertyHandler: c.propertyHandler,
-		UserHandler:  c.userHandler,
+func (c *Container) GetPlatform() *Platform {
+	resolveTenant := func(r *http.Request) (*models.Tenant, error) {
+		return c.defaultTenant, nil
+	}
+	if c.tenantConfig.MultiTenant {
+		resolveTenant = c.tenantResolver.Resolve
+	}
+	return &Platform{
+		TenantHandler: c.tenantHandler,
 		HealthHandler: c.healthHandler,
+		PlatformAdminToken: c.tenantConfig.PlatformAdminToken,
+		ResolveTenant: resolveTenant,
+		HandlersFor: func(tenant *models.Tenant) *Handlers {
+			return c.newTenantScope(tenant).handlers
+		},
 	}
-}
\ No newline at end of file
+}
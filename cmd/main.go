@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net/http"
 	"os"
 	"time"
 
@@ -40,12 +41,12 @@ func main() {
 
 	container := di.NewContainer()
 
-	r := api.SetupRouter(container.GetHandlers())
+	router := api.NewTenantRouter(container.GetPlatform())
 
 	port := os.Getenv("SERVER_PORT")
 
 	logrus.Infof("Starting server on port %s", port)
-	if err := r.Run(":" + port); err != nil {
+	if err := http.ListenAndServe(":"+port, router); err != nil {
 		logrus.WithError(err).Fatal("Failed to start server")
 	}
 }
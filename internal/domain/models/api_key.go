@@ -0,0 +1,88 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey lets an integration call the API without a user session. It acts
+// on behalf of the user that created it but only with its own scopes. Only
+// the hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	BranchID   uint       `gorm:"not null;default:0" json:"branch_id"` // Branch of the creator, which limits what the key sees
+	TenantID   uint       `gorm:"not null;default:0;index" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash    string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"size:500;not null" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// APIKeyResponse represents an API key as listed to admins
+type APIKeyResponse struct {
+	ID         uint         `json:"id"`
+	UserID     uint         `json:"user_id"`
+	BranchID   uint         `json:"branch_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	ExpiresAt  *time.Time   `json:"expires_at"`
+	RevokedAt  *time.Time   `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse is returned once, when the key is created
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyData struct {
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) ScopeList() []Permission {
+	scopes := []Permission{}
+	for _, scope := range strings.Split(k.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, Permission(scope))
+		}
+	}
+	return scopes
+}
+
+func (k *APIKey) SetScopes(scopes []Permission) {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	k.Scopes = strings.Join(values, ",")
+}
+
+func (k *APIKey) ToAPIKeyResponse() *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:         k.ID,
+		UserID:     k.UserID,
+		BranchID:   k.BranchID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		LastUsedAt: k.LastUsedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
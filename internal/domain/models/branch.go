@@ -0,0 +1,56 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultBranchName is the branch created for existing data when branches
+// are introduced to a database.
+const DefaultBranchName = "Main office"
+
+// Branch is an office of the agency. Users and properties belong to one, and
+// everyone except regional admins only sees the data of their own branch.
+type Branch struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `gorm:"not null;default:0;uniqueIndex:idx_branches_tenant_name" json:"-"`
+	Name      string    `gorm:"size:191;not null;uniqueIndex:idx_branches_tenant_name" json:"name"`
+	City      string    `gorm:"size:255" json:"city"`
+	Address   string    `gorm:"size:500" json:"address"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+type BranchData struct {
+	Name    string `json:"name"`
+	City    string `json:"city"`
+	Address string `json:"address"`
+}
+
+type UserBranchData struct {
+	BranchID uint `json:"branch_id"`
+}
+
+// BranchScope returns the branch a listing is limited to, 0 meaning every
+// branch. requested is the branch_id filter of the request, 0 when absent.
+// Callers without access to every branch are kept to their own.
+func (c *Caller) BranchScope(requested uint) (uint, error) {
+	if c.Can(PermBranchesAll) {
+		return requested, nil
+	}
+	if c == nil || c.BranchID == 0 {
+		return 0, fmt.Errorf("%w: your account is not assigned to a branch", ErrForbidden)
+	}
+	if requested != 0 && requested != c.BranchID {
+		return 0, fmt.Errorf("%w: you can only see the data of your own branch", ErrForbidden)
+	}
+	return c.BranchID, nil
+}
+
+// CanAccessBranch reports whether the caller may see or change data of the branch.
+func (c *Caller) CanAccessBranch(branchID uint) bool {
+	if c.Can(PermBranchesAll) {
+		return true
+	}
+	return c != nil && c.BranchID != 0 && c.BranchID == branchID
+}
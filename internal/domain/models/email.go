@@ -0,0 +1,8 @@
+package models
+
+// Email is a plain text message handed to a ports.Mailer
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// EmailVerificationToken is a single-use token mailed to Email to prove the
+// user controls it. Email is the address being verified, which differs from
+// the user's current one while an email change is pending.
+type EmailVerificationToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Email     string     `gorm:"size:191;not null" json:"email"`
+	TokenHash string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type VerifyEmailData struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationData struct {
+	Email string `json:"email"`
+}
+
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
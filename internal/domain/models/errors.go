@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrForbidden is wrapped by use cases when the caller is authenticated but
+// not allowed to act on the resource; handlers turn it into a 403.
+var ErrForbidden = errors.New("forbidden")
+
+// ErrUserNotFound is returned for users that do not exist, and for users of
+// another branch so their existence does not leak; handlers turn it into a 404.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrPropertyNotFound is returned for properties that do not exist, and for
+// properties of another branch the caller may not see; handlers turn it into
+// a 404.
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrInvalidAgent is wrapped when a property is assigned to a user that cannot
+// take it; handlers turn it into a 400.
+var ErrInvalidAgent = errors.New("invalid agent")
+
+// ErrStaleVersion is wrapped when a write names a version of the resource
+// that is no longer current; handlers turn it into a 412.
+var ErrStaleVersion = errors.New("the resource was changed since it was read")
+
+// ErrTooManyAttempts is returned while logins for an account or client are
+// throttled after repeated failures.
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// LoginLockedError carries how long the caller must wait before trying to log
+// in again; handlers expose it as a Retry-After header.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// ErrEmailNotVerified is returned by Login until the user has confirmed their
+// email address or an admin has verified it for them.
+var ErrEmailNotVerified = errors.New("email address has not been verified")
+
+// ErrAccountDeactivated is returned by Login for users an admin deactivated.
+var ErrAccountDeactivated = errors.New("account has been deactivated")
+
+// ErrEmailTaken and ErrUsernameTaken are returned when another user of the
+// tenant already has the email or username; handlers turn them into a 409.
+var (
+	ErrEmailTaken    = errors.New("a user with this email already exists")
+	ErrUsernameTaken = errors.New("a user with this username already exists")
+)
+
+// ErrUserHasProperties prevents purging a user that properties still point
+// to as agent or owner; handlers turn it into a 409.
+var ErrUserHasProperties = errors.New("user is still assigned to properties")
+
+// ErrMailNotSent is wrapped when an email could not be handed to the mailer.
+var ErrMailNotSent = errors.New("the email could not be sent")
+
+// ErrSSONotConfigured is returned by the single sign-on endpoints when no
+// identity provider is configured.
+var ErrSSONotConfigured = errors.New("single sign-on is not configured")
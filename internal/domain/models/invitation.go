@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationExpired  InvitationStatus = "expired"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation is how new staff join: an admin invites an email with a role
+// and the invitee chooses a username and password when accepting. Only the
+// hash of the mailed token is stored, so resending invalidates the old link.
+// The status is derived from the timestamps.
+type Invitation struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"size:191;not null;index" json:"email"`
+	Role       UserRole   `gorm:"size:20;not null" json:"role"`
+	BranchID   uint       `gorm:"not null;default:0" json:"branch_id"`
+	TenantID   uint       `gorm:"not null;default:0;index" json:"-"`
+	TokenHash  string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	InvitedBy  *uint      `gorm:"index" json:"invited_by"` // Nil when the platform invited the first admin of a tenant or the inviter was purged
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	UserID     *uint      `json:"user_id"` // Account created on acceptance
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	Inviter    *User      `gorm:"foreignKey:InvitedBy" json:"-"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// InvitationResponse represents an invitation as listed to admins
+type InvitationResponse struct {
+	ID         uint             `json:"id"`
+	Email      string           `json:"email"`
+	Role       UserRole         `json:"role"`
+	BranchID   uint             `json:"branch_id"`
+	Status     InvitationStatus `json:"status"`
+	InvitedBy  *uint            `json:"invited_by"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+	AcceptedAt *time.Time       `json:"accepted_at"`
+	RevokedAt  *time.Time       `json:"revoked_at"`
+	UserID     *uint            `json:"user_id"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type CreateInvitationData struct {
+	Email string   `json:"email"`
+	Role  UserRole `json:"role"`
+	// BranchID defaults to the branch of the admin sending the invitation
+	BranchID uint `json:"branch_id"`
+}
+
+type AcceptInvitationData struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (i *Invitation) Status(now time.Time) InvitationStatus {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+func (i *Invitation) ToInvitationResponse(now time.Time) *InvitationResponse {
+	return &InvitationResponse{
+		ID:         i.ID,
+		Email:      i.Email,
+		Role:       i.Role,
+		BranchID:   i.BranchID,
+		Status:     i.Status(now),
+		InvitedBy:  i.InvitedBy,
+		ExpiresAt:  i.ExpiresAt,
+		AcceptedAt: i.AcceptedAt,
+		RevokedAt:  i.RevokedAt,
+		UserID:     i.UserID,
+		CreatedAt:  i.CreatedAt,
+	}
+}
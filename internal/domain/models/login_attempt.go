@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// LoginAttempt counts consecutive failed logins for a key, which is either an
+// account ("email:<address>") or a client ("ip:<address>").
+type LoginAttempt struct {
+	Key          string     `gorm:"column:attempt_key;primaryKey;size:191" json:"key"`
+	Failures     int        `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// LoginPolicy holds the brute-force protection thresholds.
+type LoginPolicy struct {
+	// MaxAttempts failures lock the account for LockoutDuration
+	MaxAttempts int
+	// IPMaxAttempts failures from one IP lock that IP for LockoutDuration
+	IPMaxAttempts   int
+	LockoutDuration time.Duration
+	// FreeAttempts failures are allowed before the backoff starts; each
+	// further failure doubles the wait, starting at BackoffBase
+	FreeAttempts int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// Window after which a key's failures are forgotten
+	Window time.Duration
+}
+
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
+
+// LockFor returns how long a key must wait after its failures-th consecutive
+// failure; zero means the next attempt is allowed right away.
+func (p LoginPolicy) LockFor(failures int, maxAttempts int) time.Duration {
+	if maxAttempts > 0 && failures >= maxAttempts {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts || p.BackoffBase <= 0 {
+		return 0
+	}
+
+	delay := p.BackoffBase
+	for i := p.FreeAttempts + 1; i < failures && (p.BackoffMax <= 0 || delay < p.BackoffMax); i++ {
+		delay *= 2
+	}
+	if p.BackoffMax > 0 && delay > p.BackoffMax {
+		return p.BackoffMax
+	}
+	return delay
+}
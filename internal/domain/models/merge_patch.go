@@ -0,0 +1,117 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrInvalidPatch is wrapped when a merge patch is malformed or changes a
+// field that cannot be patched; handlers turn it into a 400.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// PropertyPatchFields and UserPatchFields are the fields PATCH may change,
+// the same ones PUT replaces.
+var (
+	PropertyPatchFields = []string{
+		"title", "listing_date", "address", "neighborhood", "city", "zone", "reference", "price",
+		"construction_m2", "land_m2", "is_occupied", "is_furnished", "floors", "bedrooms", "bathrooms",
+		"garage_size", "garden_m2", "gas_types", "amenities", "extras", "utilities", "notes",
+		"owner_id", "user_id", "branch_id", "property_type", "transaction_type", "status",
+		"price_change_reason",
+	}
+	UserPatchFields = []string{"username", "email"}
+)
+
+// MergePatch is a JSON Merge Patch (RFC 7396) document: every member
+// replaces the field of the same name and null removes it, which resets the
+// field to its zero value.
+type MergePatch map[string]json.RawMessage
+
+// ParseMergePatch reads a merge patch. Any JSON is a valid merge patch, but
+// only an object can patch a resource without replacing it whole.
+func ParseMergePatch(body []byte) (MergePatch, error) {
+	var patch MergePatch
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		return nil, fmt.Errorf("%w: the body must be a JSON object", ErrInvalidPatch)
+	}
+	return patch, nil
+}
+
+// Fields returns the names of the patched fields in alphabetical order
+func (p MergePatch) Fields() []string {
+	fields := make([]string, 0, len(p))
+	for field := range p {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Apply merges the patch into target, a pointer to a struct, through its
+// JSON representation. Patching a field outside writable is an error and
+// leaves target untouched.
+func (p MergePatch) Apply(target any, writable []string) error {
+	for _, field := range p.Fields() {
+		if !containsString(writable, field) {
+			return fmt.Errorf("%w: %q cannot be patched", ErrInvalidPatch, field)
+		}
+	}
+
+	current, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	merged, err := mergeJSON(current, patch)
+	if err != nil {
+		return err
+	}
+
+	// Decoding into a zeroed value makes removed fields zero instead of
+	// keeping their current value
+	result := reflect.New(reflect.TypeOf(target).Elem())
+	if err := json.Unmarshal(merged, result.Interface()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	reflect.ValueOf(target).Elem().Set(result.Elem())
+	return nil
+}
+
+// mergeJSON is the MergePatch algorithm of RFC 7396, section 2
+func mergeJSON(target, patch json.RawMessage) (json.RawMessage, error) {
+	var patchObject map[string]json.RawMessage
+	if !isJSONObject(patch) || json.Unmarshal(patch, &patchObject) != nil {
+		return patch, nil
+	}
+
+	targetObject := map[string]json.RawMessage{}
+	if isJSONObject(target) {
+		if err := json.Unmarshal(target, &targetObject); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range patchObject {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(targetObject, name)
+			continue
+		}
+		merged, err := mergeJSON(targetObject[name], value)
+		if err != nil {
+			return nil, err
+		}
+		targetObject[name] = merged
+	}
+	return json.Marshal(targetObject)
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '{'
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ErrInvalidPage is wrapped when limit, offset, cursor or sort of a listing
+// are malformed; handlers turn it into a 400.
+var ErrInvalidPage = errors.New("invalid page request")
+
+// PropertySortFields and UserSortFields are the fields listings can be sorted
+// by. Rows with equal values are ordered by ID. Full-text property searches
+// can also be sorted by relevance, see PropertyFilter.Query.
+var (
+	PropertySortFields     = []string{"price", "created_at", "updated_at", "bedrooms", "bathrooms", "construction_m2", "land_m2", "title"}
+	PropertyTextSortFields = append([]string{"relevance"}, PropertySortFields...)
+	UserSortFields         = []string{"created_at", "updated_at", "username", "email", "full_name"}
+)
+
+// PageRequest selects one page of a listing. A page is addressed either by
+// Offset or by the Cursor of the previous page, which stays stable while
+// rows are added or removed; the two cannot be combined.
+type PageRequest struct {
+	Limit  int
+	Offset int
+	Cursor string
+	// Sort is a comma separated list of fields, descending when prefixed with "-"
+	Sort string
+
+	// SortFields and After are filled in by Normalize
+	SortFields []SortField
+	After      *Cursor
+}
+
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// Cursor is the keyset position of the last row of a page: the values of
+// its sort fields and its ID.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	ID     uint   `json:"id"`
+}
+
+// Pagination describes a page in the response envelope of a listing
+type Pagination struct {
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items []T
+	Pagination
+}
+
+// Normalize applies the defaults and checks the request against the fields
+// the listing can be sorted by. Without a sort the cursor's is kept, so
+// following next_cursor alone continues the same order.
+func (p *PageRequest) Normalize(sortable []string, defaultSort string) error {
+	if p.Limit == 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit < 0 || p.Limit > MaxPageLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPage, MaxPageLimit)
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("%w: offset cannot be negative", ErrInvalidPage)
+	}
+
+	if p.Cursor != "" {
+		if p.Offset != 0 {
+			return fmt.Errorf("%w: offset and cursor cannot be combined", ErrInvalidPage)
+		}
+		cursor, err := DecodeCursor(p.Cursor)
+		if err != nil {
+			return err
+		}
+		if p.Sort == "" {
+			p.Sort = cursor.Sort
+		}
+		p.After = cursor
+	}
+	if p.Sort == "" {
+		p.Sort = defaultSort
+	}
+
+	fields, err := parseSort(p.Sort, sortable)
+	if err != nil {
+		return err
+	}
+	p.SortFields = fields
+	p.Sort = FormatSort(fields)
+
+	if p.After != nil && (p.After.Sort != p.Sort || len(p.After.Values) != len(fields)) {
+		return fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidPage)
+	}
+	return nil
+}
+
+func parseSort(raw string, sortable []string) ([]SortField, error) {
+	fields := []SortField{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		field := SortField{Name: strings.TrimLeft(part, "+-"), Desc: strings.HasPrefix(part, "-")}
+		if !containsString(sortable, field.Name) {
+			return nil, fmt.Errorf("%w: cannot sort by %q, use one of %s", ErrInvalidPage, field.Name, strings.Join(sortable, ", "))
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("%w: %q is sorted by more than once", ErrInvalidPage, field.Name)
+		}
+		seen[field.Name] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// FormatSort is the inverse of the sort parsing done by Normalize
+func FormatSort(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Name
+		if field.Desc {
+			parts[i] = "-" + field.Name
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Encode returns the opaque form handed out as next_cursor
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(encoded string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+	}
+	return &cursor, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token mailed to a user who forgot their
+// password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type ForgotPasswordData struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordData struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+type ChangePasswordData struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
@@ -0,0 +1,119 @@
+package models
+
+type UserRole string
+
+const (
+	RoleAdmin     UserRole = "admin"
+	RoleAgent     UserRole = "agent"
+	RoleAssistant UserRole = "assistant"
+	// RoleRegionalAdmin is an admin for every branch, other roles only see their own
+	RoleRegionalAdmin UserRole = "regional_admin"
+)
+
+// Permission is an action on a resource, written as "resource:action"
+type Permission string
+
+const (
+	PermUsersRead        Permission = "users:read"
+	PermUsersManage      Permission = "users:manage"
+	PermPropertiesRead   Permission = "properties:read"
+	PermPropertiesWrite  Permission = "properties:write"
+	PermPropertiesDelete Permission = "properties:delete"
+	PermPropertiesAssign Permission = "properties:assign"
+	PermLeadsWrite       Permission = "leads:write"
+	PermAPIKeysManage    Permission = "api_keys:manage"
+	PermBranchesManage   Permission = "branches:manage"
+	// PermBranchesAll lifts the restriction to the caller's own branch
+	PermBranchesAll Permission = "branches:all"
+)
+
+// apiKeyScopes are the permissions an API key can be granted. Managing users
+// and API keys stays with people.
+var apiKeyScopes = []Permission{
+	PermUsersRead,
+	PermPropertiesRead, PermPropertiesWrite, PermPropertiesDelete, PermPropertiesAssign,
+	PermLeadsWrite,
+}
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleRegionalAdmin: {
+		PermUsersRead, PermUsersManage,
+		PermPropertiesRead, PermPropertiesWrite, PermPropertiesDelete, PermPropertiesAssign,
+		PermLeadsWrite, PermAPIKeysManage,
+		PermBranchesManage, PermBranchesAll,
+	},
+	RoleAdmin: {
+		PermUsersRead, PermUsersManage,
+		PermPropertiesRead, PermPropertiesWrite, PermPropertiesDelete, PermPropertiesAssign,
+		PermLeadsWrite, PermAPIKeysManage,
+	},
+	RoleAgent: {
+		PermUsersRead,
+		PermPropertiesRead, PermPropertiesWrite, PermPropertiesDelete,
+		PermLeadsWrite,
+	},
+	RoleAssistant: {
+		PermUsersRead,
+		PermPropertiesRead, PermPropertiesWrite,
+	},
+}
+
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r UserRole) HasPermission(permission Permission) bool {
+	return containsPermission(rolePermissions[r], permission)
+}
+
+// IsAPIKeyScope reports whether the permission can be granted to an API key.
+func (p Permission) IsAPIKeyScope() bool {
+	return containsPermission(apiKeyScopes, p)
+}
+
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, granted := range permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresTwoFactor reports whether accounts with this role must use
+// two-factor authentication. Admins can read owner contact data.
+func (r UserRole) RequiresTwoFactor() bool {
+	return r == RoleAdmin || r == RoleRegionalAdmin
+}
+
+// Caller is the authenticated identity behind a request. Requests made with
+// an API key have no session or role, only the key's scopes.
+type Caller struct {
+	UserID    uint
+	SessionID uint
+	Role      UserRole
+	APIKeyID  uint
+	Scopes    []Permission
+	// BranchID is the caller's own branch, 0 for regional admins without one
+	BranchID uint
+}
+
+func (c *Caller) IsAdmin() bool {
+	return c != nil && (c.Role == RoleAdmin || c.Role == RoleRegionalAdmin)
+}
+
+func (c *Caller) IsAPIKey() bool {
+	return c != nil && c.APIKeyID != 0
+}
+
+// Can checks the permission against the API key scopes or else the role.
+func (c *Caller) Can(permission Permission) bool {
+	if c == nil {
+		return false
+	}
+	if c.IsAPIKey() {
+		return containsPermission(c.Scopes, permission)
+	}
+	return c.Role.HasPermission(permission)
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxFullNameLength      = 150
+	maxLicenseNumberLength = 50
+	maxBioLength           = 1000
+	maxAvatarURLLength     = 500
+)
+
+// e164Pattern is an international number: a plus sign, a country code that
+// does not start with 0 and at most 15 digits in total.
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// phoneSeparators are stripped before validating, so "+52 (55) 1234-5678"
+// is stored as "+525512345678".
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// UserProfile is what a listing shows about the agent handling a property.
+// It is embedded in User and UserResponse and edited through /users/me.
+type UserProfile struct {
+	FullName       string  `gorm:"size:150;not null;default:''" json:"full_name"`
+	Phone          string  `gorm:"size:20;not null;default:''" json:"phone"`
+	WhatsApp       string  `gorm:"column:whatsapp;size:20;not null;default:''" json:"whatsapp"`
+	LicenseNumber  string  `gorm:"size:50;not null;default:''" json:"license_number"`
+	Bio            string  `gorm:"size:1000;not null;default:''" json:"bio"`
+	AvatarURL      string  `gorm:"size:500;not null;default:''" json:"avatar_url"`
+	CommissionRate float64 `gorm:"type:decimal(5,2);not null;default:0" json:"commission_rate"` // Default commission, in percent
+}
+
+// NormalizePhone strips separators from a phone number and checks that it is
+// in international E.164 format. An empty number is allowed.
+func NormalizePhone(phone string) (string, error) {
+	normalized := phoneSeparators.Replace(strings.TrimSpace(phone))
+	if normalized == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(normalized, "00") {
+		normalized = "+" + strings.TrimPrefix(normalized, "00")
+	}
+	if !e164Pattern.MatchString(normalized) {
+		return "", fmt.Errorf("invalid phone number %q, use the international format, e.g. +52 55 1234 5678", phone)
+	}
+	return normalized, nil
+}
+
+// Normalize trims the profile, normalizes its phone numbers and validates it.
+func (p *UserProfile) Normalize() error {
+	p.FullName = strings.TrimSpace(p.FullName)
+	p.LicenseNumber = strings.TrimSpace(p.LicenseNumber)
+	p.Bio = strings.TrimSpace(p.Bio)
+	p.AvatarURL = strings.TrimSpace(p.AvatarURL)
+
+	var err error
+	if p.Phone, err = NormalizePhone(p.Phone); err != nil {
+		return err
+	}
+	if p.WhatsApp, err = NormalizePhone(p.WhatsApp); err != nil {
+		return err
+	}
+
+	if utf8.RuneCountInString(p.FullName) > maxFullNameLength {
+		return fmt.Errorf("full name cannot be longer than %d characters", maxFullNameLength)
+	}
+	if utf8.RuneCountInString(p.LicenseNumber) > maxLicenseNumberLength {
+		return fmt.Errorf("license number cannot be longer than %d characters", maxLicenseNumberLength)
+	}
+	if utf8.RuneCountInString(p.Bio) > maxBioLength {
+		return fmt.Errorf("bio cannot be longer than %d characters", maxBioLength)
+	}
+	if p.AvatarURL != "" {
+		avatar, err := url.Parse(p.AvatarURL)
+		if err != nil || (avatar.Scheme != "http" && avatar.Scheme != "https") || avatar.Host == "" || len(p.AvatarURL) > maxAvatarURLLength {
+			return errors.New("avatar must be an http or https image URL")
+		}
+	}
+	if p.CommissionRate < 0 || p.CommissionRate > 100 {
+		return errors.New("commission rate must be between 0 and 100 percent")
+	}
+	return nil
+}
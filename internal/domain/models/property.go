@@ -17,6 +17,7 @@ type Property struct {
 	Zone			string             `gorm:"size:255" json:"zone"`
     Reference       string             `gorm:"size:500" json:"reference"`
     Price           float64            `gorm:"not null" json:"price"`
+    OriginalPrice   float64            `gorm:"not null;default:0" json:"-"` // Price when first listed
     ConstructionM2  int                `gorm:"default:0" json:"construction_m2"`
     LandM2          int                `gorm:"default:0" json:"land_m2"`
     IsOccupied      bool               `gorm:"default:false" json:"is_occupied"`
@@ -33,12 +34,20 @@ type Property struct {
     Notes           string             `gorm:"type:text" json:"notes"`
     OwnerID         uint               `gorm:"not null" json:"owner_id"`
     UserID          uint               `gorm:"not null" json:"user_id"`
+    BranchID        uint               `gorm:"not null;default:0;index" json:"branch_id"`
+    TenantID        uint               `gorm:"not null;default:0;index" json:"-"`
 	PropertyType    PropertyType       `gorm:"not null" json:"property_type"`
     TransactionType TransactionType    `gorm:"not null" json:"transaction_type"`
     Status          PropertyStatus     `gorm:"default:'available'" json:"status"`
     CreatedAt       time.Time          `gorm:"autoCreateTime" json:"created_at"`
     UpdatedAt       time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+    Version         uint               `gorm:"not null;default:1" json:"version"` // Bumped by every write, served as the ETag
     DeletedAt       *time.Time         `gorm:"index" json:"-"`
+    // PriceChangeReason optionally explains a new price in the price history
+    PriceChangeReason string           `gorm:"-" json:"price_change_reason,omitempty"`
+    // PriceChange is set by the use case when a write changes the price, the
+    // repository records it with the write
+    PriceChange     *PropertyPriceChange `gorm:"-" json:"-"`
 	Owner           *User              `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
 	User            *User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
@@ -70,7 +79,18 @@ type PropertyResponse struct {
     Status          PropertyStatus  `json:"status"`
     CreatedAt       time.Time       `json:"created_at"`
     UpdatedAt       time.Time       `json:"updated_at"`
+    Version         uint            `json:"version"`
+    AgentID         uint            `json:"agent_id"`
+    BranchID        uint            `json:"branch_id"`
+    BranchName      string          `json:"branch_name,omitempty"`
+    Relevance       float64         `json:"relevance,omitempty"` // How well a full-text search matched, higher first
     Agent          	*UserResponse   `json:"agent,omitempty"` // Agent handling the property
+    PriceComparison
+}
+
+// PropertyAssignmentData is the body of the admin-only reassignment endpoint
+type PropertyAssignmentData struct {
+    UserID uint `json:"user_id"`
 }
 
 // PropertyCard represents a simplified property view for listings
@@ -87,6 +107,7 @@ type PropertyCard struct {
     TransactionType TransactionType `json:"transaction_type"`
     Status          PropertyStatus  `json:"status"`
     CreatedAt       time.Time       `json:"created_at"`
+    PriceReduced    bool            `json:"price_reduced"`
 }
 
 // Enums for better type safety
@@ -172,6 +193,10 @@ func (p *Property) ToResponse() *PropertyResponse {
         Status:          p.Status,
         CreatedAt:       p.CreatedAt,
         UpdatedAt:       p.UpdatedAt,
+        Version:         p.Version,
+        AgentID:         p.UserID,
+        BranchID:        p.BranchID,
+        PriceComparison: ComparePrice(p.Price, p.OriginalPrice),
     }
     
     if p.User != nil && p.User.ID != 0 {
@@ -181,6 +206,42 @@ func (p *Property) ToResponse() *PropertyResponse {
     return response
 }
 
+// ToProperty is the inverse of ToResponse. Fields the response leaves out,
+// like notes or owner_id, are zero.
+func (r *PropertyResponse) ToProperty() *Property {
+    return &Property{
+        ID:              r.ID,
+        Title:           r.Title,
+        Address:         r.Address,
+        Neighborhood:    r.Neighborhood,
+        City:            r.City,
+        Zone:            r.Zone,
+        Price:           r.Price,
+        ConstructionM2:  r.ConstructionM2,
+        LandM2:          r.LandM2,
+        IsOccupied:      r.IsOccupied,
+        IsFurnished:     r.IsFurnished,
+        Floors:          r.Floors,
+        Bedrooms:        r.Bedrooms,
+        Bathrooms:       r.Bathrooms,
+        GarageSize:      r.GarageSize,
+        GardenM2:        r.GardenM2,
+        GasTypes:        r.GasTypes,
+        Amenities:       r.Amenities,
+        Extras:          r.Extras,
+        Utilities:       r.Utilities,
+        PropertyType:    r.PropertyType,
+        TransactionType: r.TransactionType,
+        Status:          r.Status,
+        CreatedAt:       r.CreatedAt,
+        UpdatedAt:       r.UpdatedAt,
+        Version:         r.Version,
+        UserID:          r.AgentID,
+        BranchID:        r.BranchID,
+        OriginalPrice:   r.OriginalPrice,
+    }
+}
+
 func (p *Property) ToCard() *PropertyCard {
     return &PropertyCard{
         ID:              p.ID,
@@ -195,5 +256,6 @@ func (p *Property) ToCard() *PropertyCard {
         TransactionType: p.TransactionType,
         Status:          p.Status,
         CreatedAt:       p.CreatedAt,
+        PriceReduced:    ComparePrice(p.Price, p.OriginalPrice).PriceReduced,
     }
 }
\ No newline at end of file
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidFilter is wrapped when a property search has a malformed
+// criterion; handlers turn it into a 400.
+var ErrInvalidFilter = errors.New("invalid property filter")
+
+// MaxSearchQueryLength bounds the free text of a property search
+const MaxSearchQueryLength = 200
+
+// PropertyFilter narrows a property search. Empty strings and nil pointers
+// leave a criterion out, ranges include both ends and every listed amenity
+// must be present. BranchID is set from branch_id by the handler. Query is
+// free text matched against the title, address, neighborhood, reference and
+// notes, ignoring case and accents.
+type PropertyFilter struct {
+	BranchID          uint            `form:"-"`
+	Query             string          `form:"q"`
+	City              string          `form:"city"`
+	Neighborhood      string          `form:"neighborhood"`
+	Zone              string          `form:"zone"`
+	PropertyType      PropertyType    `form:"property_type"`
+	TransactionType   TransactionType `form:"transaction_type"`
+	Status            PropertyStatus  `form:"status"`
+	MinPrice          *float64        `form:"min_price"`
+	MaxPrice          *float64        `form:"max_price"`
+	MinBedrooms       *int            `form:"min_bedrooms"`
+	MinBathrooms      *int            `form:"min_bathrooms"`
+	MinConstructionM2 *int            `form:"min_construction_m2"`
+	MaxConstructionM2 *int            `form:"max_construction_m2"`
+	MinLandM2         *int            `form:"min_land_m2"`
+	MaxLandM2         *int            `form:"max_land_m2"`
+	IsFurnished       *bool           `form:"furnished"`
+	IsOccupied        *bool           `form:"occupied"`
+	Amenities         []string        `form:"amenities"`
+}
+
+func (t PropertyType) IsValid() bool {
+	switch t {
+	case TypeHouse, TypeApartment, TypeLand, TypeCommercial, TypeStorehouse, TypeOffice, TypeIndustrial, TypeOther:
+		return true
+	}
+	return false
+}
+
+func (t TransactionType) IsValid() bool {
+	return t == TransactionSale || t == TransactionRental
+}
+
+func (s PropertyStatus) IsValid() bool {
+	switch s {
+	case StatusAvailable, StatusSold, StatusRented, StatusReserved:
+		return true
+	}
+	return false
+}
+
+// Normalize trims the text criteria, collapses the spaces of the query,
+// splits comma separated amenities and checks that enums are known and
+// ranges are not inverted.
+func (f *PropertyFilter) Normalize() error {
+	f.Query = strings.Join(strings.Fields(f.Query), " ")
+	f.City = strings.TrimSpace(f.City)
+	f.Neighborhood = strings.TrimSpace(f.Neighborhood)
+	f.Zone = strings.TrimSpace(f.Zone)
+
+	amenities := []string{}
+	for _, value := range f.Amenities {
+		for _, amenity := range strings.Split(value, ",") {
+			if amenity = strings.TrimSpace(amenity); amenity != "" {
+				amenities = append(amenities, amenity)
+			}
+		}
+	}
+	f.Amenities = amenities
+
+	if utf8.RuneCountInString(f.Query) > MaxSearchQueryLength {
+		return fmt.Errorf("%w: q cannot exceed %d characters", ErrInvalidFilter, MaxSearchQueryLength)
+	}
+	if f.PropertyType != "" && !f.PropertyType.IsValid() {
+		return fmt.Errorf("%w: unknown property_type %q", ErrInvalidFilter, f.PropertyType)
+	}
+	if f.TransactionType != "" && !f.TransactionType.IsValid() {
+		return fmt.Errorf("%w: unknown transaction_type %q", ErrInvalidFilter, f.TransactionType)
+	}
+	if f.Status != "" && !f.Status.IsValid() {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidFilter, f.Status)
+	}
+
+	if f.MinPrice != nil && *f.MinPrice < 0 || f.MaxPrice != nil && *f.MaxPrice < 0 {
+		return fmt.Errorf("%w: prices cannot be negative", ErrInvalidFilter)
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return fmt.Errorf("%w: min_price cannot exceed max_price", ErrInvalidFilter)
+	}
+	for _, criterion := range []struct {
+		name  string
+		value *int
+	}{
+		{"min_bedrooms", f.MinBedrooms},
+		{"min_bathrooms", f.MinBathrooms},
+		{"min_construction_m2", f.MinConstructionM2},
+		{"max_construction_m2", f.MaxConstructionM2},
+		{"min_land_m2", f.MinLandM2},
+		{"max_land_m2", f.MaxLandM2},
+	} {
+		if criterion.value != nil && *criterion.value < 0 {
+			return fmt.Errorf("%w: %s cannot be negative", ErrInvalidFilter, criterion.name)
+		}
+	}
+	if f.MinConstructionM2 != nil && f.MaxConstructionM2 != nil && *f.MinConstructionM2 > *f.MaxConstructionM2 {
+		return fmt.Errorf("%w: min_construction_m2 cannot exceed max_construction_m2", ErrInvalidFilter)
+	}
+	if f.MinLandM2 != nil && f.MaxLandM2 != nil && *f.MinLandM2 > *f.MaxLandM2 {
+		return fmt.Errorf("%w: min_land_m2 cannot exceed max_land_m2", ErrInvalidFilter)
+	}
+	return nil
+}
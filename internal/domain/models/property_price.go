@@ -0,0 +1,42 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PropertyPriceChange records who changed the price of a property, when and
+// optionally why.
+type PropertyPriceChange struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PropertyID uint      `gorm:"not null;index" json:"property_id"`
+	TenantID   uint      `gorm:"not null;default:0;index" json:"-"`
+	OldPrice   float64   `gorm:"not null" json:"old_price"`
+	NewPrice   float64   `gorm:"not null" json:"new_price"`
+	Reason     string    `gorm:"size:500" json:"reason,omitempty"`
+	ChangedBy  uint      `gorm:"not null;index" json:"changed_by"`
+	ChangedAt  time.Time `gorm:"not null" json:"changed_at"`
+}
+
+// PriceComparison compares the current price of a property with the price it
+// was first listed at. Properties listed before the original price was kept
+// have none and count as never changed.
+type PriceComparison struct {
+	OriginalPrice      float64 `json:"original_price"`
+	PriceChangePercent float64 `json:"price_change_percent"` // Negative when the price went down
+	PriceReduced       bool    `json:"price_reduced"`
+}
+
+// ComparePrice compares price with the original price, the percentage
+// rounded to two decimals.
+func ComparePrice(price, originalPrice float64) PriceComparison {
+	if originalPrice <= 0 {
+		return PriceComparison{OriginalPrice: price}
+	}
+	percent := (price - originalPrice) / originalPrice * 100
+	return PriceComparison{
+		OriginalPrice:      originalPrice,
+		PriceChangePercent: math.Round(percent*100) / 100,
+		PriceReduced:       price < originalPrice,
+	}
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidStatusTransition is wrapped when a property cannot move to the
+// requested status; handlers turn it into a 409.
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+// statusTransitions lists, per transaction type, the statuses a property can
+// move to from each status. A status missing from a transaction type is never
+// reached by its properties, and a sale is final.
+var statusTransitions = map[TransactionType]map[PropertyStatus][]PropertyStatus{
+	TransactionSale: {
+		StatusAvailable: {StatusReserved, StatusSold},
+		StatusReserved:  {StatusAvailable, StatusSold},
+		StatusSold:      {},
+	},
+	TransactionRental: {
+		StatusAvailable: {StatusReserved, StatusRented},
+		StatusReserved:  {StatusAvailable, StatusRented},
+		StatusRented:    {StatusAvailable},
+	},
+}
+
+// AllowedFor tells whether properties of the transaction type can have the status
+func (s PropertyStatus) AllowedFor(transactionType TransactionType) bool {
+	_, ok := statusTransitions[transactionType][s]
+	return ok
+}
+
+// NextStatuses lists the statuses a property of the transaction type can move
+// to from the status.
+func NextStatuses(transactionType TransactionType, from PropertyStatus) []PropertyStatus {
+	return statusTransitions[transactionType][from]
+}
+
+// CheckStatus tells why properties of the transaction type cannot have the
+// status, or returns nil when they can.
+func CheckStatus(transactionType TransactionType, status PropertyStatus) error {
+	if !transactionType.IsValid() {
+		return fmt.Errorf("%w: unknown transaction_type %q, use sale or rental", ErrInvalidStatusTransition, transactionType)
+	}
+	if !status.IsValid() {
+		return fmt.Errorf("%w: unknown status %q, use one of available, reserved, sold or rented", ErrInvalidStatusTransition, status)
+	}
+	if !status.AllowedFor(transactionType) {
+		return fmt.Errorf("%w: a property for %s cannot be %s", ErrInvalidStatusTransition, transactionType, status)
+	}
+	return nil
+}
+
+// CheckStatusTransition tells why a property of the transaction type cannot
+// move from one status to the other, or returns nil when it can. Properties
+// stored without a status count as available.
+func CheckStatusTransition(transactionType TransactionType, from, to PropertyStatus) error {
+	if from == "" {
+		from = StatusAvailable
+	}
+	if err := CheckStatus(transactionType, to); err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("%w: the property is already %s", ErrInvalidStatusTransition, to)
+	}
+
+	next := NextStatuses(transactionType, from)
+	for _, status := range next {
+		if status == to {
+			return nil
+		}
+	}
+	if len(next) == 0 {
+		return fmt.Errorf("%w: a property for %s that is %s cannot change status", ErrInvalidStatusTransition, transactionType, from)
+	}
+	names := make([]string, len(next))
+	for i, status := range next {
+		names[i] = string(status)
+	}
+	return fmt.Errorf("%w: a property for %s cannot go from %s to %s, only to %s",
+		ErrInvalidStatusTransition, transactionType, from, to, strings.Join(names, " or "))
+}
+
+// PropertyStatusChange records who moved a property to another status, when and why
+type PropertyStatusChange struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	PropertyID uint           `gorm:"not null;index" json:"property_id"`
+	TenantID   uint           `gorm:"not null;default:0;index" json:"-"`
+	FromStatus PropertyStatus `gorm:"not null;size:20" json:"from_status"`
+	ToStatus   PropertyStatus `gorm:"not null;size:20" json:"to_status"`
+	Reason     string         `gorm:"not null;size:500" json:"reason"`
+	ChangedBy  uint           `gorm:"not null;index" json:"changed_by"`
+	ChangedAt  time.Time      `gorm:"not null" json:"changed_at"`
+}
+
+// PropertyStatusRequest is the body of POST /api/v1/properties/:id/status
+type PropertyStatusRequest struct {
+	Status PropertyStatus `json:"status"`
+	Reason string         `json:"reason"`
+}
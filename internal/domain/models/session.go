@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Session is a logged-in device. Each session holds the hash of its current
+// refresh token; the previous hash is kept to detect refresh token reuse.
+type Session struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	PreviousTokenHash *string    `gorm:"size:64;index" json:"-"`
+	UserAgent         string     `gorm:"size:500" json:"user_agent"`
+	IPAddress         string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt        time.Time  `gorm:"not null" json:"last_used_at"`
+	RevokedAt         *time.Time `gorm:"index" json:"revoked_at"`
+	// TwoFactorVerified is set when the login passed a second factor
+	TwoFactorVerified bool      `gorm:"not null;default:false" json:"two_factor_verified"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+	User              *User     `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// SessionResponse represents a device as listed to its owner
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// ClientInfo describes the device a request comes from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type RefreshTokenData struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func (s *Session) ToSessionResponse(currentSessionID uint) *SessionResponse {
+	return &SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.ID == currentSessionID,
+	}
+}
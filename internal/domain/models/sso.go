@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// UserIdentity links an account at an external identity provider to a user.
+// The issuer and subject pair is what the provider guarantees to be stable;
+// Email is only kept to show which address made the link. The same person
+// can be linked to their account at each tenant.
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	TenantID    uint       `gorm:"not null;default:0;uniqueIndex:idx_user_identities_tenant_subject,priority:1" json:"-"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Issuer      string     `gorm:"size:191;not null;uniqueIndex:idx_user_identities_tenant_subject" json:"issuer"`
+	Subject     string     `gorm:"size:191;not null;uniqueIndex:idx_user_identities_tenant_subject" json:"subject"`
+	Email       string     `gorm:"size:191" json:"email"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	User        *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// SSOLoginState keeps the secrets of a single sign-on attempt between the
+// redirect to the identity provider and its callback. Only the hash of the
+// state parameter is stored; the PKCE verifier and nonce never leave the server.
+// A state only completes a login at the tenant that started it.
+type SSOLoginState struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	TenantID     uint       `gorm:"not null;default:0;index" json:"-"`
+	StateHash    string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	CodeVerifier string     `gorm:"not null;size:128" json:"-"`
+	Nonce        string     `gorm:"not null;size:64" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ExternalIdentity is what the identity provider vouches for in a verified ID token.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// SSOAuthorization is returned when a single sign-on starts; the client sends
+// the browser to AuthorizationURL before ExpiresAt.
+type SSOAuthorization struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+func (s *SSOLoginState) IsUsable(now time.Time) bool {
+	return s.UsedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultTenantSlug is the tenant created for existing data when tenants are
+// introduced to a database. Single-tenant deployments serve every request
+// as this tenant.
+const DefaultTenantSlug = "default"
+
+// ErrTenantNotFound is returned when a request names a tenant that does not
+// exist; it is answered with a 404.
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// ErrTenantRequired is returned in multi-tenant mode when a request names no
+// tenant at all; it is answered with a 400.
+var ErrTenantRequired = errors.New("tenant could not be determined from the request")
+
+// Tenant is an agency hosted on the deployment. Users, properties, branches,
+// invitations and API keys belong to exactly one and are never visible to
+// another. Usernames and emails are unique within a tenant, so the same person
+// can have an account at several agencies.
+type Tenant struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Slug      string    `gorm:"size:63;not null;uniqueIndex" json:"slug"` // Subdomain and X-Tenant header value
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TenantData is the body of the provisioning endpoints. AdminEmail is only
+// read on creation, it receives the invitation of the first regional admin.
+type TenantData struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	AdminEmail string `json:"admin_email"`
+}
+
+// TenantAdminData is the body of the endpoint that invites another admin
+type TenantAdminData struct {
+	Email string `json:"email"`
+}
+
+// slugPattern is a lowercase DNS label, so every slug works as a subdomain
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// reservedSlugs are subdomains the platform itself may need
+var reservedSlugs = map[string]bool{"www": true, "api": true, "admin": true}
+
+// NormalizeTenantSlug lowercases and validates a slug.
+func NormalizeTenantSlug(slug string) (string, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugPattern.MatchString(slug) {
+		return "", errors.New("slug must be 1 to 63 lowercase letters, digits or hyphens and cannot start or end with a hyphen")
+	}
+	if reservedSlugs[slug] {
+		return "", errors.New("slug is reserved")
+	}
+	return slug, nil
+}
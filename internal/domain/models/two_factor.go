@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// TwoFactorCredential is the TOTP secret of a user. It is pending until the
+// user proves their authenticator works by confirming a first code.
+type TwoFactorCredential struct {
+	UserID uint   `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Secret string `gorm:"size:64;not null" json:"-"`
+	// LastUsedStep is the last accepted TOTP time step; older or equal steps
+	// are rejected so a code cannot be replayed
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	User         *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the
+// authenticator is lost. Only the SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null;size:64;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorCodeData struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorLoginData completes a login challenge with either a TOTP code or
+// a recovery code.
+type TwoFactorLoginData struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+func (c *TwoFactorCredential) IsEnabled() bool {
+	return c != nil && c.ConfirmedAt != nil
+}
@@ -4,11 +4,20 @@ import "time"
 
 type User struct {
 	ID	     	uint       `gorm:"primaryKey" json:"id"`
-	Username 	string     `gorm:"unique;not null" json:"username"`
-	Email       string     `gorm:"unique;not null" json:"email"`
+	Username 	string     `gorm:"size:191;not null;uniqueIndex:idx_users_tenant_username" json:"username"`
+	Email       string     `gorm:"size:191;not null;uniqueIndex:idx_users_tenant_email" json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // Login is refused while nil
 	Password 	string     `gorm:"not null" json:"password"`
+	Role        UserRole   `gorm:"size:20;not null;default:'assistant'" json:"role"`
+	BranchID    uint       `gorm:"not null;default:0;index" json:"branch_id"`
+	TenantID    uint       `gorm:"not null;default:0;index;uniqueIndex:idx_users_tenant_username,priority:1;uniqueIndex:idx_users_tenant_email,priority:1" json:"-"`
+	UserProfile
 	CreatedAt 	time.Time  `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt 	time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	Version     uint       `gorm:"not null;default:1" json:"version"` // Bumped by every write, served as the ETag
+	// A deactivated user cannot log in but still shows up in listings,
+	// unlike a deleted one
+	DeactivatedAt *time.Time `gorm:"index" json:"deactivated_at"`
 	DeletedAt   *time.Time  `gorm:"index" json:"-"`
 }
 
@@ -17,12 +26,41 @@ type UserLoginData struct {
 	Password string `json:"password"`
 }
 
+type UserRoleData struct {
+	Role UserRole `json:"role"`
+}
+
+// LoginResponse either carries the session tokens or, when the user has
+// two-factor authentication enabled, only the challenge to complete.
+type LoginResponse struct {
+	AccessToken      string        `json:"access_token,omitempty"`
+	TokenType        string        `json:"token_type,omitempty"`
+	ExpiresAt        time.Time     `json:"expires_at,omitzero"`
+	RefreshToken     string        `json:"refresh_token,omitempty"`
+	RefreshExpiresAt time.Time     `json:"refresh_expires_at,omitzero"`
+	User             *UserResponse `json:"user,omitempty"`
+	// Second step of the login, see UserUseCase.CompleteTwoFactorLogin
+	TwoFactorRequired  bool       `json:"two_factor_required,omitempty"`
+	ChallengeToken     string     `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt *time.Time `json:"challenge_expires_at,omitempty"`
+	// Set for roles that must enroll before using the API
+	TwoFactorSetupRequired bool `json:"two_factor_setup_required,omitempty"`
+}
+
 type UserResponse struct {
 	ID        uint   `json:"id"`
 	Username  string `json:"username"`
 	Email     string `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role      UserRole `json:"role"`
+	BranchID  uint     `json:"branch_id"`
+	TenantID  uint     `json:"-"`
+	UserProfile
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   uint      `json:"version"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func (user *User) ToUserResponse() *UserResponse {
@@ -33,7 +71,23 @@ func (user *User) ToUserResponse() *UserResponse {
 		ID:        user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Role:      user.Role,
+		BranchID:  user.BranchID,
+		TenantID:  user.TenantID,
+		UserProfile: user.UserProfile,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
+		Version:   user.Version,
+		DeactivatedAt: user.DeactivatedAt,
+		DeletedAt: user.DeletedAt,
 	}
-}
\ No newline at end of file
+}
+
+func (user *UserResponse) IsEmailVerified() bool {
+	return user.EmailVerifiedAt != nil
+}
+
+func (user *UserResponse) IsDeactivated() bool {
+	return user.DeactivatedAt != nil
+}
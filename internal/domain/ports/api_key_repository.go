@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"time"
+
+	"inmo-backend/internal/domain/models"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) (*models.APIKey, error)
+	GetByHash(hash string) (*models.APIKey, error)
+	// GetAll lists the keys created by a user, or by every user when userID is 0.
+	GetAll(userID uint) ([]models.APIKey, error)
+	// Revoke is restricted to the keys of userID like GetAll, the keys of
+	// other users are not found.
+	Revoke(id uint, userID uint) error
+	// TouchLastUsed records a use of the key. Writes are skipped while the
+	// stored timestamp is less than a minute old.
+	TouchLastUsed(id uint, now time.Time) error
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type APIKeyUseCase interface {
+	CreateAPIKey(caller *models.Caller, keyData *models.CreateAPIKeyData) (*models.CreatedAPIKeyResponse, error)
+	GetAPIKeys(caller *models.Caller) ([]models.APIKeyResponse, error)
+	RevokeAPIKey(caller *models.Caller, id uint) error
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type BranchRepository interface {
+	GetAll() ([]models.Branch, error)
+	GetByID(id uint) (*models.Branch, error)
+	Create(branch *models.Branch) error
+	Update(branch *models.Branch) error
+}
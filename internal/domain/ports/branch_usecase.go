@@ -0,0 +1,10 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type BranchUseCase interface {
+	GetBranches() ([]models.Branch, error)
+	CreateBranch(branchData *models.BranchData) (*models.Branch, error)
+	UpdateBranch(id uint, branchData *models.BranchData) (*models.Branch, error)
+	AssignUser(caller *models.Caller, userID uint, version uint, branchID uint) (*models.UserResponse, error)
+}
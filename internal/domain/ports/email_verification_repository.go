@@ -0,0 +1,13 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type EmailVerificationRepository interface {
+	Create(token *models.EmailVerificationToken) error
+	GetByTokenHash(hash string) (*models.EmailVerificationToken, error)
+	// InvalidateByUserID burns every pending token of the user.
+	InvalidateByUserID(userID uint) error
+	// Consume marks the token used and sets its email as the user's verified
+	// address in a single transaction. It only succeeds once per token.
+	Consume(token *models.EmailVerificationToken) error
+}
@@ -0,0 +1,14 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+// IdentityProvider is an OpenID Connect provider used for single sign-on with
+// the authorization code flow and PKCE.
+type IdentityProvider interface {
+	// AuthCodeURL is where the browser signs in. codeChallenge is the S256
+	// challenge of the verifier later passed to Exchange.
+	AuthCodeURL(state string, nonce string, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and returns the identity of the
+	// verified ID token, which must carry nonce.
+	Exchange(code string, codeVerifier string, nonce string) (*models.ExternalIdentity, error)
+}
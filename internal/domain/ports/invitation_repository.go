@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"time"
+
+	"inmo-backend/internal/domain/models"
+)
+
+type InvitationRepository interface {
+	Create(invitation *models.Invitation) error
+	GetByID(id uint) (*models.Invitation, error)
+	GetByTokenHash(hash string) (*models.Invitation, error)
+	// GetOpenByEmail returns the invitation for the email that was neither
+	// accepted nor revoked, or nil when there is none.
+	GetOpenByEmail(email string) (*models.Invitation, error)
+	// GetAll lists the invitations to a branch, or to every branch when
+	// branchID is 0.
+	GetAll(branchID uint) ([]models.Invitation, error)
+	// Renew replaces the token and expiry of an invitation that is still open.
+	Renew(id uint, tokenHash string, expiresAt time.Time) error
+	Revoke(id uint) error
+	// Accept creates the user and marks the invitation as accepted in a single
+	// transaction. It fails if the token was replaced, revoked or used meanwhile.
+	Accept(id uint, tokenHash string, user *models.User) (*models.UserResponse, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type InvitationUseCase interface {
+	CreateInvitation(caller *models.Caller, invitationData *models.CreateInvitationData) (*models.InvitationResponse, error)
+	GetInvitations(caller *models.Caller) ([]models.InvitationResponse, error)
+	ResendInvitation(caller *models.Caller, id uint) (*models.InvitationResponse, error)
+	RevokeInvitation(caller *models.Caller, id uint) error
+	AcceptInvitation(acceptData *models.AcceptInvitationData) (*models.UserResponse, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"time"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// LoginAttemptStore tracks failed logins. The SQL implementation shares the
+// counters between instances; the in-memory one is for a single instance.
+type LoginAttemptStore interface {
+	// Get returns nil when the key has no recorded failures.
+	Get(key string) (*models.LoginAttempt, error)
+	// RecordFailure adds a failure and returns the updated counter. Failures
+	// older than window are discarded first.
+	RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
@@ -0,0 +1,7 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type Mailer interface {
+	Send(email *models.Email) error
+}
@@ -0,0 +1,14 @@
+package ports
+
+// PasswordHasher is one password hashing algorithm.
+type PasswordHasher interface {
+	// Hash validates the password and returns an encoded hash that carries
+	// the algorithm and its parameters.
+	Hash(password string) (string, error)
+	Verify(hash string, password string) error
+	// Identifies reports whether hash was produced by this algorithm.
+	Identifies(hash string) bool
+	// NeedsRehash reports whether hash, produced by this algorithm, uses
+	// weaker parameters than the hasher is configured with.
+	NeedsRehash(hash string) bool
+}
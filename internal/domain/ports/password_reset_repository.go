@@ -0,0 +1,12 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	GetByTokenHash(hash string) (*models.PasswordResetToken, error)
+	// MarkUsed only succeeds once per token, which keeps tokens single-use.
+	MarkUsed(id uint) error
+	// InvalidateByUserID burns every pending token of the user.
+	InvalidateByUserID(userID uint) error
+}
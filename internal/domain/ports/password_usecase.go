@@ -0,0 +1,9 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type PasswordUseCase interface {
+	ForgotPassword(email string) error
+	ResetPassword(token string, newPassword string) error
+	ChangePassword(caller *models.Caller, userID uint, currentPassword string, newPassword string) error
+}
@@ -2,10 +2,36 @@ package ports
 
 import "inmo-backend/internal/domain/models"
 
+// PropertyRepository is bound to one tenant and never reads or changes the properties
+// of another.
 type PropertyRepository interface {
-	GetAll() ([]models.PropertyResponse, error)
+	// Search lists the properties matching every criterion of the filter, one
+	// page at a time. The page is required and must be normalized; a nil one
+	// fails with models.ErrInvalidPage.
+	Search(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error)
+	// SearchCards is Search reading only the columns of a card.
+	SearchCards(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error)
+	// TextSearch is Search also matching filter.Query against the title,
+	// address, neighborhood, reference and notes, ignoring case and accents.
+	// Every match carries its relevance and the page can be sorted by it.
+	TextSearch(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error)
 	GetByID(id uint) (*models.PropertyResponse, error)
 	Create(property *models.Property) (*models.PropertyResponse, error)
+	// Update replaces the property if it is still at property.Version and
+	// fails with models.ErrStaleVersion otherwise. Every write bumps the version.
+	// A property.PriceChange is recorded in the price history with the write.
 	Update(property *models.Property) (*models.PropertyResponse, error)
-	Delete(id uint) error
+	// Patch is Update writing only the given fields, named as in
+	// models.PropertyPatchFields.
+	Patch(property *models.Property, fields []string) (*models.PropertyResponse, error)
+	// UpdateAgent fails with models.ErrStaleVersion like Update when the
+	// property is no longer at version.
+	UpdateAgent(id uint, version uint, agentID uint) error
+	// ChangeStatus moves the property to change.ToStatus if it is still at
+	// change.FromStatus, and records the change.
+	ChangeStatus(change *models.PropertyStatusChange) (*models.PropertyResponse, error)
+	// GetPriceHistory lists the price changes of the property, oldest first.
+	GetPriceHistory(id uint) ([]models.PropertyPriceChange, error)
+	// Delete is guarded by the version like Update
+	Delete(id uint, version uint) error
 }
\ No newline at end of file
@@ -3,9 +3,14 @@ package ports
 import "inmo-backend/internal/domain/models"
 
 type PropertyUseCase interface {
-	GetAllProperties() ([]models.PropertyResponse, error)
-	GetPropertyByID(id uint) (*models.PropertyResponse, error)
-	CreateProperty(property *models.Property) (*models.PropertyResponse, error)
-	UpdateProperty(property *models.Property) (*models.PropertyResponse, error)
-	DeleteProperty(id uint) error
+	GetAllProperties(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error)
+	GetPropertyCards(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error)
+	GetPropertyByID(caller *models.Caller, id uint) (*models.PropertyResponse, error)
+	GetPriceHistory(caller *models.Caller, id uint) ([]models.PropertyPriceChange, error)
+	CreateProperty(caller *models.Caller, property *models.Property) (*models.PropertyResponse, error)
+	UpdateProperty(caller *models.Caller, property *models.Property) (*models.PropertyResponse, error)
+	PatchProperty(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.PropertyResponse, error)
+	ReassignProperty(caller *models.Caller, id uint, version uint, agentID uint) (*models.PropertyResponse, error)
+	ChangePropertyStatus(caller *models.Caller, id uint, request *models.PropertyStatusRequest) (*models.PropertyResponse, error)
+	DeleteProperty(caller *models.Caller, id uint, version uint) error
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"time"
+
+	"inmo-backend/internal/domain/models"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) (*models.Session, error)
+	GetByID(id uint) (*models.Session, error)
+	// GetByRefreshTokenHash matches either the current or the previous refresh token hash.
+	GetByRefreshTokenHash(hash string) (*models.Session, error)
+	GetActiveByUserID(userID uint) ([]models.Session, error)
+	Rotate(id uint, currentHash string, newHash string, expiresAt time.Time, client models.ClientInfo) error
+	Revoke(id uint) error
+	MarkTwoFactorVerified(id uint) error
+	// RevokeAllByUserID revokes every active session of the user except exceptID (0 revokes all).
+	RevokeAllByUserID(userID uint, exceptID uint) error
+}
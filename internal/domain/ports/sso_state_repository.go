@@ -0,0 +1,10 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type SSOStateRepository interface {
+	Create(state *models.SSOLoginState) error
+	GetByStateHash(hash string) (*models.SSOLoginState, error)
+	// Consume marks the state used. It only succeeds once per state.
+	Consume(id uint) error
+}
@@ -0,0 +1,8 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type SSOUseCase interface {
+	StartLogin() (*models.SSOAuthorization, error)
+	CompleteLogin(state string, code string, client models.ClientInfo) (*models.LoginResponse, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+// TenantRepository is the only repository that is not bound to a tenant
+type TenantRepository interface {
+	GetAll() ([]models.Tenant, error)
+	GetByID(id uint) (*models.Tenant, error)
+	// GetBySlug returns models.ErrTenantNotFound when no tenant has the slug.
+	GetBySlug(slug string) (*models.Tenant, error)
+	Create(tenant *models.Tenant) error
+	Update(tenant *models.Tenant) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type TenantUseCase interface {
+	GetTenants() ([]models.Tenant, error)
+	GetTenant(id uint) (*models.Tenant, error)
+	CreateTenant(tenantData *models.TenantData) (*models.Tenant, error)
+	UpdateTenant(id uint, tenantData *models.TenantData) (*models.Tenant, error)
+	InviteAdmin(id uint, adminData *models.TenantAdminData) (*models.InvitationResponse, error)
+}
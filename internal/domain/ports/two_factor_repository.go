@@ -0,0 +1,19 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type TwoFactorRepository interface {
+	// GetByUserID returns nil when the user never started an enrollment.
+	GetByUserID(userID uint) (*models.TwoFactorCredential, error)
+	// SavePending stores a new unconfirmed secret, replacing any previous one.
+	SavePending(userID uint, secret string) error
+	Confirm(userID uint, step int64) error
+	// UseStep records an accepted TOTP step; it fails when the step is not
+	// newer than the last one, which means the code was replayed.
+	UseStep(userID uint, step int64) error
+	// Delete removes the credential and the recovery codes.
+	Delete(userID uint) error
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	// UseRecoveryCode consumes an unused recovery code of the user.
+	UseRecoveryCode(userID uint, codeHash string) error
+}
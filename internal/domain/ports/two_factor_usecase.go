@@ -0,0 +1,9 @@
+package ports
+
+import "inmo-backend/internal/domain/models"
+
+type TwoFactorUseCase interface {
+	Enroll(caller *models.Caller) (*models.TwoFactorEnrollment, error)
+	Confirm(caller *models.Caller, code string) (*models.RecoveryCodesResponse, error)
+	Disable(caller *models.Caller, code string) error
+}
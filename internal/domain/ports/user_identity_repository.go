@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"time"
+
+	"inmo-backend/internal/domain/models"
+)
+
+type UserIdentityRepository interface {
+	// GetBySubject returns nil without an error when the identity is not linked yet.
+	GetBySubject(issuer string, subject string) (*models.UserIdentity, error)
+	Create(identity *models.UserIdentity) error
+	TouchLastLogin(id uint, now time.Time) error
+}
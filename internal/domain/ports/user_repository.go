@@ -2,12 +2,45 @@ package ports
 
 import "inmo-backend/internal/domain/models"
 
+// UserRepository is bound to one tenant and never reads or changes the users
+// of another.
 type UserRepository interface {
-	GetAll() ([]models.UserResponse, error)
+	// GetPage lists the users of a branch, or of every branch when branchID
+	// is 0, one page at a time. The page must be normalized.
+	GetPage(branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error)
 	GetByID(id uint) (*models.UserResponse, error)
+	// GetByIDIncludingDeleted is GetByID also finding deleted users.
+	GetByIDIncludingDeleted(id uint) (*models.UserResponse, error)
 	GetByEmail(email string) (*models.UserResponse, error)
 	ConsultPassword(email string) (string, error)
 	Create(user *models.User) (*models.UserResponse, error)
+	// Update changes the user if it is still at user.Version and fails with
+	// models.ErrStaleVersion otherwise. Every write bumps the version.
 	Update(user *models.User) (*models.UserResponse, error)
-	Delete(id uint) error
+	// UpdateRole and UpdateProfile fail with models.ErrStaleVersion like
+	// Update when the user is no longer at version. UpdateRole also revokes
+	// every session and API key of the user.
+	UpdateRole(id uint, version uint, role models.UserRole) error
+	// UpdateBranch is guarded by version like UpdateRole and revokes the
+	// sessions and API keys of the user, which carry the old branch.
+	UpdateBranch(id uint, version uint, branchID uint) error
+	UpdateProfile(id uint, version uint, profile *models.UserProfile) error
+	UpdatePassword(id uint, hashedPassword string) error
+	// MarkEmailVerified verifies the current email of the user, keeping the
+	// original timestamp if it was already verified.
+	MarkEmailVerified(id uint) error
+	// Deactivate blocks the user from logging in and revokes their sessions
+	// and API keys, keeping the account visible.
+	Deactivate(id uint) error
+	// Delete is guarded by the version like Update
+	Delete(id uint, version uint) error
+	// GetDeleted lists the deleted users of a branch, or of every branch when
+	// branchID is 0, most recently deleted first.
+	GetDeleted(branchID uint) ([]models.UserResponse, error)
+	// Restore brings a deleted or deactivated user back to active.
+	Restore(id uint) error
+	// Purge permanently removes a deleted user. It fails with
+	// models.ErrUserHasProperties while any property references the user.
+	// Invitations the user accepted or sent are kept without the reference.
+	Purge(id uint) error
 }
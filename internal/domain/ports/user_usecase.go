@@ -3,10 +3,26 @@ package ports
 import "inmo-backend/internal/domain/models"
 
 type UserUseCase interface {
-	Login(email string, password string) error
-	GetAllUsers() ([]models.UserResponse, error)
-	GetUserByID(id uint) (*models.UserResponse, error)
-	CreateUser(user *models.User) (*models.UserResponse, error)
-	UpdateUser(user *models.User) (*models.UserResponse, error)
-	DeleteUser(id uint) error
+	Login(email string, password string, client models.ClientInfo) (*models.LoginResponse, error)
+	CompleteTwoFactorLogin(challengeToken string, code string, recoveryCode string, client models.ClientInfo) (*models.LoginResponse, error)
+	RefreshSession(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error)
+	Logout(sessionID uint) error
+	GetSessions(userID uint, currentSessionID uint) ([]models.SessionResponse, error)
+	RevokeSession(userID uint, sessionID uint) error
+	GetAllUsers(caller *models.Caller, branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error)
+	GetUserByID(caller *models.Caller, id uint) (*models.UserResponse, error)
+	UpdateUser(caller *models.Caller, user *models.User) (*models.UserResponse, error)
+	PatchUser(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.UserResponse, error)
+	UpdateProfile(userID uint, version uint, profile *models.UserProfile) (*models.UserResponse, error)
+	UpdateUserRole(caller *models.Caller, id uint, version uint, role models.UserRole) (*models.UserResponse, error)
+	UnlockUser(caller *models.Caller, id uint) error
+	VerifyEmail(token string) (*models.UserResponse, error)
+	ResendEmailVerification(email string) error
+	MarkEmailVerified(caller *models.Caller, id uint) (*models.UserResponse, error)
+	EnsureAdmin(admin *models.User) error
+	DeactivateUser(caller *models.Caller, id uint) (*models.UserResponse, error)
+	DeleteUser(caller *models.Caller, id uint, version uint) error
+	GetDeletedUsers(caller *models.Caller, branchID uint) ([]models.UserResponse, error)
+	RestoreUser(caller *models.Caller, id uint) (*models.UserResponse, error)
+	PurgeUser(caller *models.Caller, id uint) error
 }
\ No newline at end of file
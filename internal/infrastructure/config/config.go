@@ -0,0 +1,239 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type AuthConfig struct {
+	JWTSecret       string
+	JWTIssuer       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// TOTPIssuer is the account label shown in authenticator apps
+	TOTPIssuer string
+	// Bootstrap admin, created or promoted on startup when AdminEmail is set
+	AdminEmail    string
+	AdminUsername string
+	AdminPassword string
+}
+
+func LoadAuthConfig() AuthConfig {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		logrus.Fatal("JWT_SECRET must be set to sign access tokens")
+	}
+
+	return AuthConfig{
+		JWTSecret:       secret,
+		JWTIssuer:       GetEnv("JWT_ISSUER", "inmo-backend"),
+		AccessTokenTTL:  GetDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL: GetDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+		TOTPIssuer:      GetEnv("TOTP_ISSUER", "Inmo"),
+		AdminEmail:      os.Getenv("ADMIN_EMAIL"),
+		AdminUsername:   GetEnv("ADMIN_USERNAME", "admin"),
+		AdminPassword:   os.Getenv("ADMIN_PASSWORD"),
+	}
+}
+
+type MailConfig struct {
+	// Driver is "smtp" or "outbox"; the outbox writes .eml files to OutboxDir
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutboxDir    string
+}
+
+func LoadMailConfig() MailConfig {
+	return MailConfig{
+		Driver:       GetEnv("MAIL_DRIVER", "outbox"),
+		From:         GetEnv("MAIL_FROM", "no-reply@inmo-backend.local"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     GetEnv("SMTP_PORT", "587"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		OutboxDir:    GetEnv("MAIL_OUTBOX_DIR", "outbox"),
+	}
+}
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	// ResetURL is the frontend page that receives the token as ?token=
+	ResetURL string
+}
+
+func LoadPasswordResetConfig() PasswordResetConfig {
+	return PasswordResetConfig{
+		TokenTTL: GetDuration("PASSWORD_RESET_TTL", time.Hour),
+		ResetURL: GetEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+	}
+}
+
+type InvitationConfig struct {
+	TokenTTL time.Duration
+	// AcceptURL is the frontend page that receives the token as ?token=
+	AcceptURL string
+}
+
+func LoadInvitationConfig() InvitationConfig {
+	return InvitationConfig{
+		TokenTTL:  GetDuration("INVITATION_TTL", 72*time.Hour),
+		AcceptURL: GetEnv("INVITATION_URL", "http://localhost:3000/accept-invitation"),
+	}
+}
+
+type EmailVerificationConfig struct {
+	TokenTTL time.Duration
+	// VerifyURL is the frontend page that receives the token as ?token=
+	VerifyURL string
+}
+
+func LoadEmailVerificationConfig() EmailVerificationConfig {
+	return EmailVerificationConfig{
+		TokenTTL:  GetDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		VerifyURL: GetEnv("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email"),
+	}
+}
+
+type OIDCConfig struct {
+	// Issuer of the OpenID Connect provider; single sign-on is off when empty
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback registered at the provider, it receives ?code=&state=
+	RedirectURL string
+	Scopes      []string
+	// StateTTL is how long the user has to sign in at the provider
+	StateTTL time.Duration
+}
+
+func LoadOIDCConfig() OIDCConfig {
+	return OIDCConfig{
+		Issuer:       strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  GetEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/users/login/sso/callback"),
+		Scopes:       strings.Fields(GetEnv("OIDC_SCOPES", "openid email profile")),
+		StateTTL:     GetDuration("OIDC_STATE_TTL", 10*time.Minute),
+	}
+}
+
+type LoginProtectionConfig struct {
+	// Store is "sql" to share counters between instances or "memory"
+	Store           string
+	MaxAttempts     int
+	IPMaxAttempts   int
+	LockoutDuration time.Duration
+	FreeAttempts    int
+	BackoffBase     time.Duration
+	BackoffMax      time.Duration
+	Window          time.Duration
+}
+
+func LoadLoginProtectionConfig() LoginProtectionConfig {
+	return LoginProtectionConfig{
+		Store:           GetEnv("LOGIN_ATTEMPT_STORE", "sql"),
+		MaxAttempts:     GetInt("LOGIN_MAX_ATTEMPTS", 5),
+		IPMaxAttempts:   GetInt("LOGIN_IP_MAX_ATTEMPTS", 20),
+		LockoutDuration: GetDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		FreeAttempts:    GetInt("LOGIN_BACKOFF_AFTER", 3),
+		BackoffBase:     GetDuration("LOGIN_BACKOFF_BASE", time.Second),
+		BackoffMax:      GetDuration("LOGIN_BACKOFF_MAX", time.Minute),
+		Window:          GetDuration("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+	}
+}
+
+type PasswordHashConfig struct {
+	// Algorithm for new hashes, "argon2id" or "bcrypt". Stored hashes of the
+	// other algorithm keep working and are upgraded on login.
+	Algorithm  string
+	BcryptCost int
+	// Argon2Memory is in KiB
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+}
+
+func LoadPasswordHashConfig() PasswordHashConfig {
+	return PasswordHashConfig{
+		Algorithm:         GetEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+		BcryptCost:        GetInt("BCRYPT_COST", 14),
+		Argon2Memory:      GetInt("ARGON2_MEMORY_KB", 64*1024),
+		Argon2Iterations:  GetInt("ARGON2_ITERATIONS", 3),
+		Argon2Parallelism: GetInt("ARGON2_PARALLELISM", 2),
+	}
+}
+
+type TenantConfig struct {
+	// MultiTenant serves several agencies; when off every request belongs
+	// to the default tenant
+	MultiTenant bool
+	// BaseDomain lets acme.<BaseDomain> resolve to the tenant with slug acme
+	BaseDomain string
+	// PlatformAdminToken guards the tenant provisioning endpoints, which are
+	// disabled while it is empty
+	PlatformAdminToken string
+}
+
+func LoadTenantConfig() TenantConfig {
+	return TenantConfig{
+		MultiTenant:        GetBool("MULTI_TENANT", false),
+		BaseDomain:         os.Getenv("TENANT_BASE_DOMAIN"),
+		PlatformAdminToken: os.Getenv("PLATFORM_ADMIN_TOKEN"),
+	}
+}
+
+// GetEnv returns the value of key or fallback when it is not set.
+func GetEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetDuration parses key as a time.Duration (e.g. "15m", "720h").
+func GetDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logrus.WithError(err).Warnf("Invalid duration for %s, defaulting to %s", key, fallback)
+		return fallback
+	}
+	return duration
+}
+
+func GetInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logrus.WithError(err).Warnf("Invalid integer for %s, defaulting to %d", key, fallback)
+		return fallback
+	}
+	return number
+}
+
+func GetBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logrus.WithError(err).Warnf("Invalid boolean for %s, defaulting to %t", key, fallback)
+		return fallback
+	}
+	return parsed
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/mysql"
@@ -64,11 +66,154 @@ func Init() {
 	}
 	logrus.Info("Successfully obtained SQL DB connection")
 
-	err = DB.AutoMigrate(&models.User{}, &models.Property{})
+	// Accounts that existed before email verification was introduced are
+	// trusted, otherwise every user would be locked out after the upgrade
+	backfillEmailVerification := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
+	// Properties listed before the original price was kept start from their
+	// current price
+	backfillOriginalPrice := DB.Migrator().HasTable(&models.Property{}) && !DB.Migrator().HasColumn(&models.Property{}, "OriginalPrice")
+
+	// Branch names became unique per tenant instead of globally
+	if DB.Migrator().HasIndex(&models.Branch{}, "idx_branches_name") {
+		if err := DB.Migrator().DropIndex(&models.Branch{}, "idx_branches_name"); err != nil {
+			logrus.WithError(err).Fatal("Failed to drop the global unique index on branch names")
+		}
+	}
+
+	// So did usernames and emails of users
+	for _, index := range []string{"uni_users_username", "uni_users_email"} {
+		if DB.Migrator().HasIndex(&models.User{}, index) {
+			if err := DB.Migrator().DropIndex(&models.User{}, index); err != nil {
+				logrus.WithError(err).Fatalf("Failed to drop the global unique index %s", index)
+			}
+		}
+	}
+
+	// And identity provider subjects, each tenant links its own accounts
+	if DB.Migrator().HasIndex(&models.UserIdentity{}, "idx_user_identities_subject") {
+		if err := DB.Migrator().DropIndex(&models.UserIdentity{}, "idx_user_identities_subject"); err != nil {
+			logrus.WithError(err).Fatal("Failed to drop the global unique index on identity subjects")
+		}
+	}
+
+	err = DB.AutoMigrate(&models.Tenant{}, &models.User{}, &models.Property{}, &models.PropertyStatusChange{}, &models.PropertyPriceChange{}, &models.Session{}, &models.PasswordResetToken{}, &models.LoginAttempt{}, &models.TwoFactorCredential{}, &models.RecoveryCode{}, &models.APIKey{}, &models.Invitation{}, &models.EmailVerificationToken{}, &models.UserIdentity{}, &models.SSOLoginState{}, &models.Branch{})
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to auto-migrate database")
 	} else {
 		logrus.Info("Database auto-migration completed successfully")
 	}
+
+	if backfillEmailVerification {
+		result := DB.Model(&models.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", time.Now())
+		if result.Error != nil {
+			logrus.WithError(result.Error).Fatal("Failed to mark existing users as verified")
+		}
+		logrus.Infof("Marked %d existing users as verified", result.RowsAffected)
+	}
+
+	if backfillOriginalPrice {
+		result := DB.Model(&models.Property{}).Where("original_price = 0").UpdateColumn("original_price", gorm.Expr("price"))
+		if result.Error != nil {
+			logrus.WithError(result.Error).Fatal("Failed to set the original price of existing properties")
+		}
+		logrus.Infof("Set the original price of %d existing properties", result.RowsAffected)
+	}
+
+	createPropertySearchIndex()
+
+	defaultTenant := assignDefaultTenant()
+	assignDefaultBranch(defaultTenant)
 	logrus.Info("Database initialized successfully")
 }
+
+// createPropertySearchIndex adds the FULLTEXT index behind the q= property
+// search. MATCH compares words with the collation of the indexed columns, so
+// a table created with an accent sensitive one is converted first, otherwise
+// "jardin" would not find "jardín".
+func createPropertySearchIndex() {
+	var collation string
+	err := DB.Raw("SELECT TABLE_COLLATION FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", "properties").Scan(&collation).Error
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to read the collation of the properties table")
+	}
+	if strings.Contains(collation, "_as_") || strings.HasSuffix(collation, "_bin") {
+		if err := DB.Exec("ALTER TABLE properties CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci").Error; err != nil {
+			logrus.WithError(err).Fatal("Failed to make the properties table accent insensitive")
+		}
+		logrus.Infof("Converted the properties table from %s to utf8mb4_unicode_ci", collation)
+	}
+
+	if DB.Migrator().HasIndex(&models.Property{}, "idx_properties_search") {
+		return
+	}
+	// The columns must be listed as in the MATCH of the property repository
+	err = DB.Exec("CREATE FULLTEXT INDEX idx_properties_search ON properties (title, address, neighborhood, reference, notes)").Error
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to create the full-text index on properties")
+	}
+	logrus.Info("Created the full-text index on properties")
+}
+
+// assignDefaultTenant creates the default tenant when it is missing and moves
+// everything that predates tenants into it, so single-agency deployments
+// keep working without setup.
+func assignDefaultTenant() *models.Tenant {
+	var tenant models.Tenant
+	result := DB.Where("slug = ?", models.DefaultTenantSlug).Limit(1).Find(&tenant)
+	if result.Error != nil {
+		logrus.WithError(result.Error).Fatal("Failed to look up the default tenant")
+	}
+	if result.RowsAffected == 0 {
+		tenant = models.Tenant{Slug: models.DefaultTenantSlug, Name: "Default"}
+		if err := DB.Create(&tenant).Error; err != nil {
+			logrus.WithError(err).Fatal("Failed to create the default tenant")
+		}
+		logrus.Infof("Created tenant %q for existing data", tenant.Slug)
+	}
+
+	for _, model := range []interface{}{&models.User{}, &models.Property{}, &models.Branch{}, &models.Invitation{}, &models.APIKey{}, &models.UserIdentity{}, &models.SSOLoginState{}} {
+		result := DB.Model(model).Where("tenant_id = 0").Update("tenant_id", tenant.ID)
+		if result.Error != nil {
+			logrus.WithError(result.Error).Fatal("Failed to assign existing data to the default tenant")
+		}
+	}
+	return &tenant
+}
+
+// assignDefaultBranch creates the first branch of the default tenant when it
+// has none and moves everything that predates branches into it, so
+// single-office deployments keep working without setup. Regional admins are
+// left without a branch. Provisioned tenants get their branch on creation.
+func assignDefaultBranch(tenant *models.Tenant) {
+	var count int64
+	if err := DB.Model(&models.Branch{}).Where("tenant_id = ?", tenant.ID).Count(&count).Error; err != nil {
+		logrus.WithError(err).Fatal("Failed to count branches")
+	}
+	if count > 0 {
+		return
+	}
+
+	branch := models.Branch{TenantID: tenant.ID, Name: models.DefaultBranchName}
+	if err := DB.Create(&branch).Error; err != nil {
+		logrus.WithError(err).Fatal("Failed to create the default branch")
+	}
+
+	unassigned := []struct {
+		model interface{}
+		query string
+		args  []interface{}
+	}{
+		{&models.User{}, "branch_id = 0 AND role <> ?", []interface{}{models.RoleRegionalAdmin}},
+		{&models.Property{}, "branch_id = 0", nil},
+		{&models.Invitation{}, "branch_id = 0", nil},
+		{&models.APIKey{}, "branch_id = 0", nil},
+	}
+	for _, table := range unassigned {
+		result := DB.Model(table.model).Where("tenant_id = ?", tenant.ID).Where(table.query, table.args...).Update("branch_id", branch.ID)
+		if result.Error != nil {
+			logrus.WithError(result.Error).Fatal("Failed to assign existing data to the default branch")
+		}
+	}
+	logrus.Infof("Created branch %q for existing users and properties", branch.Name)
+}
@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// OutboxMailer writes every message as an .eml file into a directory instead
+// of delivering it. It is meant for development and offline tests.
+type OutboxMailer struct {
+	dir     string
+	from    string
+	counter atomic.Uint64
+}
+
+func NewOutboxMailer(dir string, from string) ports.Mailer {
+	return &OutboxMailer{dir: dir, from: from}
+}
+
+func (m *OutboxMailer) Send(email *models.Email) error {
+	if email == nil || email.To == "" {
+		return errors.New("email recipient cannot be empty")
+	}
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		logrus.WithError(err).Errorf("Failed to create outbox directory %s", m.dir)
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%04d-%s.eml",
+		time.Now().Format("20060102T150405.000000000"),
+		m.counter.Add(1),
+		sanitizeFileName(email.To),
+	)
+	path := filepath.Join(m.dir, name)
+
+	if err := os.WriteFile(path, buildMessage(m.from, email), 0o600); err != nil {
+		logrus.WithError(err).Errorf("Failed to write email to outbox %s", path)
+		return err
+	}
+
+	logrus.Infof("Email %q to %s written to outbox %s", email.Subject, email.To, path)
+	return nil
+}
+
+func sanitizeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+}
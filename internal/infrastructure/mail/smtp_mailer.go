@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"errors"
+	"net"
+	"net/smtp"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) ports.Mailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(email *models.Email) error {
+	if email == nil || email.To == "" {
+		return errors.New("email recipient cannot be empty")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	if err := smtp.SendMail(m.addr, auth, m.from, []string{email.To}, buildMessage(m.from, email)); err != nil {
+		logrus.WithError(err).Errorf("Failed to send email to %s", email.To)
+		return err
+	}
+
+	logrus.Infof("Email %q sent to %s", email.Subject, email.To)
+	return nil
+}
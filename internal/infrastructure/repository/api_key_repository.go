@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// lastUsedResolution bounds how often a busy key writes its last-used timestamp
+const lastUsedResolution = time.Minute
+
+var apiKeyColumns = []string{
+	"id", "user_id", "branch_id", "name", "prefix", "key_hash", "scopes",
+	"last_used_at", "expires_at", "revoked_at", "created_at",
+}
+
+// APIKeyRepository only sees the keys of one tenant, so a key only
+// authenticates requests to the tenant it was created in.
+type APIKeyRepository struct {
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
+}
+
+func NewAPIKeyRepository(db *sql.DB, tenantID uint) ports.APIKeyRepository {
+	return &APIKeyRepository{
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
+	}
+}
+
+func (r *APIKeyRepository) selectAPIKeys() squirrel.SelectBuilder {
+	return r.qb.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func scanAPIKey(row squirrel.RowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.BranchID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) (*models.APIKey, error) {
+	now := time.Now()
+	query := r.qb.Insert("api_keys").
+		Columns("tenant_id", "user_id", "branch_id", "name", "prefix", "key_hash", "scopes", "expires_at", "created_at").
+		Values(r.tenantID, key.UserID, key.BranchID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.ExpiresAt, now)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create API key")
+		return nil, err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create API key")
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for API key")
+		return nil, err
+	}
+
+	key.ID = uint(id)
+	key.TenantID = r.tenantID
+	key.CreatedAt = now
+	logrus.Infof("API key %d created by user %d", key.ID, key.UserID)
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	query := r.selectAPIKeys().
+		Where(squirrel.Eq{"key_hash": hash})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting API key by hash")
+		return nil, err
+	}
+
+	key, err := scanAPIKey(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warn("No API key found for the provided key")
+			return nil, errors.New("api key not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting API key by hash")
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetAll(userID uint) ([]models.APIKey, error) {
+	query := r.selectAPIKeys().
+		OrderBy("created_at DESC")
+	if userID != 0 {
+		query = query.Where(squirrel.Eq{"user_id": userID})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting API keys")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting API keys")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close API key rows")
+		}
+	}()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan API key row")
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over API key rows")
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(id uint, userID uint) error {
+	query := r.qb.Update("api_keys").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+	if userID != 0 {
+		query = query.Where(squirrel.Eq{"user_id": userID})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for revoking API key")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for revoking API key")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for revoking API key")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No active API key found with ID: %d", id)
+		return errors.New("api key not found or already revoked")
+	}
+
+	logrus.Infof("API key %d revoked", id)
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uint, now time.Time) error {
+	query := r.qb.Update("api_keys").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Set("last_used_at", now).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Or{
+			squirrel.Expr("last_used_at IS NULL"),
+			squirrel.Lt{"last_used_at": now.Add(-lastUsedResolution)},
+		})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating API key last use")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating API key last use")
+		return err
+	}
+	return nil
+}
+
+// revokeUserAPIKeys is shared with the user repository so deleting or
+// deactivating a user revokes their keys in the same transaction.
+func revokeUserAPIKeys(runner squirrel.BaseRunner, qb squirrel.StatementBuilderType, userID uint) error {
+	query := qb.Update("api_keys").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for revoking user API keys")
+		return err
+	}
+
+	result, err := runner.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for revoking user API keys")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for revoking user API keys")
+		return err
+	}
+
+	logrus.Infof("Revoked %d API keys for user %d", rowsAffected, userID)
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+var branchColumns = []string{"id", "name", "city", "address", "created_at", "updated_at"}
+
+// BranchRepository only sees the branches of one tenant
+type BranchRepository struct {
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
+}
+
+func NewBranchRepository(db *sql.DB, tenantID uint) ports.BranchRepository {
+	return &BranchRepository{
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
+	}
+}
+
+func (r *BranchRepository) selectBranches() squirrel.SelectBuilder {
+	return r.qb.Select(branchColumns...).
+		From("branches").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func scanBranch(row squirrel.RowScanner) (*models.Branch, error) {
+	var branch models.Branch
+	if err := row.Scan(&branch.ID, &branch.Name, &branch.City, &branch.Address, &branch.CreatedAt, &branch.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *BranchRepository) GetAll() ([]models.Branch, error) {
+	query := r.selectBranches().
+		OrderBy("name")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting branches")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting branches")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close rows after getting branches")
+		}
+	}()
+
+	branches := []models.Branch{}
+	for rows.Next() {
+		branch, err := scanBranch(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan branch row")
+			return nil, err
+		}
+		branches = append(branches, *branch)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over branch rows")
+		return nil, err
+	}
+	return branches, nil
+}
+
+func (r *BranchRepository) GetByID(id uint) (*models.Branch, error) {
+	query := r.selectBranches().
+		Where(squirrel.Eq{"id": id})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting branch by ID")
+		return nil, err
+	}
+
+	branch, err := scanBranch(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warnf("No branch found with ID: %d", id)
+			return nil, errors.New("branch not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting branch by ID")
+		return nil, err
+	}
+	return branch, nil
+}
+
+func (r *BranchRepository) Create(branch *models.Branch) error {
+	now := time.Now()
+	query := r.qb.Insert("branches").
+		Columns("tenant_id", "name", "city", "address", "created_at", "updated_at").
+		Values(r.tenantID, branch.Name, branch.City, branch.Address, now, now)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create branch")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create branch")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for branch")
+		return err
+	}
+
+	branch.ID = uint(id)
+	branch.TenantID = r.tenantID
+	branch.CreatedAt = now
+	branch.UpdatedAt = now
+	logrus.Infof("Branch %q created with ID: %d", branch.Name, branch.ID)
+	return nil
+}
+
+func (r *BranchRepository) Update(branch *models.Branch) error {
+	query := r.qb.Update("branches").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Set("name", branch.Name).
+		Set("city", branch.City).
+		Set("address", branch.Address).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": branch.ID})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating branch")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating branch")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating branch")
+		return err
+	}
+
+	// MySQL reports 0 rows when nothing changed, so only a missing branch is an error
+	if rowsAffected == 0 {
+		if _, err := r.GetByID(branch.ID); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("Branch with ID: %d updated", branch.ID)
+	return nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type EmailVerificationRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewEmailVerificationRepository(db *sql.DB) ports.EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func (r *EmailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	query := r.qb.Insert("email_verification_tokens").
+		Columns("user_id", "email", "token_hash", "expires_at", "created_at").
+		Values(token.UserID, token.Email, token.TokenHash, token.ExpiresAt, time.Now())
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create email verification token")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create email verification token")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for email verification token")
+		return err
+	}
+
+	token.ID = uint(id)
+	return nil
+}
+
+func (r *EmailVerificationRepository) GetByTokenHash(hash string) (*models.EmailVerificationToken, error) {
+	query := r.qb.Select("id", "user_id", "email", "token_hash", "expires_at", "used_at", "created_at").
+		From("email_verification_tokens").
+		Where(squirrel.Eq{"token_hash": hash})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting email verification token")
+		return nil, err
+	}
+
+	var token models.EmailVerificationToken
+	err = r.db.QueryRow(sqlStr, args...).Scan(
+		&token.ID, &token.UserID, &token.Email, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warn("No email verification token found for the provided token")
+			return nil, errors.New("email verification token not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting email verification token")
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *EmailVerificationRepository) InvalidateByUserID(userID uint) error {
+	query := r.qb.Update("email_verification_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for invalidating email verification tokens")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for invalidating email verification tokens")
+		return err
+	}
+	return nil
+}
+
+func (r *EmailVerificationRepository) Consume(token *models.EmailVerificationToken) error {
+	now := time.Now()
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for consuming email verification token")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for consuming email verification token")
+		}
+	}()
+
+	claim := r.qb.Update("email_verification_tokens").
+		Set("used_at", now).
+		Where(squirrel.Eq{"id": token.ID}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	sqlStr, args, err := claim.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for using email verification token")
+		return err
+	}
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for using email verification token")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for using email verification token")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("Email verification token %d was already used", token.ID)
+		return errors.New("email verification token already used")
+	}
+
+	verify := r.qb.Update("users").
+		Set("email", token.Email).
+		Set("email_verified_at", now).
+		Set("updated_at", now).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": token.UserID}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err = verify.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for verifying user email")
+		return err
+	}
+
+	result, err = tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for verifying user email")
+		return takenError(err)
+	}
+
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for verifying user email")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No user found with ID: %d", token.UserID)
+		return errors.New("user not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for consuming email verification token")
+		return err
+	}
+
+	logrus.Infof("Email %s verified for user with ID: %d", token.Email, token.UserID)
+	return nil
+}
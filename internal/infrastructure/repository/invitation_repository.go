@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+var invitationColumns = []string{
+	"id", "email", "role", "branch_id", "token_hash", "invited_by", "expires_at",
+	"accepted_at", "revoked_at", "user_id", "created_at", "updated_at",
+}
+
+// InvitationRepository only sees the invitations of one tenant, and the
+// users it creates on acceptance join that tenant.
+type InvitationRepository struct {
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
+}
+
+func NewInvitationRepository(db *sql.DB, tenantID uint) ports.InvitationRepository {
+	return &InvitationRepository{
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
+	}
+}
+
+func (r *InvitationRepository) selectInvitations() squirrel.SelectBuilder {
+	return r.qb.Select(invitationColumns...).
+		From("invitations").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func (r *InvitationRepository) updateInvitations() squirrel.UpdateBuilder {
+	return r.qb.Update("invitations").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func scanInvitation(row squirrel.RowScanner) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.BranchID,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.RevokedAt,
+		&invitation.UserID,
+		&invitation.CreatedAt,
+		&invitation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// openInvitation matches invitations that were neither accepted nor revoked
+var openInvitation = squirrel.Expr("accepted_at IS NULL AND revoked_at IS NULL")
+
+func (r *InvitationRepository) Create(invitation *models.Invitation) error {
+	now := time.Now()
+	query := r.qb.Insert("invitations").
+		Columns("tenant_id", "email", "role", "branch_id", "token_hash", "invited_by", "expires_at", "created_at", "updated_at").
+		Values(r.tenantID, invitation.Email, invitation.Role, invitation.BranchID, invitation.TokenHash, invitation.InvitedBy, invitation.ExpiresAt, now, now)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create invitation")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create invitation")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for invitation")
+		return err
+	}
+
+	invitation.ID = uint(id)
+	invitation.TenantID = r.tenantID
+	invitation.CreatedAt = now
+	invitation.UpdatedAt = now
+	logrus.Infof("Invitation %d created for %s", invitation.ID, invitation.Email)
+	return nil
+}
+
+func (r *InvitationRepository) GetByID(id uint) (*models.Invitation, error) {
+	return r.getOne(squirrel.Eq{"id": id}, "ID")
+}
+
+func (r *InvitationRepository) GetByTokenHash(hash string) (*models.Invitation, error) {
+	return r.getOne(squirrel.Eq{"token_hash": hash}, "token")
+}
+
+func (r *InvitationRepository) getOne(where squirrel.Eq, lookup string) (*models.Invitation, error) {
+	query := r.selectInvitations().
+		Where(where)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to build SQL query for getting invitation by %s", lookup)
+		return nil, err
+	}
+
+	invitation, err := scanInvitation(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warnf("No invitation found for the provided %s", lookup)
+			return nil, errors.New("invitation not found")
+		}
+		logrus.WithError(err).Errorf("Failed to execute query for getting invitation by %s", lookup)
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *InvitationRepository) GetOpenByEmail(email string) (*models.Invitation, error) {
+	query := r.selectInvitations().
+		Where(squirrel.Eq{"email": email}).
+		Where(openInvitation).
+		OrderBy("created_at DESC").
+		Limit(1)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting open invitation by email")
+		return nil, err
+	}
+
+	invitation, err := scanInvitation(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting open invitation by email")
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *InvitationRepository) GetAll(branchID uint) ([]models.Invitation, error) {
+	query := r.selectInvitations()
+	if branchID != 0 {
+		query = query.Where(squirrel.Eq{"branch_id": branchID})
+	}
+	query = query.OrderBy("created_at DESC")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting invitations")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting invitations")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close invitation rows")
+		}
+	}()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan invitation row")
+			return nil, err
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over invitation rows")
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *InvitationRepository) Renew(id uint, tokenHash string, expiresAt time.Time) error {
+	query := r.updateInvitations().
+		Set("token_hash", tokenHash).
+		Set("expires_at", expiresAt).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(openInvitation)
+
+	return r.execOnOpen(query, id, "renewing")
+}
+
+func (r *InvitationRepository) Revoke(id uint) error {
+	now := time.Now()
+	query := r.updateInvitations().
+		Set("revoked_at", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": id}).
+		Where(openInvitation)
+
+	return r.execOnOpen(query, id, "revoking")
+}
+
+func (r *InvitationRepository) execOnOpen(query squirrel.UpdateBuilder, id uint, action string) error {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to build SQL query for %s invitation", action)
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to execute query for %s invitation", action)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to retrieve rows affected for %s invitation", action)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No open invitation found with ID: %d", id)
+		return errors.New("invitation not found, already accepted or revoked")
+	}
+	return nil
+}
+
+func (r *InvitationRepository) Accept(id uint, tokenHash string, user *models.User) (*models.UserResponse, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for accepting invitation")
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for accepting invitation")
+		}
+	}()
+
+	// Claim the invitation first so two concurrent accepts cannot both create a user
+	now := time.Now()
+	claim := r.updateInvitations().
+		Set("accepted_at", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": id, "token_hash": tokenHash}).
+		Where(openInvitation).
+		Where(squirrel.Gt{"expires_at": now})
+
+	sqlStr, args, err := claim.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for accepting invitation")
+		return nil, err
+	}
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for accepting invitation")
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for accepting invitation")
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		logrus.Warnf("Invitation %d is no longer open", id)
+		return nil, errors.New("invalid or expired invitation")
+	}
+
+	if err := insertUser(tx, r.qb, r.tenantID, user); err != nil {
+		return nil, err
+	}
+
+	link := r.updateInvitations().
+		Set("user_id", user.ID).
+		Where(squirrel.Eq{"id": id})
+
+	sqlStr, args, err = link.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for linking invitation to user")
+		return nil, err
+	}
+
+	if _, err := tx.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for linking invitation to user")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for accepting invitation")
+		return nil, err
+	}
+
+	logrus.Infof("Invitation %d accepted by new user %d", id, user.ID)
+	return user.ToUserResponse(), nil
+}
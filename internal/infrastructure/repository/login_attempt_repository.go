@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// LoginAttemptRepository keeps failed login counters in MySQL so every
+// instance behind a load balancer sees the same lockouts.
+type LoginAttemptRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewLoginAttemptRepository(db *sql.DB) ports.LoginAttemptStore {
+	return &LoginAttemptRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func (r *LoginAttemptRepository) Get(key string) (*models.LoginAttempt, error) {
+	query := r.qb.Select("attempt_key", "failures", "last_failed_at", "locked_until").
+		From("login_attempts").
+		Where(squirrel.Eq{"attempt_key": key})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting login attempts")
+		return nil, err
+	}
+
+	var attempt models.LoginAttempt
+	err = r.db.QueryRow(sqlStr, args...).Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailedAt, &attempt.LockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting login attempts")
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (r *LoginAttemptRepository) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	// The counter restarts when the last failure is older than the window.
+	// MySQL applies the assignments left to right, so failures still sees the
+	// previous last_failed_at.
+	query := r.qb.Insert("login_attempts").
+		Columns("attempt_key", "failures", "last_failed_at").
+		Values(key, 1, now).
+		Suffix("ON DUPLICATE KEY UPDATE failures = IF(last_failed_at < ?, 1, failures + 1), last_failed_at = VALUES(last_failed_at)", now.Add(-window))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for recording login failure")
+		return nil, err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for recording login failure")
+		return nil, err
+	}
+
+	return r.Get(key)
+}
+
+func (r *LoginAttemptRepository) Lock(key string, until time.Time) error {
+	query := r.qb.Update("login_attempts").
+		Set("locked_until", until).
+		Where(squirrel.Eq{"attempt_key": key})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for locking login attempts")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for locking login attempts")
+		return err
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) Reset(key string) error {
+	query := r.qb.Delete("login_attempts").
+		Where(squirrel.Eq{"attempt_key": key})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for resetting login attempts")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for resetting login attempts")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// sortColumn is the column behind a sortable field and reads the field from
+// a row to build the cursor of the next page. column may also be an
+// expression, whose placeholders are bound to args.
+type sortColumn[T any] struct {
+	column string
+	args   []any
+	isTime bool
+	value  func(row *T) any
+}
+
+// compare matches the rows whose value in the column relates to value by op
+func (c sortColumn[T]) compare(op string, value any) squirrel.Sqlizer {
+	args := append(append([]any{}, c.args...), value)
+	return squirrel.Expr(c.column+" "+op+" ?", args...)
+}
+
+// keyset describes how the rows of a listing are ordered. idColumn breaks
+// ties, so every row has a distinct position.
+type keyset[T any] struct {
+	columns  map[string]sortColumn[T]
+	idColumn string
+	id       func(row *T) uint
+}
+
+// paginate orders the query by the sort fields of the page and restricts it
+// to the rows after the cursor or offset. It fetches one row more than the
+// limit, which tells page whether there is a next page.
+func (k keyset[T]) paginate(query squirrel.SelectBuilder, page *models.PageRequest) (squirrel.SelectBuilder, error) {
+	for _, field := range page.SortFields {
+		column, ok := k.columns[field.Name]
+		if !ok {
+			return query, fmt.Errorf("%w: cannot sort by %q", models.ErrInvalidPage, field.Name)
+		}
+		direction := " ASC"
+		if field.Desc {
+			direction = " DESC"
+		}
+		query = query.OrderByClause(column.column+direction, column.args...)
+	}
+	query = query.OrderBy(k.idColumn + " ASC")
+
+	if page.After != nil {
+		after, err := k.after(page)
+		if err != nil {
+			return query, err
+		}
+		query = query.Where(after)
+	}
+	if page.Offset > 0 {
+		query = query.Offset(uint64(page.Offset))
+	}
+	return query.Limit(uint64(page.Limit) + 1), nil
+}
+
+// after matches the rows that sort after the cursor: those with the same
+// values up to some field and a later value in it, or the same values in all
+// fields and a greater ID.
+func (k keyset[T]) after(page *models.PageRequest) (squirrel.Sqlizer, error) {
+	values := make([]any, len(page.SortFields))
+	for i, field := range page.SortFields {
+		values[i] = page.After.Values[i]
+		if k.columns[field.Name].isTime {
+			raw, _ := values[i].(string)
+			parsed, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: malformed cursor", models.ErrInvalidPage)
+			}
+			values[i] = parsed
+		}
+	}
+
+	after := squirrel.Or{}
+	for i := 0; i <= len(page.SortFields); i++ {
+		condition := squirrel.And{}
+		for j := 0; j < i; j++ {
+			condition = append(condition, k.columns[page.SortFields[j].Name].compare("=", values[j]))
+		}
+		switch {
+		case i == len(page.SortFields):
+			condition = append(condition, squirrel.Gt{k.idColumn: page.After.ID})
+		case page.SortFields[i].Desc:
+			condition = append(condition, k.columns[page.SortFields[i].Name].compare("<", values[i]))
+		default:
+			condition = append(condition, k.columns[page.SortFields[i].Name].compare(">", values[i]))
+		}
+		after = append(after, condition)
+	}
+	return after, nil
+}
+
+// page trims the extra row fetched by paginate and points the next cursor at
+// the last row that is kept.
+func (k keyset[T]) page(rows []T, page *models.PageRequest, total int) *models.Page[T] {
+	result := &models.Page[T]{
+		Items: rows,
+		Pagination: models.Pagination{
+			Total:  total,
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		},
+	}
+	if len(rows) <= page.Limit {
+		return result
+	}
+
+	result.Items = rows[:page.Limit]
+	last := &result.Items[page.Limit-1]
+	cursor := &models.Cursor{Sort: page.Sort, Values: make([]any, len(page.SortFields)), ID: k.id(last)}
+	for i, field := range page.SortFields {
+		cursor.Values[i] = k.columns[field.Name].value(last)
+	}
+	result.NextCursor = cursor.Encode()
+	return result
+}
+
+// countRows replaces the columns of a listing query to count its rows
+func countRows(query squirrel.SelectBuilder) squirrel.SelectBuilder {
+	return query.RemoveColumns().Column("COUNT(*)")
+}
+
+// listRows runs a listing query and scans every row. action completes the
+// log messages, e.g. "searching properties".
+func listRows[T any](db *sql.DB, query squirrel.SelectBuilder, action string, scan func(squirrel.RowScanner) (*T, error)) ([]T, error) {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to build SQL query for %s", action)
+		return nil, err
+	}
+	rows, err := db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to execute query for %s", action)
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Errorf("Failed to close rows after %s", action)
+		}
+	}()
+
+	items := []T{}
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			logrus.WithError(err).Errorf("Failed to scan row while %s", action)
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Errorf("Error occurred while iterating over rows while %s", action)
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type PasswordResetRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewPasswordResetRepository(db *sql.DB) ports.PasswordResetRepository {
+	return &PasswordResetRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func (r *PasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	query := r.qb.Insert("password_reset_tokens").
+		Columns("user_id", "token_hash", "expires_at", "created_at").
+		Values(token.UserID, token.TokenHash, token.ExpiresAt, time.Now())
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create password reset token")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create password reset token")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for password reset token")
+		return err
+	}
+
+	token.ID = uint(id)
+	return nil
+}
+
+func (r *PasswordResetRepository) GetByTokenHash(hash string) (*models.PasswordResetToken, error) {
+	query := r.qb.Select("id", "user_id", "token_hash", "expires_at", "used_at", "created_at").
+		From("password_reset_tokens").
+		Where(squirrel.Eq{"token_hash": hash})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting password reset token")
+		return nil, err
+	}
+
+	var token models.PasswordResetToken
+	err = r.db.QueryRow(sqlStr, args...).Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warn("No password reset token found for the provided token")
+			return nil, errors.New("password reset token not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting password reset token")
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *PasswordResetRepository) MarkUsed(id uint) error {
+	query := r.qb.Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for using password reset token")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for using password reset token")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for using password reset token")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("Password reset token %d was already used", id)
+		return errors.New("password reset token already used")
+	}
+	return nil
+}
+
+func (r *PasswordResetRepository) InvalidateByUserID(userID uint) error {
+	query := r.qb.Update("password_reset_tokens").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for invalidating password reset tokens")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for invalidating password reset tokens")
+		return err
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/sirupsen/logrus"
@@ -11,112 +13,52 @@ import (
 	"inmo-backend/internal/domain/ports"
 )
 
+// propertyColumns is the column order scanProperty expects. The branch name
+// comes from a join, see selectProperties.
+var propertyColumns = []string{
+	"properties.id", "properties.title", "properties.listing_date", "properties.address",
+	"properties.neighborhood", "properties.city", "properties.zone", "properties.reference",
+	"properties.price", "properties.original_price", "properties.construction_m2", "properties.land_m2",
+	"properties.is_occupied", "properties.is_furnished", "properties.floors", "properties.bedrooms",
+	"properties.bathrooms", "properties.garage_size", "properties.garden_m2", "properties.gas_types",
+	"properties.amenities", "properties.extras", "properties.utilities", "properties.notes",
+	"properties.owner_id", "properties.user_id", "properties.branch_id", "properties.property_type",
+	"properties.transaction_type", "properties.status", "properties.created_at", "properties.updated_at",
+	"properties.version", "properties.deleted_at", "COALESCE(branches.name, '')",
+}
+
+// PropertyRepository only sees the properties of one tenant, every statement
+// is built from selectProperties, updateProperties or the tenant's insert.
 type PropertyRepository struct {
-	db *sql.DB
-	qb squirrel.StatementBuilderType
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
 }
 
-func NewPropertyRepository(db *sql.DB) ports.PropertyRepository {
+func NewPropertyRepository(db *sql.DB, tenantID uint) ports.PropertyRepository {
 	return &PropertyRepository{
-		db: db,
-		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
 	}
 }
 
-func (r *PropertyRepository) GetAll() ([]models.PropertyResponse, error) {
-	query := r.qb.Select("*").
+func (r *PropertyRepository) selectProperties(columns ...string) squirrel.SelectBuilder {
+	return r.qb.Select(columns...).
 		From("properties").
-		Where(squirrel.Expr("deleted_at IS NULL"))
-
-	sqlStr, args, err := query.ToSql()
-	if err != nil {
-		logrus.WithError(err).Error("Failed to build SQL query for getting all properties")
-		return nil, err
-	}
-	rows, err := r.db.Query(sqlStr, args...)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to execute query for getting all properties")
-		return nil, err
-	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			logrus.WithError(err).Error("Failed to close rows after getting all properties")
-		}
-	}()
-
-	var properties []models.PropertyResponse
-	for rows.Next() {
-		var property models.Property
-
-        if err := rows.Scan(
-            &property.ID,
-            &property.Title,
-            &property.ListingDate,
-            &property.Address,
-            &property.Neighborhood,
-            &property.City,
-            &property.Zone,
-            &property.Reference,
-            &property.Price,
-            &property.ConstructionM2,
-            &property.LandM2,
-            &property.IsOccupied,
-            &property.IsFurnished,
-            &property.Floors,
-            &property.Bedrooms,
-            &property.Bathrooms,
-            &property.GarageSize,
-            &property.GardenM2,
-            &property.GasTypes,
-            &property.Amenities,
-            &property.Extras,
-            &property.Utilities,
-            &property.Notes,
-            &property.OwnerID,
-            &property.UserID,
-            &property.PropertyType,
-            &property.TransactionType,
-            &property.Status,
-            &property.CreatedAt,
-            &property.UpdatedAt,
-            &property.DeletedAt,
-		); err != nil {
-			logrus.WithError(err).Error("Failed to scan property row")
-			return nil, err
-		}
-
-		properties = append(properties, *property.ToResponse())
-
-		if err := rows.Err(); err != nil {
-			logrus.WithError(err).Error("Error occurred while iterating over property rows")
-			return nil, err
-		}
-	}
-
-	if len(properties) == 0 {
-		logrus.Warn("No properties found in the database")
-		return []models.PropertyResponse{}, nil
-	}
-
-	return properties, nil
+		LeftJoin("branches ON branches.id = properties.branch_id AND branches.tenant_id = properties.tenant_id").
+		Where(squirrel.Eq{"properties.tenant_id": r.tenantID})
 }
 
-func (r *PropertyRepository) GetByID(id uint) (*models.PropertyResponse, error) {
-	query := r.qb.Select("*").
-		From("properties").
-		Where(squirrel.And{
-			squirrel.Eq{"id": id},
-			squirrel.Expr("deleted_at IS NULL"),
-		})
-
-	sqlStr, args, err := query.ToSql()
-	if err != nil {
-		logrus.WithError(err).Error("Failed to build SQL query for getting property by ID")
-		return nil, err
-	}
+func (r *PropertyRepository) updateProperties() squirrel.UpdateBuilder {
+	return r.qb.Update("properties").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
 
+func scanProperty(row squirrel.RowScanner) (*models.PropertyResponse, error) {
 	var property models.Property
-	err = r.db.QueryRow(sqlStr, args...).Scan(
+	var branchName string
+	err := row.Scan(
 		&property.ID,
 		&property.Title,
 		&property.ListingDate,
@@ -126,6 +68,7 @@ func (r *PropertyRepository) GetByID(id uint) (*models.PropertyResponse, error)
 		&property.Zone,
 		&property.Reference,
 		&property.Price,
+		&property.OriginalPrice,
 		&property.ConstructionM2,
 		&property.LandM2,
 		&property.IsOccupied,
@@ -142,42 +85,327 @@ func (r *PropertyRepository) GetByID(id uint) (*models.PropertyResponse, error)
 		&property.Notes,
 		&property.OwnerID,
 		&property.UserID,
+		&property.BranchID,
 		&property.PropertyType,
 		&property.TransactionType,
 		&property.Status,
 		&property.CreatedAt,
 		&property.UpdatedAt,
+		&property.Version,
 		&property.DeletedAt,
+		&branchName,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	response := property.ToResponse()
+	response.BranchName = branchName
+	return response, nil
+}
+
+// propertyKeyset orders property listings, see models.PropertySortFields
+var propertyKeyset = keyset[models.PropertyResponse]{
+	columns: map[string]sortColumn[models.PropertyResponse]{
+		"price":           {column: "properties.price", value: func(p *models.PropertyResponse) any { return p.Price }},
+		"created_at":      {column: "properties.created_at", isTime: true, value: func(p *models.PropertyResponse) any { return p.CreatedAt }},
+		"updated_at":      {column: "properties.updated_at", isTime: true, value: func(p *models.PropertyResponse) any { return p.UpdatedAt }},
+		"bedrooms":        {column: "properties.bedrooms", value: func(p *models.PropertyResponse) any { return p.Bedrooms }},
+		"bathrooms":       {column: "properties.bathrooms", value: func(p *models.PropertyResponse) any { return p.Bathrooms }},
+		"construction_m2": {column: "properties.construction_m2", value: func(p *models.PropertyResponse) any { return p.ConstructionM2 }},
+		"land_m2":         {column: "properties.land_m2", value: func(p *models.PropertyResponse) any { return p.LandM2 }},
+		"title":           {column: "properties.title", value: func(p *models.PropertyResponse) any { return p.Title }},
+	},
+	idColumn: "properties.id",
+	id:       func(p *models.PropertyResponse) uint { return p.ID },
+}
+
+// propertyCardColumns is the column order scanPropertyCard expects. Cards
+// leave out land_m2 and updated_at but they are read to sort by them, and
+// original_price to flag reduced prices.
+var propertyCardColumns = []string{
+	"properties.id", "properties.title", "properties.price", "properties.original_price",
+	"properties.bedrooms", "properties.bathrooms", "properties.construction_m2", "properties.city",
+	"properties.neighborhood", "properties.property_type", "properties.transaction_type", "properties.status",
+	"properties.created_at", "properties.land_m2", "properties.updated_at",
+}
+
+// propertyCardRow is a card with the sort fields it does not show
+type propertyCardRow struct {
+	models.PropertyCard
+	landM2    int
+	updatedAt time.Time
+}
+
+func scanPropertyCard(row squirrel.RowScanner) (*propertyCardRow, error) {
+	var card propertyCardRow
+	var originalPrice float64
+	err := row.Scan(
+		&card.ID,
+		&card.Title,
+		&card.Price,
+		&originalPrice,
+		&card.Bedrooms,
+		&card.Bathrooms,
+		&card.ConstructionM2,
+		&card.City,
+		&card.Neighborhood,
+		&card.PropertyType,
+		&card.TransactionType,
+		&card.Status,
+		&card.CreatedAt,
+		&card.landM2,
+		&card.updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	card.PriceReduced = models.ComparePrice(card.Price, originalPrice).PriceReduced
+	return &card, nil
+}
+
+// propertyCardKeyset orders card listings like propertyKeyset
+var propertyCardKeyset = keyset[propertyCardRow]{
+	columns: map[string]sortColumn[propertyCardRow]{
+		"price":           {column: "properties.price", value: func(c *propertyCardRow) any { return c.Price }},
+		"created_at":      {column: "properties.created_at", isTime: true, value: func(c *propertyCardRow) any { return c.CreatedAt }},
+		"updated_at":      {column: "properties.updated_at", isTime: true, value: func(c *propertyCardRow) any { return c.updatedAt }},
+		"bedrooms":        {column: "properties.bedrooms", value: func(c *propertyCardRow) any { return c.Bedrooms }},
+		"bathrooms":       {column: "properties.bathrooms", value: func(c *propertyCardRow) any { return c.Bathrooms }},
+		"construction_m2": {column: "properties.construction_m2", value: func(c *propertyCardRow) any { return c.ConstructionM2 }},
+		"land_m2":         {column: "properties.land_m2", value: func(c *propertyCardRow) any { return c.landM2 }},
+		"title":           {column: "properties.title", value: func(c *propertyCardRow) any { return c.Title }},
+	},
+	idColumn: "properties.id",
+	id:       func(c *propertyCardRow) uint { return c.ID },
+}
+
+// Search returns the page of properties matching the filter
+func (r *PropertyRepository) Search(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	query := filterProperties(r.selectProperties(propertyColumns...).
+		Where(squirrel.Expr("properties.deleted_at IS NULL")), filter)
+
+	return searchPage(r, query, page, propertyKeyset, scanProperty)
+}
+
+// propertyMatch ranks a property against free text through the FULLTEXT
+// index idx_properties_search, whose columns it must list in the same order.
+const propertyMatch = "MATCH(properties.title, properties.address, properties.neighborhood, properties.reference, properties.notes) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// TextSearch is Search restricted to the properties matching filter.Query,
+// each scored by how well it matched. A page sorted by relevance lists the
+// best matches first.
+func (r *PropertyRepository) TextSearch(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	if filter == nil || filter.Query == "" {
+		return nil, fmt.Errorf("%w: the search text is empty", models.ErrInvalidFilter)
+	}
+	query := filterProperties(r.selectProperties(propertyColumns...).
+		Column(squirrel.Expr(propertyMatch, filter.Query)).
+		Where(squirrel.Expr("properties.deleted_at IS NULL")).
+		Where(squirrel.Expr(propertyMatch, filter.Query)), filter)
+
+	return searchPage(r, query, page, rankedPropertyKeyset(filter.Query), scanRankedProperty)
+}
+
+// rankedRow scans the relevance selected after the columns of a property
+type rankedRow struct {
+	squirrel.RowScanner
+	relevance *float64
+}
+
+func (r rankedRow) Scan(dest ...any) error {
+	return r.RowScanner.Scan(append(dest, r.relevance)...)
+}
+
+func scanRankedProperty(row squirrel.RowScanner) (*models.PropertyResponse, error) {
+	var relevance float64
+	property, err := scanProperty(rankedRow{RowScanner: row, relevance: &relevance})
+	if err != nil {
+		return nil, err
+	}
+	property.Relevance = relevance
+	return property, nil
+}
+
+// rankedPropertyKeyset is propertyKeyset with the relevance to text
+func rankedPropertyKeyset(text string) keyset[models.PropertyResponse] {
+	ranked := propertyKeyset
+	ranked.columns = make(map[string]sortColumn[models.PropertyResponse], len(propertyKeyset.columns)+1)
+	for field, column := range propertyKeyset.columns {
+		ranked.columns[field] = column
+	}
+	ranked.columns["relevance"] = sortColumn[models.PropertyResponse]{
+		column: propertyMatch,
+		args:   []any{text},
+		value:  func(p *models.PropertyResponse) any { return p.Relevance },
+	}
+	return ranked
+}
+
+func (r *PropertyRepository) SearchCards(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error) {
+	query := filterProperties(r.selectProperties(propertyCardColumns...).
+		Where(squirrel.Expr("properties.deleted_at IS NULL")), filter)
+
+	rows, err := searchPage(r, query, page, propertyCardKeyset, scanPropertyCard)
+	if err != nil {
+		return nil, err
+	}
+	cards := &models.Page[models.PropertyCard]{
+		Items:      make([]models.PropertyCard, len(rows.Items)),
+		Pagination: rows.Pagination,
+	}
+	for i, row := range rows.Items {
+		cards.Items[i] = row.PropertyCard
+	}
+	return cards, nil
+}
+
+// searchPage counts the rows of a property search and reads the requested page
+func searchPage[T any](r *PropertyRepository, query squirrel.SelectBuilder, page *models.PageRequest, keys keyset[T], scan func(squirrel.RowScanner) (*T, error)) (*models.Page[T], error) {
+	if page == nil {
+		logrus.Error("Property search without a page")
+		return nil, fmt.Errorf("%w: a page is required", models.ErrInvalidPage)
+	}
+	total, err := r.count(countRows(query))
+	if err != nil {
+		return nil, err
+	}
+	query, err = keys.paginate(query, page)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := listRows(r.db, query, "searching properties", scan)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		logrus.Warn("No properties matched the search")
+	}
+	return keys.page(rows, page, total), nil
+}
+
+func (r *PropertyRepository) count(query squirrel.SelectBuilder) (int, error) {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for counting properties")
+		return 0, err
+	}
+	var total int
+	if err := r.db.QueryRow(sqlStr, args...).Scan(&total); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for counting properties")
+		return 0, err
+	}
+	return total, nil
+}
+
+// filterProperties adds a condition for every criterion set in the filter
+func filterProperties(query squirrel.SelectBuilder, filter *models.PropertyFilter) squirrel.SelectBuilder {
+	if filter == nil {
+		return query
+	}
+	if filter.BranchID != 0 {
+		query = query.Where(squirrel.Eq{"properties.branch_id": filter.BranchID})
+	}
+	if filter.City != "" {
+		query = query.Where(squirrel.Eq{"properties.city": filter.City})
+	}
+	if filter.Neighborhood != "" {
+		query = query.Where(squirrel.Eq{"properties.neighborhood": filter.Neighborhood})
+	}
+	if filter.Zone != "" {
+		query = query.Where(squirrel.Eq{"properties.zone": filter.Zone})
+	}
+	if filter.PropertyType != "" {
+		query = query.Where(squirrel.Eq{"properties.property_type": filter.PropertyType})
+	}
+	if filter.TransactionType != "" {
+		query = query.Where(squirrel.Eq{"properties.transaction_type": filter.TransactionType})
+	}
+	if filter.Status != "" {
+		query = query.Where(squirrel.Eq{"properties.status": filter.Status})
+	}
+	if filter.MinPrice != nil {
+		query = query.Where(squirrel.GtOrEq{"properties.price": *filter.MinPrice})
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where(squirrel.LtOrEq{"properties.price": *filter.MaxPrice})
+	}
+	if filter.MinBedrooms != nil {
+		query = query.Where(squirrel.GtOrEq{"properties.bedrooms": *filter.MinBedrooms})
+	}
+	if filter.MinBathrooms != nil {
+		query = query.Where(squirrel.GtOrEq{"properties.bathrooms": *filter.MinBathrooms})
+	}
+	if filter.MinConstructionM2 != nil {
+		query = query.Where(squirrel.GtOrEq{"properties.construction_m2": *filter.MinConstructionM2})
+	}
+	if filter.MaxConstructionM2 != nil {
+		query = query.Where(squirrel.LtOrEq{"properties.construction_m2": *filter.MaxConstructionM2})
+	}
+	if filter.MinLandM2 != nil {
+		query = query.Where(squirrel.GtOrEq{"properties.land_m2": *filter.MinLandM2})
+	}
+	if filter.MaxLandM2 != nil {
+		query = query.Where(squirrel.LtOrEq{"properties.land_m2": *filter.MaxLandM2})
+	}
+	if filter.IsFurnished != nil {
+		query = query.Where(squirrel.Eq{"properties.is_furnished": *filter.IsFurnished})
+	}
+	if filter.IsOccupied != nil {
+		query = query.Where(squirrel.Eq{"properties.is_occupied": *filter.IsOccupied})
+	}
+	// amenities is a JSON array of strings
+	for _, amenity := range filter.Amenities {
+		query = query.Where(squirrel.Expr("JSON_CONTAINS(properties.amenities, JSON_QUOTE(?))", amenity))
+	}
+	return query
+}
+
+func (r *PropertyRepository) GetByID(id uint) (*models.PropertyResponse, error) {
+	query := r.selectProperties(propertyColumns...).
+		Where(squirrel.And{
+			squirrel.Eq{"properties.id": id},
+			squirrel.Expr("properties.deleted_at IS NULL"),
+		})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting property by ID")
+		return nil, err
+	}
+
+	property, err := scanProperty(r.db.QueryRow(sqlStr, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logrus.WithError(err).Warnf("No property found with ID %d", id)
-			return nil, err
+			return nil, fmt.Errorf("%w: %w", models.ErrPropertyNotFound, err)
 		}
 		logrus.WithError(err).Error("Failed to execute query for getting property by ID")
 		return nil, err
 	}
 
-	return property.ToResponse(), nil
+	return property, nil
 }
 
 func (r *PropertyRepository) Create(property *models.Property) (*models.PropertyResponse, error) {
+    property.TenantID = r.tenantID
+    property.OriginalPrice = property.Price
     query := r.qb.Insert("properties").
         Columns(
             "title", "listing_date", "address", "neighborhood", "city",
-            "zone", "reference", "price", "construction_m2", "land_m2",
+            "zone", "reference", "price", "original_price", "construction_m2", "land_m2",
             "is_occupied", "is_furnished", "floors", "bedrooms", "bathrooms",
             "garage_size", "garden_m2", "gas_types", "amenities", "extras",
-            "utilities", "notes", "owner_id", "user_id", "property_type",
-            "transaction_type", "status",
+            "utilities", "notes", "owner_id", "user_id", "branch_id",
+            "tenant_id", "property_type", "transaction_type", "status",
         ).
         Values(
             property.Title, property.ListingDate, property.Address, property.Neighborhood, property.City,
-            property.Zone, property.Reference, property.Price, property.ConstructionM2, property.LandM2,
+            property.Zone, property.Reference, property.Price, property.OriginalPrice, property.ConstructionM2, property.LandM2,
             property.IsOccupied, property.IsFurnished, property.Floors, property.Bedrooms, property.Bathrooms,
             property.GarageSize, property.GardenM2, property.GasTypes, property.Amenities, property.Extras,
-            property.Utilities, property.Notes, property.OwnerID, property.UserID, property.PropertyType,
-            property.TransactionType, property.Status,
+            property.Utilities, property.Notes, property.OwnerID, property.UserID, property.BranchID,
+            r.tenantID, property.PropertyType, property.TransactionType, property.Status,
         )
 
     sqlStr, args, err := query.ToSql()
@@ -199,12 +427,13 @@ func (r *PropertyRepository) Create(property *models.Property) (*models.Property
     }
 
     property.ID = uint(id)
+    property.Version = 1
     logrus.Infof("Property created successfully with ID: %d", property.ID)
     return property.ToResponse(), nil
 }
 
 func (r *PropertyRepository) Update(property *models.Property) (*models.PropertyResponse, error) {
-	query := r.qb.Update("properties").
+	query := r.updateProperties().
 		Set("title", property.Title).
 		Set("listing_date", property.ListingDate).
 		Set("address", property.Address).
@@ -229,11 +458,14 @@ func (r *PropertyRepository) Update(property *models.Property) (*models.Property
 		Set("notes", property.Notes).
 		Set("owner_id", property.OwnerID).
 		Set("user_id", property.UserID).
+		Set("branch_id", property.BranchID).
 		Set("property_type", property.PropertyType).
 		Set("transaction_type", property.TransactionType).
 		Set("status", property.Status).
 		Set("updated_at", squirrel.Expr("NOW()")).
+		Set("version", squirrel.Expr("version + 1")).
 		Where(squirrel.Eq{"id": property.ID}).
+		Where(squirrel.Eq{"version": property.Version}).
 		Where(squirrel.Expr("deleted_at IS NULL"))
 
 	sqlStr, args, err := query.ToSql()
@@ -242,7 +474,7 @@ func (r *PropertyRepository) Update(property *models.Property) (*models.Property
 		return nil, err
 	}
 
-	result, err := r.db.Exec(sqlStr, args...)
+	result, err := r.execRecordingPrice(property.PriceChange, sqlStr, args)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to execute query for updating a property")
 		return nil, err
@@ -255,18 +487,327 @@ func (r *PropertyRepository) Update(property *models.Property) (*models.Property
 	}
 
 	if rowsAffected == 0 {
-		logrus.Warnf("No rows updated for Property ID %d. It may not exist or has already been deleted.", property.ID)
-		return nil, errors.New("property not found or already deleted")
+		return nil, r.missedWrite(property.ID, property.Version)
 	}
 
 	logrus.Infof("Property with ID %d updated successfully", property.ID)
-	return property.ToResponse(), nil
+	return r.GetByID(property.ID)
+}
+
+// Patch writes only the given fields of the property, leaving the other
+// columns as they are, and returns the stored property.
+func (r *PropertyRepository) Patch(property *models.Property, fields []string) (*models.PropertyResponse, error) {
+	values := propertyValues(property)
+	query := r.updateProperties()
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q cannot be patched", models.ErrInvalidPatch, field)
+		}
+		query = query.Set(field, value)
+	}
+	query = query.
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": property.ID}).
+		Where(squirrel.Eq{"version": property.Version}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for patching a property")
+		return nil, err
+	}
+
+	result, err := r.execRecordingPrice(property.PriceChange, sqlStr, args)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for patching a property")
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get rows affected after patching a property")
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, r.missedWrite(property.ID, property.Version)
+	}
+
+	logrus.Infof("Property with ID %d patched successfully", property.ID)
+	return r.GetByID(property.ID)
+}
+
+// execRecordingPrice runs a write of the property and, when the write changes
+// the price, records the change in the same transaction. A write that matches
+// no row records nothing.
+func (r *PropertyRepository) execRecordingPrice(change *models.PropertyPriceChange, sqlStr string, args []any) (sql.Result, error) {
+	if change == nil {
+		return r.db.Exec(sqlStr, args...)
+	}
+
+	change.TenantID = r.tenantID
+	change.ChangedAt = time.Now()
+	insert := r.qb.Insert("property_price_changes").
+		Columns("property_id", "tenant_id", "old_price", "new_price", "reason", "changed_by", "changed_at").
+		Values(change.PropertyID, change.TenantID, change.OldPrice, change.NewPrice, change.Reason, change.ChangedBy, change.ChangedAt)
+
+	insertSQL, insertArgs, err := insert.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for recording a price change")
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for changing the price of a property")
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for changing the price of a property")
+		}
+	}()
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return result, err
+	}
+
+	inserted, err := tx.Exec(insertSQL, insertArgs...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for recording a price change")
+		return nil, err
+	}
+	id, err := inserted.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get last insert ID")
+		return nil, err
+	}
+	change.ID = uint(id)
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for changing the price of a property")
+		return nil, err
+	}
+	logrus.Infof("Price of property %d changed from %.2f to %.2f by user %d", change.PropertyID, change.OldPrice, change.NewPrice, change.ChangedBy)
+	return result, nil
+}
+
+// GetPriceHistory lists the price changes of the property, oldest first
+func (r *PropertyRepository) GetPriceHistory(id uint) ([]models.PropertyPriceChange, error) {
+	query := r.qb.Select("id", "property_id", "tenant_id", "old_price", "new_price", "reason", "changed_by", "changed_at").
+		From("property_price_changes").
+		Where(squirrel.Eq{"tenant_id": r.tenantID, "property_id": id}).
+		OrderBy("changed_at ASC", "id ASC")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting the price history of a property")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting the price history of a property")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close price history rows")
+		}
+	}()
+
+	changes := []models.PropertyPriceChange{}
+	for rows.Next() {
+		var change models.PropertyPriceChange
+		if err := rows.Scan(&change.ID, &change.PropertyID, &change.TenantID, &change.OldPrice, &change.NewPrice,
+			&change.Reason, &change.ChangedBy, &change.ChangedAt); err != nil {
+			logrus.WithError(err).Error("Failed to scan a price change")
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Failed to read the price history of a property")
+		return nil, err
+	}
+	return changes, nil
+}
+
+// propertyValues maps the writable columns to the values of the property
+func propertyValues(property *models.Property) map[string]any {
+	return map[string]any{
+		"title":            property.Title,
+		"listing_date":     property.ListingDate,
+		"address":          property.Address,
+		"neighborhood":     property.Neighborhood,
+		"city":             property.City,
+		"zone":             property.Zone,
+		"reference":        property.Reference,
+		"price":            property.Price,
+		"construction_m2":  property.ConstructionM2,
+		"land_m2":          property.LandM2,
+		"is_occupied":      property.IsOccupied,
+		"is_furnished":     property.IsFurnished,
+		"floors":           property.Floors,
+		"bedrooms":         property.Bedrooms,
+		"bathrooms":        property.Bathrooms,
+		"garage_size":      property.GarageSize,
+		"garden_m2":        property.GardenM2,
+		"gas_types":        property.GasTypes,
+		"amenities":        property.Amenities,
+		"extras":           property.Extras,
+		"utilities":        property.Utilities,
+		"notes":            property.Notes,
+		"owner_id":         property.OwnerID,
+		"user_id":          property.UserID,
+		"branch_id":        property.BranchID,
+		"property_type":    property.PropertyType,
+		"transaction_type": property.TransactionType,
+		"status":           property.Status,
+	}
+}
+
+func (r *PropertyRepository) UpdateAgent(id uint, version uint, agentID uint) error {
+	query := r.updateProperties().
+		Set("user_id", agentID).
+		Set("updated_at", squirrel.Expr("NOW()")).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for reassigning a property")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for reassigning a property")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get rows affected after reassigning a property")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missedWrite(id, version)
+	}
+
+	logrus.Infof("Property with ID %d reassigned to agent %d", id, agentID)
+	return nil
+}
+
+// ChangeStatus moves the property from change.FromStatus to change.ToStatus
+// and records the change with it. It fails with
+// models.ErrInvalidStatusTransition when the property left FromStatus since
+// it was read.
+func (r *PropertyRepository) ChangeStatus(change *models.PropertyStatusChange) (*models.PropertyResponse, error) {
+	change.TenantID = r.tenantID
+	change.ChangedAt = time.Now()
+
+	update := r.updateProperties().
+		Set("status", change.ToStatus).
+		Set("updated_at", change.ChangedAt).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": change.PropertyID}).
+		Where(squirrel.Eq{"status": change.FromStatus}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	updateSQL, updateArgs, err := update.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for changing the status of a property")
+		return nil, err
+	}
+
+	insert := r.qb.Insert("property_status_changes").
+		Columns("property_id", "tenant_id", "from_status", "to_status", "reason", "changed_by", "changed_at").
+		Values(change.PropertyID, change.TenantID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedBy, change.ChangedAt)
+
+	insertSQL, insertArgs, err := insert.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for recording a status change")
+		return nil, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for changing the status of a property")
+		return nil, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for changing the status of a property")
+		}
+	}()
+
+	result, err := tx.Exec(updateSQL, updateArgs...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for changing the status of a property")
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get rows affected after changing the status of a property")
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, r.missedStatusChange(change)
+	}
+
+	result, err = tx.Exec(insertSQL, insertArgs...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for recording a status change")
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get last insert ID")
+		return nil, err
+	}
+	change.ID = uint(id)
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for changing the status of a property")
+		return nil, err
+	}
+
+	logrus.Infof("Property with ID %d changed from %s to %s by user %d", change.PropertyID, change.FromStatus, change.ToStatus, change.ChangedBy)
+	return r.GetByID(change.PropertyID)
+}
+
+// missedStatusChange explains why a status change matched no row: either the
+// property is gone or another change got to it first.
+func (r *PropertyRepository) missedStatusChange(change *models.PropertyStatusChange) error {
+	current, err := r.GetByID(change.PropertyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logrus.Warnf("No property found with ID %d or already deleted", change.PropertyID)
+		return errors.New("property not found or already deleted")
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Warnf("Property %d is %s, the status change expected %s", change.PropertyID, current.Status, change.FromStatus)
+	return fmt.Errorf("%w: the property is now %s", models.ErrInvalidStatusTransition, current.Status)
 }
 
-func (r *PropertyRepository) Delete(id uint) error {
-	query := r.qb.Update("properties").
+func (r *PropertyRepository) Delete(id uint, version uint) error {
+	query := r.updateProperties().
 		Set("deleted_at", squirrel.Expr("NOW()")).
+		Set("version", squirrel.Expr("version + 1")).
 		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
 		Where(squirrel.Expr("deleted_at IS NULL"))
 
 	sqlStr, args, err := query.ToSql()
@@ -288,9 +829,23 @@ func (r *PropertyRepository) Delete(id uint) error {
 	}
 
 	if rowsAffected == 0 {
-		logrus.Warnf("No property found with ID %d or already deleted", id)
-		return errors.New("property not found or already deleted")
+		return r.missedWrite(id, version)
 	}
 
 	return nil
+}
+
+// missedWrite explains why a write guarded by a version matched no row:
+// either the property is gone or it changed since that version was read.
+func (r *PropertyRepository) missedWrite(id uint, version uint) error {
+	current, err := r.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		logrus.Warnf("No property found with ID %d or already deleted", id)
+		return errors.New("property not found or already deleted")
+	}
+	if err != nil {
+		return err
+	}
+	logrus.Warnf("Property %d is at version %d, the write expected version %d", id, current.Version, version)
+	return fmt.Errorf("%w: property %d is at version %d", models.ErrStaleVersion, id, current.Version)
 } 
\ No newline at end of file
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+var sessionColumns = []string{
+	"id", "user_id", "refresh_token_hash", "previous_token_hash", "user_agent",
+	"ip_address", "expires_at", "last_used_at", "revoked_at", "two_factor_verified", "created_at",
+}
+
+type SessionRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewSessionRepository(db *sql.DB) ports.SessionRepository {
+	return &SessionRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func scanSession(row squirrel.RowScanner) (*models.Session, error) {
+	var session models.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.RefreshTokenHash,
+		&session.PreviousTokenHash,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.ExpiresAt,
+		&session.LastUsedAt,
+		&session.RevokedAt,
+		&session.TwoFactorVerified,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) Create(session *models.Session) (*models.Session, error) {
+	now := time.Now()
+	query := r.qb.Insert("sessions").
+		Columns("user_id", "refresh_token_hash", "user_agent", "ip_address", "expires_at", "last_used_at", "two_factor_verified", "created_at").
+		Values(session.UserID, session.RefreshTokenHash, session.UserAgent, session.IPAddress, session.ExpiresAt, now, session.TwoFactorVerified, now)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create session")
+		return nil, err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create session")
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for session")
+		return nil, err
+	}
+
+	session.ID = uint(id)
+	session.LastUsedAt = now
+	session.CreatedAt = now
+	logrus.Infof("Session %d created for user %d", session.ID, session.UserID)
+	return session, nil
+}
+
+func (r *SessionRepository) GetByID(id uint) (*models.Session, error) {
+	query := r.qb.Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"id": id})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting session by ID")
+		return nil, err
+	}
+
+	session, err := scanSession(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warnf("No session found with ID: %d", id)
+			return nil, errors.New("session not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting session by ID")
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) GetByRefreshTokenHash(hash string) (*models.Session, error) {
+	query := r.qb.Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Or{
+			squirrel.Eq{"refresh_token_hash": hash},
+			squirrel.Eq{"previous_token_hash": hash},
+		})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting session by refresh token")
+		return nil, err
+	}
+
+	session, err := scanSession(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warn("No session found for the provided refresh token")
+			return nil, errors.New("session not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting session by refresh token")
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) GetActiveByUserID(userID uint) ([]models.Session, error) {
+	query := r.qb.Select(sessionColumns...).
+		From("sessions").
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("revoked_at IS NULL")).
+		Where(squirrel.Gt{"expires_at": time.Now()}).
+		OrderBy("last_used_at DESC")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting active sessions")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting active sessions")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close session rows")
+		}
+	}()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan session row")
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over session rows")
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Rotate swaps the refresh token hash only if currentHash is still the active
+// one, so two concurrent refreshes with the same token cannot both succeed.
+func (r *SessionRepository) Rotate(id uint, currentHash string, newHash string, expiresAt time.Time, client models.ClientInfo) error {
+	query := r.qb.Update("sessions").
+		Set("previous_token_hash", currentHash).
+		Set("refresh_token_hash", newHash).
+		Set("expires_at", expiresAt).
+		Set("last_used_at", time.Now()).
+		Set("ip_address", client.IPAddress).
+		Set("user_agent", client.UserAgent).
+		Where(squirrel.Eq{"id": id, "refresh_token_hash": currentHash}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for rotating refresh token")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for rotating refresh token")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for rotating refresh token")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("Refresh token for session %d was already rotated or revoked", id)
+		return errors.New("session not found or already revoked")
+	}
+	return nil
+}
+
+func (r *SessionRepository) Revoke(id uint) error {
+	query := r.qb.Update("sessions").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for revoking session")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for revoking session")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for revoking session")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No active session found with ID: %d", id)
+		return errors.New("session not found or already revoked")
+	}
+
+	logrus.Infof("Session %d revoked", id)
+	return nil
+}
+
+func (r *SessionRepository) MarkTwoFactorVerified(id uint) error {
+	query := r.qb.Update("sessions").
+		Set("two_factor_verified", true).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for marking session as two-factor verified")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for marking session as two-factor verified")
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllByUserID(userID uint, exceptID uint) error {
+	return revokeUserSessions(r.db, r.qb, userID, exceptID)
+}
+
+// revokeUserSessions is shared with UserRepository so that deleting a user can
+// revoke its sessions inside the same transaction.
+func revokeUserSessions(runner squirrel.BaseRunner, qb squirrel.StatementBuilderType, userID uint, exceptID uint) error {
+	query := qb.Update("sessions").
+		Set("revoked_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("revoked_at IS NULL"))
+	if exceptID != 0 {
+		query = query.Where(squirrel.NotEq{"id": exceptID})
+	}
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for revoking user sessions")
+		return err
+	}
+
+	result, err := runner.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for revoking user sessions")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for revoking user sessions")
+		return err
+	}
+
+	logrus.Infof("Revoked %d sessions for user %d", rowsAffected, userID)
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// SSOStateRepository only sees the attempts started at one tenant, so a
+// callback cannot finish a login that began at another.
+type SSOStateRepository struct {
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
+}
+
+func NewSSOStateRepository(db *sql.DB, tenantID uint) ports.SSOStateRepository {
+	return &SSOStateRepository{
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
+	}
+}
+
+func (r *SSOStateRepository) Create(state *models.SSOLoginState) error {
+	query := r.qb.Insert("sso_login_states").
+		Columns("tenant_id", "state_hash", "code_verifier", "nonce", "expires_at", "created_at").
+		Values(r.tenantID, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt, time.Now())
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create single sign-on state")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create single sign-on state")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for single sign-on state")
+		return err
+	}
+
+	state.ID = uint(id)
+	state.TenantID = r.tenantID
+	return nil
+}
+
+func (r *SSOStateRepository) GetByStateHash(hash string) (*models.SSOLoginState, error) {
+	query := r.qb.Select("id", "state_hash", "code_verifier", "nonce", "expires_at", "used_at", "created_at").
+		From("sso_login_states").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Where(squirrel.Eq{"state_hash": hash})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting single sign-on state")
+		return nil, err
+	}
+
+	var state models.SSOLoginState
+	err = r.db.QueryRow(sqlStr, args...).Scan(
+		&state.ID, &state.StateHash, &state.CodeVerifier, &state.Nonce, &state.ExpiresAt, &state.UsedAt, &state.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warn("No single sign-on state found for the provided state")
+			return nil, errors.New("single sign-on state not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting single sign-on state")
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *SSOStateRepository) Consume(id uint) error {
+	query := r.qb.Update("sso_login_states").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"tenant_id": r.tenantID, "id": id}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for using single sign-on state")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for using single sign-on state")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for using single sign-on state")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("Single sign-on state %d was already used", id)
+		return errors.New("single sign-on state already used")
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+var tenantColumns = []string{"id", "slug", "name", "created_at", "updated_at"}
+
+type TenantRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewTenantRepository(db *sql.DB) ports.TenantRepository {
+	return &TenantRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func scanTenant(row squirrel.RowScanner) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := row.Scan(&tenant.ID, &tenant.Slug, &tenant.Name, &tenant.CreatedAt, &tenant.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+func (r *TenantRepository) GetAll() ([]models.Tenant, error) {
+	query := r.qb.Select(tenantColumns...).
+		From("tenants").
+		OrderBy("slug")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting tenants")
+		return nil, err
+	}
+
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting tenants")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close rows after getting tenants")
+		}
+	}()
+
+	tenants := []models.Tenant{}
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan tenant row")
+			return nil, err
+		}
+		tenants = append(tenants, *tenant)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over tenant rows")
+		return nil, err
+	}
+	return tenants, nil
+}
+
+func (r *TenantRepository) GetByID(id uint) (*models.Tenant, error) {
+	return r.getOne(squirrel.Eq{"id": id}, "ID")
+}
+
+func (r *TenantRepository) GetBySlug(slug string) (*models.Tenant, error) {
+	return r.getOne(squirrel.Eq{"slug": slug}, "slug")
+}
+
+func (r *TenantRepository) getOne(where squirrel.Eq, lookup string) (*models.Tenant, error) {
+	query := r.qb.Select(tenantColumns...).
+		From("tenants").
+		Where(where)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to build SQL query for getting tenant by %s", lookup)
+		return nil, err
+	}
+
+	tenant, err := scanTenant(r.db.QueryRow(sqlStr, args...))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warnf("No tenant found for the provided %s", lookup)
+			return nil, models.ErrTenantNotFound
+		}
+		logrus.WithError(err).Errorf("Failed to execute query for getting tenant by %s", lookup)
+		return nil, err
+	}
+	return tenant, nil
+}
+
+func (r *TenantRepository) Create(tenant *models.Tenant) error {
+	now := time.Now()
+	query := r.qb.Insert("tenants").
+		Columns("slug", "name", "created_at", "updated_at").
+		Values(tenant.Slug, tenant.Name, now, now)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create tenant")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create tenant")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for tenant")
+		return err
+	}
+
+	tenant.ID = uint(id)
+	tenant.CreatedAt = now
+	tenant.UpdatedAt = now
+	logrus.Infof("Tenant %q created with ID: %d", tenant.Slug, tenant.ID)
+	return nil
+}
+
+func (r *TenantRepository) Update(tenant *models.Tenant) error {
+	query := r.qb.Update("tenants").
+		Set("slug", tenant.Slug).
+		Set("name", tenant.Name).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": tenant.ID})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating tenant")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating tenant")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating tenant")
+		return err
+	}
+
+	// MySQL reports 0 rows when nothing changed, so only a missing tenant is an error
+	if rowsAffected == 0 {
+		if _, err := r.GetByID(tenant.ID); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("Tenant with ID: %d updated", tenant.ID)
+	return nil
+}
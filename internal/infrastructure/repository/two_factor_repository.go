@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type TwoFactorRepository struct {
+	db *sql.DB
+	qb squirrel.StatementBuilderType
+}
+
+func NewTwoFactorRepository(db *sql.DB) ports.TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: db,
+		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+	}
+}
+
+func (r *TwoFactorRepository) GetByUserID(userID uint) (*models.TwoFactorCredential, error) {
+	query := r.qb.Select("user_id", "secret", "last_used_step", "confirmed_at", "created_at").
+		From("two_factor_credentials").
+		Where(squirrel.Eq{"user_id": userID})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting two-factor credential")
+		return nil, err
+	}
+
+	var credential models.TwoFactorCredential
+	err = r.db.QueryRow(sqlStr, args...).Scan(
+		&credential.UserID, &credential.Secret, &credential.LastUsedStep, &credential.ConfirmedAt, &credential.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting two-factor credential")
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *TwoFactorRepository) SavePending(userID uint, secret string) error {
+	query := r.qb.Insert("two_factor_credentials").
+		Columns("user_id", "secret", "last_used_step", "created_at").
+		Values(userID, secret, 0, time.Now()).
+		Suffix("ON DUPLICATE KEY UPDATE secret = VALUES(secret), last_used_step = 0, confirmed_at = NULL, created_at = VALUES(created_at)")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to save two-factor credential")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query to save two-factor credential")
+		return err
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) Confirm(userID uint, step int64) error {
+	query := r.qb.Update("two_factor_credentials").
+		Set("confirmed_at", time.Now()).
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Expr("confirmed_at IS NULL"))
+
+	return r.execExpectingRow(query, "confirming two-factor credential", "two-factor enrollment not found or already confirmed")
+}
+
+func (r *TwoFactorRepository) UseStep(userID uint, step int64) error {
+	query := r.qb.Update("two_factor_credentials").
+		Set("last_used_step", step).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.Lt{"last_used_step": step})
+
+	return r.execExpectingRow(query, "using two-factor code", "two-factor code already used")
+}
+
+func (r *TwoFactorRepository) Delete(userID uint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for deleting two-factor credential")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logrus.WithError(err).Error("Failed to roll back two-factor deletion")
+		}
+	}()
+
+	for _, table := range []string{"recovery_codes", "two_factor_credentials"} {
+		sqlStr, args, err := r.qb.Delete(table).Where(squirrel.Eq{"user_id": userID}).ToSql()
+		if err != nil {
+			logrus.WithError(err).Errorf("Failed to build SQL query for deleting from %s", table)
+			return err
+		}
+		if _, err := tx.Exec(sqlStr, args...); err != nil {
+			logrus.WithError(err).Errorf("Failed to execute query for deleting from %s", table)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit two-factor deletion")
+		return err
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for replacing recovery codes")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logrus.WithError(err).Error("Failed to roll back recovery code replacement")
+		}
+	}()
+
+	sqlStr, args, err := r.qb.Delete("recovery_codes").Where(squirrel.Eq{"user_id": userID}).ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for deleting recovery codes")
+		return err
+	}
+	if _, err := tx.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for deleting recovery codes")
+		return err
+	}
+
+	if len(codeHashes) > 0 {
+		now := time.Now()
+		insert := r.qb.Insert("recovery_codes").Columns("user_id", "code_hash", "created_at")
+		for _, hash := range codeHashes {
+			insert = insert.Values(userID, hash, now)
+		}
+		sqlStr, args, err := insert.ToSql()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to build SQL query for inserting recovery codes")
+			return err
+		}
+		if _, err := tx.Exec(sqlStr, args...); err != nil {
+			logrus.WithError(err).Error("Failed to execute query for inserting recovery codes")
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit recovery code replacement")
+		return err
+	}
+	return nil
+}
+
+func (r *TwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) error {
+	query := r.qb.Update("recovery_codes").
+		Set("used_at", time.Now()).
+		Where(squirrel.Eq{"user_id": userID, "code_hash": codeHash}).
+		Where(squirrel.Expr("used_at IS NULL"))
+
+	return r.execExpectingRow(query, "using recovery code", "recovery code not found or already used")
+}
+
+func (r *TwoFactorRepository) execExpectingRow(query squirrel.UpdateBuilder, action string, notFound string) error {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to build SQL query for %s", action)
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to execute query for %s", action)
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to retrieve rows affected for %s", action)
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No rows updated when %s", action)
+		return errors.New(notFound)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// UserIdentityRepository only sees the links of one tenant, so an identity
+// linked at one agency never signs in to another.
+type UserIdentityRepository struct {
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
+}
+
+func NewUserIdentityRepository(db *sql.DB, tenantID uint) ports.UserIdentityRepository {
+	return &UserIdentityRepository{
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
+	}
+}
+
+func (r *UserIdentityRepository) GetBySubject(issuer string, subject string) (*models.UserIdentity, error) {
+	query := r.qb.Select("id", "user_id", "issuer", "subject", "email", "created_at", "last_login_at").
+		From("user_identities").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Where(squirrel.Eq{"issuer": issuer, "subject": subject})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting user identity")
+		return nil, err
+	}
+
+	var identity models.UserIdentity
+	err = r.db.QueryRow(sqlStr, args...).Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject, &identity.Email, &identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		logrus.WithError(err).Error("Failed to execute query for getting user identity")
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *UserIdentityRepository) Create(identity *models.UserIdentity) error {
+	query := r.qb.Insert("user_identities").
+		Columns("tenant_id", "user_id", "issuer", "subject", "email", "created_at", "last_login_at").
+		Values(r.tenantID, identity.UserID, identity.Issuer, identity.Subject, identity.Email, time.Now(), identity.LastLoginAt)
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query to create user identity")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query to create user identity")
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve last insert ID for user identity")
+		return err
+	}
+
+	identity.ID = uint(id)
+	identity.TenantID = r.tenantID
+	logrus.Infof("Identity %s of %s linked to user with ID: %d", identity.Subject, identity.Issuer, identity.UserID)
+	return nil
+}
+
+func (r *UserIdentityRepository) TouchLastLogin(id uint, now time.Time) error {
+	query := r.qb.Update("user_identities").
+		Set("last_login_at", now).
+		Where(squirrel.Eq{"tenant_id": r.tenantID, "id": id})
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating user identity last login")
+		return err
+	}
+
+	if _, err := r.db.Exec(sqlStr, args...); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating user identity last login")
+		return err
+	}
+	return nil
+}
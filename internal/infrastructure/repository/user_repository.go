@@ -3,30 +3,66 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/go-sql-driver/mysql"
 	"github.com/sirupsen/logrus"
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/domain/ports"
 )
 
+var userColumns = []string{
+	"id", "username", "email", "email_verified_at", "role", "branch_id", "tenant_id",
+	"full_name", "phone", "whatsapp", "license_number", "bio", "avatar_url", "commission_rate",
+	"created_at", "updated_at", "version", "deactivated_at", "deleted_at",
+}
+
+// UserRepository only sees the users of one tenant. Every statement is built
+// from selectUsers, updateUsers or carries the tenant_id condition itself.
 type UserRepository struct {
-	db *sql.DB
-	qb squirrel.StatementBuilderType
+	db       *sql.DB
+	qb       squirrel.StatementBuilderType
+	tenantID uint
 }
 
-func NewUserRepository(db *sql.DB) ports.UserRepository {
+func NewUserRepository(db *sql.DB, tenantID uint) ports.UserRepository {
 	return &UserRepository{
-		db: db,
-		qb: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		db:       db,
+		qb:       squirrel.StatementBuilder.PlaceholderFormat(squirrel.Question),
+		tenantID: tenantID,
 	}
 }
 
-func (r *UserRepository) ConsultPassword(email string) (string, error) {
-	query := r.qb.Select("password").
+func (r *UserRepository) selectUsers(columns ...string) squirrel.SelectBuilder {
+	return r.qb.Select(columns...).
 		From("users").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func (r *UserRepository) updateUsers() squirrel.UpdateBuilder {
+	return r.qb.Update("users").
+		Where(squirrel.Eq{"tenant_id": r.tenantID})
+}
+
+func scanUser(row squirrel.RowScanner) (*models.UserResponse, error) {
+	var user models.UserResponse
+	err := row.Scan(
+		&user.ID, &user.Username, &user.Email, &user.EmailVerifiedAt, &user.Role, &user.BranchID, &user.TenantID,
+		&user.FullName, &user.Phone, &user.WhatsApp, &user.LicenseNumber, &user.Bio, &user.AvatarURL, &user.CommissionRate,
+		&user.CreatedAt, &user.UpdatedAt, &user.Version, &user.DeactivatedAt, &user.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) ConsultPassword(email string) (string, error) {
+	query := r.selectUsers("password").
 		Where(squirrel.Eq{"email": email}).
 		Where(squirrel.Expr("deleted_at IS NULL")) // Ensure deleted_at is NULL
 
@@ -51,8 +87,7 @@ func (r *UserRepository) ConsultPassword(email string) (string, error) {
 }
 
 func (r *UserRepository) GetByEmail(email string) (*models.UserResponse, error) {
-	query := r.qb.Select("id", "username", "email", "created_at", "updated_at").
-		From("users").
+	query := r.selectUsers(userColumns...).
 		Where(squirrel.Eq{"email": email}).
 		Where(squirrel.Expr("deleted_at IS NULL")) // Ensure deleted_at is NULL
 
@@ -62,10 +97,7 @@ func (r *UserRepository) GetByEmail(email string) (*models.UserResponse, error)
 		return nil, err
 	}
 
-	var User models.UserResponse
-	err = r.db.QueryRow(sqlStr, args...).Scan(
-		&User.ID, &User.Username, &User.Email, &User.CreatedAt, &User.UpdatedAt,
-	)
+	User, err := scanUser(r.db.QueryRow(sqlStr, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logrus.Warn("No user found with the provided email")
@@ -76,121 +108,177 @@ func (r *UserRepository) GetByEmail(email string) (*models.UserResponse, error)
 	}
 
 	logrus.Infof("User found successfully with email: %s", email)
-	return &User, nil
+	return User, nil
 }
 
 func (r *UserRepository) Create(user *models.User) (*models.UserResponse, error) {
-	query := r.qb.Insert("users").
-		Columns("username", "email", "password", "created_at", "updated_at").
-		Values(user.Username, user.Email, user.Password, time.Now(), time.Now())
+	if err := insertUser(r.db, r.qb, r.tenantID, user); err != nil {
+		return nil, err
+	}
+	return user.ToUserResponse(), nil
+}
+
+// insertUser is shared with the invitation repository, which creates the user
+// inside the transaction that accepts the invitation.
+func insertUser(runner squirrel.BaseRunner, qb squirrel.StatementBuilderType, tenantID uint, user *models.User) error {
+	user.TenantID = tenantID
+	query := qb.Insert("users").
+		Columns("username", "email", "email_verified_at", "password", "role", "branch_id", "tenant_id", "created_at", "updated_at").
+		Values(user.Username, user.Email, user.EmailVerifiedAt, user.Password, user.Role, user.BranchID, user.TenantID, time.Now(), time.Now())
 
 	sql, args, err := query.ToSql()
 	if err != nil {
         logrus.WithError(err).Error("Failed to build SQL query to create user")
-		return nil, err
+		return err
 	}
 
-	result, err := r.db.Exec(sql, args...)
+	result, err := runner.Exec(sql, args...)
 	if err != nil {
         logrus.WithError(err).Error("Failed to execute query to create user")
-		return nil, err
+		return takenError(err)
 	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
         logrus.WithError(err).Error("Failed to retrieve last insert ID")
-		return nil, err
+		return err
 	}
 
 	user.ID = uint(id)
+	user.Version = 1
     logrus.Infof("User created successfully with ID: %d", user.ID)
+	return nil
+}
 
-	return user.ToUserResponse(), nil
+// takenError turns the duplicate key error of the username or email unique
+// index into ErrUsernameTaken or ErrEmailTaken. The checks before a write
+// cannot see a user created concurrently, the index can.
+func takenError(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return err
+	}
+	if strings.Contains(mysqlErr.Message, "idx_users_tenant_username") {
+		return models.ErrUsernameTaken
+	}
+	if strings.Contains(mysqlErr.Message, "idx_users_tenant_email") {
+		return models.ErrEmailTaken
+	}
+	return err
+}
+
+// userKeyset orders user listings, see models.UserSortFields
+var userKeyset = keyset[models.UserResponse]{
+	columns: map[string]sortColumn[models.UserResponse]{
+		"created_at": {column: "created_at", isTime: true, value: func(u *models.UserResponse) any { return u.CreatedAt }},
+		"updated_at": {column: "updated_at", isTime: true, value: func(u *models.UserResponse) any { return u.UpdatedAt }},
+		"username":   {column: "username", value: func(u *models.UserResponse) any { return u.Username }},
+		"email":      {column: "email", value: func(u *models.UserResponse) any { return u.Email }},
+		"full_name":  {column: "full_name", value: func(u *models.UserResponse) any { return u.FullName }},
+	},
+	idColumn: "id",
+	id:       func(u *models.UserResponse) uint { return u.ID },
 }
 
-func (r *UserRepository) GetAll() ([]models.UserResponse, error) {
-	logrus.Info("Retrieving all users from the database")
-	if r.db == nil {
-		logrus.Error("Database connection is nil")
-		return nil, errors.New("database connection is not initialized")
+func (r *UserRepository) GetPage(branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error) {
+	query := r.selectUsers(userColumns...).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+	if branchID != 0 {
+		query = query.Where(squirrel.Eq{"branch_id": branchID})
 	}
-	query := r.qb.Select("id", "username", "email", "created_at", "updated_at").
-		From("users").
-		Where(squirrel.Expr("deleted_at IS NULL")).
-		OrderBy("created_at DESC")
 
-	sql, args, err := query.ToSql()
+	countSQL, countArgs, err := countRows(query).ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for counting users")
+		return nil, err
+	}
+	var total int
+	if err := r.db.QueryRow(countSQL, countArgs...).Scan(&total); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for counting users")
+		return nil, err
+	}
+
+	query, err = userKeyset.paginate(query, page)
+	if err != nil {
+		return nil, err
+	}
+	sqlStr, args, err := query.ToSql()
 	if err != nil {
-		logrus.WithError(err).Error("Failed to build SQL query")
+		logrus.WithError(err).Error("Failed to build SQL query for getting a page of users")
 		return nil, err
 	}
 
-	rows, err := r.db.Query(sql, args...)
+	rows, err := r.db.Query(sqlStr, args...)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to execute query to get all users")
+		logrus.WithError(err).Error("Failed to execute query to get a page of users")
 		return nil, err
 	}
 	defer func() {
-        if err := rows.Close(); err != nil {
-            logrus.WithError(err).Error("Failed to close database rows")
-        }
-    }()
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close database rows")
+		}
+	}()
 
-	var users []models.UserResponse
+	users := []models.UserResponse{}
 	for rows.Next() {
-		var user models.UserResponse
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		user, err := scanUser(rows)
+		if err != nil {
 			logrus.WithError(err).Error("Failed to scan user row")
 			return nil, err
 		}
-		users = append(users, user)
+		users = append(users, *user)
 	}
 	if err := rows.Err(); err != nil {
 		logrus.WithError(err).Error("Error occurred while iterating over user rows")
 		return nil, err
 	}
-	logrus.Infof("Retrieved %d users from the database", len(users))
-	return users, nil
+	return userKeyset.page(users, page, total), nil
 }
 
 func (r *UserRepository) GetByID(id uint) (*models.UserResponse, error) {
-	query := r.qb.Select("id", "username", "email", "created_at", "updated_at").
-		From("users").
+	return r.getUser(id, r.selectUsers(userColumns...).
 		Where(squirrel.And{
 			squirrel.Eq{"id": id},
 			squirrel.Expr("deleted_at IS NULL"),
-	})
+	}))
+}
+
+// GetByIDIncludingDeleted is GetByID also finding deleted users, which can
+// still be restored or purged.
+func (r *UserRepository) GetByIDIncludingDeleted(id uint) (*models.UserResponse, error) {
+	return r.getUser(id, r.selectUsers(userColumns...).
+		Where(squirrel.Eq{"id": id}))
+}
 
+func (r *UserRepository) getUser(id uint, query squirrel.SelectBuilder) (*models.UserResponse, error) {
 	sqlStr, args, err := query.ToSql()
 	if err != nil {
 		logrus.WithError(err).Error("Failed to build SQL query for getting user by ID")
 		return nil, err
 	}
 
-	var user models.UserResponse
-	err = r.db.QueryRow(sqlStr, args...).Scan(
-		&user.ID, &user.Username, &user.Email,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+	user, err := scanUser(r.db.QueryRow(sqlStr, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logrus.Warnf("No user found with ID: %d", id)
-			return nil, errors.New("user not found")
+			return nil, models.ErrUserNotFound
 		}
 		logrus.WithError(err).Error("Failed to execute query for getting user by ID")
 		return nil, err
 	}
 
 	logrus.Infof("User found successfully with ID: %d", id)
-	return &user, nil
+	return user, nil
 }
 
 func (r *UserRepository) Update(user *models.User) (*models.UserResponse, error) {
-	query := r.qb.Update("users").
+	query := r.updateUsers().
 		Set("username", user.Username).
 		Set("email", user.Email).
 		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
 		Where(squirrel.Eq{"id": user.ID}).
+		Where(squirrel.Eq{"version": user.Version}).
 		Where(squirrel.Expr("deleted_at IS NULL"))
 
 	sql, args, err := query.ToSql()
@@ -200,7 +288,7 @@ func (r *UserRepository) Update(user *models.User) (*models.UserResponse, error)
 
 	result, err := r.db.Exec(sql, args...)
 	if err != nil {
-		return nil, err
+		return nil, takenError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -209,25 +297,268 @@ func (r *UserRepository) Update(user *models.User) (*models.UserResponse, error)
 	}
 
 	if rowsAffected == 0 {
-		return nil, errors.New("user not found")
+		return nil, r.missedWrite(user.ID, user.Version)
 	}
 
+	user.Version++
 	return user.ToUserResponse(), nil
 }
 
-func (r *UserRepository) Delete(id uint) error {
-	query := r.qb.Update("users").
+func (r *UserRepository) UpdateProfile(id uint, version uint, profile *models.UserProfile) error {
+	query := r.updateUsers().
+		Set("full_name", profile.FullName).
+		Set("phone", profile.Phone).
+		Set("whatsapp", profile.WhatsApp).
+		Set("license_number", profile.LicenseNumber).
+		Set("bio", profile.Bio).
+		Set("avatar_url", profile.AvatarURL).
+		Set("commission_rate", profile.CommissionRate).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating user profile")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating user profile")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating user profile")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missedWrite(id, version)
+	}
+
+	logrus.Infof("Profile updated for user with ID: %d", id)
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(id uint, hashedPassword string) error {
+	query := r.updateUsers().
+		Set("password", hashedPassword).
+		Set("updated_at", time.Now()).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating user password")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating user password")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating user password")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No user found with ID: %d", id)
+		return errors.New("user not found")
+	}
+
+	logrus.Infof("Password updated for user with ID: %d", id)
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(id uint) error {
+	query := r.updateUsers().
+		Set("email_verified_at", squirrel.Expr("COALESCE(email_verified_at, ?)", time.Now())).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for verifying user email")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for verifying user email")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for verifying user email")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No user found with ID: %d", id)
+		return errors.New("user not found")
+	}
+
+	logrus.Infof("Email verified for user with ID: %d", id)
+	return nil
+}
+
+func (r *UserRepository) UpdateRole(id uint, version uint, role models.UserRole) error {
+	query := r.updateUsers().
+		Set("role", role).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating user role")
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for updating user role")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for updating user role")
+		}
+	}()
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating user role")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating user role")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missedWrite(id, version)
+	}
+
+	// The sessions carry the old role in their tokens, so they must log in
+	// again, and the keys may have scopes the new role lacks
+	if err := revokeUserSessions(tx, r.qb, id, 0); err != nil {
+		return err
+	}
+	if err := revokeUserAPIKeys(tx, r.qb, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for updating user role")
+		return err
+	}
+
+	logrus.Infof("User with ID: %d is now %s", id, role)
+	return nil
+}
+
+func (r *UserRepository) UpdateBranch(id uint, version uint, branchID uint) error {
+	query := r.updateUsers().
+		Set("branch_id", branchID).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for updating user branch")
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for updating user branch")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for updating user branch")
+		}
+	}()
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for updating user branch")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for updating user branch")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return r.missedWrite(id, version)
+	}
+
+	// Tokens and keys are scoped to the branch they were issued in
+	if err := revokeUserSessions(tx, r.qb, id, 0); err != nil {
+		return err
+	}
+	if err := revokeUserAPIKeys(tx, r.qb, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for updating user branch")
+		return err
+	}
+
+	logrus.Infof("User with ID: %d moved to branch %d", id, branchID)
+	return nil
+}
+
+func (r *UserRepository) Delete(id uint, version uint) error {
+	query := r.updateUsers().
 		Set("deleted_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
 		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Eq{"version": version}).
 		Where(squirrel.Expr("deleted_at IS NULL"))
 
-	sql, args, err := query.ToSql()
+	sqlStr, args, err := query.ToSql()
 	if err != nil {
 		logrus.WithError(err).Error("Failed to build SQL query for deleting user")
 		return err
 	}
 
-	result, err := r.db.Exec(sql, args...)
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for deleting user")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for deleting user")
+		}
+	}()
+
+	result, err := tx.Exec(sqlStr, args...)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to execute query for deleting user")
 		return err
@@ -240,12 +571,268 @@ func (r *UserRepository) Delete(id uint) error {
 	}
 
 	if rowsAffected == 0 {
+		return r.missedWrite(id, version)
+	}
+
+	// A deleted user must not keep any device logged in
+	if err := revokeUserSessions(tx, r.qb, id, 0); err != nil {
+		return err
+	}
+	if err := revokeUserAPIKeys(tx, r.qb, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for deleting user")
+		return err
+	}
+
+	logrus.Infof("User with ID: %d deleted successfully", id)
+	return nil
+}
+
+// missedWrite explains why a write guarded by a version matched no row:
+// either the user is gone or it changed since that version was read.
+func (r *UserRepository) missedWrite(id uint, version uint) error {
+	current, err := r.GetByID(id)
+	if err != nil {
 		logrus.Warnf("No user found with ID: %d or already deleted", id)
 		return errors.New("user not found or already deleted")
 	}
+	logrus.Warnf("User %d is at version %d, the write expected version %d", id, current.Version, version)
+	return fmt.Errorf("%w: user %d is at version %d", models.ErrStaleVersion, id, current.Version)
+}
 
-	logrus.Infof("User with ID: %d deleted successfully", id)
+func (r *UserRepository) Deactivate(id uint) error {
+	query := r.updateUsers().
+		Set("deactivated_at", time.Now()).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("deactivated_at IS NULL")).
+		Where(squirrel.Expr("deleted_at IS NULL"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for deactivating user")
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for deactivating user")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for deactivating user")
+		}
+	}()
+
+	result, err := tx.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for deactivating user")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for deactivating user")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No active user found with ID: %d", id)
+		return errors.New("user not found or already deactivated")
+	}
+
+	if err := revokeUserSessions(tx, r.qb, id, 0); err != nil {
+		return err
+	}
+	if err := revokeUserAPIKeys(tx, r.qb, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for deactivating user")
+		return err
+	}
+
+	logrus.Infof("User with ID: %d deactivated", id)
 	return nil
 }
 
+func (r *UserRepository) GetDeleted(branchID uint) ([]models.UserResponse, error) {
+	query := r.selectUsers(userColumns...).
+		Where(squirrel.Expr("deleted_at IS NOT NULL"))
+	if branchID != 0 {
+		query = query.Where(squirrel.Eq{"branch_id": branchID})
+	}
+	query = query.OrderBy("deleted_at DESC")
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for getting deleted users")
+		return nil, err
+	}
 
+	rows, err := r.db.Query(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for getting deleted users")
+		return nil, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logrus.WithError(err).Error("Failed to close database rows")
+		}
+	}()
+
+	users := []models.UserResponse{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to scan deleted user row")
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		logrus.WithError(err).Error("Error occurred while iterating over deleted user rows")
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepository) Restore(id uint) error {
+	query := r.updateUsers().
+		Set("deleted_at", nil).
+		Set("deactivated_at", nil).
+		Set("updated_at", time.Now()).
+		Set("version", squirrel.Expr("version + 1")).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Expr("(deleted_at IS NOT NULL OR deactivated_at IS NOT NULL)"))
+
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for restoring user")
+		return err
+	}
+
+	result, err := r.db.Exec(sqlStr, args...)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to execute query for restoring user")
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve rows affected for restoring user")
+		return err
+	}
+
+	if rowsAffected == 0 {
+		logrus.Warnf("No deleted or deactivated user found with ID: %d", id)
+		return errors.New("user not found or not deleted or deactivated")
+	}
+
+	logrus.Infof("User with ID: %d restored", id)
+	return nil
+}
+
+// userOwnedTables hold rows that only make sense with the user and are
+// removed when it is purged.
+var userOwnedTables = []string{
+	"sessions", "password_reset_tokens", "email_verification_tokens",
+	"recovery_codes", "two_factor_credentials", "api_keys",
+}
+
+func (r *UserRepository) Purge(id uint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to begin transaction for purging user")
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.WithError(err).Error("Failed to rollback transaction for purging user")
+		}
+	}()
+
+	lockQuery := r.selectUsers("deleted_at").
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE")
+
+	sqlStr, args, err := lockQuery.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for locking user to purge")
+		return err
+	}
+
+	var deletedAt *time.Time
+	if err := tx.QueryRow(sqlStr, args...).Scan(&deletedAt); err != nil {
+		if err == sql.ErrNoRows {
+			logrus.Warnf("No user found with ID: %d", id)
+			return errors.New("user not found")
+		}
+		logrus.WithError(err).Error("Failed to execute query for locking user to purge")
+		return err
+	}
+	if deletedAt == nil {
+		logrus.Warnf("User with ID: %d must be deleted before it is purged", id)
+		return errors.New("only deleted users can be purged")
+	}
+
+	// Deleted properties count too, the foreign keys still point at the user
+	countQuery := r.qb.Select("COUNT(*)").
+		From("properties").
+		Where(squirrel.Eq{"tenant_id": r.tenantID}).
+		Where(squirrel.Or{squirrel.Eq{"user_id": id}, squirrel.Eq{"owner_id": id}})
+
+	sqlStr, args, err = countQuery.ToSql()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to build SQL query for counting user properties")
+		return err
+	}
+
+	var properties int
+	if err := tx.QueryRow(sqlStr, args...).Scan(&properties); err != nil {
+		logrus.WithError(err).Error("Failed to execute query for counting user properties")
+		return err
+	}
+	if properties > 0 {
+		logrus.Warnf("User with ID: %d is still assigned to %d properties", id, properties)
+		return fmt.Errorf("%w: reassign its %d properties first", models.ErrUserHasProperties, properties)
+	}
+
+	// The tables in userOwnedTables have no tenant of their own, the lock
+	// above already proved the user belongs to this one
+	statements := []squirrel.Sqlizer{
+		r.qb.Update("invitations").Set("user_id", nil).Where(squirrel.Eq{"tenant_id": r.tenantID, "user_id": id}),
+		r.qb.Update("invitations").Set("invited_by", nil).Where(squirrel.Eq{"tenant_id": r.tenantID, "invited_by": id}),
+		r.qb.Delete("user_identities").Where(squirrel.Eq{"tenant_id": r.tenantID, "user_id": id}),
+	}
+	for _, table := range userOwnedTables {
+		statements = append(statements, r.qb.Delete(table).Where(squirrel.Eq{"user_id": id}))
+	}
+	statements = append(statements, r.qb.Delete("users").Where(squirrel.Eq{"tenant_id": r.tenantID, "id": id}))
+
+	for _, statement := range statements {
+		sqlStr, args, err := statement.ToSql()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to build SQL query for purging user")
+			return err
+		}
+		if _, err := tx.Exec(sqlStr, args...); err != nil {
+			logrus.WithError(err).Errorf("Failed to execute query for purging user: %s", sqlStr)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logrus.WithError(err).Error("Failed to commit transaction for purging user")
+		return err
+	}
+
+	logrus.Infof("User with ID: %d purged permanently", id)
+	return nil
+}
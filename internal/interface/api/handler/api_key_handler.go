@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type APIKeyHandler struct {
+	apiKeyUsecase ports.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(apiKeyUsecase ports.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+// CreateAPIKey handles POST /api/v1/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var keyData models.CreateAPIKeyData
+	if err := c.ShouldBindJSON(&keyData); err != nil {
+		logrus.WithError(err).Error("Invalid API key data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide a name and the scopes of the key",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	apiKey, err := h.apiKeyUsecase.CreateAPIKey(caller, &keyData)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to create API key")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create API key",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    apiKey,
+		"message": "API key created, store it safely as it will not be shown again",
+	})
+}
+
+// GetAPIKeys handles GET /api/v1/api-keys
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	caller, _ := middleware.GetCaller(c)
+	apiKeys, err := h.apiKeyUsecase.GetAPIKeys(caller)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to retrieve API keys")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve API keys",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    apiKeys,
+		"message": "API keys retrieved successfully",
+	})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid API key ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid API key ID",
+			"message": "API key ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.apiKeyUsecase.RevokeAPIKey(caller, uint(keyID)); err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to revoke API key")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "API key not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked successfully",
+	})
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type BranchHandler struct {
+	branchUsecase ports.BranchUseCase
+}
+
+func NewBranchHandler(branchUsecase ports.BranchUseCase) *BranchHandler {
+	return &BranchHandler{
+		branchUsecase: branchUsecase,
+	}
+}
+
+// GetBranches handles GET /api/v1/branches
+func (h *BranchHandler) GetBranches(c *gin.Context) {
+	branches, err := h.branchUsecase.GetBranches()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve branches")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve branches",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    branches,
+		"message": "Branches retrieved successfully",
+	})
+}
+
+// CreateBranch handles POST /api/v1/branches
+func (h *BranchHandler) CreateBranch(c *gin.Context) {
+	var branchData models.BranchData
+	if err := c.ShouldBindJSON(&branchData); err != nil {
+		logrus.WithError(err).Error("Invalid branch data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the name of the branch",
+		})
+		return
+	}
+
+	branch, err := h.branchUsecase.CreateBranch(&branchData)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to create branch")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create branch",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    branch,
+		"message": "Branch created successfully",
+	})
+}
+
+// UpdateBranch handles PUT /api/v1/branches/:id
+func (h *BranchHandler) UpdateBranch(c *gin.Context) {
+	branchID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid branch ID",
+			"message": "Branch ID must be a valid number",
+		})
+		return
+	}
+
+	var branchData models.BranchData
+	if err := c.ShouldBindJSON(&branchData); err != nil {
+		logrus.WithError(err).Error("Invalid branch data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the name of the branch",
+		})
+		return
+	}
+
+	branch, err := h.branchUsecase.UpdateBranch(uint(branchID), &branchData)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update branch")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update branch",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    branch,
+		"message": "Branch updated successfully",
+	})
+}
+
+// AssignUser handles PUT /api/v1/users/:id/branch
+func (h *BranchHandler) AssignUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	var branchData models.UserBranchData
+	if err := c.ShouldBindJSON(&branchData); err != nil {
+		logrus.WithError(err).Error("Invalid branch assignment")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the branch_id of the user",
+		})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	user, err := h.branchUsecase.AssignUser(caller, uint(userID), version, branchData.BranchID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to assign user to branch")
+		if userAccessError(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to assign branch",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "User assigned to branch successfully",
+	})
+}
+
+// branchFilter reads the optional branch_id query parameter of listings,
+// 0 when absent. It responds with 400 and returns false when it is invalid.
+func branchFilter(c *gin.Context) (uint, bool) {
+	raw := c.Query("branch_id")
+	if raw == "" {
+		return 0, true
+	}
+	branchID, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || branchID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid branch ID",
+			"message": "branch_id must be a positive integer",
+		})
+		return 0, false
+	}
+	return uint(branchID), true
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag exposes the version of a resource. Writes send it back in If-Match.
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatch reads the version a write is based on. Every PUT, PATCH and DELETE
+// of a versioned resource must send the ETag it read, so two people editing
+// the same resource cannot silently overwrite each other.
+func ifMatch(c *gin.Context) (uint, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{
+			"error":   "Precondition required",
+			"message": "Send the ETag of the resource in the If-Match header",
+		})
+		return 0, false
+	}
+
+	// Only a single strong ETag can name a version
+	version, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`), 10, 32)
+	if err != nil || version == 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error":   "Precondition failed",
+			"message": "If-Match does not match the ETag of the resource",
+		})
+		return 0, false
+	}
+	return uint(version), true
+}
+
+// preconditionFailed answers a write based on an outdated version
+func preconditionFailed(c *gin.Context, err error) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   "Precondition failed",
+		"message": err.Error() + ", fetch it again and retry",
+	})
+}
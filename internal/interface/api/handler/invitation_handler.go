@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type InvitationHandler struct {
+	invitationUsecase ports.InvitationUseCase
+}
+
+func NewInvitationHandler(invitationUsecase ports.InvitationUseCase) *InvitationHandler {
+	return &InvitationHandler{
+		invitationUsecase: invitationUsecase,
+	}
+}
+
+// CreateInvitation handles POST /api/v1/invitations
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var invitationData models.CreateInvitationData
+	if err := c.ShouldBindJSON(&invitationData); err != nil {
+		logrus.WithError(err).Error("Invalid invitation data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the email and role to invite",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	invitation, err := h.invitationUsecase.CreateInvitation(caller, &invitationData)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to create invitation")
+		if abortIfTaken(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create invitation",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    invitation,
+		"message": "Invitation sent successfully",
+	})
+}
+
+// GetInvitations handles GET /api/v1/invitations
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	caller, _ := middleware.GetCaller(c)
+	invitations, err := h.invitationUsecase.GetInvitations(caller)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to retrieve invitations")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve invitations",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    invitations,
+		"message": "Invitations retrieved successfully",
+		"count":   len(invitations),
+	})
+}
+
+// ResendInvitation handles POST /api/v1/invitations/:id/resend
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	invitation, err := h.invitationUsecase.ResendInvitation(caller, invitationID)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to resend invitation")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to resend invitation",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    invitation,
+		"message": "Invitation resent successfully",
+	})
+}
+
+// RevokeInvitation handles DELETE /api/v1/invitations/:id
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	invitationID, ok := parseInvitationID(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.invitationUsecase.RevokeInvitation(caller, invitationID); err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to revoke invitation")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Invitation not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invitation revoked successfully",
+	})
+}
+
+// AcceptInvitation handles POST /api/v1/invitations/accept
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var acceptData models.AcceptInvitationData
+	if err := c.ShouldBindJSON(&acceptData); err != nil {
+		logrus.WithError(err).Error("Invalid invitation acceptance data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the token, a username and a password",
+		})
+		return
+	}
+
+	user, err := h.invitationUsecase.AcceptInvitation(&acceptData)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to accept invitation")
+		if abortIfTaken(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to accept invitation",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    user,
+		"message": "Invitation accepted, you can now log in",
+	})
+}
+
+func parseInvitationID(c *gin.Context) (uint, bool) {
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid invitation ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid invitation ID",
+			"message": "Invitation ID must be a valid number",
+		})
+		return 0, false
+	}
+	return uint(invitationID), true
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+// mergePatch reads the merge patch in the request body. Clients that cannot
+// set the merge patch media type may send plain JSON.
+func mergePatch(c *gin.Context) (models.MergePatch, bool) {
+	if contentType := c.ContentType(); contentType != MergePatchContentType && contentType != gin.MIMEJSON {
+		c.Header("Accept-Patch", MergePatchContentType)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{
+			"error":   "Unsupported media type",
+			"message": "Send the changes as " + MergePatchContentType,
+		})
+		return nil, false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": "Failed to read the request body",
+		})
+		return nil, false
+	}
+	patch, err := models.ParseMergePatch(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+	return patch, true
+}
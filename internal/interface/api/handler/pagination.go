@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// pageRequest reads limit, offset, cursor and sort from the query string.
+// It only checks that the numbers are numbers, the use case validates the rest.
+func pageRequest(c *gin.Context) (*models.PageRequest, bool) {
+	page := &models.PageRequest{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+	}
+	for name, target := range map[string]*int{"limit": &page.Limit, "offset": &page.Offset} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid pagination",
+				"message": name + " must be an integer",
+			})
+			return nil, false
+		}
+		*target = value
+	}
+	return page, true
+}
+
+// respondWithPage writes the page in the listing envelope and links the
+// first, previous and next pages in the Link header. The next link follows
+// the cursor, the previous link only exists for offset pages.
+func respondWithPage[T any](c *gin.Context, page *models.Page[T], message string) {
+	links := []string{pageLink(c, "first", nil)}
+	if page.Offset > 0 {
+		previous := page.Offset - page.Limit
+		if previous < 0 {
+			previous = 0
+		}
+		links = append(links, pageLink(c, "prev", map[string]string{"offset": strconv.Itoa(previous)}))
+	}
+	if page.NextCursor != "" {
+		links = append(links, pageLink(c, "next", map[string]string{"cursor": page.NextCursor}))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":       items,
+		"message":    message,
+		"pagination": page.Pagination,
+	})
+}
+
+// pageLink is the current request with its page position replaced
+func pageLink(c *gin.Context, rel string, position map[string]string) string {
+	query := c.Request.URL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+	for name, value := range position {
+		query.Set(name, value)
+	}
+	target := *c.Request.URL
+	target.RawQuery = query.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, target.RequestURI(), rel)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type PasswordHandler struct {
+	passwordUsecase ports.PasswordUseCase
+}
+
+func NewPasswordHandler(passwordUsecase ports.PasswordUseCase) *PasswordHandler {
+	return &PasswordHandler{
+		passwordUsecase: passwordUsecase,
+	}
+}
+
+// ForgotPassword handles POST /api/v1/users/password/forgot
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var forgotData models.ForgotPasswordData
+	if err := c.ShouldBindJSON(&forgotData); err != nil || forgotData.Email == "" {
+		logrus.WithError(err).Error("Invalid forgot password data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide an email",
+		})
+		return
+	}
+
+	err := h.passwordUsecase.ForgotPassword(forgotData.Email)
+	if errors.Is(err, models.ErrMailNotSent) {
+		// Answering otherwise would tell that the email is registered
+		logrus.WithError(err).Error("Failed to send password reset email")
+	} else if err != nil {
+		logrus.WithError(err).Error("Failed to start password reset")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to start password reset",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	// Same answer whether or not the email exists
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /api/v1/users/password/reset
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var resetData models.ResetPasswordData
+	if err := c.ShouldBindJSON(&resetData); err != nil || resetData.Token == "" {
+		logrus.WithError(err).Error("Invalid reset password data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the reset token and a new password",
+		})
+		return
+	}
+
+	if err := h.passwordUsecase.ResetPassword(resetData.Token, resetData.Password); err != nil {
+		logrus.WithError(err).Error("Failed to reset password")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to reset password",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset successfully",
+	})
+}
+
+// ChangePassword handles PUT /api/v1/users/:id/password
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	var changeData models.ChangePasswordData
+	if err := c.ShouldBindJSON(&changeData); err != nil || changeData.CurrentPassword == "" {
+		logrus.WithError(err).Error("Invalid change password data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the current password and a new password",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.passwordUsecase.ChangePassword(caller, uint(userID), changeData.CurrentPassword, changeData.NewPassword); err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to change password")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to change password",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password changed successfully",
+	})
+}
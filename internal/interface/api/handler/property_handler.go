@@ -1,14 +1,17 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
 )
 
 type PropertyHandler struct {
@@ -24,25 +27,82 @@ func NewPropertyHandler(propertyUsecase ports.PropertyUseCase) *PropertyHandler
 func (h *PropertyHandler) GetProperties(c *gin.Context) {
 	logrus.Info("GetProperties endpoint called")
 
-	properties, err := h.propertyUsecase.GetAllProperties()
+	filter, pageReq, ok := propertySearch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	page, err := h.propertyUsecase.GetAllProperties(caller, filter, pageReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve properties",
-			"message": err.Error(),
-		})
+		propertySearchError(c, err)
 		return
 	}
 
-	if len(properties) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "No properties found",
-			"message": "No properties available in the database",
-		})
+	logrus.Infof("Retrieved %d of %d properties", len(page.Items), page.Total)
+	respondWithPage(c, page, "Properties retrieved successfully")
+}
+
+// GetPropertyCards handles GET /api/v1/properties/cards, the listing of the
+// catalog grid. It takes the same filters and pagination as GetProperties.
+func (h *PropertyHandler) GetPropertyCards(c *gin.Context) {
+	logrus.Info("GetPropertyCards endpoint called")
+
+	filter, pageReq, ok := propertySearch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	page, err := h.propertyUsecase.GetPropertyCards(caller, filter, pageReq)
+	if err != nil {
+		propertySearchError(c, err)
 		return
 	}
 
-	logrus.Infof("Retrieved %d properties", len(properties))
-	c.JSON(http.StatusOK, properties)
+	logrus.Infof("Retrieved %d of %d property cards", len(page.Items), page.Total)
+	respondWithPage(c, page, "Properties retrieved successfully")
+}
+
+// propertySearch reads the filters and pagination of the property listings
+func propertySearch(c *gin.Context) (*models.PropertyFilter, *models.PageRequest, bool) {
+	branchID, ok := branchFilter(c)
+	if !ok {
+		return nil, nil, false
+	}
+	var filter models.PropertyFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		logrus.WithError(err).Error("Invalid property filter")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property filter",
+			"message": "Numeric filters must be numbers and furnished/occupied must be true or false",
+		})
+		return nil, nil, false
+	}
+	filter.BranchID = branchID
+	pageReq, ok := pageRequest(c)
+	if !ok {
+		return nil, nil, false
+	}
+	return &filter, pageReq, true
+}
+
+func propertySearchError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrForbidden) {
+		middleware.AbortForbidden(c, err.Error())
+		return
+	}
+	if errors.Is(err, models.ErrInvalidFilter) || errors.Is(err, models.ErrInvalidPage) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property search",
+			"message": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error":   "Failed to retrieve properties",
+		"message": err.Error(),
+	})
 }
 
 func (h *PropertyHandler) GetPropertyByID(c *gin.Context) {
@@ -59,7 +119,12 @@ func (h *PropertyHandler) GetPropertyByID(c *gin.Context) {
 		return
 	}
 
-	property, err := h.propertyUsecase.GetPropertyByID(uint(id))
+	caller, _ := middleware.GetCaller(c)
+	property, err := h.propertyUsecase.GetPropertyByID(caller, uint(id))
+	if errors.Is(err, models.ErrPropertyNotFound) {
+		propertyNotFound(c)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to retrieve property",
@@ -69,17 +134,52 @@ func (h *PropertyHandler) GetPropertyByID(c *gin.Context) {
 	}
 
 	if property == nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Property not found",
-			"message": "No property found with the given ID",
-		})
+		propertyNotFound(c)
 		return
 	}
 
 	logrus.Infof("Retrieved property with ID: %d", id)
+	setETag(c, property.Version)
 	c.JSON(http.StatusOK, property)
 }
 
+// GetPriceHistory handles GET /api/v1/properties/:id/price-history, listing
+// the price changes of the property oldest first.
+func (h *PropertyHandler) GetPriceHistory(c *gin.Context) {
+	logrus.Info("GetPriceHistory endpoint called")
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		logrus.WithError(err).Error("Invalid property ID")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property ID",
+			"message": "Property ID must be a positive integer",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	changes, err := h.propertyUsecase.GetPriceHistory(caller, uint(id))
+	if err != nil {
+		if errors.Is(err, models.ErrPropertyNotFound) {
+			propertyNotFound(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve price history",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	logrus.Infof("Retrieved %d price changes of property %d", len(changes), id)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    changes,
+		"message": "Price history retrieved successfully",
+		"count":   len(changes),
+	})
+}
+
 func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	logrus.Info("CreateProperty endpoint called")
 
@@ -93,8 +193,21 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 		return
 	}
 
-	newProperty, err := h.propertyUsecase.CreateProperty(&property)
+	caller, _ := middleware.GetCaller(c)
+	newProperty, err := h.propertyUsecase.CreateProperty(caller, &property)
 	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrInvalidStatusTransition) {
+			invalidStatus(c, err)
+			return
+		}
+		if errors.Is(err, models.ErrInvalidAgent) {
+			invalidAgent(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create property",
 			"message": err.Error(),
@@ -103,6 +216,7 @@ func (h *PropertyHandler) CreateProperty(c *gin.Context) {
 	}
 
 	logrus.Infof("Property created successfully with ID: %d", newProperty.ID)
+	setETag(c, newProperty.Version)
 	c.JSON(http.StatusCreated, newProperty)
 }
 
@@ -118,20 +232,227 @@ func (h *PropertyHandler) UpdateProperty(c *gin.Context) {
 		})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+	property.Version = version
 
-	updatedProperty, err := h.propertyUsecase.UpdateProperty(&property)
+	caller, _ := middleware.GetCaller(c)
+	updatedProperty, err := h.propertyUsecase.UpdateProperty(caller, &property)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update property",
-			"message": err.Error(),
-		})
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			middleware.AbortForbidden(c, err.Error())
+		case errors.Is(err, models.ErrPropertyNotFound):
+			propertyNotFound(c)
+		case errors.Is(err, models.ErrStaleVersion):
+			preconditionFailed(c, err)
+		case errors.Is(err, models.ErrInvalidStatusTransition):
+			invalidStatus(c, err)
+		case errors.Is(err, models.ErrInvalidAgent):
+			invalidAgent(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update property",
+				"message": err.Error(),
+			})
+		}
 		return
 	}
 
 	logrus.Infof("Property updated successfully with ID: %d", updatedProperty.ID)
+	setETag(c, updatedProperty.Version)
 	c.JSON(http.StatusOK, updatedProperty)
 }
 
+// PatchProperty handles PATCH /api/v1/properties/:id with a JSON Merge Patch
+func (h *PropertyHandler) PatchProperty(c *gin.Context) {
+	logrus.Info("PatchProperty endpoint called")
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		logrus.WithError(err).Error("Invalid property ID")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property ID",
+			"message": "Property ID must be a positive integer",
+		})
+		return
+	}
+	patch, ok := mergePatch(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	updatedProperty, err := h.propertyUsecase.PatchProperty(caller, uint(id), version, patch)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			middleware.AbortForbidden(c, err.Error())
+		case errors.Is(err, models.ErrPropertyNotFound):
+			propertyNotFound(c)
+		case errors.Is(err, models.ErrStaleVersion):
+			preconditionFailed(c, err)
+		case errors.Is(err, models.ErrInvalidPatch):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid patch",
+				"message": err.Error(),
+			})
+		case errors.Is(err, models.ErrInvalidStatusTransition):
+			invalidStatus(c, err)
+		case errors.Is(err, models.ErrInvalidAgent):
+			invalidAgent(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update property",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	logrus.Infof("Property patched successfully with ID: %d", updatedProperty.ID)
+	setETag(c, updatedProperty.Version)
+	c.JSON(http.StatusOK, updatedProperty)
+}
+
+func (h *PropertyHandler) ReassignProperty(c *gin.Context) {
+	logrus.Info("ReassignProperty endpoint called")
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		logrus.WithError(err).Error("Invalid property ID")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property ID",
+			"message": "Property ID must be a positive integer",
+		})
+		return
+	}
+
+	var assignment models.PropertyAssignmentData
+	if err := c.ShouldBindJSON(&assignment); err != nil || assignment.UserID == 0 {
+		logrus.WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": "Please provide the user_id of the new agent",
+		})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	property, err := h.propertyUsecase.ReassignProperty(caller, uint(id), version, assignment.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			middleware.AbortForbidden(c, err.Error())
+		case errors.Is(err, models.ErrStaleVersion):
+			preconditionFailed(c, err)
+		case errors.Is(err, models.ErrPropertyNotFound):
+			propertyNotFound(c)
+		case errors.Is(err, models.ErrInvalidAgent):
+			invalidAgent(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to reassign property",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	logrus.Infof("Property %d reassigned to agent %d", id, assignment.UserID)
+	setETag(c, property.Version)
+	c.JSON(http.StatusOK, property)
+}
+
+// ChangePropertyStatus handles POST /api/v1/properties/:id/status. The body
+// names the new status and the reason for the change.
+func (h *PropertyHandler) ChangePropertyStatus(c *gin.Context) {
+	logrus.Info("ChangePropertyStatus endpoint called")
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		logrus.WithError(err).Error("Invalid property ID")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid property ID",
+			"message": "Property ID must be a positive integer",
+		})
+		return
+	}
+
+	var request models.PropertyStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil || request.Status == "" || strings.TrimSpace(request.Reason) == "" {
+		logrus.WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"message": "Please provide the new status and the reason for the change",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	property, err := h.propertyUsecase.ChangePropertyStatus(caller, uint(id), &request)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			middleware.AbortForbidden(c, err.Error())
+		case errors.Is(err, models.ErrPropertyNotFound):
+			propertyNotFound(c)
+		case errors.Is(err, models.ErrInvalidStatusTransition):
+			invalidStatus(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to change property status",
+				"message": err.Error(),
+			})
+		}
+		return
+	}
+
+	logrus.Infof("Property %d is now %s", id, property.Status)
+	setETag(c, property.Version)
+	c.JSON(http.StatusOK, property)
+}
+
+// propertyNotFound answers for a property that does not exist or that the
+// caller may not see
+func propertyNotFound(c *gin.Context) {
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":   "Property not found",
+		"message": "No property found with the given ID",
+	})
+}
+
+// invalidStatus answers a write that would put a property in a status it
+// cannot have or reach
+func invalidStatus(c *gin.Context, err error) {
+	logrus.WithError(err).Warn("Refused property status")
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "Invalid status transition",
+		"message": err.Error(),
+	})
+}
+
+// invalidAgent answers a write that would assign the property to a user who
+// cannot hold it
+func invalidAgent(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid agent",
+		"message": err.Error(),
+	})
+}
+
 func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 	logrus.Info("DeleteProperty endpoint called")
 
@@ -146,12 +467,27 @@ func (h *PropertyHandler) DeleteProperty(c *gin.Context) {
 		return
 	}
 
-	err = h.propertyUsecase.DeleteProperty(uint(id))
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	err = h.propertyUsecase.DeleteProperty(caller, uint(id), version)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete property",
-			"message": err.Error(),
-		})
+		switch {
+		case errors.Is(err, models.ErrForbidden):
+			middleware.AbortForbidden(c, err.Error())
+		case errors.Is(err, models.ErrPropertyNotFound):
+			propertyNotFound(c)
+		case errors.Is(err, models.ErrStaleVersion):
+			preconditionFailed(c, err)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to delete property",
+				"message": err.Error(),
+			})
+		}
 		return
 	}
 
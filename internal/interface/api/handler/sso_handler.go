@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type SSOHandler struct {
+	ssoUsecase ports.SSOUseCase
+}
+
+func NewSSOHandler(ssoUsecase ports.SSOUseCase) *SSOHandler {
+	return &SSOHandler{
+		ssoUsecase: ssoUsecase,
+	}
+}
+
+// StartLogin handles GET /api/v1/users/login/sso
+func (h *SSOHandler) StartLogin(c *gin.Context) {
+	authorization, err := h.ssoUsecase.StartLogin()
+	if err != nil {
+		if abortIfSSONotConfigured(c, err) {
+			return
+		}
+		logrus.WithError(err).Error("Failed to start single sign-on")
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "Single sign-on unavailable",
+			"message": "The identity provider could not be reached, please try again later",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    authorization,
+		"message": "Continue the sign-in at the identity provider",
+	})
+}
+
+// Callback handles GET /api/v1/users/login/sso/callback, where the identity
+// provider sends the browser back with ?code=&state= or ?error=.
+func (h *SSOHandler) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		logrus.Warnf("Identity provider returned %s: %s", providerError, c.Query("error_description"))
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "The sign-in was cancelled or refused by the identity provider",
+		})
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		logrus.Error("Single sign-on callback without state or code")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "The state and code query parameters are required",
+		})
+		return
+	}
+
+	loginResponse, err := h.ssoUsecase.CompleteLogin(state, code, clientInfo(c))
+	if err != nil {
+		if abortIfSSONotConfigured(c, err) || abortIfLoginRefused(c, err) {
+			return
+		}
+		logrus.WithError(err).Error("Single sign-on failed")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if loginResponse.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"data":    loginResponse,
+			"message": "Two-factor authentication required",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":    loginResponse,
+		"message": "Login successful",
+	})
+}
+
+func abortIfSSONotConfigured(c *gin.Context, err error) bool {
+	if !errors.Is(err, models.ErrSSONotConfigured) {
+		return false
+	}
+	c.JSON(http.StatusNotFound, gin.H{
+		"error":   "Not found",
+		"message": "Single sign-on is not configured",
+	})
+	return true
+}
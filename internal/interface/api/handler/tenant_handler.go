@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// TenantHandler serves the provisioning endpoints of the platform, which sit
+// outside of every tenant.
+type TenantHandler struct {
+	tenantUsecase ports.TenantUseCase
+}
+
+func NewTenantHandler(tenantUsecase ports.TenantUseCase) *TenantHandler {
+	return &TenantHandler{
+		tenantUsecase: tenantUsecase,
+	}
+}
+
+// GetTenants handles GET /api/v1/tenants
+func (h *TenantHandler) GetTenants(c *gin.Context) {
+	tenants, err := h.tenantUsecase.GetTenants()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to retrieve tenants")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve tenants",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tenants,
+		"message": "Tenants retrieved successfully",
+	})
+}
+
+// GetTenant handles GET /api/v1/tenants/:id
+func (h *TenantHandler) GetTenant(c *gin.Context) {
+	tenantID, ok := tenantIDParam(c)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.tenantUsecase.GetTenant(tenantID)
+	if err != nil {
+		tenantError(c, "Failed to retrieve tenant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tenant,
+		"message": "Tenant retrieved successfully",
+	})
+}
+
+// CreateTenant handles POST /api/v1/tenants
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var tenantData models.TenantData
+	if err := c.ShouldBindJSON(&tenantData); err != nil {
+		logrus.WithError(err).Error("Invalid tenant data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the slug, name and admin_email of the tenant",
+		})
+		return
+	}
+
+	tenant, err := h.tenantUsecase.CreateTenant(&tenantData)
+	if err != nil {
+		tenantError(c, "Failed to create tenant", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    tenant,
+		"message": "Tenant created successfully, an invitation was sent to its admin",
+	})
+}
+
+// UpdateTenant handles PUT /api/v1/tenants/:id
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	tenantID, ok := tenantIDParam(c)
+	if !ok {
+		return
+	}
+
+	var tenantData models.TenantData
+	if err := c.ShouldBindJSON(&tenantData); err != nil {
+		logrus.WithError(err).Error("Invalid tenant data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the slug and name of the tenant",
+		})
+		return
+	}
+
+	tenant, err := h.tenantUsecase.UpdateTenant(tenantID, &tenantData)
+	if err != nil {
+		tenantError(c, "Failed to update tenant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    tenant,
+		"message": "Tenant updated successfully",
+	})
+}
+
+// InviteAdmin handles POST /api/v1/tenants/:id/admins
+func (h *TenantHandler) InviteAdmin(c *gin.Context) {
+	tenantID, ok := tenantIDParam(c)
+	if !ok {
+		return
+	}
+
+	var adminData models.TenantAdminData
+	if err := c.ShouldBindJSON(&adminData); err != nil {
+		logrus.WithError(err).Error("Invalid tenant admin data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the email of the admin",
+		})
+		return
+	}
+
+	invitation, err := h.tenantUsecase.InviteAdmin(tenantID, &adminData)
+	if err != nil {
+		tenantError(c, "Failed to invite tenant admin", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    invitation,
+		"message": "Invitation sent successfully",
+	})
+}
+
+func tenantIDParam(c *gin.Context) (uint, bool) {
+	tenantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid tenant ID",
+			"message": "Tenant ID must be a valid number",
+		})
+		return 0, false
+	}
+	return uint(tenantID), true
+}
+
+func tenantError(c *gin.Context, message string, err error) {
+	logrus.WithError(err).Error(message)
+	status := http.StatusBadRequest
+	if errors.Is(err, models.ErrTenantNotFound) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{
+		"error":   message,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase ports.TwoFactorUseCase
+}
+
+func NewTwoFactorHandler(twoFactorUsecase ports.TwoFactorUseCase) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorUsecase: twoFactorUsecase,
+	}
+}
+
+// Enroll handles POST /api/v1/users/2fa/enroll
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	caller, _ := middleware.GetCaller(c)
+	enrollment, err := h.twoFactorUsecase.Enroll(caller)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to start two-factor enrollment")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to start two-factor enrollment",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    enrollment,
+		"message": "Scan the URI with an authenticator app and confirm with a code",
+	})
+}
+
+// Confirm handles POST /api/v1/users/2fa/confirm
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	var codeData models.TwoFactorCodeData
+	if err := c.ShouldBindJSON(&codeData); err != nil || codeData.Code == "" {
+		logrus.WithError(err).Error("Invalid two-factor code data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the code from the authenticator app",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	recoveryCodes, err := h.twoFactorUsecase.Confirm(caller, codeData.Code)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to confirm two-factor enrollment")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to confirm two-factor authentication",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    recoveryCodes,
+		"message": "Two-factor authentication enabled, store the recovery codes safely",
+	})
+}
+
+// Disable handles POST /api/v1/users/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var codeData models.TwoFactorCodeData
+	if err := c.ShouldBindJSON(&codeData); err != nil || codeData.Code == "" {
+		logrus.WithError(err).Error("Invalid two-factor code data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the code from the authenticator app",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.twoFactorUsecase.Disable(caller, codeData.Code); err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to disable two-factor authentication")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to disable two-factor authentication",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -9,6 +11,7 @@ import (
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
 )
 
 type UserHandler struct {
@@ -33,7 +36,11 @@ func (h *UserHandler) UserLogin(c *gin.Context) {
 		return
 	}
 
-	if err := h.userUsecase.Login(loginData.Email, loginData.Password); err != nil {
+	loginResponse, err := h.userUsecase.Login(loginData.Email, loginData.Password, clientInfo(c))
+	if err != nil {
+		if abortIfThrottled(c, err) || abortIfLoginRefused(c, err) {
+			return
+		}
 		logrus.WithError(err).Error("Login failed")
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "Unauthorized",
@@ -41,42 +48,243 @@ func (h *UserHandler) UserLogin(c *gin.Context) {
 		})
 		return
 	}
+
+	if loginResponse.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"data":    loginResponse,
+			"message": "Two-factor authentication required",
+		})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{
+		"data":    loginResponse,
 		"message": "Login successful",
 	})
 }
 
-// GetUsers handles GET /api/v1/users
-func (h *UserHandler) GetUsers(c *gin.Context) {
-	logrus.Info("GetUsers endpoint called")
+// VerifyTwoFactorLogin handles POST /api/v1/users/login/2fa
+func (h *UserHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var twoFactorData models.TwoFactorLoginData
+	if err := c.ShouldBindJSON(&twoFactorData); err != nil || twoFactorData.ChallengeToken == "" ||
+		(twoFactorData.Code == "" && twoFactorData.RecoveryCode == "") {
+		logrus.WithError(err).Error("Invalid two-factor login data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the challenge token and a code or recovery code",
+		})
+		return
+	}
 
-	// Use the actual usecase to get users from database
-	users, err := h.userUsecase.GetAllUsers()
+	loginResponse, err := h.userUsecase.CompleteTwoFactorLogin(twoFactorData.ChallengeToken, twoFactorData.Code, twoFactorData.RecoveryCode, clientInfo(c))
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get users")
+		if abortIfThrottled(c, err) {
+			return
+		}
+		logrus.WithError(err).Error("Two-factor login failed")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "Invalid or expired two-factor code",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    loginResponse,
+		"message": "Login successful",
+	})
+}
+
+// abortIfLoginRefused answers 403 when the credentials were right but the
+// account may not log in.
+func abortIfLoginRefused(c *gin.Context, err error) bool {
+	if errors.Is(err, models.ErrAccountDeactivated) {
+		logrus.WithError(err).Warn("Login refused")
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Account deactivated",
+			"message": "This account has been deactivated, please contact an administrator",
+		})
+		return true
+	}
+	if errors.Is(err, models.ErrEmailNotVerified) {
+		logrus.WithError(err).Warn("Login refused")
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "Email not verified",
+			"message": "Please verify your email address before logging in, a new link can be requested",
+		})
+		return true
+	}
+	return false
+}
+
+// abortIfThrottled answers 429 with a Retry-After header when err is a login lockout.
+func abortIfThrottled(c *gin.Context, err error) bool {
+	var locked *models.LoginLockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+	logrus.WithError(err).Warn("Login throttled")
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":   "Too many login attempts",
+		"message": "Too many failed login attempts, please try again later",
+	})
+	return true
+}
+
+// RefreshToken handles POST /api/v1/users/refresh
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var refreshData models.RefreshTokenData
+	if err := c.ShouldBindJSON(&refreshData); err != nil || refreshData.RefreshToken == "" {
+		logrus.WithError(err).Error("Invalid refresh data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Failed to parse refresh token",
+		})
+		return
+	}
+
+	loginResponse, err := h.userUsecase.RefreshSession(refreshData.RefreshToken, clientInfo(c))
+	if err != nil {
+		logrus.WithError(err).Error("Token refresh failed")
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "Invalid or expired refresh token",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    loginResponse,
+		"message": "Token refreshed successfully",
+	})
+}
+
+// Logout handles POST /api/v1/users/logout
+func (h *UserHandler) Logout(c *gin.Context) {
+	sessionID, ok := middleware.GetSessionID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "No active session",
+		})
+		return
+	}
+
+	if err := h.userUsecase.Logout(sessionID); err != nil {
+		logrus.WithError(err).Error("Failed to logout")
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to retrieve users",
+			"error":   "Failed to logout",
 			"message": err.Error(),
 		})
 		return
 	}
 
-	if len(users) == 0 {
-		logrus.Warn("No users found")
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "No users found",
-			"message": "No users available in the database",
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logout successful",
+	})
+}
+
+// GetSessions handles GET /api/v1/users/sessions
+func (h *UserHandler) GetSessions(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "No authenticated user",
+		})
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	sessions, err := h.userUsecase.GetSessions(userID, sessionID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get sessions")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve sessions",
+			"message": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":    users,
-		"message": "Users retrieved successfully",
-		"count":   len(users),
+		"data":    sessions,
+		"message": "Sessions retrieved successfully",
+		"count":   len(sessions),
 	})
 }
 
+// RevokeSession handles DELETE /api/v1/users/sessions/:id
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "No authenticated user",
+		})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid session ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid session ID",
+			"message": "Session ID must be a valid number",
+		})
+		return
+	}
+
+	if err := h.userUsecase.RevokeSession(userID, uint(sessionID)); err != nil {
+		logrus.WithError(err).Error("Failed to revoke session")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Session not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetUsers handles GET /api/v1/users
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	logrus.Info("GetUsers endpoint called")
+
+	branchID, ok := branchFilter(c)
+	if !ok {
+		return
+	}
+
+	pageReq, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	page, err := h.userUsecase.GetAllUsers(caller, branchID, pageReq)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		if errors.Is(err, models.ErrInvalidPage) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid pagination",
+				"message": err.Error(),
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to get users")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve users",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	respondWithPage(c, page, "Users retrieved successfully")
+}
+
 // GetUserByID handles GET /api/v1/users/:id
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -93,8 +301,8 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	// Use the actual usecase to get user from database
-	user, err := h.userUsecase.GetUserByID(uint(userID))
+	caller, _ := middleware.GetCaller(c)
+	user, err := h.userUsecase.GetUserByID(caller, uint(userID))
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get user")
 		c.JSON(http.StatusNotFound, gin.H{
@@ -103,6 +311,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		})
 		return
 	}
+	setETag(c, user.Version)
 
 	c.JSON(http.StatusOK, gin.H{
 		"data":    user,
@@ -110,30 +319,77 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	})
 }
 
-func (h *UserHandler) CreateUser(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		logrus.WithError(err).Error("Invalid request body")
+// GetMe handles GET /api/v1/users/me
+func (h *UserHandler) GetMe(c *gin.Context) {
+	caller, ok := middleware.GetCaller(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "No authenticated user",
+		})
+		return
+	}
+
+	user, err := h.userUsecase.GetUserByID(caller, caller.UserID)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get own profile")
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Profile retrieved successfully",
+	})
+}
+
+// UpdateMe handles PUT /api/v1/users/me
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "Unauthorized",
+			"message": "No authenticated user",
+		})
+		return
+	}
+
+	var profile models.UserProfile
+	if err := c.ShouldBindJSON(&profile); err != nil {
+		logrus.WithError(err).Error("Invalid profile data")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request",
-			"message": "Failed to parse user data",
+			"message": "Failed to parse profile data",
 		})
 		return
 	}
 
-	userResponse, err := h.userUsecase.CreateUser(&user)
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUsecase.UpdateProfile(userID, version, &profile)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create user")
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create user",
+		logrus.WithError(err).Error("Failed to update own profile")
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update profile",
 			"message": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"data":    userResponse,
-		"message": "User created successfully",
+	setETag(c, user.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Profile updated successfully",
 	})
 }
 
@@ -147,10 +403,23 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		})
 		return
 	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+	user.Version = version
 
-	UserResponse, err := h.userUsecase.UpdateUser(&user)
+	caller, _ := middleware.GetCaller(c)
+	UserResponse, err := h.userUsecase.UpdateUser(caller, &user)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to update user")
+		if userAccessError(c, err) || abortIfTaken(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update user",
 			"message": err.Error(),
@@ -158,12 +427,234 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	setETag(c, UserResponse.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"data":    UserResponse,
 		"message": "User updated successfully",
 	})
 }
 
+// PatchUser handles PATCH /api/v1/users/:id with a JSON Merge Patch
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+	patch, ok := mergePatch(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	userResponse, err := h.userUsecase.PatchUser(caller, uint(userID), version, patch)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to patch user")
+		if userAccessError(c, err) || abortIfTaken(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
+		status := http.StatusInternalServerError
+		if errors.Is(err, models.ErrInvalidPatch) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
+			"error":   "Failed to update user",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, userResponse.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    userResponse,
+		"message": "User updated successfully",
+	})
+}
+
+// UpdateUserRole handles PUT /api/v1/users/:id/role
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	var roleData models.UserRoleData
+	if err := c.ShouldBindJSON(&roleData); err != nil || !roleData.Role.IsValid() {
+		logrus.WithError(err).Error("Invalid role data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Role must be one of admin, agent or assistant",
+		})
+		return
+	}
+
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	userResponse, err := h.userUsecase.UpdateUserRole(caller, uint(userID), version, roleData.Role)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update user role")
+		if userAccessError(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update user role",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setETag(c, userResponse.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"data":    userResponse,
+		"message": "User role updated successfully",
+	})
+}
+
+// UnlockUser handles POST /api/v1/users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.userUsecase.UnlockUser(caller, uint(userID)); err != nil {
+		logrus.WithError(err).Error("Failed to unlock user")
+		if userAccessError(c, err) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked successfully",
+	})
+}
+
+// VerifyEmail handles POST /api/v1/users/email/verify
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var verifyData models.VerifyEmailData
+	if err := c.ShouldBindJSON(&verifyData); err != nil || verifyData.Token == "" {
+		logrus.WithError(err).Error("Invalid email verification data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide the verification token",
+		})
+		return
+	}
+
+	user, err := h.userUsecase.VerifyEmail(verifyData.Token)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to verify email")
+		if abortIfTaken(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to verify email",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Email verified successfully",
+	})
+}
+
+// ResendEmailVerification handles POST /api/v1/users/email/resend
+func (h *UserHandler) ResendEmailVerification(c *gin.Context) {
+	var resendData models.ResendVerificationData
+	if err := c.ShouldBindJSON(&resendData); err != nil || resendData.Email == "" {
+		logrus.WithError(err).Error("Invalid email verification resend data")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"message": "Please provide an email",
+		})
+		return
+	}
+
+	if err := h.userUsecase.ResendEmailVerification(resendData.Email); err != nil {
+		logrus.WithError(err).Error("Failed to resend verification email")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to resend verification email",
+			"message": "Please try again later",
+		})
+		return
+	}
+
+	// Same answer whether or not the email exists
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If the email is registered and not verified yet, a new link has been sent",
+	})
+}
+
+// VerifyUserEmail handles POST /api/v1/users/:id/verify-email
+func (h *UserHandler) VerifyUserEmail(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	user, err := h.userUsecase.MarkEmailVerified(caller, uint(userID))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to verify user email")
+		if userAccessError(c, err) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Email verified successfully",
+	})
+}
+
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userIDStr := c.Param("id")
 	logrus.Infof("DeleteUser endpoint called with ID: %s", userIDStr)
@@ -180,8 +671,21 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userUsecase.DeleteUser(uint(userID)); err != nil {
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.userUsecase.DeleteUser(caller, uint(userID), version); err != nil {
 		logrus.WithError(err).Error("Failed to delete user")
+		if userAccessError(c, err) {
+			return
+		}
+		if errors.Is(err, models.ErrStaleVersion) {
+			preconditionFailed(c, err)
+			return
+		}
 		logrus.Info("🔍 DEBUG: Returning 500 (Internal Server Error)")  // ← Debug
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete user",
@@ -194,4 +698,172 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// DeactivateUser handles POST /api/v1/users/:id/deactivate
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	user, err := h.userUsecase.DeactivateUser(caller, uint(userID))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to deactivate user")
+		if userAccessError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to deactivate user",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "User deactivated successfully",
+	})
+}
+
+// GetDeletedUsers handles GET /api/v1/users/deleted
+func (h *UserHandler) GetDeletedUsers(c *gin.Context) {
+	branchID, ok := branchFilter(c)
+	if !ok {
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	users, err := h.userUsecase.GetDeletedUsers(caller, branchID)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) {
+			middleware.AbortForbidden(c, err.Error())
+			return
+		}
+		logrus.WithError(err).Error("Failed to get deleted users")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to retrieve deleted users",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    users,
+		"message": "Deleted users retrieved successfully",
+		"count":   len(users),
+	})
+}
+
+// RestoreUser handles POST /api/v1/users/:id/restore
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	user, err := h.userUsecase.RestoreUser(caller, uint(userID))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to restore user")
+		if userAccessError(c, err) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "User restored successfully",
+	})
+}
+
+// PurgeUser handles DELETE /api/v1/users/:id/purge
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		logrus.WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid user ID",
+			"message": "User ID must be a valid number",
+		})
+		return
+	}
+
+	caller, _ := middleware.GetCaller(c)
+	if err := h.userUsecase.PurgeUser(caller, uint(userID)); err != nil {
+		if userAccessError(c, err) {
+			logrus.WithError(err).Warn("Refused to purge user")
+			return
+		}
+		if errors.Is(err, models.ErrUserHasProperties) {
+			logrus.WithError(err).Warn("Refused to purge user with properties")
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "User still has properties",
+				"message": err.Error(),
+			})
+			return
+		}
+		logrus.WithError(err).Error("Failed to purge user")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to purge user",
+			"message": err.Error(),
+		})
+		return
+	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User purged permanently",
+	})
+}
+
+// userAccessError answers for a user the caller cannot manage, either because
+// it is outside their branch or because it is a regional admin. It reports
+// whether it answered.
+func userAccessError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		middleware.AbortForbidden(c, err.Error())
+	case errors.Is(err, models.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "User not found",
+			"message": err.Error(),
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// abortIfTaken answers a write that would give the user the username or email
+// of another user of the tenant. It reports whether it answered.
+func abortIfTaken(c *gin.Context, err error) bool {
+	if !errors.Is(err, models.ErrEmailTaken) && !errors.Is(err, models.ErrUsernameTaken) {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   "User already exists",
+		"message": err.Error(),
+	})
+	return true
+}
@@ -4,16 +4,56 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"inmo-backend/cmd/di"
+	"inmo-backend/middleware"
 )
 
 func SetupRouter(handlers *di.Handlers) *gin.Engine {
 	r := gin.Default()
+	if handlers.Tenant != nil {
+		r.Use(middleware.SetTenant(handlers.Tenant.ID))
+	}
 
 	v1 := r.Group("/api/v1")
 	{
 		setupHealthRoutes(v1, handlers.HealthHandler)
-		setupUserRoutes(v1, handlers.UserHandler)
-		setupPropertyRoutes(v1, handlers.PropertyHandler)
+		setupAuthRoutes(v1, handlers.UserHandler)
+		setupSSORoutes(v1, handlers.SSOHandler)
+		setupPasswordRoutes(v1, handlers.PasswordHandler)
+		setupEmailVerificationRoutes(v1, handlers.UserHandler)
+		setupInvitationRoutes(v1, handlers.InvitationHandler)
+	}
+
+	// Everything registered on protected requires a valid access token
+	protected := v1.Group("", handlers.AuthMiddleware)
+	{
+		setupTwoFactorRoutes(protected, handlers.TwoFactorHandler)
+		setupSessionRoutes(protected, handlers.UserHandler)
+	}
+
+	// Roles with mandatory 2FA can only use the routes above until they enroll
+	enforced := protected.Group("", middleware.RequireTwoFactor())
+	{
+		setupUserRoutes(enforced, handlers.UserHandler, handlers.PasswordHandler)
+		setupPropertyRoutes(enforced, handlers.PropertyHandler)
+		setupAPIKeyRoutes(enforced, handlers.APIKeyHandler)
+		setupInvitationManagementRoutes(enforced, handlers.InvitationHandler)
+		setupBranchRoutes(enforced, handlers.BranchHandler)
+	}
+
+	return r
+}
+
+// SetupPlatformRouter serves the endpoints that sit outside of every tenant.
+// Tenant provisioning is only registered when PLATFORM_ADMIN_TOKEN is set.
+func SetupPlatformRouter(platform *di.Platform) *gin.Engine {
+	r := gin.Default()
+
+	v1 := r.Group("/api/v1")
+	{
+		setupHealthRoutes(v1, platform.HealthHandler)
+		if platform.PlatformAdminToken != "" {
+			setupTenantRoutes(v1, platform.TenantHandler, platform.PlatformAdminToken)
+		}
 	}
 
 	return r
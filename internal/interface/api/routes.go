@@ -3,29 +3,156 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 
+	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
 )
 
-func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler) {
+func setupAuthRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler) {
 	users := rg.Group("/users")
 	{
-		users.GET("", userHandler.GetUsers)          // GET /api/v1/users
-		users.GET("/:id", userHandler.GetUserByID)   // GET /api/v1/users/:id
-		users.POST("", userHandler.CreateUser)       // POST /api/v1/users
-		users.PUT("/:id", userHandler.UpdateUser)    // PUT /api/v1/users
-		users.DELETE("/:id", userHandler.DeleteUser) // DELETE /api/v1/users/:id
-		users.POST("/login", userHandler.UserLogin)  // POST /api/v1/users/login
+		users.POST("/login", userHandler.UserLogin)                // POST /api/v1/users/login
+		users.POST("/login/2fa", userHandler.VerifyTwoFactorLogin) // POST /api/v1/users/login/2fa
+		users.POST("/refresh", userHandler.RefreshToken)           // POST /api/v1/users/refresh
+	}
+}
+
+func setupSSORoutes(rg *gin.RouterGroup, ssoHandler *handler.SSOHandler) {
+	sso := rg.Group("/users/login/sso")
+	{
+		sso.GET("", ssoHandler.StartLogin)        // GET /api/v1/users/login/sso
+		sso.GET("/callback", ssoHandler.Callback) // GET /api/v1/users/login/sso/callback
+	}
+}
+
+func setupPasswordRoutes(rg *gin.RouterGroup, passwordHandler *handler.PasswordHandler) {
+	password := rg.Group("/users/password")
+	{
+		password.POST("/forgot", passwordHandler.ForgotPassword) // POST /api/v1/users/password/forgot
+		password.POST("/reset", passwordHandler.ResetPassword)   // POST /api/v1/users/password/reset
+	}
+}
+
+func setupEmailVerificationRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler) {
+	email := rg.Group("/users/email")
+	{
+		email.POST("/verify", userHandler.VerifyEmail)             // POST /api/v1/users/email/verify
+		email.POST("/resend", userHandler.ResendEmailVerification) // POST /api/v1/users/email/resend
+	}
+}
+
+func setupInvitationRoutes(rg *gin.RouterGroup, invitationHandler *handler.InvitationHandler) {
+	invitations := rg.Group("/invitations")
+	{
+		invitations.POST("/accept", invitationHandler.AcceptInvitation) // POST /api/v1/invitations/accept
+	}
+}
+
+func setupInvitationManagementRoutes(rg *gin.RouterGroup, invitationHandler *handler.InvitationHandler) {
+	invitations := rg.Group("/invitations", middleware.RequireUserSession(), middleware.RequirePermission(models.PermUsersManage))
+	{
+		invitations.POST("", invitationHandler.CreateInvitation)            // POST /api/v1/invitations
+		invitations.GET("", invitationHandler.GetInvitations)               // GET /api/v1/invitations
+		invitations.POST("/:id/resend", invitationHandler.ResendInvitation) // POST /api/v1/invitations/:id/resend
+		invitations.DELETE("/:id", invitationHandler.RevokeInvitation)      // DELETE /api/v1/invitations/:id
+	}
+}
+
+// setupTwoFactorRoutes stays reachable for sessions that still have to
+// enroll, so it is registered outside RequireTwoFactor.
+func setupTwoFactorRoutes(rg *gin.RouterGroup, twoFactorHandler *handler.TwoFactorHandler) {
+	twoFactor := rg.Group("/users/2fa", middleware.RequireUserSession())
+	{
+		twoFactor.POST("/enroll", twoFactorHandler.Enroll)   // POST /api/v1/users/2fa/enroll
+		twoFactor.POST("/confirm", twoFactorHandler.Confirm) // POST /api/v1/users/2fa/confirm
+		twoFactor.POST("/disable", twoFactorHandler.Disable) // POST /api/v1/users/2fa/disable
+	}
+}
+
+// setupSessionRoutes lets a session that still has to enroll in 2FA log out
+// and manage its other sessions, so it is registered outside RequireTwoFactor.
+func setupSessionRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler) {
+	users := rg.Group("/users", middleware.RequireUserSession())
+	{
+		users.POST("/logout", userHandler.Logout)                // POST /api/v1/users/logout
+		users.GET("/sessions", userHandler.GetSessions)          // GET /api/v1/users/sessions
+		users.DELETE("/sessions/:id", userHandler.RevokeSession) // DELETE /api/v1/users/sessions/:id
+	}
+}
+
+func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler, passwordHandler *handler.PasswordHandler) {
+	users := rg.Group("/users")
+	{
+		requireSession := middleware.RequireUserSession()
+		users.GET("/me", requireSession, userHandler.GetMe)                        // GET /api/v1/users/me
+		users.PUT("/me", requireSession, userHandler.UpdateMe)                     // PUT /api/v1/users/me
+		users.PUT("/:id/password", requireSession, passwordHandler.ChangePassword) // PUT /api/v1/users/:id/password
+
+		canRead := middleware.RequirePermission(models.PermUsersRead)
+		canManage := middleware.RequirePermission(models.PermUsersManage)
+		users.GET("/deleted", canManage, userHandler.GetDeletedUsers)           // GET /api/v1/users/deleted
+		users.GET("", canRead, userHandler.GetUsers)                            // GET /api/v1/users
+		users.GET("/:id", canRead, userHandler.GetUserByID)                     // GET /api/v1/users/:id
+		users.PUT("/:id", canManage, userHandler.UpdateUser)                    // PUT /api/v1/users
+		users.PATCH("/:id", canManage, userHandler.PatchUser)                   // PATCH /api/v1/users/:id
+		users.PUT("/:id/role", canManage, userHandler.UpdateUserRole)           // PUT /api/v1/users/:id/role
+		users.POST("/:id/unlock", canManage, userHandler.UnlockUser)            // POST /api/v1/users/:id/unlock
+		users.POST("/:id/verify-email", canManage, userHandler.VerifyUserEmail) // POST /api/v1/users/:id/verify-email
+		users.POST("/:id/deactivate", canManage, userHandler.DeactivateUser)    // POST /api/v1/users/:id/deactivate
+		users.POST("/:id/restore", canManage, userHandler.RestoreUser)          // POST /api/v1/users/:id/restore
+		users.DELETE("/:id", canManage, userHandler.DeleteUser)                 // DELETE /api/v1/users/:id
+		users.DELETE("/:id/purge", canManage, userHandler.PurgeUser)            // DELETE /api/v1/users/:id/purge
 	}
 }
 
 func setupPropertyRoutes(rg *gin.RouterGroup, propertyHandler *handler.PropertyHandler) {
 	properties := rg.Group("/properties")
 	{
-		properties.GET("", propertyHandler.GetProperties)          // GET /api/v1/properties
-		properties.GET("/:id", propertyHandler.GetPropertyByID)    // GET /api/v1/properties/:id
-		properties.POST("", propertyHandler.CreateProperty)        // POST /api/v1/properties
-		properties.PUT("/:id", propertyHandler.UpdateProperty)     // PUT /api/v1/properties/:id
-		properties.DELETE("/:id", propertyHandler.DeleteProperty)  // DELETE /api/v1/properties/:id
+		canRead := middleware.RequirePermission(models.PermPropertiesRead)
+		canWrite := middleware.RequirePermission(models.PermPropertiesWrite)
+		canDelete := middleware.RequirePermission(models.PermPropertiesDelete)
+		canAssign := middleware.RequirePermission(models.PermPropertiesAssign)
+		properties.GET("", canRead, propertyHandler.GetProperties)                     // GET /api/v1/properties
+		properties.GET("/cards", canRead, propertyHandler.GetPropertyCards)            // GET /api/v1/properties/cards
+		properties.GET("/:id", canRead, propertyHandler.GetPropertyByID)               // GET /api/v1/properties/:id
+		properties.GET("/:id/price-history", canRead, propertyHandler.GetPriceHistory) // GET /api/v1/properties/:id/price-history
+		properties.POST("", canWrite, propertyHandler.CreateProperty)                  // POST /api/v1/properties
+		properties.PUT("/:id", canWrite, propertyHandler.UpdateProperty)               // PUT /api/v1/properties/:id
+		properties.PATCH("/:id", canWrite, propertyHandler.PatchProperty)              // PATCH /api/v1/properties/:id
+		properties.PUT("/:id/agent", canAssign, propertyHandler.ReassignProperty)      // PUT /api/v1/properties/:id/agent
+		properties.POST("/:id/status", canWrite, propertyHandler.ChangePropertyStatus) // POST /api/v1/properties/:id/status
+		properties.DELETE("/:id", canDelete, propertyHandler.DeleteProperty)           // DELETE /api/v1/properties/:id
+	}
+}
+
+func setupAPIKeyRoutes(rg *gin.RouterGroup, apiKeyHandler *handler.APIKeyHandler) {
+	apiKeys := rg.Group("/api-keys", middleware.RequireUserSession(), middleware.RequirePermission(models.PermAPIKeysManage))
+	{
+		apiKeys.POST("", apiKeyHandler.CreateAPIKey)       // POST /api/v1/api-keys
+		apiKeys.GET("", apiKeyHandler.GetAPIKeys)          // GET /api/v1/api-keys
+		apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey) // DELETE /api/v1/api-keys/:id
+	}
+}
+
+func setupBranchRoutes(rg *gin.RouterGroup, branchHandler *handler.BranchHandler) {
+	rg.GET("/branches", middleware.RequirePermission(models.PermUsersRead), branchHandler.GetBranches) // GET /api/v1/branches
+
+	manage := rg.Group("", middleware.RequireUserSession(), middleware.RequirePermission(models.PermBranchesManage))
+	{
+		manage.POST("/branches", branchHandler.CreateBranch)      // POST /api/v1/branches
+		manage.PUT("/branches/:id", branchHandler.UpdateBranch)   // PUT /api/v1/branches/:id
+		manage.PUT("/users/:id/branch", branchHandler.AssignUser) // PUT /api/v1/users/:id/branch
+	}
+}
+
+func setupTenantRoutes(rg *gin.RouterGroup, tenantHandler *handler.TenantHandler, platformAdminToken string) {
+	tenants := rg.Group("/tenants", middleware.RequirePlatformToken(platformAdminToken))
+	{
+		tenants.GET("", tenantHandler.GetTenants)              // GET /api/v1/tenants
+		tenants.POST("", tenantHandler.CreateTenant)           // POST /api/v1/tenants
+		tenants.GET("/:id", tenantHandler.GetTenant)           // GET /api/v1/tenants/:id
+		tenants.PUT("/:id", tenantHandler.UpdateTenant)        // PUT /api/v1/tenants/:id
+		tenants.POST("/:id/admins", tenantHandler.InviteAdmin) // POST /api/v1/tenants/:id/admins
 	}
 }
 
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/cmd/di"
+	"inmo-backend/internal/domain/models"
+)
+
+// platformPaths are served by the platform router without resolving a tenant
+var platformPaths = []string{"/api/v1/tenants", "/api/v1/health"}
+
+// TenantRouter hands every request to the engine of its tenant. Each tenant
+// gets its own engine, built on first use from handlers bound to that tenant,
+// so a request can only ever reach the data of the tenant it resolved to.
+type TenantRouter struct {
+	platform      *gin.Engine
+	resolveTenant func(r *http.Request) (*models.Tenant, error)
+	handlersFor   func(tenant *models.Tenant) *di.Handlers
+
+	mu sync.Mutex
+	// engines is keyed by slug so renaming a tenant rebuilds the links it mails
+	engines map[string]*gin.Engine
+}
+
+func NewTenantRouter(platform *di.Platform) *TenantRouter {
+	return &TenantRouter{
+		platform:      SetupPlatformRouter(platform),
+		resolveTenant: platform.ResolveTenant,
+		handlersFor:   platform.HandlersFor,
+		engines:       map[string]*gin.Engine{},
+	}
+}
+
+func (tr *TenantRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, path := range platformPaths {
+		if r.URL.Path == path || strings.HasPrefix(r.URL.Path, path+"/") {
+			tr.platform.ServeHTTP(w, r)
+			return
+		}
+	}
+
+	tenant, err := tr.resolveTenant(r)
+	if err != nil {
+		writeTenantError(w, err)
+		return
+	}
+	tr.engineFor(tenant).ServeHTTP(w, r)
+}
+
+func (tr *TenantRouter) engineFor(tenant *models.Tenant) *gin.Engine {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	engine, ok := tr.engines[tenant.Slug]
+	if !ok {
+		logrus.Infof("Building router for tenant %q", tenant.Slug)
+		engine = SetupRouter(tr.handlersFor(tenant))
+		tr.engines[tenant.Slug] = engine
+	}
+	return engine
+}
+
+func writeTenantError(w http.ResponseWriter, err error) {
+	status, message := http.StatusInternalServerError, "Failed to resolve tenant"
+	switch {
+	case errors.Is(err, models.ErrTenantRequired):
+		status, message = http.StatusBadRequest, "Tenant required"
+	case errors.Is(err, models.ErrTenantNotFound):
+		status, message = http.StatusNotFound, "Tenant not found"
+	default:
+		logrus.WithError(err).Error("Failed to resolve tenant")
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message, "message": err.Error()}); err != nil {
+		logrus.WithError(err).Error("Failed to write tenant error")
+	}
+}
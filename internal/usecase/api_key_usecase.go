@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+const (
+	apiKeyPrefix        = "inmo_"
+	apiKeyDisplayLength = 12
+)
+
+type APIKeyUseCase struct {
+	apiKeyRepo ports.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepo ports.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// CreateAPIKey issues a key for the caller. The raw key is only part of this
+// response; afterwards it is known by its prefix. A key cannot be granted a
+// scope its creator does not have.
+func (uc *APIKeyUseCase) CreateAPIKey(caller *models.Caller, keyData *models.CreateAPIKeyData) (*models.CreatedAPIKeyResponse, error) {
+	if caller == nil {
+		return nil, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+	if keyData == nil || strings.TrimSpace(keyData.Name) == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+	if len(keyData.Scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	if keyData.ExpiresAt != nil && !keyData.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	scopes := []models.Permission{}
+	for _, scope := range keyData.Scopes {
+		if !scope.IsAPIKeyScope() {
+			return nil, fmt.Errorf("invalid scope %q", scope)
+		}
+		if !caller.Can(scope) {
+			return nil, fmt.Errorf("%w: cannot grant scope %q", models.ErrForbidden, scope)
+		}
+		if !containsScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate API key")
+		return nil, err
+	}
+	rawKey := apiKeyPrefix + token
+
+	// Integrations see the data of the branch the key was made for
+	key := &models.APIKey{
+		UserID:    caller.UserID,
+		BranchID:  caller.BranchID,
+		Name:      strings.TrimSpace(keyData.Name),
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   middleware.HashToken(rawKey),
+		ExpiresAt: keyData.ExpiresAt,
+	}
+	key.SetScopes(scopes)
+
+	created, err := uc.apiKeyRepo.Create(key)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreatedAPIKeyResponse{
+		APIKeyResponse: *created.ToAPIKeyResponse(),
+		Key:            rawKey,
+	}, nil
+}
+
+// GetAPIKeys lists the caller's own keys, or those of every user to a
+// regional admin.
+func (uc *APIKeyUseCase) GetAPIKeys(caller *models.Caller) ([]models.APIKeyResponse, error) {
+	owner, err := apiKeyOwner(caller)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := uc.apiKeyRepo.GetAll(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, *key.ToAPIKeyResponse())
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey revokes one of the caller's keys, or any key for a regional
+// admin. The keys of other users are not found.
+func (uc *APIKeyUseCase) RevokeAPIKey(caller *models.Caller, id uint) error {
+	owner, err := apiKeyOwner(caller)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return errors.New("api key ID must be provided")
+	}
+	return uc.apiKeyRepo.Revoke(id, owner)
+}
+
+// apiKeyOwner is the user whose keys the caller manages, 0 for the keys of
+// every user
+func apiKeyOwner(caller *models.Caller) (uint, error) {
+	if caller == nil {
+		return 0, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+	if caller.Can(models.PermBranchesAll) {
+		return 0, nil
+	}
+	return caller.UserID, nil
+}
+
+func containsScope(scopes []models.Permission, scope models.Permission) bool {
+	for _, existing := range scopes {
+		if existing == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+type BranchUseCase struct {
+	branchRepo ports.BranchRepository
+	userRepo   ports.UserRepository
+}
+
+func NewBranchUseCase(branchRepo ports.BranchRepository, userRepo ports.UserRepository) *BranchUseCase {
+	return &BranchUseCase{
+		branchRepo: branchRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (uc *BranchUseCase) GetBranches() ([]models.Branch, error) {
+	return uc.branchRepo.GetAll()
+}
+
+func (uc *BranchUseCase) CreateBranch(branchData *models.BranchData) (*models.Branch, error) {
+	branch, err := newBranch(branchData)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.branchRepo.Create(branch); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Branch %d created", branch.ID)
+	return branch, nil
+}
+
+func (uc *BranchUseCase) UpdateBranch(id uint, branchData *models.BranchData) (*models.Branch, error) {
+	if id == 0 {
+		return nil, errors.New("branch ID must be provided")
+	}
+	branch, err := newBranch(branchData)
+	if err != nil {
+		return nil, err
+	}
+	branch.ID = id
+	if err := uc.branchRepo.Update(branch); err != nil {
+		return nil, err
+	}
+	return uc.branchRepo.GetByID(id)
+}
+
+// AssignUser moves a user to another branch. Their properties stay where they
+// are, and they must log in again since their sessions and API keys are
+// revoked. version must be the current one.
+func (uc *BranchUseCase) AssignUser(caller *models.Caller, userID uint, version uint, branchID uint) (*models.UserResponse, error) {
+	if userID == 0 {
+		return nil, errors.New("user ID must be provided")
+	}
+	if branchID == 0 {
+		return nil, errors.New("branch_id is required")
+	}
+	if _, err := uc.branchRepo.GetByID(branchID); err != nil {
+		return nil, errors.New("branch not found")
+	}
+	current, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("user", userID, current.Version, version); err != nil {
+		return nil, err
+	}
+
+	if err := uc.userRepo.UpdateBranch(userID, version, branchID); err != nil {
+		return nil, err
+	}
+	logrus.Infof("User %d assigned to branch %d by user %d", userID, branchID, caller.UserID)
+	return uc.userRepo.GetByID(userID)
+}
+
+func newBranch(branchData *models.BranchData) (*models.Branch, error) {
+	if branchData == nil || strings.TrimSpace(branchData.Name) == "" {
+		logrus.Error("Branch name cannot be empty")
+		return nil, errors.New("name cannot be empty")
+	}
+	return &models.Branch{
+		Name:    strings.TrimSpace(branchData.Name),
+		City:    strings.TrimSpace(branchData.City),
+		Address: strings.TrimSpace(branchData.Address),
+	}, nil
+}
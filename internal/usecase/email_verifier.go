@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+var errInvalidVerification = errors.New("invalid or expired verification token")
+
+// EmailVerifier mails single-use links that prove a user controls an email
+// address. The address stored with the token is the one that gets verified,
+// which is how an email change waits for the new address to confirm it.
+type EmailVerifier struct {
+	verificationRepo ports.EmailVerificationRepository
+	userRepo         ports.UserRepository
+	mailer           ports.Mailer
+	tokenTTL         time.Duration
+	verifyURL        string
+}
+
+func NewEmailVerifier(
+	verificationRepo ports.EmailVerificationRepository,
+	userRepo ports.UserRepository,
+	mailer ports.Mailer,
+	tokenTTL time.Duration,
+	verifyURL string,
+) *EmailVerifier {
+	return &EmailVerifier{
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		mailer:           mailer,
+		tokenTTL:         tokenTTL,
+		verifyURL:        verifyURL,
+	}
+}
+
+// Send mails a verification link for email to that address. Links sent
+// earlier to the same user stop working.
+func (v *EmailVerifier) Send(user *models.UserResponse, email string) error {
+	if err := v.verificationRepo.InvalidateByUserID(user.ID); err != nil {
+		return err
+	}
+
+	rawToken, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate email verification token")
+		return err
+	}
+
+	if err := v.verificationRepo.Create(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: middleware.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(v.tokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	return v.mailer.Send(&models.Email{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nPlease confirm that %s is your email address by opening the link below:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not expect this email, you can ignore it.\n",
+			user.Username, email, v.verifyLink(rawToken), v.tokenTTL,
+		),
+	})
+}
+
+// Verify consumes a token and makes its address the verified email of the user.
+func (v *EmailVerifier) Verify(token string) (*models.UserResponse, error) {
+	if token == "" {
+		logrus.Error("Verification token cannot be empty")
+		return nil, errors.New("verification token cannot be empty")
+	}
+
+	verification, err := v.verificationRepo.GetByTokenHash(middleware.HashToken(token))
+	if err != nil {
+		return nil, errInvalidVerification
+	}
+	if !verification.IsUsable(time.Now()) {
+		logrus.Warnf("Email verification token %d is used or expired", verification.ID)
+		return nil, errInvalidVerification
+	}
+
+	// The address may have been taken by another account since the link was sent
+	if owner, err := v.userRepo.GetByEmail(verification.Email); err == nil && owner.ID != verification.UserID {
+		logrus.Warnf("Email %s of verification token %d belongs to user %d", verification.Email, verification.ID, owner.ID)
+		return nil, models.ErrEmailTaken
+	}
+
+	if err := v.verificationRepo.Consume(verification); err != nil {
+		if errors.Is(err, models.ErrEmailTaken) {
+			return nil, err
+		}
+		return nil, errInvalidVerification
+	}
+	return v.userRepo.GetByID(verification.UserID)
+}
+
+func (v *EmailVerifier) verifyLink(token string) string {
+	link, err := url.Parse(v.verifyURL)
+	if err != nil {
+		return v.verifyURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,307 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+var errInvalidInvitation = errors.New("invalid or expired invitation")
+
+type InvitationUseCase struct {
+	invitationRepo ports.InvitationRepository
+	userRepo       ports.UserRepository
+	branchRepo     ports.BranchRepository
+	tokens         *middleware.TokenManager
+	mailer         ports.Mailer
+	invitationTTL  time.Duration
+	acceptURL      string
+}
+
+func NewInvitationUseCase(
+	invitationRepo ports.InvitationRepository,
+	userRepo ports.UserRepository,
+	branchRepo ports.BranchRepository,
+	tokens *middleware.TokenManager,
+	mailer ports.Mailer,
+	invitationTTL time.Duration,
+	acceptURL string,
+) *InvitationUseCase {
+	return &InvitationUseCase{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		branchRepo:     branchRepo,
+		tokens:         tokens,
+		mailer:         mailer,
+		invitationTTL:  invitationTTL,
+		acceptURL:      acceptURL,
+	}
+}
+
+// CreateInvitation mails an invitation to join with the given role and
+// branch. An email that already has an account or an open invitation cannot
+// be invited again; open invitations are resent instead.
+func (uc *InvitationUseCase) CreateInvitation(caller *models.Caller, invitationData *models.CreateInvitationData) (*models.InvitationResponse, error) {
+	if caller == nil {
+		return nil, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+	if invitationData == nil {
+		logrus.Error("Invitation data cannot be nil")
+		return nil, errors.New("invitation data cannot be nil")
+	}
+
+	address, err := mail.ParseAddress(strings.TrimSpace(invitationData.Email))
+	if err != nil {
+		logrus.WithError(err).Error("Invalid invitation email")
+		return nil, errors.New("a valid email is required")
+	}
+	email := strings.ToLower(address.Address)
+
+	role := invitationData.Role
+	if role == "" {
+		role = models.RoleAssistant
+	}
+	if !role.IsValid() {
+		logrus.Errorf("Invalid role %q", role)
+		return nil, errors.New("invalid role")
+	}
+	if role == models.RoleRegionalAdmin && !caller.Can(models.PermBranchesManage) {
+		logrus.Warnf("Caller is not allowed to invite %s as regional admin", email)
+		return nil, fmt.Errorf("%w: only a regional admin can invite a regional admin", models.ErrForbidden)
+	}
+
+	branchID, err := uc.invitationBranch(caller, invitationData.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.userRepo.GetByEmail(email); err == nil {
+		logrus.Warnf("Invitation requested for existing user %s", email)
+		return nil, models.ErrEmailTaken
+	}
+	open, err := uc.invitationRepo.GetOpenByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		logrus.Warnf("Invitation %d is already open for %s", open.ID, email)
+		return nil, errors.New("an invitation for this email is already open, resend it instead")
+	}
+
+	expiresAt := time.Now().Add(uc.invitationTTL)
+	token, err := uc.tokens.GenerateInvitationToken(email, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		Role:      role,
+		BranchID:  branchID,
+		TokenHash: middleware.HashToken(token),
+		ExpiresAt: expiresAt,
+	}
+	// The platform has no user of its own when it provisions a tenant
+	if invitedBy := caller.UserID; invitedBy != 0 {
+		invitation.InvitedBy = &invitedBy
+	}
+	if err := uc.invitationRepo.Create(invitation); err != nil {
+		return nil, err
+	}
+
+	if err := uc.sendInvitation(invitation, token); err != nil {
+		return nil, err
+	}
+	return invitation.ToInvitationResponse(time.Now()), nil
+}
+
+// GetInvitations lists the invitations to the caller's branch, or to every
+// branch for regional admins.
+func (uc *InvitationUseCase) GetInvitations(caller *models.Caller) ([]models.InvitationResponse, error) {
+	scope, err := caller.BranchScope(0)
+	if err != nil {
+		logrus.WithError(err).Warn("Refused invitation listing outside the caller's branch")
+		return nil, err
+	}
+	invitations, err := uc.invitationRepo.GetAll(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]models.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, *invitation.ToInvitationResponse(now))
+	}
+	return responses, nil
+}
+
+// ResendInvitation mails a new link with a fresh expiry. The previous link
+// stops working. Expired invitations can be resent; accepted or revoked
+// ones cannot.
+func (uc *InvitationUseCase) ResendInvitation(caller *models.Caller, id uint) (*models.InvitationResponse, error) {
+	invitation, err := uc.manageableInvitation(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if status := invitation.Status(time.Now()); status == models.InvitationAccepted || status == models.InvitationRevoked {
+		logrus.Warnf("Cannot resend invitation %d, it is %s", id, status)
+		return nil, fmt.Errorf("invitation is already %s", status)
+	}
+
+	expiresAt := time.Now().Add(uc.invitationTTL)
+	token, err := uc.tokens.GenerateInvitationToken(invitation.Email, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenHash := middleware.HashToken(token)
+	if err := uc.invitationRepo.Renew(id, tokenHash, expiresAt); err != nil {
+		return nil, err
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = expiresAt
+
+	if err := uc.sendInvitation(invitation, token); err != nil {
+		return nil, err
+	}
+	return invitation.ToInvitationResponse(time.Now()), nil
+}
+
+func (uc *InvitationUseCase) RevokeInvitation(caller *models.Caller, id uint) error {
+	if id == 0 {
+		return errors.New("invitation ID must be provided")
+	}
+	if _, err := uc.manageableInvitation(caller, id); err != nil {
+		return err
+	}
+	if err := uc.invitationRepo.Revoke(id); err != nil {
+		return err
+	}
+
+	logrus.Infof("Invitation %d revoked", id)
+	return nil
+}
+
+// AcceptInvitation creates the invited account with the username and
+// password chosen by the invitee. The email and role come from the invitation.
+func (uc *InvitationUseCase) AcceptInvitation(acceptData *models.AcceptInvitationData) (*models.UserResponse, error) {
+	if acceptData == nil || acceptData.Token == "" {
+		logrus.Error("Invitation token cannot be empty")
+		return nil, errors.New("invitation token cannot be empty")
+	}
+
+	email, err := uc.tokens.ParseInvitationToken(acceptData.Token)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid invitation token")
+		return nil, errInvalidInvitation
+	}
+
+	tokenHash := middleware.HashToken(acceptData.Token)
+	invitation, err := uc.invitationRepo.GetByTokenHash(tokenHash)
+	if err != nil {
+		return nil, errInvalidInvitation
+	}
+	if invitation.Email != email || invitation.Status(time.Now()) != models.InvitationPending {
+		logrus.Warnf("Invitation %d cannot be accepted", invitation.ID)
+		return nil, errInvalidInvitation
+	}
+
+	username := strings.TrimSpace(acceptData.Username)
+	if username == "" {
+		logrus.Error("Username cannot be empty")
+		return nil, errors.New("username cannot be empty")
+	}
+	hashedPassword, err := middleware.HashPassword(acceptData.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token was mailed to the invited address, which proves the invitee owns it
+	verifiedAt := time.Now()
+	return uc.invitationRepo.Accept(invitation.ID, tokenHash, &models.User{
+		Username:        username,
+		Email:           invitation.Email,
+		EmailVerifiedAt: &verifiedAt,
+		Password:        hashedPassword,
+		Role:            invitation.Role,
+		BranchID:        invitation.BranchID,
+	})
+}
+
+// manageableInvitation returns the invitation if the caller may resend or
+// revoke it. Invitations to other branches are reported as missing.
+func (uc *InvitationUseCase) manageableInvitation(caller *models.Caller, id uint) (*models.Invitation, error) {
+	if caller == nil {
+		return nil, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+	invitation, err := uc.invitationRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.CanAccessBranch(invitation.BranchID) {
+		logrus.Warnf("Caller cannot see invitation %d of branch %d", id, invitation.BranchID)
+		return nil, errors.New("invitation not found")
+	}
+	if invitation.Role == models.RoleRegionalAdmin && !caller.Can(models.PermBranchesManage) {
+		logrus.Warnf("Caller is not allowed to manage regional admin invitation %d", id)
+		return nil, fmt.Errorf("%w: only a regional admin can manage a regional admin invitation", models.ErrForbidden)
+	}
+	return invitation, nil
+}
+
+// invitationBranch resolves the branch the invitee joins: the caller's own
+// unless a regional admin picks another one.
+func (uc *InvitationUseCase) invitationBranch(caller *models.Caller, requested uint) (uint, error) {
+	if requested == 0 || requested == caller.BranchID {
+		if caller.BranchID == 0 {
+			logrus.Error("Invitation without a branch")
+			return 0, errors.New("branch_id is required")
+		}
+		return caller.BranchID, nil
+	}
+	if !caller.Can(models.PermBranchesAll) {
+		logrus.Warnf("Caller is not allowed to invite to branch %d", requested)
+		return 0, fmt.Errorf("%w: you can only invite to your own branch", models.ErrForbidden)
+	}
+	if _, err := uc.branchRepo.GetByID(requested); err != nil {
+		return 0, errors.New("branch not found")
+	}
+	return requested, nil
+}
+
+func (uc *InvitationUseCase) sendInvitation(invitation *models.Invitation, token string) error {
+	err := uc.mailer.Send(&models.Email{
+		To:      invitation.Email,
+		Subject: "You have been invited to Inmo",
+		Body: fmt.Sprintf(
+			"Hello,\n\nYou have been invited to join Inmo as %s. Open the link below to choose your username and password:\n\n%s\n\nThe link expires on %s.\n",
+			invitation.Role, uc.acceptLink(token), invitation.ExpiresAt.Format(time.RFC1123),
+		),
+	})
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to send invitation %d", invitation.ID)
+		return err
+	}
+	return nil
+}
+
+func (uc *InvitationUseCase) acceptLink(token string) string {
+	link, err := url.Parse(uc.acceptURL)
+	if err != nil {
+		return uc.acceptURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// LoginGuard throttles logins per account and per client IP. Every failure
+// past the free attempts doubles the wait, and reaching the maximum locks the
+// key for the lockout duration.
+type LoginGuard struct {
+	store    ports.LoginAttemptStore
+	policy   models.LoginPolicy
+	tenantID uint
+}
+
+func NewLoginGuard(store ports.LoginAttemptStore, policy models.LoginPolicy) *LoginGuard {
+	return &LoginGuard{store: store, policy: policy}
+}
+
+// ForTenant returns a guard sharing the store and policy that counts account
+// failures per tenant, since the same email can have an account at several.
+// Failures per IP are still counted across tenants.
+func (g *LoginGuard) ForTenant(tenantID uint) *LoginGuard {
+	return &LoginGuard{store: g.store, policy: g.policy, tenantID: tenantID}
+}
+
+// Check returns a *models.LoginLockedError when either the account or the IP
+// has to wait before trying again.
+func (g *LoginGuard) Check(email string, ipAddress string, now time.Time) error {
+	var retryAfter time.Duration
+	for _, key := range g.loginAttemptKeys(email, ipAddress) {
+		attempt, err := g.store.Get(key)
+		if err != nil {
+			return err
+		}
+		if attempt != nil && attempt.IsLocked(now) {
+			if wait := attempt.LockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		logrus.Warnf("Login for %s from %s is throttled for %s", email, ipAddress, retryAfter)
+		return &models.LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the account and the IP and
+// locks whichever crossed a threshold.
+func (g *LoginGuard) RecordFailure(email string, ipAddress string, now time.Time) error {
+	emailKey, ipKey := g.emailAttemptKey(email), ipAttemptKey(ipAddress)
+	if err := g.recordFailure(emailKey, g.policy.MaxAttempts, now); err != nil {
+		return err
+	}
+	if ipKey == "" {
+		return nil
+	}
+	return g.recordFailure(ipKey, g.policy.IPMaxAttempts, now)
+}
+
+// RecordSuccess clears the account counter. The IP counter is left to expire
+// so one valid account cannot be used to reset it.
+func (g *LoginGuard) RecordSuccess(email string) error {
+	return g.store.Reset(g.emailAttemptKey(email))
+}
+
+// Unlock lifts the lockout and clears the failures of an account.
+func (g *LoginGuard) Unlock(email string) error {
+	return g.store.Reset(g.emailAttemptKey(email))
+}
+
+func (g *LoginGuard) recordFailure(key string, maxAttempts int, now time.Time) error {
+	attempt, err := g.store.RecordFailure(key, now, g.policy.Window)
+	if err != nil {
+		return err
+	}
+
+	wait := g.policy.LockFor(attempt.Failures, maxAttempts)
+	if wait <= 0 {
+		return nil
+	}
+	if maxAttempts > 0 && attempt.Failures >= maxAttempts {
+		logrus.Warnf("Locking %s for %s after %d failed logins", key, wait, attempt.Failures)
+	}
+	return g.store.Lock(key, now.Add(wait))
+}
+
+func (g *LoginGuard) loginAttemptKeys(email string, ipAddress string) []string {
+	keys := []string{g.emailAttemptKey(email)}
+	if ipKey := ipAttemptKey(ipAddress); ipKey != "" {
+		keys = append(keys, ipKey)
+	}
+	return keys
+}
+
+func (g *LoginGuard) emailAttemptKey(email string) string {
+	return fmt.Sprintf("email:%d:%s", g.tenantID, strings.ToLower(strings.TrimSpace(email)))
+}
+
+func ipAttemptKey(ipAddress string) string {
+	if ipAddress == "" {
+		return ""
+	}
+	return "ip:" + ipAddress
+}
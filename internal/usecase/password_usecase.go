@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+type PasswordUseCase struct {
+	userRepo    ports.UserRepository
+	resetRepo   ports.PasswordResetRepository
+	sessionRepo ports.SessionRepository
+	mailer      ports.Mailer
+	resetTTL    time.Duration
+	resetURL    string
+}
+
+func NewPasswordUseCase(
+	userRepo ports.UserRepository,
+	resetRepo ports.PasswordResetRepository,
+	sessionRepo ports.SessionRepository,
+	mailer ports.Mailer,
+	resetTTL time.Duration,
+	resetURL string,
+) *PasswordUseCase {
+	return &PasswordUseCase{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mailer,
+		resetTTL:    resetTTL,
+		resetURL:    resetURL,
+	}
+}
+
+// ForgotPassword mails a reset link. Unknown emails are not reported back to
+// the caller so the endpoint cannot be used to discover accounts.
+func (uc *PasswordUseCase) ForgotPassword(email string) error {
+	if email == "" {
+		logrus.Error("Email cannot be empty")
+		return errors.New("email cannot be empty")
+	}
+
+	user, err := uc.userRepo.GetByEmail(email)
+	if err != nil {
+		logrus.WithError(err).Warn("Password reset requested for unknown email")
+		return nil
+	}
+
+	// Only the newest link should work
+	if err := uc.resetRepo.InvalidateByUserID(user.ID); err != nil {
+		return err
+	}
+
+	rawToken, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate password reset token")
+		return err
+	}
+
+	expiresAt := time.Now().Add(uc.resetTTL)
+	if err := uc.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: middleware.HashToken(rawToken),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	err = uc.mailer.Send(&models.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request it, you can ignore this email.\n",
+			user.Username, uc.resetLink(rawToken), uc.resetTTL,
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", models.ErrMailNotSent, err)
+	}
+	return nil
+}
+
+// ResetPassword consumes a reset token, stores the new password and signs
+// the user out everywhere.
+func (uc *PasswordUseCase) ResetPassword(token string, newPassword string) error {
+	if token == "" {
+		logrus.Error("Reset token cannot be empty")
+		return errors.New("reset token cannot be empty")
+	}
+
+	resetToken, err := uc.resetRepo.GetByTokenHash(middleware.HashToken(token))
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+	if !resetToken.IsUsable(time.Now()) {
+		logrus.Warnf("Password reset token %d is used or expired", resetToken.ID)
+		return errors.New("invalid or expired reset token")
+	}
+
+	hashedPassword, err := middleware.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.resetRepo.MarkUsed(resetToken.ID); err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	if err := uc.userRepo.UpdatePassword(resetToken.UserID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := uc.sessionRepo.RevokeAllByUserID(resetToken.UserID, 0); err != nil {
+		return err
+	}
+
+	logrus.Infof("Password reset completed for user %d", resetToken.UserID)
+	return nil
+}
+
+// ChangePassword replaces the caller's own password after checking the
+// current one. Every other session of the user is revoked; the session making
+// the request stays signed in.
+func (uc *PasswordUseCase) ChangePassword(caller *models.Caller, userID uint, currentPassword string, newPassword string) error {
+	if caller == nil || caller.UserID != userID {
+		logrus.Warnf("Caller is not allowed to change the password of user %d", userID)
+		return fmt.Errorf("%w: users can only change their own password", models.ErrForbidden)
+	}
+	if currentPassword == "" {
+		logrus.Error("Current password cannot be empty")
+		return errors.New("current password cannot be empty")
+	}
+
+	user, err := uc.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	storedPassword, err := uc.userRepo.ConsultPassword(user.Email)
+	if err != nil {
+		return err
+	}
+	if err := middleware.VerifyPassword(storedPassword, currentPassword); err != nil {
+		logrus.Warnf("Wrong current password for user %d", userID)
+		return errors.New("current password is incorrect")
+	}
+
+	hashedPassword, err := middleware.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+		return err
+	}
+
+	if err := uc.sessionRepo.RevokeAllByUserID(userID, caller.SessionID); err != nil {
+		return err
+	}
+
+	logrus.Infof("Password changed for user %d", userID)
+	return nil
+}
+
+func (uc *PasswordUseCase) resetLink(token string) string {
+	link, err := url.Parse(uc.resetURL)
+	if err != nil {
+		return uc.resetURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
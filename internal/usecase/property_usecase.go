@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 
@@ -11,34 +13,105 @@ import (
 
 type PropertyUseCase struct {
 	propertyRepo ports.PropertyRepository
+	userRepo     ports.UserRepository
 }
 
-func NewPropertyUseCase(propertyRepo ports.PropertyRepository) *PropertyUseCase {
+func NewPropertyUseCase(propertyRepo ports.PropertyRepository, userRepo ports.UserRepository) *PropertyUseCase {
 	return &PropertyUseCase{
 		propertyRepo: propertyRepo,
+		userRepo:     userRepo,
 	}
 }
 
-func (p *PropertyUseCase) GetAllProperties() ([]models.PropertyResponse, error) {
-	properties, err := p.propertyRepo.GetAll()
+// GetAllProperties returns a page of the properties of the caller's branch
+// that match the filter, newest first unless the page sorts otherwise.
+// filter.BranchID searches another branch, which only regional admins may
+// do; for them 0 searches every branch. A filter.Query makes it a full-text
+// search, listing the best matches first.
+func (p *PropertyUseCase) GetAllProperties(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	filter, page, err := p.searchScope(caller, filter, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Query != "" {
+		return p.propertyRepo.TextSearch(filter, page)
+	}
+	properties, err := p.propertyRepo.Search(filter, page)
 	if err != nil {
 		return nil, err
 	}
 	return properties, nil
 }
 
-func (p *PropertyUseCase) GetPropertyByID(id uint) (*models.PropertyResponse, error) {
+// GetPropertyCards is GetAllProperties for the catalog grid, returning cards
+// instead of full properties.
+func (p *PropertyUseCase) GetPropertyCards(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error) {
+	filter, page, err := p.searchScope(caller, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Query != "" {
+		logrus.Warn("Refused full-text search of property cards")
+		return nil, fmt.Errorf("%w: q is not supported by cards, search /properties instead", models.ErrInvalidFilter)
+	}
+	return p.propertyRepo.SearchCards(filter, page)
+}
+
+// searchScope validates a property search and restricts it to the branches
+// the caller may see. A nil page becomes the first page, the repository
+// always gets a normalized one.
+func (p *PropertyUseCase) searchScope(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.PropertyFilter, *models.PageRequest, error) {
+	if filter == nil {
+		filter = &models.PropertyFilter{}
+	}
+	if err := filter.Normalize(); err != nil {
+		logrus.WithError(err).Warn("Refused property search with an invalid filter")
+		return nil, nil, err
+	}
+	if page == nil {
+		page = &models.PageRequest{}
+	}
+	sortable, defaultSort := models.PropertySortFields, "-created_at"
+	if filter.Query != "" {
+		sortable, defaultSort = models.PropertyTextSortFields, "-relevance"
+	}
+	if err := page.Normalize(sortable, defaultSort); err != nil {
+		logrus.WithError(err).Warn("Refused property search with an invalid page")
+		return nil, nil, err
+	}
+
+	scope, err := caller.BranchScope(filter.BranchID)
+	if err != nil {
+		logrus.WithError(err).Warn("Refused property listing outside the caller's branch")
+		return nil, nil, err
+	}
+	filter.BranchID = scope
+	return filter, page, nil
+}
+
+func (p *PropertyUseCase) GetPropertyByID(caller *models.Caller, id uint) (*models.PropertyResponse, error) {
 	property, err := p.propertyRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
-	if property == nil {
-		return nil, errors.New("property not found")
+	// Properties of other branches are reported as missing
+	if property == nil || !caller.CanAccessBranch(property.BranchID) {
+		return nil, models.ErrPropertyNotFound
 	}
 	return property, nil
 }
 
-func (p *PropertyUseCase) CreateProperty(property *models.Property) (*models.PropertyResponse, error) {
+// GetPriceHistory lists the price changes of a property the caller can see,
+// oldest first.
+func (p *PropertyUseCase) GetPriceHistory(caller *models.Caller, id uint) ([]models.PropertyPriceChange, error) {
+	if _, err := p.GetPropertyByID(caller, id); err != nil {
+		return nil, err
+	}
+	return p.propertyRepo.GetPriceHistory(id)
+}
+
+func (p *PropertyUseCase) CreateProperty(caller *models.Caller, property *models.Property) (*models.PropertyResponse, error) {
 	if property == nil {
 		logrus.Error("Property cannot be nil")
 		return nil, errors.New("property cannot be nil")
@@ -51,6 +124,38 @@ func (p *PropertyUseCase) CreateProperty(property *models.Property) (*models.Pro
 		logrus.Error("Price must be greater than zero")
 		return nil, errors.New("price must be greater than zero")
 	}
+	if property.Status == "" {
+		property.Status = models.StatusAvailable
+	}
+	if err := models.CheckStatus(property.TransactionType, property.Status); err != nil {
+		logrus.WithError(err).Warn("Refused property with an invalid status")
+		return nil, err
+	}
+
+	if property.BranchID == 0 {
+		property.BranchID = caller.BranchID
+	}
+	if !caller.CanAccessBranch(property.BranchID) {
+		logrus.Warnf("Caller is not allowed to create a property in branch %d", property.BranchID)
+		return nil, fmt.Errorf("%w: you can only add properties to your own branch", models.ErrForbidden)
+	}
+	if property.BranchID == 0 {
+		logrus.Error("Property has no branch")
+		return nil, errors.New("branch_id is required")
+	}
+
+	// Agents list their own properties, only admins pick the agent
+	if !caller.IsAdmin() {
+		property.UserID = caller.UserID
+	} else {
+		if property.UserID == 0 {
+			logrus.Error("Agent ID must be provided")
+			return nil, fmt.Errorf("%w: user_id of the agent is required", models.ErrInvalidAgent)
+		}
+		if err := p.checkAgent(property.UserID, property.BranchID); err != nil {
+			return nil, err
+		}
+	}
 
 	createdProperty, err := p.propertyRepo.Create(property)
 	if err != nil {
@@ -59,7 +164,7 @@ func (p *PropertyUseCase) CreateProperty(property *models.Property) (*models.Pro
 	return createdProperty, nil
 }
 
-func (p *PropertyUseCase) UpdateProperty(property *models.Property) (*models.PropertyResponse, error) {
+func (p *PropertyUseCase) UpdateProperty(caller *models.Caller, property *models.Property) (*models.PropertyResponse, error) {
 	if property == nil {
 		logrus.Error("Property cannot be nil")
 		return nil, errors.New("property cannot be nil")
@@ -68,14 +173,24 @@ func (p *PropertyUseCase) UpdateProperty(property *models.Property) (*models.Pro
 		logrus.Error("Property ID must be provided")
 		return nil, errors.New("property ID must be provided")
 	}
-	if property.Address == "" {
-		logrus.Error("Address cannot be empty")
-		return nil, errors.New("address cannot be empty")
+	if err := validateUpdate(property); err != nil {
+		return nil, err
 	}
-	if property.Price <= 0 {
-		logrus.Error("Price must be greater than zero")
-		return nil, errors.New("price must be greater than zero")
+
+	existing, err := p.authorizeChange(caller, property.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("property", property.ID, existing.Version, property.Version); err != nil {
+		return nil, err
+	}
+	if err := checkStatusChange(property, existing); err != nil {
+		return nil, err
+	}
+	if err := p.checkReassignment(caller, property, existing); err != nil {
+		return nil, err
 	}
+	property.PriceChange = priceChange(caller, property, existing)
 
 	updatedProperty, err := p.propertyRepo.Update(property)
 	if err != nil {
@@ -84,15 +199,263 @@ func (p *PropertyUseCase) UpdateProperty(property *models.Property) (*models.Pro
 	return updatedProperty, nil
 }
 
-func (p *PropertyUseCase) DeleteProperty(id uint) error {
+// PatchProperty applies a JSON Merge Patch to the property and writes only
+// the patched fields. The merged property must pass the checks of
+// UpdateProperty, and version must be the current one.
+func (p *PropertyUseCase) PatchProperty(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.PropertyResponse, error) {
+	if id == 0 {
+		logrus.Error("Property ID must be provided")
+		return nil, errors.New("property ID must be provided")
+	}
+
+	existing, err := p.authorizeChange(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("property", id, existing.Version, version); err != nil {
+		return nil, err
+	}
+	property := existing.ToProperty()
+	if err := patch.Apply(property, models.PropertyPatchFields); err != nil {
+		logrus.WithError(err).Warnf("Refused patch of property %d", id)
+		return nil, err
+	}
+	if len(patch) == 0 {
+		return existing, nil
+	}
+
+	if err := validateUpdate(property); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidPatch, err)
+	}
+	if err := checkStatusChange(property, existing); err != nil {
+		return nil, err
+	}
+	if err := p.checkReassignment(caller, property, existing); err != nil {
+		return nil, err
+	}
+	property.PriceChange = priceChange(caller, property, existing)
+
+	// The reason goes to the price history, not to a column
+	fields := make([]string, 0, len(patch))
+	for _, field := range patch.Fields() {
+		if field != "price_change_reason" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return existing, nil
+	}
+	return p.propertyRepo.Patch(property, fields)
+}
+
+// ReassignProperty hands a property over to another agent. Routes restrict it
+// to admins; the new agent must be an active agent of the property's branch.
+// version must be the current one.
+func (p *PropertyUseCase) ReassignProperty(caller *models.Caller, id uint, version uint, agentID uint) (*models.PropertyResponse, error) {
+	if id == 0 {
+		logrus.Error("Property ID must be provided")
+		return nil, errors.New("property ID must be provided")
+	}
+	if agentID == 0 {
+		logrus.Error("Agent ID must be provided")
+		return nil, fmt.Errorf("%w: agent ID must be provided", models.ErrInvalidAgent)
+	}
+
+	existing, err := p.authorizeChange(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("property", id, existing.Version, version); err != nil {
+		return nil, err
+	}
+	if err := p.checkAgent(agentID, existing.BranchID); err != nil {
+		return nil, err
+	}
+
+	if err := p.propertyRepo.UpdateAgent(id, version, agentID); err != nil {
+		return nil, err
+	}
+	return p.propertyRepo.GetByID(id)
+}
+
+// ChangePropertyStatus moves the property to another status if its
+// transaction type allows it from the current one, and records who changed it
+// and why. Routes let the same callers as UpdateProperty do it.
+func (p *PropertyUseCase) ChangePropertyStatus(caller *models.Caller, id uint, request *models.PropertyStatusRequest) (*models.PropertyResponse, error) {
+	if id == 0 {
+		logrus.Error("Property ID must be provided")
+		return nil, errors.New("property ID must be provided")
+	}
+	if request == nil || strings.TrimSpace(request.Reason) == "" {
+		logrus.Error("Status change without a reason")
+		return nil, errors.New("a reason for the status change must be provided")
+	}
+
+	existing, err := p.authorizeChange(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.CheckStatusTransition(existing.TransactionType, existing.Status, request.Status); err != nil {
+		logrus.WithError(err).Warnf("Refused status change of property %d", id)
+		return nil, err
+	}
+
+	return p.propertyRepo.ChangeStatus(&models.PropertyStatusChange{
+		PropertyID: id,
+		FromStatus: existing.Status,
+		ToStatus:   request.Status,
+		Reason:     strings.TrimSpace(request.Reason),
+		ChangedBy:  caller.UserID,
+	})
+}
+
+func (p *PropertyUseCase) DeleteProperty(caller *models.Caller, id uint, version uint) error {
 	if id <= 0 {
 		logrus.Error("Property ID must be provided")
 		return errors.New("property ID must be provided")
 	}
 
-	err := p.propertyRepo.Delete(id)
+	existing, err := p.authorizeChange(caller, id)
+	if err != nil {
+		return err
+	}
+	if err := checkVersion("property", id, existing.Version, version); err != nil {
+		return err
+	}
+
+	err = p.propertyRepo.Delete(id, version)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// authorizeChange loads the property and checks that the caller is its
+// assigned agent or an admin of its branch. Properties of other branches are
+// reported as missing, like GetPropertyByID does.
+func (p *PropertyUseCase) authorizeChange(caller *models.Caller, id uint) (*models.PropertyResponse, error) {
+	existing, err := p.propertyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if caller != nil && !caller.CanAccessBranch(existing.BranchID) {
+		logrus.Warnf("Caller cannot see property %d of branch %d", id, existing.BranchID)
+		return nil, models.ErrPropertyNotFound
+	}
+	if caller == nil || (!caller.IsAdmin() && caller.UserID != existing.AgentID) {
+		logrus.Warnf("Caller is not allowed to modify property %d", id)
+		return nil, fmt.Errorf("%w: only the assigned agent or an admin can modify this property", models.ErrForbidden)
+	}
+	return existing, nil
+}
+
+// checkAgent makes sure the user can be the agent of a property of the branch
+func (p *PropertyUseCase) checkAgent(agentID uint, branchID uint) error {
+	agent, err := p.userRepo.GetByID(agentID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		logrus.Warnf("Refused assignment to missing user %d", agentID)
+		return fmt.Errorf("%w: user %d does not exist", models.ErrInvalidAgent, agentID)
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case agent.IsDeactivated():
+		logrus.Warnf("Refused assignment to deactivated user %d", agentID)
+		return fmt.Errorf("%w: user %d is deactivated", models.ErrInvalidAgent, agentID)
+	case agent.Role != models.RoleAgent:
+		logrus.Warnf("Refused assignment to user %d with role %s", agentID, agent.Role)
+		return fmt.Errorf("%w: user %d is not an agent", models.ErrInvalidAgent, agentID)
+	case agent.BranchID != branchID:
+		logrus.Warnf("Refused assignment to user %d of another branch", agentID)
+		return fmt.Errorf("%w: user %d does not belong to the property's branch", models.ErrInvalidAgent, agentID)
+	}
+	return nil
+}
+
+func validateUpdate(property *models.Property) error {
+	if property.Address == "" {
+		logrus.Error("Address cannot be empty")
+		return errors.New("address cannot be empty")
+	}
+	if property.Price <= 0 {
+		logrus.Error("Price must be greater than zero")
+		return errors.New("price must be greater than zero")
+	}
+	if len(property.PriceChangeReason) > 500 {
+		logrus.Error("Price change reason is too long")
+		return errors.New("price_change_reason cannot be longer than 500 characters")
+	}
+	return nil
+}
+
+// priceChange describes the change of price an update makes, for the price
+// history. It is nil when the price stays.
+func priceChange(caller *models.Caller, property *models.Property, existing *models.PropertyResponse) *models.PropertyPriceChange {
+	if property.Price == existing.Price {
+		return nil
+	}
+	return &models.PropertyPriceChange{
+		PropertyID: property.ID,
+		OldPrice:   existing.Price,
+		NewPrice:   property.Price,
+		Reason:     strings.TrimSpace(property.PriceChangeReason),
+		ChangedBy:  caller.UserID,
+	}
+}
+
+// checkStatusChange keeps the status out of updates, it only changes through
+// ChangePropertyStatus so that every change is recorded. Empty keeps the
+// current status and transaction type, and a new transaction type must allow
+// the status.
+func checkStatusChange(property *models.Property, existing *models.PropertyResponse) error {
+	if property.Status == "" {
+		property.Status = existing.Status
+	}
+	if property.TransactionType == "" {
+		property.TransactionType = existing.TransactionType
+	}
+	if property.Status != existing.Status {
+		logrus.Warnf("Attempt to change status of property %d through update", property.ID)
+		return fmt.Errorf("%w: the status of property %d can only change through its status endpoint", models.ErrInvalidStatusTransition, property.ID)
+	}
+	if property.TransactionType == existing.TransactionType {
+		return nil
+	}
+
+	status := property.Status
+	if status == "" {
+		status = models.StatusAvailable
+	}
+	if err := models.CheckStatus(property.TransactionType, status); err != nil {
+		logrus.WithError(err).Warnf("Refused transaction type change of property %d", property.ID)
+		return err
+	}
+	return nil
+}
+
+// checkReassignment keeps the agent and branch of the property unless they
+// may change: the agent only through ReassignProperty, the branch only by a
+// regional admin and only if the agent works at the new branch. Zero keeps
+// the current value.
+func (p *PropertyUseCase) checkReassignment(caller *models.Caller, property *models.Property, existing *models.PropertyResponse) error {
+	if property.UserID == 0 {
+		property.UserID = existing.AgentID
+	}
+	if property.UserID != existing.AgentID {
+		logrus.Warnf("Attempt to change agent of property %d through update", property.ID)
+		return fmt.Errorf("%w: the assigned agent can only be changed by an admin through reassignment", models.ErrForbidden)
+	}
+	if property.BranchID == 0 {
+		property.BranchID = existing.BranchID
+	}
+	if property.BranchID != existing.BranchID && !caller.Can(models.PermBranchesAll) {
+		logrus.Warnf("Attempt to move property %d to branch %d", property.ID, property.BranchID)
+		return fmt.Errorf("%w: only a regional admin can move a property to another branch", models.ErrForbidden)
+	}
+	if property.BranchID != existing.BranchID {
+		return p.checkAgent(property.UserID, property.BranchID)
+	}
+	return nil
+}
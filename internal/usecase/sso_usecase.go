@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+var errInvalidSSOState = errors.New("invalid or expired single sign-on attempt")
+
+// SSOUseCase signs staff in through the OpenID Connect provider of the agency.
+// An external identity is linked to an existing user the first time by the
+// email address the provider has verified; later logins follow the link even
+// if the email changes. Nobody gets an account this way, that still takes an
+// invitation.
+type SSOUseCase struct {
+	provider     ports.IdentityProvider
+	stateRepo    ports.SSOStateRepository
+	identityRepo ports.UserIdentityRepository
+	userRepo     ports.UserRepository
+	logins       *UserUseCase
+	stateTTL     time.Duration
+}
+
+// NewSSOUseCase builds the single sign-on flow. provider is nil when no
+// identity provider is configured, which turns every call into ErrSSONotConfigured.
+func NewSSOUseCase(
+	provider ports.IdentityProvider,
+	stateRepo ports.SSOStateRepository,
+	identityRepo ports.UserIdentityRepository,
+	userRepo ports.UserRepository,
+	logins *UserUseCase,
+	stateTTL time.Duration,
+) *SSOUseCase {
+	return &SSOUseCase{
+		provider:     provider,
+		stateRepo:    stateRepo,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		logins:       logins,
+		stateTTL:     stateTTL,
+	}
+}
+
+// StartLogin stores the PKCE verifier and nonce of a new attempt and returns
+// the provider URL the browser has to visit.
+func (uc *SSOUseCase) StartLogin() (*models.SSOAuthorization, error) {
+	if uc.provider == nil {
+		return nil, models.ErrSSONotConfigured
+	}
+
+	state, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := middleware.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(uc.stateTTL)
+	if err := uc.stateRepo.Create(&models.SSOLoginState{
+		StateHash:    middleware.HashToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    expiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := uc.provider.AuthCodeURL(state, nonce, codeChallenge(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+	return &models.SSOAuthorization{AuthorizationURL: authorizationURL, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteLogin handles the callback of the provider. Each state works once,
+// so a replayed callback is refused.
+func (uc *SSOUseCase) CompleteLogin(state string, code string, client models.ClientInfo) (*models.LoginResponse, error) {
+	if uc.provider == nil {
+		return nil, models.ErrSSONotConfigured
+	}
+	if state == "" || code == "" {
+		logrus.Error("Single sign-on callback without state or code")
+		return nil, errors.New("state and code cannot be empty")
+	}
+
+	loginState, err := uc.stateRepo.GetByStateHash(middleware.HashToken(state))
+	if err != nil {
+		return nil, errInvalidSSOState
+	}
+	if !loginState.IsUsable(time.Now()) {
+		logrus.Warnf("Single sign-on state %d is used or expired", loginState.ID)
+		return nil, errInvalidSSOState
+	}
+	if err := uc.stateRepo.Consume(loginState.ID); err != nil {
+		return nil, errInvalidSSOState
+	}
+
+	identity, err := uc.provider.Exchange(code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, errors.New("the identity provider sign-in could not be verified")
+	}
+
+	user, err := uc.resolveUser(identity)
+	if err != nil {
+		return nil, err
+	}
+	return uc.logins.CompleteExternalLogin(user, client)
+}
+
+func (uc *SSOUseCase) resolveUser(identity *models.ExternalIdentity) (*models.UserResponse, error) {
+	now := time.Now()
+
+	link, err := uc.identityRepo.GetBySubject(identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if link != nil {
+		if err := uc.identityRepo.TouchLastLogin(link.ID, now); err != nil {
+			logrus.WithError(err).Warnf("Failed to record login of identity %d", link.ID)
+		}
+		return uc.userRepo.GetByID(link.UserID)
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		logrus.Warnf("Identity %s of %s has no verified email to link", identity.Subject, identity.Issuer)
+		return nil, errors.New("the identity provider has not verified your email address")
+	}
+
+	user, err := uc.userRepo.GetByEmail(identity.Email)
+	if err != nil {
+		logrus.WithError(err).Warnf("No user to link identity %s of %s to", identity.Subject, identity.Issuer)
+		return nil, errors.New("no account matches this identity, ask an administrator for an invitation")
+	}
+
+	if err := uc.identityRepo.Create(&models.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+
+	// The provider just proved the user controls this address
+	if !user.IsEmailVerified() {
+		if err := uc.userRepo.MarkEmailVerified(user.ID); err != nil {
+			return nil, err
+		}
+		return uc.userRepo.GetByID(user.ID)
+	}
+	return user, nil
+}
+
+// codeChallenge is the PKCE S256 challenge of verifier (RFC 7636).
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
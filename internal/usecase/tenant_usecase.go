@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+// TenantServices are the parts of a tenant that provisioning works with, all
+// bound to that tenant.
+type TenantServices struct {
+	Branches    ports.BranchRepository
+	Invitations ports.InvitationUseCase
+}
+
+type TenantUseCase struct {
+	tenantRepo  ports.TenantRepository
+	servicesFor func(tenant *models.Tenant) TenantServices
+}
+
+func NewTenantUseCase(tenantRepo ports.TenantRepository, servicesFor func(tenant *models.Tenant) TenantServices) *TenantUseCase {
+	return &TenantUseCase{
+		tenantRepo:  tenantRepo,
+		servicesFor: servicesFor,
+	}
+}
+
+func (uc *TenantUseCase) GetTenants() ([]models.Tenant, error) {
+	return uc.tenantRepo.GetAll()
+}
+
+func (uc *TenantUseCase) GetTenant(id uint) (*models.Tenant, error) {
+	return uc.tenantRepo.GetByID(id)
+}
+
+// CreateTenant provisions an agency: the tenant, its first branch and the
+// invitation of its first regional admin, who sets up everything else.
+func (uc *TenantUseCase) CreateTenant(tenantData *models.TenantData) (*models.Tenant, error) {
+	tenant, err := uc.newTenant(0, tenantData)
+	if err != nil {
+		return nil, err
+	}
+	adminEmail, err := parseAdminEmail(tenantData.AdminEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.tenantRepo.Create(tenant); err != nil {
+		return nil, err
+	}
+	services := uc.servicesFor(tenant)
+	branch := &models.Branch{Name: models.DefaultBranchName}
+	if err := services.Branches.Create(branch); err != nil {
+		return nil, err
+	}
+	if _, err := uc.inviteAdmin(services, branch.ID, adminEmail); err != nil {
+		logrus.WithError(err).Errorf("Tenant %q was created without inviting its admin", tenant.Slug)
+		return nil, err
+	}
+
+	logrus.Infof("Tenant %q provisioned with ID: %d", tenant.Slug, tenant.ID)
+	return tenant, nil
+}
+
+func (uc *TenantUseCase) UpdateTenant(id uint, tenantData *models.TenantData) (*models.Tenant, error) {
+	if id == 0 {
+		return nil, errors.New("tenant ID must be provided")
+	}
+	current, err := uc.tenantRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := uc.newTenant(id, tenantData)
+	if err != nil {
+		return nil, err
+	}
+	// Single-tenant deployments find their data through this slug
+	if current.Slug == models.DefaultTenantSlug && tenant.Slug != current.Slug {
+		return nil, errors.New("the slug of the default tenant cannot change")
+	}
+
+	tenant.ID = id
+	if err := uc.tenantRepo.Update(tenant); err != nil {
+		return nil, err
+	}
+	return uc.tenantRepo.GetByID(id)
+}
+
+// InviteAdmin invites another regional admin to a tenant, for instance when
+// the first invitation expired before anyone accepted it.
+func (uc *TenantUseCase) InviteAdmin(id uint, adminData *models.TenantAdminData) (*models.InvitationResponse, error) {
+	if adminData == nil {
+		return nil, errors.New("admin data cannot be nil")
+	}
+	adminEmail, err := parseAdminEmail(adminData.Email)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := uc.tenantRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	services := uc.servicesFor(tenant)
+	branches, err := services.Branches.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, errors.New("tenant has no branch to invite the admin to")
+	}
+	return uc.inviteAdmin(services, branches[0].ID, adminEmail)
+}
+
+// inviteAdmin sends the invitation on behalf of the platform, which has no
+// user in the tenant.
+func (uc *TenantUseCase) inviteAdmin(services TenantServices, branchID uint, email string) (*models.InvitationResponse, error) {
+	platform := &models.Caller{Role: models.RoleRegionalAdmin, BranchID: branchID}
+	return services.Invitations.CreateInvitation(platform, &models.CreateInvitationData{
+		Email:    email,
+		Role:     models.RoleRegionalAdmin,
+		BranchID: branchID,
+	})
+}
+
+// newTenant validates the data and checks that no other tenant has the slug
+func (uc *TenantUseCase) newTenant(id uint, tenantData *models.TenantData) (*models.Tenant, error) {
+	if tenantData == nil {
+		logrus.Error("Tenant data cannot be nil")
+		return nil, errors.New("tenant data cannot be nil")
+	}
+	slug, err := models.NormalizeTenantSlug(tenantData.Slug)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(tenantData.Name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	existing, err := uc.tenantRepo.GetBySlug(slug)
+	if err != nil && !errors.Is(err, models.ErrTenantNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.ID != id {
+		logrus.Warnf("Tenant slug %q is already taken", slug)
+		return nil, errors.New("a tenant with this slug already exists")
+	}
+	return &models.Tenant{Slug: slug, Name: name}, nil
+}
+
+func parseAdminEmail(email string) (string, error) {
+	address, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil {
+		return "", errors.New("a valid admin_email is required")
+	}
+	return strings.ToLower(address.Address), nil
+}
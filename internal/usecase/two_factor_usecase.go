@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+const recoveryCodeCount = 10
+
+var errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+type TwoFactorUseCase struct {
+	userRepo      ports.UserRepository
+	twoFactorRepo ports.TwoFactorRepository
+	sessionRepo   ports.SessionRepository
+	issuer        string
+}
+
+func NewTwoFactorUseCase(userRepo ports.UserRepository, twoFactorRepo ports.TwoFactorRepository, sessionRepo ports.SessionRepository, issuer string) *TwoFactorUseCase {
+	return &TwoFactorUseCase{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		sessionRepo:   sessionRepo,
+		issuer:        issuer,
+	}
+}
+
+// Enroll creates a new pending TOTP secret. It only takes effect once
+// Confirm receives a valid code for it.
+func (uc *TwoFactorUseCase) Enroll(caller *models.Caller) (*models.TwoFactorEnrollment, error) {
+	if caller == nil {
+		return nil, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+
+	credential, err := uc.twoFactorRepo.GetByUserID(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.IsEnabled() {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	user, err := uc.userRepo.GetByID(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := middleware.GenerateTOTPSecret()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate TOTP secret")
+		return nil, err
+	}
+
+	if err := uc.twoFactorRepo.SavePending(caller.UserID, secret); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Two-factor enrollment started for user %d", caller.UserID)
+	return &models.TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURI: middleware.TOTPURI(uc.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication with the first code from the
+// authenticator and returns the recovery codes. They are shown only once.
+// The calling session counts as verified from then on.
+func (uc *TwoFactorUseCase) Confirm(caller *models.Caller, code string) (*models.RecoveryCodesResponse, error) {
+	if caller == nil {
+		return nil, fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+
+	credential, err := uc.twoFactorRepo.GetByUserID(caller.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, errors.New("no two-factor enrollment in progress")
+	}
+	if credential.IsEnabled() {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := middleware.ValidateTOTP(credential.Secret, code, time.Now(), credential.LastUsedStep)
+	if !ok {
+		logrus.Warnf("Invalid two-factor confirmation code for user %d", caller.UserID)
+		return nil, errInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate recovery codes")
+		return nil, err
+	}
+
+	if err := uc.twoFactorRepo.Confirm(caller.UserID, step); err != nil {
+		return nil, err
+	}
+	if err := uc.twoFactorRepo.ReplaceRecoveryCodes(caller.UserID, hashes); err != nil {
+		return nil, err
+	}
+	if err := uc.sessionRepo.MarkTwoFactorVerified(caller.SessionID); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Two-factor authentication enabled for user %d", caller.UserID)
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable turns two-factor authentication off after checking a current code.
+// Roles for which it is mandatory cannot turn it off.
+func (uc *TwoFactorUseCase) Disable(caller *models.Caller, code string) error {
+	if caller == nil {
+		return fmt.Errorf("%w: authentication required", models.ErrForbidden)
+	}
+	if caller.Role.RequiresTwoFactor() {
+		logrus.Warnf("User %d attempted to disable mandatory two-factor authentication", caller.UserID)
+		return fmt.Errorf("%w: two-factor authentication is mandatory for the %s role", models.ErrForbidden, caller.Role)
+	}
+
+	credential, err := uc.twoFactorRepo.GetByUserID(caller.UserID)
+	if err != nil {
+		return err
+	}
+	if !credential.IsEnabled() {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := verifySecondFactor(uc.twoFactorRepo, credential, code, "", time.Now()); err != nil {
+		return err
+	}
+
+	if err := uc.twoFactorRepo.Delete(caller.UserID); err != nil {
+		return err
+	}
+
+	logrus.Infof("Two-factor authentication disabled for user %d", caller.UserID)
+	return nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+func verifySecondFactor(repo ports.TwoFactorRepository, credential *models.TwoFactorCredential, code string, recoveryCode string, now time.Time) error {
+	if recoveryCode != "" {
+		if err := repo.UseRecoveryCode(credential.UserID, middleware.HashToken(normalizeRecoveryCode(recoveryCode))); err != nil {
+			logrus.Warnf("Invalid recovery code for user %d", credential.UserID)
+			return errInvalidTwoFactorCode
+		}
+		logrus.Infof("Recovery code used by user %d", credential.UserID)
+		return nil
+	}
+
+	step, ok := middleware.ValidateTOTP(credential.Secret, code, now, credential.LastUsedStep)
+	if !ok {
+		logrus.Warnf("Invalid two-factor code for user %d", credential.UserID)
+		return errInvalidTwoFactorCode
+	}
+	if err := repo.UseStep(credential.UserID, step); err != nil {
+		return errInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted as xxxx-xxxx-xxxx-xxxx
+// (80 random bits each) and the hashes to store.
+func generateRecoveryCodes(n int) ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, n)
+	hashes := make([]string, 0, n)
+	for range n {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(buf))
+		codes = append(codes, raw[0:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:16])
+		hashes = append(hashes, middleware.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -2,6 +2,10 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -11,35 +15,448 @@ import (
 )
 
 type UserUseCase struct {
-	repo ports.UserRepository
+	repo          ports.UserRepository
+	sessionRepo   ports.SessionRepository
+	twoFactorRepo ports.TwoFactorRepository
+	tokens        *middleware.TokenManager
+	loginGuard    *LoginGuard
+	emailVerifier *EmailVerifier
 }
 
-func NewUserUseCase(repo ports.UserRepository) *UserUseCase {
-	return &UserUseCase{repo: repo}
+func NewUserUseCase(repo ports.UserRepository, sessionRepo ports.SessionRepository, twoFactorRepo ports.TwoFactorRepository, tokens *middleware.TokenManager, loginGuard *LoginGuard, emailVerifier *EmailVerifier) *UserUseCase {
+	return &UserUseCase{repo: repo, sessionRepo: sessionRepo, twoFactorRepo: twoFactorRepo, tokens: tokens, loginGuard: loginGuard, emailVerifier: emailVerifier}
 }
 
-func (uc *UserUseCase) Login(email string, password string) error {
+func (uc *UserUseCase) Login(email string, password string, client models.ClientInfo) (*models.LoginResponse, error) {
+	now := time.Now()
+	if err := uc.loginGuard.Check(email, client.IPAddress, now); err != nil {
+		return nil, err
+	}
+
 	databasePassword, err := uc.repo.ConsultPassword(email)
 	if err != nil {
-		return err
+		uc.recordLoginFailure(email, client, now)
+		return nil, err
 	}
 
 	if err := middleware.VerifyPassword(databasePassword, password); err != nil {
 		logrus.WithError(err).Error("Password verification failed")
-		return err
+		uc.recordLoginFailure(email, client, now)
+		return nil, err
+	}
+
+	user, err := uc.repo.GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	// The password was right, so a refused account does not count as a failed attempt
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	if middleware.PasswordNeedsRehash(databasePassword) {
+		uc.rehashPassword(user.ID, password)
+	}
+
+	loginResponse, err := uc.beginLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+	// The failed attempts are only cleared once the second factor passes,
+	// otherwise knowing the password would allow unlimited code guesses
+	if !loginResponse.TwoFactorRequired {
+		if err := uc.loginGuard.RecordSuccess(email); err != nil {
+			logrus.WithError(err).Error("Failed to clear failed login attempts")
+		}
+	}
+	return loginResponse, nil
+}
+
+// CompleteExternalLogin signs in a user whose identity was proven somewhere
+// else, such as by single sign-on. The account checks and the second factor
+// of a password login still apply.
+func (uc *UserUseCase) CompleteExternalLogin(user *models.UserResponse, client models.ClientInfo) (*models.LoginResponse, error) {
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+	return uc.beginLogin(user, client)
+}
+
+func checkCanLogin(user *models.UserResponse) error {
+	if user.IsDeactivated() {
+		logrus.Warnf("Login refused for deactivated user %d", user.ID)
+		return models.ErrAccountDeactivated
+	}
+	if !user.IsEmailVerified() {
+		logrus.Warnf("Login refused for user %d, email address not verified", user.ID)
+		return models.ErrEmailNotVerified
+	}
+	return nil
+}
+
+// beginLogin runs once the first factor passed: it answers with a two-factor
+// challenge when the user has a second factor, or opens the session.
+func (uc *UserUseCase) beginLogin(user *models.UserResponse, client models.ClientInfo) (*models.LoginResponse, error) {
+	credential, err := uc.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.IsEnabled() {
+		challenge, challengeExpiresAt, err := uc.tokens.GenerateTwoFactorChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		logrus.Infof("Two-factor challenge issued for user %d", user.ID)
+		return &models.LoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challenge,
+			ChallengeExpiresAt: &challengeExpiresAt,
+		}, nil
 	}
+
+	loginResponse, err := uc.startSession(user, client, false)
+	if err != nil {
+		return nil, err
+	}
+	// The session only reaches the enrollment endpoints until 2FA is set up
+	loginResponse.TwoFactorSetupRequired = user.Role.RequiresTwoFactor()
+
 	logrus.Info("User login successful")
+	return loginResponse, nil
+}
+
+// CompleteTwoFactorLogin finishes a login started by Login with the
+// challenge token and either a TOTP code or a recovery code.
+func (uc *UserUseCase) CompleteTwoFactorLogin(challengeToken string, code string, recoveryCode string, client models.ClientInfo) (*models.LoginResponse, error) {
+	userID, err := uc.tokens.ParseTwoFactorChallenge(challengeToken)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid two-factor challenge")
+		return nil, errors.New("invalid or expired two-factor challenge")
+	}
+
+	user, err := uc.repo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeactivated() {
+		logrus.Warnf("Two-factor login refused for deactivated user %d", user.ID)
+		return nil, models.ErrAccountDeactivated
+	}
+
+	now := time.Now()
+	if err := uc.loginGuard.Check(user.Email, client.IPAddress, now); err != nil {
+		return nil, err
+	}
+
+	credential, err := uc.twoFactorRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !credential.IsEnabled() {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := verifySecondFactor(uc.twoFactorRepo, credential, code, recoveryCode, now); err != nil {
+		uc.recordLoginFailure(user.Email, client, now)
+		return nil, err
+	}
+
+	if err := uc.loginGuard.RecordSuccess(user.Email); err != nil {
+		logrus.WithError(err).Error("Failed to clear failed login attempts")
+	}
+
+	loginResponse, err := uc.startSession(user, client, true)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Info("User login with two-factor authentication successful")
+	return loginResponse, nil
+}
+
+// rehashPassword upgrades a stored hash to the current hashing policy. The
+// plain password is only available at login, so this is the place to do it;
+// a failure here must not block the login.
+func (uc *UserUseCase) rehashPassword(userID uint, password string) {
+	hashedPassword, err := middleware.HashPassword(password)
+	if err != nil {
+		logrus.WithError(err).Warnf("Failed to rehash password of user %d", userID)
+		return
+	}
+	if err := uc.repo.UpdatePassword(userID, hashedPassword); err != nil {
+		logrus.WithError(err).Warnf("Failed to store rehashed password of user %d", userID)
+		return
+	}
+	logrus.Infof("Password hash of user %d upgraded to the current policy", userID)
+}
+
+func (uc *UserUseCase) recordLoginFailure(email string, client models.ClientInfo, now time.Time) {
+	if err := uc.loginGuard.RecordFailure(email, client.IPAddress, now); err != nil {
+		logrus.WithError(err).Error("Failed to record failed login attempt")
+	}
+}
+
+// UnlockUser clears the failed login counter of a user so they can log in
+// again before the lockout expires.
+func (uc *UserUseCase) UnlockUser(caller *models.Caller, id uint) error {
+	user, err := uc.manageableUser(caller, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.loginGuard.Unlock(user.Email); err != nil {
+		return err
+	}
+
+	logrus.Infof("Login lockout cleared for user %d", id)
 	return nil
 }
 
-func (uc *UserUseCase) GetAllUsers() ([]models.UserResponse, error) {
-	return uc.repo.GetAll()
+// VerifyEmail consumes a token from a verification email. For an email change
+// this is the moment the new address replaces the old one.
+func (uc *UserUseCase) VerifyEmail(token string) (*models.UserResponse, error) {
+	user, err := uc.emailVerifier.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Email address of user %d verified", user.ID)
+	return user, nil
+}
+
+// ResendEmailVerification mails a new link to an account that is not verified
+// yet. Like ForgotPassword, it does not tell whether the email is registered.
+func (uc *UserUseCase) ResendEmailVerification(email string) error {
+	if email == "" {
+		logrus.Error("Email cannot be empty")
+		return errors.New("email cannot be empty")
+	}
+
+	user, err := uc.repo.GetByEmail(email)
+	if err != nil {
+		logrus.WithError(err).Warn("Email verification requested for unknown email")
+		return nil
+	}
+	if user.IsEmailVerified() {
+		logrus.Infof("Email verification requested for already verified user %d", user.ID)
+		return nil
+	}
+
+	return uc.emailVerifier.Send(user, user.Email)
 }
 
-func (uc *UserUseCase) GetUserByID(id uint) (*models.UserResponse, error) {
+// MarkEmailVerified is the admin override for users who cannot receive the
+// verification email. It verifies the current address, not a pending change.
+func (uc *UserUseCase) MarkEmailVerified(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	if _, err := uc.manageableUser(caller, id); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.MarkEmailVerified(id); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Email address of user %d verified by an admin", id)
 	return uc.repo.GetByID(id)
 }
 
+// RefreshSession exchanges a refresh token for a new access/refresh token pair.
+// Presenting an already rotated refresh token revokes the whole session, since
+// it means the token was copied.
+func (uc *UserUseCase) RefreshSession(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error) {
+	if refreshToken == "" {
+		logrus.Error("Refresh token cannot be empty")
+		return nil, errors.New("refresh token cannot be empty")
+	}
+
+	tokenHash := middleware.HashToken(refreshToken)
+	session, err := uc.sessionRepo.GetByRefreshTokenHash(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.RefreshTokenHash != tokenHash {
+		logrus.Warnf("Reuse of rotated refresh token detected for session %d, revoking it", session.ID)
+		if err := uc.sessionRepo.Revoke(session.ID); err != nil {
+			logrus.WithError(err).Error("Failed to revoke session after refresh token reuse")
+		}
+		return nil, errors.New("refresh token has already been used")
+	}
+
+	if !session.IsActive(time.Now()) {
+		logrus.Warnf("Refresh attempted on inactive session %d", session.ID)
+		return nil, errors.New("session has been revoked or expired")
+	}
+
+	user, err := uc.repo.GetByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, newHash, refreshExpiresAt, err := uc.tokens.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.sessionRepo.Rotate(session.ID, tokenHash, newHash, refreshExpiresAt, client); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := uc.tokens.GenerateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
+	}, nil
+}
+
+func (uc *UserUseCase) Logout(sessionID uint) error {
+	return uc.sessionRepo.Revoke(sessionID)
+}
+
+func (uc *UserUseCase) GetSessions(userID uint, currentSessionID uint) ([]models.SessionResponse, error) {
+	sessions, err := uc.sessionRepo.GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, *sessions[i].ToSessionResponse(currentSessionID))
+	}
+	return responses, nil
+}
+
+// RevokeSession lets a user sign out one of their own devices remotely.
+func (uc *UserUseCase) RevokeSession(userID uint, sessionID uint) error {
+	session, err := uc.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		logrus.Warnf("User %d attempted to revoke session %d of another user", userID, sessionID)
+		return errors.New("session not found")
+	}
+	return uc.sessionRepo.Revoke(sessionID)
+}
+
+func (uc *UserUseCase) startSession(user *models.UserResponse, client models.ClientInfo, twoFactorVerified bool) (*models.LoginResponse, error) {
+	refreshToken, refreshHash, refreshExpiresAt, err := uc.tokens.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := uc.sessionRepo.Create(&models.Session{
+		UserID:            user.ID,
+		RefreshTokenHash:  refreshHash,
+		UserAgent:         client.UserAgent,
+		IPAddress:         client.IPAddress,
+		ExpiresAt:         refreshExpiresAt,
+		TwoFactorVerified: twoFactorVerified,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := uc.tokens.GenerateAccessToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+		User:             user,
+	}, nil
+}
+
+// GetAllUsers returns a page of the users of the caller's branch, newest
+// first unless the page sorts otherwise. branchID filters by another branch,
+// which only regional admins may do; for them 0 lists everyone.
+func (uc *UserUseCase) GetAllUsers(caller *models.Caller, branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error) {
+	scope, err := caller.BranchScope(branchID)
+	if err != nil {
+		logrus.WithError(err).Warn("Refused user listing outside the caller's branch")
+		return nil, err
+	}
+	if page == nil {
+		page = &models.PageRequest{}
+	}
+	if err := page.Normalize(models.UserSortFields, "-created_at"); err != nil {
+		logrus.WithError(err).Warn("Refused user listing with an invalid page")
+		return nil, err
+	}
+	return uc.repo.GetPage(scope, page)
+}
+
+// GetUserByID returns a user of the caller's branch. Users of other branches
+// are reported as not found so their existence does not leak.
+func (uc *UserUseCase) GetUserByID(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	user, err := uc.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if caller == nil || (caller.UserID != user.ID && !caller.CanAccessBranch(user.BranchID)) {
+		logrus.Warnf("User %d is outside the caller's branch", id)
+		return nil, models.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// manageableUser loads a user the caller may change. As in GetUserByID, users
+// of other branches are reported as not found, and only callers who see every
+// branch may change a regional admin.
+func (uc *UserUseCase) manageableUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	user, err := uc.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanManage(caller, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func checkCanManage(caller *models.Caller, user *models.UserResponse) error {
+	if !caller.CanAccessBranch(user.BranchID) {
+		logrus.Warnf("User %d is outside the caller's branch", user.ID)
+		return models.ErrUserNotFound
+	}
+	if user.Role == models.RoleRegionalAdmin && !caller.Can(models.PermBranchesAll) {
+		logrus.Warnf("Caller is not allowed to change regional admin %d", user.ID)
+		return fmt.Errorf("%w: only a regional admin can change a regional admin", models.ErrForbidden)
+	}
+	return nil
+}
+
+// UpdateProfile replaces the caller's own profile after normalizing phone
+// numbers and validating the fields. version must be the current one.
+func (uc *UserUseCase) UpdateProfile(userID uint, version uint, profile *models.UserProfile) (*models.UserResponse, error) {
+	if profile == nil {
+		logrus.Error("Profile cannot be nil")
+		return nil, errors.New("profile cannot be nil")
+	}
+	if err := profile.Normalize(); err != nil {
+		logrus.WithError(err).Warnf("Invalid profile for user %d", userID)
+		return nil, err
+	}
+
+	if err := uc.repo.UpdateProfile(userID, version, profile); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetByID(userID)
+}
+
 func (uc *UserUseCase) CreateUser(user *models.User) (*models.UserResponse, error) {
 	if(user.Password == "") {
 		logrus.Error("Password cannot be empty")
@@ -59,14 +476,230 @@ func (uc *UserUseCase) CreateUser(user *models.User) (*models.UserResponse, erro
 		logrus.Error("Email cannot be empty")
 		return nil, errors.New("email cannot be empty")
 	}
+	if user.Role == "" {
+		user.Role = models.RoleAssistant
+	}
+	if !user.Role.IsValid() {
+		logrus.Errorf("Invalid role %q", user.Role)
+		return nil, errors.New("invalid role")
+	}
+
+	created, err := uc.repo.Create(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !created.IsEmailVerified() {
+		// The account exists either way; the link can be requested again
+		if err := uc.emailVerifier.Send(created, created.Email); err != nil {
+			logrus.WithError(err).Errorf("Failed to send verification email to user %d", created.ID)
+		}
+	}
+	return created, nil
+}
+
+// UpdateUser saves the username of a user the caller may manage right away.
+// A new email is only mailed a verification link; it replaces the current
+// one once that link is opened.
+func (uc *UserUseCase) UpdateUser(caller *models.Caller, user *models.User) (*models.UserResponse, error) {
+	current, err := uc.manageableUser(caller, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("user", user.ID, current.Version, user.Version); err != nil {
+		return nil, err
+	}
+
+	newEmail := strings.TrimSpace(user.Email)
+	emailChanged := newEmail != "" && !strings.EqualFold(newEmail, current.Email)
+	if emailChanged {
+		address, err := mail.ParseAddress(newEmail)
+		if err != nil {
+			logrus.WithError(err).Error("Invalid email")
+			return nil, errors.New("a valid email is required")
+		}
+		newEmail = address.Address
+		if owner, err := uc.repo.GetByEmail(newEmail); err == nil && owner.ID != user.ID {
+			logrus.Warnf("User %d attempted to change their email to one of user %d", user.ID, owner.ID)
+			return nil, models.ErrEmailTaken
+		}
+	}
+
+	user.Email = current.Email
+	updated, err := uc.repo.Update(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		if err := uc.emailVerifier.Send(updated, newEmail); err != nil {
+			return nil, err
+		}
+		logrus.Infof("Email change of user %d is waiting for verification", user.ID)
+	}
+	return updated, nil
+}
+
+// PatchUser applies a JSON Merge Patch to the username and email of a user
+// of the caller's branch, which must still be at version. A new email waits
+// for verification like in UpdateUser.
+func (uc *UserUseCase) PatchUser(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.UserResponse, error) {
+	current, err := uc.manageableUser(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("user", id, current.Version, version); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: current.ID, Username: current.Username, Email: current.Email, Version: version}
+	if err := patch.Apply(user, models.UserPatchFields); err != nil {
+		logrus.WithError(err).Warnf("Refused patch of user %d", id)
+		return nil, err
+	}
+	if len(patch) == 0 {
+		return current, nil
+	}
+	if strings.TrimSpace(user.Username) == "" {
+		logrus.Errorf("Patch of user %d removes the username", id)
+		return nil, fmt.Errorf("%w: username cannot be empty", models.ErrInvalidPatch)
+	}
+	if strings.TrimSpace(user.Email) == "" {
+		logrus.Errorf("Patch of user %d removes the email", id)
+		return nil, fmt.Errorf("%w: email cannot be empty", models.ErrInvalidPatch)
+	}
+
+	if _, err := uc.UpdateUser(caller, user); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetByID(id)
+}
+
+// UpdateUserRole changes the role of a user the caller may manage. Admins
+// cannot change their own role so the last admin cannot lock everyone out by
+// accident.
+func (uc *UserUseCase) UpdateUserRole(caller *models.Caller, id uint, version uint, role models.UserRole) (*models.UserResponse, error) {
+	if !role.IsValid() {
+		logrus.Errorf("Invalid role %q", role)
+		return nil, errors.New("invalid role")
+	}
+	if caller != nil && caller.UserID == id {
+		logrus.Warnf("User %d attempted to change their own role", id)
+		return nil, errors.New("you cannot change your own role")
+	}
+	if role == models.RoleRegionalAdmin && !caller.Can(models.PermBranchesManage) {
+		logrus.Warnf("Caller is not allowed to make user %d a regional admin", id)
+		return nil, fmt.Errorf("%w: only a regional admin can grant the regional admin role", models.ErrForbidden)
+	}
+	current, err := uc.manageableUser(caller, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion("user", id, current.Version, version); err != nil {
+		return nil, err
+	}
 
-	return uc.repo.Create(user)
+	if err := uc.repo.UpdateRole(id, version, role); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetByID(id)
 }
 
-func (uc *UserUseCase) UpdateUser(user *models.User) (*models.UserResponse, error) {
-	return uc.repo.Update(user)
+// EnsureAdmin makes sure the configured bootstrap account exists and is a
+// regional admin, so a fresh deployment always has someone who can manage
+// users and branches.
+func (uc *UserUseCase) EnsureAdmin(admin *models.User) error {
+	existing, err := uc.repo.GetByEmail(admin.Email)
+	if err == nil {
+		if existing.Role == models.RoleRegionalAdmin {
+			return nil
+		}
+		logrus.Infof("Promoting bootstrap user %s to regional admin", admin.Email)
+		return uc.repo.UpdateRole(existing.ID, existing.Version, models.RoleRegionalAdmin)
+	}
+
+	logrus.Infof("Creating bootstrap admin %s", admin.Email)
+	admin.Role = models.RoleRegionalAdmin
+	// The address comes from the deployment configuration, so it is trusted
+	verifiedAt := time.Now()
+	admin.EmailVerifiedAt = &verifiedAt
+	_, err = uc.CreateUser(admin)
+	return err
 }
 
-func (uc *UserUseCase) DeleteUser(id uint) error {
-	return uc.repo.Delete(id)
+// DeactivateUser is for staff who left: they can no longer log in, every
+// session and API key is revoked, but they still appear in listings.
+func (uc *UserUseCase) DeactivateUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	if caller != nil && caller.UserID == id {
+		logrus.Warnf("User %d attempted to deactivate their own account", id)
+		return nil, errors.New("you cannot deactivate your own account")
+	}
+	if _, err := uc.manageableUser(caller, id); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Deactivate(id); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetByID(id)
+}
+
+// DeleteUser deletes a user the caller may manage if it is still at version
+func (uc *UserUseCase) DeleteUser(caller *models.Caller, id uint, version uint) error {
+	if _, err := uc.manageableUser(caller, id); err != nil {
+		return err
+	}
+	return uc.repo.Delete(id, version)
+}
+
+// GetDeletedUsers lists the deleted users of the caller's branch. branchID
+// filters by another branch, which only regional admins may do; for them 0
+// lists everyone.
+func (uc *UserUseCase) GetDeletedUsers(caller *models.Caller, branchID uint) ([]models.UserResponse, error) {
+	scope, err := caller.BranchScope(branchID)
+	if err != nil {
+		logrus.WithError(err).Warn("Refused deleted user listing outside the caller's branch")
+		return nil, err
+	}
+	return uc.repo.GetDeleted(scope)
+}
+
+// deletedManageableUser is manageableUser also finding deleted users
+func (uc *UserUseCase) deletedManageableUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	user, err := uc.repo.GetByIDIncludingDeleted(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCanManage(caller, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RestoreUser reactivates a deleted or deactivated user the caller may
+// manage. Their sessions and API keys stay revoked, so they have to log in
+// again.
+func (uc *UserUseCase) RestoreUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	if _, err := uc.deletedManageableUser(caller, id); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("User %d restored", id)
+	return uc.repo.GetByID(id)
+}
+
+// PurgeUser permanently removes a user that was deleted before. Properties
+// must be reassigned first, they are never removed along with their agent.
+func (uc *UserUseCase) PurgeUser(caller *models.Caller, id uint) error {
+	if caller != nil && caller.UserID == id {
+		logrus.Warnf("User %d attempted to purge their own account", id)
+		return errors.New("you cannot purge your own account")
+	}
+	if _, err := uc.deletedManageableUser(caller, id); err != nil {
+		return err
+	}
+	return uc.repo.Purge(id)
 }
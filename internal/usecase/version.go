@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// checkVersion refuses a write based on an outdated read before any work is
+// done. The repositories repeat the comparison atomically in the write.
+func checkVersion(resource string, id uint, current uint, expected uint) error {
+	if current == expected {
+		return nil
+	}
+	logrus.Warnf("Refused write of %s %d at version %d, it is at version %d", resource, id, expected, current)
+	return fmt.Errorf("%w: %s %d is at version %d", models.ErrStaleVersion, resource, id, current)
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+const (
+	ContextUserIDKey    = "user_id"
+	ContextSessionIDKey = "session_id"
+	ContextRoleKey      = "user_role"
+	ContextTwoFactorKey = "two_factor_verified"
+	ContextAPIKeyIDKey  = "api_key_id"
+	ContextScopesKey    = "api_key_scopes"
+	ContextBranchIDKey  = "branch_id"
+
+	// APIKeyHeader carries the key of integrations that call the API
+	// without a user session.
+	APIKeyHeader = "X-API-Key"
+)
+
+// AuthMiddleware rejects requests without a valid Bearer access token or whose
+// session has been revoked, and stores the caller's identity on the gin context.
+// Requests with an API key header are authenticated by the key instead.
+func AuthMiddleware(tokens *TokenManager, sessions ports.SessionRepository, apiKeys ports.APIKeyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" {
+			authenticateAPIKey(c, apiKeys, rawKey)
+			return
+		}
+
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenString == "" {
+			logrus.Warn("Missing or malformed Authorization header")
+			abortUnauthorized(c, "Missing or malformed Authorization header")
+			return
+		}
+
+		claims, err := tokens.ParseAccessToken(tokenString)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid access token")
+			abortUnauthorized(c, "Invalid or expired access token")
+			return
+		}
+
+		// Sessions are not bound to a tenant, the token is
+		if tenantID, ok := GetTenantID(c); ok && claims.TenantID != tenantID {
+			logrus.Warnf("Access token of tenant %d used for tenant %d", claims.TenantID, tenantID)
+			abortUnauthorized(c, "Access token was issued for another tenant")
+			return
+		}
+
+		session, err := sessions.GetByID(claims.SessionID)
+		if err != nil || session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+			logrus.WithError(err).Warnf("Session %d is no longer active", claims.SessionID)
+			abortUnauthorized(c, "Session has been revoked or expired")
+			return
+		}
+
+		c.Set(ContextUserIDKey, claims.UserID)
+		c.Set(ContextSessionIDKey, claims.SessionID)
+		c.Set(ContextRoleKey, claims.Role)
+		c.Set(ContextBranchIDKey, claims.BranchID)
+		c.Set(ContextTwoFactorKey, session.TwoFactorVerified)
+		c.Next()
+	}
+}
+
+// authenticateAPIKey lets the request through as the key's creator, limited
+// to the key's scopes.
+func authenticateAPIKey(c *gin.Context, apiKeys ports.APIKeyRepository, rawKey string) {
+	now := time.Now()
+	key, err := apiKeys.GetByHash(HashToken(rawKey))
+	if err != nil || !key.IsActive(now) {
+		logrus.WithError(err).Warn("Invalid, revoked or expired API key")
+		abortUnauthorized(c, "Invalid, revoked or expired API key")
+		return
+	}
+
+	if err := apiKeys.TouchLastUsed(key.ID, now); err != nil {
+		logrus.WithError(err).Warnf("Failed to record use of API key %d", key.ID)
+	}
+
+	c.Set(ContextUserIDKey, key.UserID)
+	c.Set(ContextAPIKeyIDKey, key.ID)
+	c.Set(ContextScopesKey, key.ScopeList())
+	c.Set(ContextBranchIDKey, key.BranchID)
+	c.Next()
+}
+
+// GetUserID returns the authenticated user ID set by AuthMiddleware.
+func GetUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get(ContextUserIDKey)
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	return userID, ok && userID != 0
+}
+
+// GetSessionID returns the session of the authenticated access token.
+func GetSessionID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get(ContextSessionIDKey)
+	if !exists {
+		return 0, false
+	}
+	sessionID, ok := value.(uint)
+	return sessionID, ok && sessionID != 0
+}
+
+// GetRole returns the role carried by the authenticated access token.
+func GetRole(c *gin.Context) (models.UserRole, bool) {
+	value, exists := c.Get(ContextRoleKey)
+	if !exists {
+		return "", false
+	}
+	role, ok := value.(models.UserRole)
+	return role, ok
+}
+
+// IsTwoFactorVerified reports whether the session passed a second factor.
+func IsTwoFactorVerified(c *gin.Context) bool {
+	return c.GetBool(ContextTwoFactorKey)
+}
+
+// GetCaller gathers the identity stored by AuthMiddleware.
+func GetCaller(c *gin.Context) (*models.Caller, bool) {
+	caller := contextCaller(c)
+	if caller.UserID == 0 {
+		return nil, false
+	}
+	return caller, true
+}
+
+func contextCaller(c *gin.Context) *models.Caller {
+	userID, _ := GetUserID(c)
+	sessionID, _ := GetSessionID(c)
+	role, _ := GetRole(c)
+	caller := &models.Caller{UserID: userID, SessionID: sessionID, Role: role}
+	if branchID, ok := c.Get(ContextBranchIDKey); ok {
+		caller.BranchID, _ = branchID.(uint)
+	}
+	if apiKeyID, ok := c.Get(ContextAPIKeyIDKey); ok {
+		scopes, _ := c.Get(ContextScopesKey)
+		caller.APIKeyID, _ = apiKeyID.(uint)
+		caller.Scopes, _ = scopes.([]models.Permission)
+	}
+	return caller
+}
+
+func abortUnauthorized(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":   "Unauthorized",
+		"message": message,
+	})
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+
+	"inmo-backend/internal/domain/ports"
+)
+
+type BcryptHasher struct {
+	cost int
+}
+
+func NewBcryptHasher(cost int) ports.PasswordHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	// bcrypt ignores everything past 72 bytes
+	if len(password) > 72 {
+		logrus.Error("Password must not exceed 72 characters")
+		return "", errors.New("password must not exceed 72 characters")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to hash password")
+		return "", err
+	}
+
+	return string(hashedPassword), nil
+}
+
+func (h *BcryptHasher) Verify(hash string, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+func (h *BcryptHasher) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+	return cost < h.cost
+}
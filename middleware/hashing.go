@@ -2,38 +2,82 @@ package middleware
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
+
+	"inmo-backend/internal/domain/ports"
 )
 
 const (
 	COST = 14
+
+	MinPasswordLength = 8
+)
+
+var (
+	hasherMu sync.RWMutex
+	// currentHasher hashes new passwords. Bcrypt stays the default so that
+	// existing deployments keep working until a policy is configured.
+	currentHasher ports.PasswordHasher = NewBcryptHasher(COST)
+	// knownHashers can verify stored hashes, whatever the current policy is.
+	knownHashers = []ports.PasswordHasher{
+		NewBcryptHasher(COST),
+		NewArgon2idHasher(DefaultArgon2idParams),
+	}
 )
 
+// SetPasswordHasher changes the algorithm used for new hashes. Hashes made by
+// the other supported algorithms can still be verified.
+func SetPasswordHasher(hasher ports.PasswordHasher) {
+	hasherMu.Lock()
+	defer hasherMu.Unlock()
+	currentHasher = hasher
+}
+
 func HashPassword(password string) (string, error) {
-	if len(password) < 8 {
+	if len(password) < MinPasswordLength {
 		logrus.Error("Password must be at least 8 characters long")
 		return "", errors.New("password must be at least 8 characters long")
 	}
-	if len(password) > 72 {
-		logrus.Error("Password must not exceed 72 characters")
-		return "", errors.New("password must not exceed 72 characters")
+
+	return passwordHasher().Hash(password)
+}
+
+// VerifyPassword checks password against a stored hash, picking the algorithm
+// from the hash prefix.
+func VerifyPassword(databasePassword string, password string) error {
+	hasher := hasherFor(databasePassword)
+	if hasher == nil {
+		return errors.New("unknown password hash format")
 	}
-	
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), COST)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to hash password")
-		return "", err
+	return hasher.Verify(databasePassword, password)
+}
+
+// PasswordNeedsRehash reports whether a stored hash was made with another
+// algorithm or weaker parameters than the current policy.
+func PasswordNeedsRehash(databasePassword string) bool {
+	current := passwordHasher()
+	if !current.Identifies(databasePassword) {
+		return true
 	}
+	return current.NeedsRehash(databasePassword)
+}
 
-	return string(hashedPassword), nil
+func passwordHasher() ports.PasswordHasher {
+	hasherMu.RLock()
+	defer hasherMu.RUnlock()
+	return currentHasher
 }
 
-func VerifyPassword(databasePassword string, password string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(databasePassword), []byte(password))
-	if err != nil {
-		return err
+func hasherFor(hash string) ports.PasswordHasher {
+	if current := passwordHasher(); current.Identifies(hash) {
+		return current
+	}
+	for _, hasher := range knownHashers {
+		if hasher.Identifies(hash) {
+			return hasher
+		}
 	}
 	return nil
-}
\ No newline at end of file
+}
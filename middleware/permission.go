@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+)
+
+// RequirePermission only lets the request through when the caller's role, or
+// the scopes of its API key, grant the permission. It must run after AuthMiddleware.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := contextCaller(c)
+		if !caller.Can(permission) {
+			logrus.Warnf("Role %q (API key %d) denied permission %q on %s %s", caller.Role, caller.APIKeyID, permission, c.Request.Method, c.FullPath())
+			AbortForbidden(c, "You do not have permission to perform this action")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTwoFactor blocks roles that must use two-factor authentication
+// until the session has passed a second factor. It must run after
+// AuthMiddleware; the enrollment routes are registered outside of it.
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := GetRole(c)
+		if role.RequiresTwoFactor() && !IsTwoFactorVerified(c) {
+			logrus.Warnf("Role %q without two-factor authentication denied on %s %s", role, c.Request.Method, c.FullPath())
+			AbortForbidden(c, "Two-factor authentication is required for this account, enroll at /api/v1/users/2fa/enroll")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireUserSession keeps API keys away from account routes that only make
+// sense for a person, such as sessions, passwords and two-factor settings.
+func RequireUserSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetSessionID(c); !ok {
+			logrus.Warnf("Request without a user session denied on %s %s", c.Request.Method, c.FullPath())
+			AbortForbidden(c, "This endpoint requires a user session and cannot be used with an API key")
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortForbidden writes the 403 body shared by every authorization check.
+func AbortForbidden(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error":   "Forbidden",
+		"message": message,
+	})
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+)
+
+const (
+	ContextTenantIDKey = "tenant_id"
+
+	// TenantHeader names the tenant of a request by its slug. It takes
+	// precedence over the subdomain and the access token.
+	TenantHeader = "X-Tenant"
+)
+
+// TenantResolver works out which tenant a request is for in multi-tenant
+// mode: the X-Tenant header, then the subdomain of BaseDomain, then the
+// tenant claim of the Bearer token.
+type TenantResolver struct {
+	tenants    ports.TenantRepository
+	tokens     *TokenManager
+	baseDomain string
+}
+
+func NewTenantResolver(tenants ports.TenantRepository, tokens *TokenManager, baseDomain string) *TenantResolver {
+	return &TenantResolver{
+		tenants:    tenants,
+		tokens:     tokens,
+		baseDomain: strings.ToLower(strings.Trim(baseDomain, ".")),
+	}
+}
+
+// Resolve returns models.ErrTenantRequired when the request names no tenant
+// and models.ErrTenantNotFound when the tenant it names does not exist.
+func (tr *TenantResolver) Resolve(r *http.Request) (*models.Tenant, error) {
+	if slug := strings.ToLower(strings.TrimSpace(r.Header.Get(TenantHeader))); slug != "" {
+		return tr.tenants.GetBySlug(slug)
+	}
+	if slug := tr.subdomain(r.Host); slug != "" {
+		return tr.tenants.GetBySlug(slug)
+	}
+	if tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found && tokenString != "" {
+		tenantID, err := tr.tokens.TenantOfAccessToken(tokenString)
+		if err != nil {
+			logrus.WithError(err).Warn("Bearer token does not name a tenant")
+			return nil, models.ErrTenantRequired
+		}
+		return tr.tenants.GetByID(tenantID)
+	}
+	return nil, models.ErrTenantRequired
+}
+
+// subdomain returns the single label in front of the base domain, so
+// acme.inmo.example resolves to acme while inmo.example and a.b.inmo.example
+// resolve to nothing.
+func (tr *TenantResolver) subdomain(host string) string {
+	if tr.baseDomain == "" {
+		return ""
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	label, found := strings.CutSuffix(strings.ToLower(host), "."+tr.baseDomain)
+	if !found || label == "" || strings.Contains(label, ".") {
+		return ""
+	}
+	return label
+}
+
+// SetTenant stores the tenant a request was routed to; AuthMiddleware refuses
+// tokens issued in any other tenant.
+func SetTenant(tenantID uint) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextTenantIDKey, tenantID)
+		c.Next()
+	}
+}
+
+// GetTenantID returns the tenant stored by SetTenant.
+func GetTenantID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get(ContextTenantIDKey)
+	if !exists {
+		return 0, false
+	}
+	tenantID, ok := value.(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// RequirePlatformToken guards the tenant provisioning endpoints, which act on
+// the whole deployment, with the shared PLATFORM_ADMIN_TOKEN.
+func RequirePlatformToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !found || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			logrus.Warnf("Request without the platform token denied on %s %s", c.Request.Method, c.FullPath())
+			abortUnauthorized(c, "A valid platform admin token is required")
+			return
+		}
+		c.Next()
+	}
+}
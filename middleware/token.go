@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+
+	"inmo-backend/internal/domain/models"
+)
+
+const (
+	TokenTypeAccess = "access"
+	// TokenTypeTwoFactor proves the password step of a login; it is exchanged
+	// for a session once the second factor is verified
+	TokenTypeTwoFactor = "2fa"
+	// TokenTypeInvitation is mailed to invited staff; the subject is the email
+	TokenTypeInvitation = "invite"
+
+	twoFactorChallengeTTL = 5 * time.Minute
+)
+
+// Claims is the payload carried by every token signed by TokenManager.
+type Claims struct {
+	UserID    uint            `json:"uid"`
+	SessionID uint            `json:"sid"`
+	Role      models.UserRole `json:"role"`
+	BranchID  uint            `json:"bid,omitempty"`
+	TenantID  uint            `json:"tid"`
+	TokenType string          `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+type TokenManager struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewTokenManager(secret string, issuer string, accessTTL time.Duration, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// GenerateAccessToken signs a short-lived HS256 token bound to a user session.
+func (tm *TokenManager) GenerateAccessToken(user *models.UserResponse, sessionID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(tm.accessTTL)
+
+	claims := Claims{
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Role:      user.Role,
+		BranchID:  user.BranchID,
+		TenantID:  user.TenantID,
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tm.issuer,
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tm.secret)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to sign access token")
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseAccessToken validates signature, issuer and expiry and returns the claims.
+func (tm *TokenManager) ParseAccessToken(tokenString string) (*Claims, error) {
+	claims, err := tm.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeAccess || claims.UserID == 0 {
+		return nil, errors.New("invalid access token")
+	}
+	return claims, nil
+}
+
+// TenantOfAccessToken returns the tenant an access token was issued in. The
+// signature is checked but not the expiry, so an expired token still routes
+// the request to its tenant, where AuthMiddleware rejects it.
+func (tm *TokenManager) TenantOfAccessToken(tokenString string) (uint, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return tm.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithoutClaimsValidation(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if claims.TokenType != TokenTypeAccess || claims.Issuer != tm.issuer || claims.TenantID == 0 {
+		return 0, errors.New("access token carries no tenant")
+	}
+	return claims.TenantID, nil
+}
+
+// GenerateTwoFactorChallenge signs a short-lived token for a user who passed
+// the password step and still has to provide a second factor.
+func (tm *TokenManager) GenerateTwoFactorChallenge(userID uint) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(twoFactorChallengeTTL)
+
+	claims := Claims{
+		UserID:    userID,
+		TokenType: TokenTypeTwoFactor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tm.issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tm.secret)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to sign two-factor challenge")
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseTwoFactorChallenge returns the user a challenge was issued to.
+func (tm *TokenManager) ParseTwoFactorChallenge(tokenString string) (uint, error) {
+	claims, err := tm.parse(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	if claims.TokenType != TokenTypeTwoFactor || claims.UserID == 0 {
+		return 0, errors.New("invalid two-factor challenge")
+	}
+	return claims.UserID, nil
+}
+
+// GenerateInvitationToken signs the token of an invitation to email. Each
+// token carries a random ID so resending yields a different one.
+func (tm *TokenManager) GenerateInvitationToken(email string, expiresAt time.Time) (string, error) {
+	nonce, err := GenerateRandomToken(16)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate invitation token ID")
+		return "", err
+	}
+
+	claims := Claims{
+		TokenType: TokenTypeInvitation,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        nonce,
+			Issuer:    tm.issuer,
+			Subject:   email,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(tm.secret)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to sign invitation token")
+		return "", err
+	}
+	return signed, nil
+}
+
+// ParseInvitationToken returns the email an invitation token was issued to.
+func (tm *TokenManager) ParseInvitationToken(tokenString string) (string, error) {
+	claims, err := tm.parse(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	if claims.TokenType != TokenTypeInvitation || claims.Subject == "" {
+		return "", errors.New("invalid invitation token")
+	}
+	return claims.Subject, nil
+}
+
+// GenerateRefreshToken returns an opaque random refresh token together with
+// the hash that is stored server side and its expiry.
+func (tm *TokenManager) GenerateRefreshToken() (string, string, time.Time, error) {
+	raw, err := GenerateRandomToken(32)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate refresh token")
+		return "", "", time.Time{}, err
+	}
+	return raw, HashToken(raw), time.Now().Add(tm.refreshTTL), nil
+}
+
+func (tm *TokenManager) parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return tm.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tm.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateRandomToken returns n random bytes encoded as URL-safe base64.
+func GenerateRandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken hashes high-entropy tokens for storage. Unlike passwords they do
+// not need a slow hash, and a deterministic one lets us look them up.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,129 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
+)
+
+type mockAPIKeyUseCase struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyUseCase) CreateAPIKey(caller *models.Caller, keyData *models.CreateAPIKeyData) (*models.CreatedAPIKeyResponse, error) {
+	args := m.Called(caller, keyData)
+	created, _ := args.Get(0).(*models.CreatedAPIKeyResponse)
+	return created, args.Error(1)
+}
+func (m *mockAPIKeyUseCase) GetAPIKeys(caller *models.Caller) ([]models.APIKeyResponse, error) {
+	args := m.Called(caller)
+	keys, _ := args.Get(0).([]models.APIKeyResponse)
+	return keys, args.Error(1)
+}
+func (m *mockAPIKeyUseCase) RevokeAPIKey(caller *models.Caller, id uint) error {
+	args := m.Called(caller, id)
+	return args.Error(0)
+}
+
+func newAPIKeyContext(w *httptest.ResponseRecorder, body string) *gin.Context {
+	c := newPasswordContext(w, body)
+	c.Set(middleware.ContextUserIDKey, uint(1))
+	c.Set(middleware.ContextSessionIDKey, uint(2))
+	c.Set(middleware.ContextRoleKey, models.RoleAdmin)
+	return c
+}
+
+func TestCreateAPIKey_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockAPIKeyUseCase)
+	mockUC.On("CreateAPIKey", mock.Anything, &models.CreateAPIKeyData{Name: "Website", Scopes: []models.Permission{models.PermPropertiesRead}}).
+		Return(&models.CreatedAPIKeyResponse{APIKeyResponse: models.APIKeyResponse{ID: 7, Name: "Website"}, Key: "inmo_secret"}, nil)
+
+	h := handler.NewAPIKeyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateAPIKey(newAPIKeyContext(w, `{"name":"Website","scopes":["properties:read"]}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "inmo_secret")
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreateAPIKey_InvalidScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockAPIKeyUseCase)
+	mockUC.On("CreateAPIKey", mock.Anything, mock.Anything).Return(nil, errors.New(`invalid scope "everything"`))
+
+	h := handler.NewAPIKeyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateAPIKey(newAPIKeyContext(w, `{"name":"Website","scopes":["everything"]}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid scope")
+}
+
+func TestCreateAPIKey_ScopeAboveCreator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockAPIKeyUseCase)
+	mockUC.On("CreateAPIKey", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: cannot grant scope %q", models.ErrForbidden, models.PermPropertiesAssign))
+
+	h := handler.NewAPIKeyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateAPIKey(newAPIKeyContext(w, `{"name":"Website","scopes":["properties:assign"]}`))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("revokes the key", func(t *testing.T) {
+		mockUC := new(mockAPIKeyUseCase)
+		mockUC.On("RevokeAPIKey", mock.MatchedBy(func(caller *models.Caller) bool { return caller.UserID == 1 }), uint(7)).Return(nil)
+
+		h := handler.NewAPIKeyHandler(mockUC)
+		w := httptest.NewRecorder()
+		c := newAPIKeyContext(w, ``)
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+		h.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUC.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid ID", func(t *testing.T) {
+		mockUC := new(mockAPIKeyUseCase)
+
+		h := handler.NewAPIKeyHandler(mockUC)
+		w := httptest.NewRecorder()
+		c := newAPIKeyContext(w, ``)
+		c.Params = gin.Params{{Key: "id", Value: "abc"}}
+		h.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockUC.AssertNotCalled(t, "RevokeAPIKey", mock.Anything, mock.Anything)
+	})
+
+	t.Run("hides the keys of other users", func(t *testing.T) {
+		mockUC := new(mockAPIKeyUseCase)
+		mockUC.On("RevokeAPIKey", mock.Anything, uint(7)).Return(errors.New("api key not found or already revoked"))
+
+		h := handler.NewAPIKeyHandler(mockUC)
+		w := httptest.NewRecorder()
+		c := newAPIKeyContext(w, ``)
+		c.Params = gin.Params{{Key: "id", Value: "7"}}
+		h.RevokeAPIKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+)
+
+type mockBranchUseCase struct {
+	mock.Mock
+}
+
+func (m *mockBranchUseCase) GetBranches() ([]models.Branch, error) {
+	args := m.Called()
+	branches, _ := args.Get(0).([]models.Branch)
+	return branches, args.Error(1)
+}
+func (m *mockBranchUseCase) CreateBranch(branchData *models.BranchData) (*models.Branch, error) {
+	args := m.Called(branchData)
+	branch, _ := args.Get(0).(*models.Branch)
+	return branch, args.Error(1)
+}
+func (m *mockBranchUseCase) UpdateBranch(id uint, branchData *models.BranchData) (*models.Branch, error) {
+	args := m.Called(id, branchData)
+	branch, _ := args.Get(0).(*models.Branch)
+	return branch, args.Error(1)
+}
+func (m *mockBranchUseCase) AssignUser(caller *models.Caller, userID uint, version uint, branchID uint) (*models.UserResponse, error) {
+	args := m.Called(caller, userID, version, branchID)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+
+func TestCreateBranch_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+	mockUC.On("CreateBranch", &models.BranchData{Name: "Guadalajara", City: "Guadalajara"}).
+		Return(&models.Branch{ID: 3, Name: "Guadalajara", City: "Guadalajara"}, nil)
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateBranch(newAPIKeyContext(w, `{"name":"Guadalajara","city":"Guadalajara"}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Guadalajara"`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreateBranch_MissingName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+	mockUC.On("CreateBranch", mock.Anything).Return(nil, errors.New("name cannot be empty"))
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateBranch(newAPIKeyContext(w, `{"city":"Guadalajara"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "name cannot be empty")
+}
+
+// assignBranchContext is a PUT of the user's branch naming version in If-Match
+func assignBranchContext(w *httptest.ResponseRecorder, body string, version string) *gin.Context {
+	c := newAPIKeyContext(w, body)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	if version != "" {
+		c.Request.Header.Set("If-Match", version)
+	}
+	return c
+}
+
+func TestAssignUserBranch_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+	mockUC.On("AssignUser", mock.Anything, uint(5), uint(2), uint(3)).Return(&models.UserResponse{ID: 5, BranchID: 3, Version: 3}, nil)
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AssignUser(assignBranchContext(w, `{"branch_id":3}`, `"2"`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"branch_id":3`)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockUC.AssertExpectations(t)
+}
+
+func TestAssignUserBranch_UnknownBranch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+	mockUC.On("AssignUser", mock.Anything, uint(5), uint(2), uint(9)).Return(nil, errors.New("branch not found"))
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AssignUser(assignBranchContext(w, `{"branch_id":9}`, `"2"`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "branch not found")
+}
+
+func TestAssignUserBranch_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AssignUser(assignBranchContext(w, `{"branch_id":3}`, ``))
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockUC.AssertNotCalled(t, "AssignUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAssignUserBranch_StaleVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockBranchUseCase)
+	stale := fmt.Errorf("%w: user 5 is at version 4", models.ErrStaleVersion)
+	mockUC.On("AssignUser", mock.Anything, uint(5), uint(2), uint(3)).Return(nil, stale)
+
+	h := handler.NewBranchHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AssignUser(assignBranchContext(w, `{"branch_id":3}`, `"2"`))
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockUC.AssertExpectations(t)
+}
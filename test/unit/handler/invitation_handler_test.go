@@ -0,0 +1,165 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+)
+
+type mockInvitationUseCase struct {
+	mock.Mock
+}
+
+func (m *mockInvitationUseCase) CreateInvitation(caller *models.Caller, invitationData *models.CreateInvitationData) (*models.InvitationResponse, error) {
+	args := m.Called(caller, invitationData)
+	invitation, _ := args.Get(0).(*models.InvitationResponse)
+	return invitation, args.Error(1)
+}
+func (m *mockInvitationUseCase) GetInvitations(caller *models.Caller) ([]models.InvitationResponse, error) {
+	args := m.Called(caller)
+	invitations, _ := args.Get(0).([]models.InvitationResponse)
+	return invitations, args.Error(1)
+}
+func (m *mockInvitationUseCase) ResendInvitation(caller *models.Caller, id uint) (*models.InvitationResponse, error) {
+	args := m.Called(caller, id)
+	invitation, _ := args.Get(0).(*models.InvitationResponse)
+	return invitation, args.Error(1)
+}
+func (m *mockInvitationUseCase) RevokeInvitation(caller *models.Caller, id uint) error {
+	args := m.Called(caller, id)
+	return args.Error(0)
+}
+func (m *mockInvitationUseCase) AcceptInvitation(acceptData *models.AcceptInvitationData) (*models.UserResponse, error) {
+	args := m.Called(acceptData)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+
+func TestCreateInvitation_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("CreateInvitation", mock.Anything, &models.CreateInvitationData{Email: "ana@example.com", Role: models.RoleAgent}).
+		Return(&models.InvitationResponse{ID: 4, Email: "ana@example.com", Role: models.RoleAgent, Status: models.InvitationPending}, nil)
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateInvitation(newAPIKeyContext(w, `{"email":"ana@example.com","role":"agent"}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestCreateInvitation_Conflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("CreateInvitation", mock.Anything, mock.Anything).Return(nil, models.ErrEmailTaken)
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateInvitation(newAPIKeyContext(w, `{"email":"ana@example.com"}`))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "already exists")
+}
+
+func TestCreateInvitation_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("CreateInvitation", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("%w: authentication required", models.ErrForbidden))
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.CreateInvitation(newAPIKeyContext(w, `{"email":"ana@example.com"}`))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestResendInvitation_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewInvitationHandler(new(mockInvitationUseCase))
+
+	w := httptest.NewRecorder()
+	c := newAPIKeyContext(w, "")
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	h.ResendInvitation(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRevokeInvitation_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("RevokeInvitation", mock.Anything, uint(4)).Return(errors.New("invitation not found, already accepted or revoked"))
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	c := newAPIKeyContext(w, "")
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+	h.RevokeInvitation(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRevokeInvitation_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("RevokeInvitation", mock.Anything, uint(4)).Return(models.ErrForbidden)
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	c := newAPIKeyContext(w, "")
+	c.Params = gin.Params{{Key: "id", Value: "4"}}
+	h.RevokeInvitation(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAcceptInvitation_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("AcceptInvitation", &models.AcceptInvitationData{Token: "tok", Username: "ana", Password: "Str0ng!Passw0rd"}).
+		Return(&models.UserResponse{ID: 9, Username: "ana", Role: models.RoleAgent}, nil)
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AcceptInvitation(newPasswordContext(w, `{"token":"tok","username":"ana","password":"Str0ng!Passw0rd"}`))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"ana"`)
+}
+
+func TestAcceptInvitation_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("AcceptInvitation", mock.Anything).Return(nil, errors.New("invalid or expired invitation"))
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AcceptInvitation(newPasswordContext(w, `{"token":"tok","username":"ana","password":"x"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired invitation")
+}
+
+func TestAcceptInvitation_UsernameTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockInvitationUseCase)
+	mockUC.On("AcceptInvitation", mock.Anything).Return(nil, models.ErrUsernameTaken)
+
+	h := handler.NewInvitationHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.AcceptInvitation(newPasswordContext(w, `{"token":"tok","username":"ana","password":"x"}`))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "a user with this username already exists")
+}
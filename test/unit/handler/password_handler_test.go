@@ -0,0 +1,168 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
+)
+
+type mockPasswordUseCase struct {
+	mock.Mock
+}
+
+func (m *mockPasswordUseCase) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+func (m *mockPasswordUseCase) ResetPassword(token string, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+func (m *mockPasswordUseCase) ChangePassword(caller *models.Caller, userID uint, currentPassword string, newPassword string) error {
+	args := m.Called(caller, userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func newPasswordContext(w *httptest.ResponseRecorder, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c
+}
+
+func TestForgotPassword_Accepted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ForgotPassword", "ana@example.com").Return(nil)
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ForgotPassword(newPasswordContext(w, `{"email":"ana@example.com"}`))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestForgotPassword_MailerFailureIsNotDisclosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ForgotPassword", "ana@example.com").Return(fmt.Errorf("%w: smtp timeout", models.ErrMailNotSent))
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ForgotPassword(newPasswordContext(w, `{"email":"ana@example.com"}`))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"message":"If the email is registered, a reset link has been sent"}`, w.Body.String())
+}
+
+func TestForgotPassword_MissingEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ForgotPassword(newPasswordContext(w, `{}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "ForgotPassword", mock.Anything)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ResetPassword", "abc", "new-password").Return(nil)
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ResetPassword(newPasswordContext(w, `{"token":"abc","password":"new-password"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ResetPassword", "abc", "new-password").Return(errors.New("invalid or expired reset token"))
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ResetPassword(newPasswordContext(w, `{"token":"abc","password":"new-password"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired reset token")
+}
+
+func newChangePasswordContext(w *httptest.ResponseRecorder, id string, body string) *gin.Context {
+	c := newPasswordContext(w, body)
+	c.Params = gin.Params{{Key: "id", Value: id}}
+	c.Set(middleware.ContextUserIDKey, uint(4))
+	c.Set(middleware.ContextSessionIDKey, uint(12))
+	c.Set(middleware.ContextRoleKey, models.RoleAgent)
+	return c
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	caller := &models.Caller{UserID: 4, SessionID: 12, Role: models.RoleAgent}
+	mockUC.On("ChangePassword", caller, uint(4), "old-password", "new-password").Return(nil)
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePassword(newChangePasswordContext(w, "4", `{"current_password":"old-password","new_password":"new-password"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestChangePassword_MissingCurrentPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePassword(newChangePasswordContext(w, "4", `{"new_password":"new-password"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ChangePassword", mock.Anything, uint(9), "old-password", "new-password").
+		Return(fmt.Errorf("%w: users can only change their own password", models.ErrForbidden))
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePassword(newChangePasswordContext(w, "9", `{"current_password":"old-password","new_password":"new-password"}`))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPasswordUseCase)
+	mockUC.On("ChangePassword", mock.Anything, uint(4), "wrong-password", "new-password").
+		Return(errors.New("current password is incorrect"))
+
+	h := handler.NewPasswordHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePassword(newChangePasswordContext(w, "4", `{"current_password":"wrong-password","new_password":"new-password"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "current password is incorrect")
+}
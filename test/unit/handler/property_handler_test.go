@@ -2,6 +2,7 @@ package handler_test
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,7 @@ import (
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
 )
 
 // Mock for PropertyUseCase
@@ -21,27 +23,74 @@ type mockPropertyUseCase struct {
 	mock.Mock
 }
 
-func (m *mockPropertyUseCase) GetAllProperties() ([]models.PropertyResponse, error) {
-	args := m.Called()
-	return args.Get(0).([]models.PropertyResponse), args.Error(1)
+func (m *mockPropertyUseCase) GetAllProperties(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	args := m.Called(caller, filter, page)
+	properties, _ := args.Get(0).(*models.Page[models.PropertyResponse])
+	return properties, args.Error(1)
 }
-func (m *mockPropertyUseCase) GetPropertyByID(id uint) (*models.PropertyResponse, error) {
-	args := m.Called(id)
+func (m *mockPropertyUseCase) GetPropertyCards(caller *models.Caller, filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error) {
+	args := m.Called(caller, filter, page)
+	cards, _ := args.Get(0).(*models.Page[models.PropertyCard])
+	return cards, args.Error(1)
+}
+func (m *mockPropertyUseCase) GetPropertyByID(caller *models.Caller, id uint) (*models.PropertyResponse, error) {
+	args := m.Called(caller, id)
 	return args.Get(0).(*models.PropertyResponse), args.Error(1)
 }
-func (m *mockPropertyUseCase) CreateProperty(p *models.Property) (*models.PropertyResponse, error) {
-	args := m.Called(p)
+func (m *mockPropertyUseCase) GetPriceHistory(caller *models.Caller, id uint) ([]models.PropertyPriceChange, error) {
+	args := m.Called(caller, id)
+	changes, _ := args.Get(0).([]models.PropertyPriceChange)
+	return changes, args.Error(1)
+}
+func (m *mockPropertyUseCase) CreateProperty(caller *models.Caller, p *models.Property) (*models.PropertyResponse, error) {
+	args := m.Called(caller, p)
 	return args.Get(0).(*models.PropertyResponse), args.Error(1)
 }
-func (m *mockPropertyUseCase) UpdateProperty(p *models.Property) (*models.PropertyResponse, error) {
-	args := m.Called(p)
+func (m *mockPropertyUseCase) UpdateProperty(caller *models.Caller, p *models.Property) (*models.PropertyResponse, error) {
+	args := m.Called(caller, p)
 	return args.Get(0).(*models.PropertyResponse), args.Error(1)
 }
-func (m *mockPropertyUseCase) DeleteProperty(id uint) error {
-	args := m.Called(id)
+func (m *mockPropertyUseCase) PatchProperty(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.PropertyResponse, error) {
+	args := m.Called(caller, id, version, patch)
+	if property, ok := args.Get(0).(*models.PropertyResponse); ok {
+		return property, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockPropertyUseCase) ReassignProperty(caller *models.Caller, id uint, version uint, agentID uint) (*models.PropertyResponse, error) {
+	args := m.Called(caller, id, version, agentID)
+	if property, ok := args.Get(0).(*models.PropertyResponse); ok {
+		return property, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockPropertyUseCase) ChangePropertyStatus(caller *models.Caller, id uint, request *models.PropertyStatusRequest) (*models.PropertyResponse, error) {
+	args := m.Called(caller, id, request)
+	if property, ok := args.Get(0).(*models.PropertyResponse); ok {
+		return property, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *mockPropertyUseCase) DeleteProperty(caller *models.Caller, id uint, version uint) error {
+	args := m.Called(caller, id, version)
 	return args.Error(0)
 }
 
+// propertyPage wraps properties in the only page of a listing
+func propertyPage(properties ...models.PropertyResponse) *models.Page[models.PropertyResponse] {
+	return &models.Page[models.PropertyResponse]{
+		Items:      properties,
+		Pagination: models.Pagination{Total: len(properties), Limit: models.DefaultPageLimit},
+	}
+}
+
+// filterOfBranch matches a property filter scoped to the branch
+func filterOfBranch(branchID uint) any {
+	return mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+		return filter.BranchID == branchID
+	})
+}
+
 func TestGetProperties_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
@@ -49,11 +98,12 @@ func TestGetProperties_Success(t *testing.T) {
 		{ID: 1, Title: "Prop1"},
 		{ID: 2, Title: "Prop2"},
 	}
-	mockUC.On("GetAllProperties").Return(properties, nil)
+	mockUC.On("GetAllProperties", mock.Anything, filterOfBranch(0), mock.Anything).Return(propertyPage(properties...), nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties", nil)
 
 	h.GetProperties(c)
 
@@ -64,11 +114,12 @@ func TestGetProperties_Success(t *testing.T) {
 func TestGetProperties_Error(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("GetAllProperties").Return([]models.PropertyResponse{}, errors.New("db error"))
+	mockUC.On("GetAllProperties", mock.Anything, filterOfBranch(0), mock.Anything).Return(nil, errors.New("db error"))
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties", nil)
 
 	h.GetProperties(c)
 
@@ -79,23 +130,231 @@ func TestGetProperties_Error(t *testing.T) {
 func TestGetProperties_EmptyList(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("GetAllProperties").Return([]models.PropertyResponse{}, nil)
+	mockUC.On("GetAllProperties", mock.Anything, filterOfBranch(0), mock.Anything).Return(propertyPage(), nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties", nil)
 
 	h.GetProperties(c)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data":[],"message":"Properties retrieved successfully","pagination":{"total":0,"limit":20,"offset":0}}`, w.Body.String())
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetProperties_BranchFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, filterOfBranch(3), mock.Anything).Return(propertyPage(models.PropertyResponse{ID: 1, BranchID: 3, BranchName: "Guadalajara"}), nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?branch_id=3", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"branch_name":"Guadalajara"`)
 	mockUC.AssertExpectations(t)
 }
 
+func TestGetProperties_InvalidBranchFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?branch_id=main", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "GetAllProperties", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetProperties_OtherBranchForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, filterOfBranch(4), mock.Anything).
+		Return(nil, fmt.Errorf("%w: you can only see the data of your own branch", models.ErrForbidden))
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?branch_id=4", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetProperties_SearchFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+		return filter.City == "Monterrey" &&
+			filter.TransactionType == models.TransactionRental &&
+			filter.MinPrice != nil && *filter.MinPrice == 1000 &&
+			filter.MaxPrice != nil && *filter.MaxPrice == 2500.5 &&
+			filter.MinBedrooms != nil && *filter.MinBedrooms == 2 &&
+			filter.IsFurnished != nil && *filter.IsFurnished &&
+			filter.IsOccupied == nil &&
+			assert.ObjectsAreEqual([]string{"pool", "gym"}, filter.Amenities)
+	}), mock.Anything).Return(propertyPage(models.PropertyResponse{ID: 1}), nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?city=Monterrey&transaction_type=rental&min_price=1000&max_price=2500.5&min_bedrooms=2&furnished=true&amenities=pool&amenities=gym", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetProperties_TextSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+		return filter.Query == "casa cerca de la iglesia en Colonia Centro"
+	}), mock.Anything).Return(propertyPage(models.PropertyResponse{ID: 1, Relevance: 2.5}), nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?q=casa+cerca+de+la+iglesia+en+Colonia+Centro", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"relevance":2.5`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetProperties_MalformedFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, query := range []string{"min_price=cheap", "min_bedrooms=1.5", "furnished=maybe"} {
+		mockUC := new(mockPropertyUseCase)
+
+		h := handler.NewPropertyHandler(mockUC)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/properties?"+query, nil)
+
+		h.GetProperties(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		mockUC.AssertNotCalled(t, "GetAllProperties", mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestGetProperties_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: min_price cannot exceed max_price", models.ErrInvalidFilter))
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties?min_price=5&max_price=1", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "min_price cannot exceed max_price")
+}
+
+func TestGetProperties_PageLinks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	page := propertyPage(models.PropertyResponse{ID: 7})
+	page.Total, page.Limit, page.Offset, page.NextCursor = 30, 10, 10, "abc"
+	mockUC.On("GetAllProperties", mock.Anything, mock.Anything, &models.PageRequest{Limit: 10, Offset: 10, Sort: "price,-created_at"}).
+		Return(page, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/properties?city=Monterrey&limit=10&offset=10&sort=price,-created_at", nil)
+
+	h.GetProperties(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"pagination":{"total":30,"limit":10,"offset":10,"next_cursor":"abc"}`)
+	assert.Equal(t, `</api/v1/properties?city=Monterrey&limit=10&sort=price%2C-created_at>; rel="first", `+
+		`</api/v1/properties?city=Monterrey&limit=10&offset=0&sort=price%2C-created_at>; rel="prev", `+
+		`</api/v1/properties?city=Monterrey&cursor=abc&limit=10&sort=price%2C-created_at>; rel="next"`, w.Header().Get("Link"))
+}
+
+func TestGetProperties_InvalidPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetAllProperties", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: cannot sort by \"owner_id\"", models.ErrInvalidPage))
+
+	h := handler.NewPropertyHandler(mockUC)
+	for _, query := range []string{"limit=ten", "sort=owner_id"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/properties?"+query, nil)
+
+		h.GetProperties(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockUC.AssertNumberOfCalls(t, "GetAllProperties", 1)
+}
+
+func TestGetPropertyCards_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetPropertyCards", mock.Anything, mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+		return filter.BranchID == 2 && filter.City == "Monterrey"
+	}), &models.PageRequest{Limit: 12}).Return(&models.Page[models.PropertyCard]{
+		Items:      []models.PropertyCard{{ID: 1, Title: "House", Price: 1500000}},
+		Pagination: models.Pagination{Total: 1, Limit: 12},
+	}, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties/cards?branch_id=2&city=Monterrey&limit=12", nil)
+
+	h.GetPropertyCards(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"House"`)
+	assert.NotContains(t, w.Body.String(), "amenities")
+	mockUC.AssertNotCalled(t, "GetAllProperties", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetPropertyCards_InvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetPropertyCards", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("%w: unknown status \"gone\"", models.ErrInvalidFilter))
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/properties/cards?status=gone", nil)
+
+	h.GetPropertyCards(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestGetPropertyByID_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	expected := &models.PropertyResponse{ID: 1, Title: "Prop1"}
-	mockUC.On("GetPropertyByID", uint(1)).Return(expected, nil)
+	expected := &models.PropertyResponse{ID: 1, Title: "Prop1", Version: 3}
+	mockUC.On("GetPropertyByID", mock.Anything, uint(1)).Return(expected, nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -105,6 +364,7 @@ func TestGetPropertyByID_Success(t *testing.T) {
 	h.GetPropertyByID(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
 	mockUC.AssertExpectations(t)
 }
 
@@ -139,7 +399,7 @@ func TestGetPropertyByID_ErrorFromUsecase(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("GetPropertyByID", uint(2)).Return(property, errors.New("db error"))
+	mockUC.On("GetPropertyByID", mock.Anything, uint(2)).Return(property, errors.New("db error"))
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -156,7 +416,7 @@ func TestGetPropertyByID_NotFound(t *testing.T) {
 	var property *models.PropertyResponse = nil
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("GetPropertyByID", uint(3)).Return(property, nil)
+	mockUC.On("GetPropertyByID", mock.Anything, uint(3)).Return(property, nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -172,7 +432,7 @@ func TestCreateProperty_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
 	expected := &models.PropertyResponse{ID: 1, Title: "New Property"}
-	mockUC.On("CreateProperty", mock.AnythingOfType("*models.Property")).Return(expected, nil)
+	mockUC.On("CreateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return(expected, nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -209,7 +469,7 @@ func TestCreateProperty_UsecaseError(t *testing.T) {
 	var property *models.PropertyResponse = nil
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("CreateProperty", mock.AnythingOfType("*models.Property")).Return(property, errors.New("db error"))
+	mockUC.On("CreateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return(property, errors.New("db error"))
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -225,11 +485,33 @@ func TestCreateProperty_UsecaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockUC.AssertExpectations(t)
 }
+
+func TestCreateProperty_InvalidAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	invalid := fmt.Errorf("%w: user 9 does not belong to the property's branch", models.ErrInvalidAgent)
+	mockUC.On("CreateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return((*models.PropertyResponse)(nil), invalid)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/properties", strings.NewReader(`{"title":"New Property","user_id":9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.CreateProperty(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"Invalid agent"`)
+	mockUC.AssertExpectations(t)
+}
+
 func TestUpdateProperty_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	expected := &models.PropertyResponse{ID: 1, Title: "Updated Property"}
-	mockUC.On("UpdateProperty", mock.AnythingOfType("*models.Property")).Return(expected, nil)
+	expected := &models.PropertyResponse{ID: 1, Title: "Updated Property", Version: 4}
+	mockUC.On("UpdateProperty", mock.Anything, mock.MatchedBy(func(p *models.Property) bool {
+		return p.Version == 3
+	})).Return(expected, nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -237,12 +519,14 @@ func TestUpdateProperty_Success(t *testing.T) {
 
 	c.Request, _ = http.NewRequest("PUT", "/properties/1", nil)
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"3"`)
 	c.Set("Content-Type", "application/json")
 	c.Request.Body = io.NopCloser(strings.NewReader(`{"id":1,"title":"Updated Property"}`))
 
 	h.UpdateProperty(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
 	mockUC.AssertExpectations(t)
 }
 
@@ -267,7 +551,7 @@ func TestUpdateProperty_UsecaseError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
 	expected := &models.PropertyResponse{ID: 1, Title: "Updated Property"}
-	mockUC.On("UpdateProperty", mock.AnythingOfType("*models.Property")).Return(expected, errors.New("db error"))
+	mockUC.On("UpdateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return(expected, errors.New("db error"))
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
@@ -275,6 +559,7 @@ func TestUpdateProperty_UsecaseError(t *testing.T) {
 
 	c.Request, _ = http.NewRequest("PUT", "/properties/1", nil)
 	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"3"`)
 	c.Set("Content-Type", "application/json")
 	c.Request.Body = io.NopCloser(strings.NewReader(`{"id":1,"title":"Updated Property"}`))
 
@@ -286,12 +571,14 @@ func TestUpdateProperty_UsecaseError(t *testing.T) {
 func TestDeleteProperty_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("DeleteProperty", uint(1)).Return(nil)
+	mockUC.On("DeleteProperty", mock.Anything, uint(1), uint(2)).Return(nil)
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("DELETE", "/properties/1", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 
 	h.DeleteProperty(c)
 
@@ -306,6 +593,7 @@ func TestDeleteProperty_InvalidID(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	c.Request, _ = http.NewRequest("DELETE", "/properties/abc", nil)
 
 	h.DeleteProperty(c)
 
@@ -319,6 +607,7 @@ func TestDeleteProperty_NegativeID(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "id", Value: "-10"}}
+	c.Request, _ = http.NewRequest("DELETE", "/properties/-10", nil)
 
 	h.DeleteProperty(c)
 
@@ -328,12 +617,14 @@ func TestDeleteProperty_NegativeID(t *testing.T) {
 func TestDeleteProperty_UsecaseError(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUC := new(mockPropertyUseCase)
-	mockUC.On("DeleteProperty", uint(2)).Return(errors.New("db error"))
+	mockUC.On("DeleteProperty", mock.Anything, uint(2), uint(2)).Return(errors.New("db error"))
 
 	h := handler.NewPropertyHandler(mockUC)
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	c.Request, _ = http.NewRequest("DELETE", "/properties/2", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
 
 	h.DeleteProperty(c)
 
@@ -341,4 +632,448 @@ func TestDeleteProperty_UsecaseError(t *testing.T) {
 	mockUC.AssertExpectations(t)
 }
 
+func TestUpdateProperty_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	caller := &models.Caller{UserID: 8, Role: models.RoleAgent}
+	forbidden := fmt.Errorf("%w: only the assigned agent or an admin can modify this property", models.ErrForbidden)
+	mockUC.On("UpdateProperty", caller, mock.AnythingOfType("*models.Property")).Return((*models.PropertyResponse)(nil), forbidden)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.ContextUserIDKey, uint(8))
+	c.Set(middleware.ContextRoleKey, models.RoleAgent)
+
+	c.Request, _ = http.NewRequest("PUT", "/properties/1", strings.NewReader(`{"id":1,"title":"Taken over"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"1"`)
+
+	h.UpdateProperty(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"Forbidden"`)
+	mockUC.AssertExpectations(t)
+}
+
+func patchPropertyContext(w *httptest.ResponseRecorder, contentType string, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PATCH", "/properties/1", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", contentType)
+	c.Request.Header.Set("If-Match", `"4"`)
+	return c
+}
+
+func TestPatchProperty_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	expected := &models.PropertyResponse{ID: 1, Price: 95000, Version: 5}
+	mockUC.On("PatchProperty", mock.Anything, uint(1), uint(4), models.MergePatch{"price": []byte("95000")}).Return(expected, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.PatchProperty(patchPropertyContext(w, handler.MergePatchContentType, `{"price": 95000}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"price":95000`)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	mockUC.AssertExpectations(t)
+}
+
+func TestPatchProperty_UnsupportedMediaType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+
+	h.PatchProperty(patchPropertyContext(w, "application/json-patch+json", `[{"op":"remove","path":"/notes"}]`))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, handler.MergePatchContentType, w.Header().Get("Accept-Patch"))
+	mockUC.AssertNotCalled(t, "PatchProperty", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchProperty_InvalidPatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	invalid := fmt.Errorf("%w: address cannot be empty", models.ErrInvalidPatch)
+	mockUC.On("PatchProperty", mock.Anything, uint(1), uint(4), mock.Anything).Return(nil, invalid)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.PatchProperty(patchPropertyContext(w, "application/json", `{"address": null}`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "address cannot be empty")
+
+	w = httptest.NewRecorder()
+	h.PatchProperty(patchPropertyContext(w, handler.MergePatchContentType, `["price"]`))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNumberOfCalls(t, "PatchProperty", 1)
+}
+
+func TestPatchProperty_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	forbidden := fmt.Errorf("%w: only the assigned agent or an admin can modify this property", models.ErrForbidden)
+	mockUC.On("PatchProperty", mock.Anything, uint(1), uint(4), mock.Anything).Return(nil, forbidden)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.PatchProperty(patchPropertyContext(w, handler.MergePatchContentType, `{"price": 1}`))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestDeleteProperty_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	forbidden := fmt.Errorf("%w: only the assigned agent or an admin can modify this property", models.ErrForbidden)
+	mockUC.On("DeleteProperty", mock.Anything, uint(3), uint(2)).Return(forbidden)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	c.Request, _ = http.NewRequest("DELETE", "/properties/3", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	h.DeleteProperty(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestUpdateProperty_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request, _ = http.NewRequest("PUT", "/properties/1", strings.NewReader(`{"id":1,"title":"Blind write"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.UpdateProperty(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockUC.AssertNotCalled(t, "UpdateProperty", mock.Anything, mock.Anything)
+}
+
+func TestDeleteProperty_MalformedIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	h := handler.NewPropertyHandler(mockUC)
+
+	for _, header := range []string{`*`, `W/"2"`, `2`, `"0"`, `"two"`} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest("DELETE", "/properties/1", nil)
+		c.Request.Header.Set("If-Match", header)
+
+		h.DeleteProperty(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, header)
+	}
+	mockUC.AssertNotCalled(t, "DeleteProperty", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchProperty_StaleVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	stale := fmt.Errorf("%w: property 1 is at version 6", models.ErrStaleVersion)
+	mockUC.On("PatchProperty", mock.Anything, uint(1), uint(4), mock.Anything).Return(nil, stale)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.PatchProperty(patchPropertyContext(w, handler.MergePatchContentType, `{"price": 1}`))
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), "property 1 is at version 6")
+	mockUC.AssertExpectations(t)
+}
+
+func TestReassignProperty_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("ReassignProperty", mock.Anything, uint(1), uint(4), uint(9)).Return(&models.PropertyResponse{ID: 1, AgentID: 9, Version: 5}, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PUT", "/properties/1/agent", strings.NewReader(`{"user_id":9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"4"`)
+
+	h.ReassignProperty(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"agent_id":9`)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	mockUC.AssertExpectations(t)
+}
+
+func TestReassignProperty_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PUT", "/properties/1/agent", strings.NewReader(`{"user_id":9}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ReassignProperty(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockUC.AssertNotCalled(t, "ReassignProperty", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReassignProperty_MissingAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PUT", "/properties/1/agent", strings.NewReader(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	h.ReassignProperty(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReassignProperty_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"missing property", fmt.Errorf("%w: sql: no rows in result set", models.ErrPropertyNotFound), http.StatusNotFound},
+		{"invalid agent", fmt.Errorf("%w: user 9 is not an agent", models.ErrInvalidAgent), http.StatusBadRequest},
+		{"forbidden", fmt.Errorf("%w: only the assigned agent or an admin can modify this property", models.ErrForbidden), http.StatusForbidden},
+		{"stale version", fmt.Errorf("%w: property 1 is at version 6", models.ErrStaleVersion), http.StatusPreconditionFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockUC := new(mockPropertyUseCase)
+			mockUC.On("ReassignProperty", mock.Anything, uint(1), uint(4), uint(9)).Return(nil, tc.err)
+
+			h := handler.NewPropertyHandler(mockUC)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+			c.Request, _ = http.NewRequest("PUT", "/properties/1/agent", strings.NewReader(`{"user_id":9}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Header.Set("If-Match", `"4"`)
+
+			h.ReassignProperty(c)
+
+			assert.Equal(t, tc.status, w.Code)
+			mockUC.AssertExpectations(t)
+		})
+	}
+}
+
+func statusContext(w *httptest.ResponseRecorder, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("POST", "/properties/1/status", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c
+}
+
+func TestChangePropertyStatus_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	request := &models.PropertyStatusRequest{Status: models.StatusReserved, Reason: "deposit received"}
+	expected := &models.PropertyResponse{ID: 1, Status: models.StatusReserved, Version: 6}
+	mockUC.On("ChangePropertyStatus", mock.Anything, uint(1), request).Return(expected, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePropertyStatus(statusContext(w, `{"status":"reserved","reason":"deposit received"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"reserved"`)
+	assert.Equal(t, `"6"`, w.Header().Get("ETag"))
+	mockUC.AssertExpectations(t)
+}
+
+func TestChangePropertyStatus_MissingReason(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	h := handler.NewPropertyHandler(mockUC)
+
+	for _, body := range []string{`{"status":"sold"}`, `{"status":"sold","reason":"  "}`, `{"reason":"sold"}`, `sold`} {
+		w := httptest.NewRecorder()
+		h.ChangePropertyStatus(statusContext(w, body))
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	mockUC.AssertNotCalled(t, "ChangePropertyStatus", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePropertyStatus_InvalidTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	invalid := fmt.Errorf("%w: a property for sale cannot be rented", models.ErrInvalidStatusTransition)
+	mockUC.On("ChangePropertyStatus", mock.Anything, uint(1), mock.Anything).Return(nil, invalid)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.ChangePropertyStatus(statusContext(w, `{"status":"rented","reason":"signed lease"}`))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "a property for sale cannot be rented")
+	mockUC.AssertExpectations(t)
+}
+
+func TestUpdateProperty_StatusChangeConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	invalid := fmt.Errorf("%w: the status of property 1 can only change through its status endpoint", models.ErrInvalidStatusTransition)
+	mockUC.On("UpdateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return((*models.PropertyResponse)(nil), invalid)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/properties/1", strings.NewReader(`{"id":1,"status":"sold"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"1"`)
 
+	h.UpdateProperty(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUC.AssertExpectations(t)
+}
+
+func TestUpdateProperty_InvalidAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	invalid := fmt.Errorf("%w: user 5 does not belong to the property's branch", models.ErrInvalidAgent)
+	mockUC.On("UpdateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return((*models.PropertyResponse)(nil), invalid)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/properties/1", strings.NewReader(`{"id":1,"branch_id":2}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"1"`)
+
+	h.UpdateProperty(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"Invalid agent"`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPriceHistory_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	history := []models.PropertyPriceChange{{ID: 1, PropertyID: 1, OldPrice: 120000, NewPrice: 100000, Reason: "slow season", ChangedBy: 5}}
+	mockUC.On("GetPriceHistory", mock.Anything, uint(1)).Return(history, nil)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.GetPriceHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"old_price":120000`)
+	assert.Contains(t, w.Body.String(), `"reason":"slow season"`)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPriceHistory_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	mockUC.On("GetPriceHistory", mock.Anything, uint(1)).Return(nil, models.ErrPropertyNotFound)
+
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	h.GetPriceHistory(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Property not found")
+	mockUC.AssertExpectations(t)
+}
+
+func TestGetPriceHistory_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockPropertyUseCase)
+	h := handler.NewPropertyHandler(mockUC)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	h.GetPriceHistory(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUC.AssertNotCalled(t, "GetPriceHistory", mock.Anything, mock.Anything)
+}
+
+func TestPropertyHandlers_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errs := map[string]error{
+		"missing property":           fmt.Errorf("%w: sql: no rows in result set", models.ErrPropertyNotFound),
+		"property of another branch": models.ErrPropertyNotFound,
+	}
+	requests := []struct {
+		name   string
+		method string
+		body   string
+		expect func(m *mockPropertyUseCase, err error)
+		call   func(h *handler.PropertyHandler, c *gin.Context)
+	}{
+		{"GetPropertyByID", "GET", ``, func(m *mockPropertyUseCase, err error) {
+			m.On("GetPropertyByID", mock.Anything, uint(1)).Return((*models.PropertyResponse)(nil), err)
+		}, (*handler.PropertyHandler).GetPropertyByID},
+		{"UpdateProperty", "PUT", `{"id":1,"title":"Taken over"}`, func(m *mockPropertyUseCase, err error) {
+			m.On("UpdateProperty", mock.Anything, mock.AnythingOfType("*models.Property")).Return((*models.PropertyResponse)(nil), err)
+		}, (*handler.PropertyHandler).UpdateProperty},
+		{"PatchProperty", "PATCH", `{"price": 1}`, func(m *mockPropertyUseCase, err error) {
+			m.On("PatchProperty", mock.Anything, uint(1), uint(4), mock.Anything).Return(nil, err)
+		}, (*handler.PropertyHandler).PatchProperty},
+		{"ChangePropertyStatus", "POST", `{"status":"sold","reason":"signed"}`, func(m *mockPropertyUseCase, err error) {
+			m.On("ChangePropertyStatus", mock.Anything, uint(1), mock.Anything).Return(nil, err)
+		}, (*handler.PropertyHandler).ChangePropertyStatus},
+		{"DeleteProperty", "DELETE", ``, func(m *mockPropertyUseCase, err error) {
+			m.On("DeleteProperty", mock.Anything, uint(1), uint(4)).Return(err)
+		}, (*handler.PropertyHandler).DeleteProperty},
+	}
+	for _, req := range requests {
+		for name, err := range errs {
+			t.Run(req.name+" of a "+name, func(t *testing.T) {
+				mockUC := new(mockPropertyUseCase)
+				req.expect(mockUC, err)
+
+				h := handler.NewPropertyHandler(mockUC)
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Params = gin.Params{{Key: "id", Value: "1"}}
+				c.Request, _ = http.NewRequest(req.method, "/properties/1", strings.NewReader(req.body))
+				c.Request.Header.Set("Content-Type", "application/json")
+				c.Request.Header.Set("If-Match", `"4"`)
+
+				req.call(h, c)
+
+				assert.Equal(t, http.StatusNotFound, w.Code)
+				assert.JSONEq(t, `{"error":"Property not found","message":"No property found with the given ID"}`, w.Body.String())
+				mockUC.AssertExpectations(t)
+			})
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package handler_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+)
+
+type mockSSOUseCase struct {
+	mock.Mock
+}
+
+func (m *mockSSOUseCase) StartLogin() (*models.SSOAuthorization, error) {
+	args := m.Called()
+	authorization, _ := args.Get(0).(*models.SSOAuthorization)
+	return authorization, args.Error(1)
+}
+func (m *mockSSOUseCase) CompleteLogin(state string, code string, client models.ClientInfo) (*models.LoginResponse, error) {
+	args := m.Called(state, code, client)
+	loginResponse, _ := args.Get(0).(*models.LoginResponse)
+	return loginResponse, args.Error(1)
+}
+
+func newSSORouter(mockUC *mockSSOUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewSSOHandler(mockUC)
+	r := gin.New()
+	r.GET("/users/login/sso", h.StartLogin)
+	r.GET("/users/login/sso/callback", h.Callback)
+	return r
+}
+
+func TestSSOStartLogin_Success(t *testing.T) {
+	mockUC := new(mockSSOUseCase)
+	mockUC.On("StartLogin").Return(&models.SSOAuthorization{
+		AuthorizationURL: "https://idp.example.com/authorize?state=abc",
+		ExpiresAt:        time.Now().Add(10 * time.Minute),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/login/sso", nil)
+	newSSORouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"authorization_url":"https://idp.example.com/authorize?state=abc"`)
+}
+
+func TestSSOStartLogin_NotConfigured(t *testing.T) {
+	mockUC := new(mockSSOUseCase)
+	mockUC.On("StartLogin").Return(nil, models.ErrSSONotConfigured)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/login/sso", nil)
+	newSSORouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSSOCallback_Success(t *testing.T) {
+	mockUC := new(mockSSOUseCase)
+	mockUC.On("CompleteLogin", "abc", "xyz", mock.Anything).Return(&models.LoginResponse{
+		AccessToken: "access-token",
+		TokenType:   "Bearer",
+		User:        &models.UserResponse{ID: 4},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/login/sso/callback?state=abc&code=xyz", nil)
+	newSSORouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"access_token":"access-token"`)
+	assert.Contains(t, w.Body.String(), "Login successful")
+}
+
+func TestSSOCallback_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+	}{
+		{"provider refused the sign-in", "?error=access_denied&state=abc", nil, http.StatusUnauthorized},
+		{"missing code", "?state=abc", nil, http.StatusBadRequest},
+		{"deactivated account", "?state=abc&code=xyz", models.ErrAccountDeactivated, http.StatusForbidden},
+		{"no matching account", "?state=abc&code=xyz", errors.New("no account matches this identity"), http.StatusUnauthorized},
+		{"not configured", "?state=abc&code=xyz", models.ErrSSONotConfigured, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUC := new(mockSSOUseCase)
+			mockUC.On("CompleteLogin", "abc", "xyz", mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/users/login/sso/callback"+tt.query, nil)
+			newSSORouter(mockUC).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.err == nil {
+				mockUC.AssertNotCalled(t, "CompleteLogin", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
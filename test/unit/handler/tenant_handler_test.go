@@ -0,0 +1,108 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+)
+
+type mockTenantUseCase struct {
+	mock.Mock
+}
+
+func (m *mockTenantUseCase) GetTenants() ([]models.Tenant, error) {
+	args := m.Called()
+	tenants, _ := args.Get(0).([]models.Tenant)
+	return tenants, args.Error(1)
+}
+func (m *mockTenantUseCase) GetTenant(id uint) (*models.Tenant, error) {
+	args := m.Called(id)
+	tenant, _ := args.Get(0).(*models.Tenant)
+	return tenant, args.Error(1)
+}
+func (m *mockTenantUseCase) CreateTenant(tenantData *models.TenantData) (*models.Tenant, error) {
+	args := m.Called(tenantData)
+	tenant, _ := args.Get(0).(*models.Tenant)
+	return tenant, args.Error(1)
+}
+func (m *mockTenantUseCase) UpdateTenant(id uint, tenantData *models.TenantData) (*models.Tenant, error) {
+	args := m.Called(id, tenantData)
+	tenant, _ := args.Get(0).(*models.Tenant)
+	return tenant, args.Error(1)
+}
+func (m *mockTenantUseCase) InviteAdmin(id uint, adminData *models.TenantAdminData) (*models.InvitationResponse, error) {
+	args := m.Called(id, adminData)
+	invitation, _ := args.Get(0).(*models.InvitationResponse)
+	return invitation, args.Error(1)
+}
+
+func newTenantRouter(mockUC *mockTenantUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewTenantHandler(mockUC)
+	r := gin.New()
+	r.GET("/tenants/:id", h.GetTenant)
+	r.POST("/tenants", h.CreateTenant)
+	r.POST("/tenants/:id/admins", h.InviteAdmin)
+	return r
+}
+
+func TestCreateTenant_Success(t *testing.T) {
+	mockUC := new(mockTenantUseCase)
+	mockUC.On("CreateTenant", &models.TenantData{Slug: "acme", Name: "Acme", AdminEmail: "owner@acme.com"}).
+		Return(&models.Tenant{ID: 4, Slug: "acme", Name: "Acme"}, nil)
+
+	w := httptest.NewRecorder()
+	body := `{"slug":"acme","name":"Acme","admin_email":"owner@acme.com"}`
+	req, _ := http.NewRequest("POST", "/tenants", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	newTenantRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"slug":"acme"`)
+}
+
+func TestCreateTenant_Invalid(t *testing.T) {
+	mockUC := new(mockTenantUseCase)
+	mockUC.On("CreateTenant", mock.Anything).Return(nil, errors.New("a tenant with this slug already exists"))
+
+	w := httptest.NewRecorder()
+	body := `{"slug":"acme","name":"Acme","admin_email":"owner@acme.com"}`
+	req, _ := http.NewRequest("POST", "/tenants", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	newTenantRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTenant_NotFound(t *testing.T) {
+	mockUC := new(mockTenantUseCase)
+	mockUC.On("GetTenant", uint(9)).Return(nil, models.ErrTenantNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tenants/9", nil)
+	newTenantRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInviteTenantAdmin_Success(t *testing.T) {
+	mockUC := new(mockTenantUseCase)
+	mockUC.On("InviteAdmin", uint(4), &models.TenantAdminData{Email: "second@acme.com"}).
+		Return(&models.InvitationResponse{ID: 2, Email: "second@acme.com"}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tenants/4/admins", bytes.NewBufferString(`{"email":"second@acme.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	newTenantRouter(mockUC).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,98 @@
+package handler_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
+)
+
+type mockTwoFactorUseCase struct {
+	mock.Mock
+}
+
+func (m *mockTwoFactorUseCase) Enroll(caller *models.Caller) (*models.TwoFactorEnrollment, error) {
+	args := m.Called(caller)
+	enrollment, _ := args.Get(0).(*models.TwoFactorEnrollment)
+	return enrollment, args.Error(1)
+}
+func (m *mockTwoFactorUseCase) Confirm(caller *models.Caller, code string) (*models.RecoveryCodesResponse, error) {
+	args := m.Called(caller, code)
+	codes, _ := args.Get(0).(*models.RecoveryCodesResponse)
+	return codes, args.Error(1)
+}
+func (m *mockTwoFactorUseCase) Disable(caller *models.Caller, code string) error {
+	args := m.Called(caller, code)
+	return args.Error(0)
+}
+
+func newTwoFactorContext(w *httptest.ResponseRecorder, body string, role models.UserRole) *gin.Context {
+	c := newPasswordContext(w, body)
+	c.Set(middleware.ContextUserIDKey, uint(4))
+	c.Set(middleware.ContextSessionIDKey, uint(12))
+	c.Set(middleware.ContextRoleKey, role)
+	return c
+}
+
+func TestTwoFactorEnroll_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockTwoFactorUseCase)
+	caller := &models.Caller{UserID: 4, SessionID: 12, Role: models.RoleAgent}
+	mockUC.On("Enroll", caller).Return(&models.TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP", OTPAuthURI: "otpauth://totp/Inmo:ana"}, nil)
+
+	h := handler.NewTwoFactorHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.Enroll(newTwoFactorContext(w, ``, models.RoleAgent))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "otpauth://totp/Inmo:ana")
+	mockUC.AssertExpectations(t)
+}
+
+func TestTwoFactorConfirm_ReturnsRecoveryCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockTwoFactorUseCase)
+	mockUC.On("Confirm", mock.Anything, "123456").Return(&models.RecoveryCodesResponse{RecoveryCodes: []string{"abcd-efgh-ijkl-mnop"}}, nil)
+
+	h := handler.NewTwoFactorHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.Confirm(newTwoFactorContext(w, `{"code":"123456"}`, models.RoleAgent))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "abcd-efgh-ijkl-mnop")
+}
+
+func TestTwoFactorConfirm_InvalidCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockTwoFactorUseCase)
+	mockUC.On("Confirm", mock.Anything, "000000").Return(nil, errors.New("invalid two-factor code"))
+
+	h := handler.NewTwoFactorHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.Confirm(newTwoFactorContext(w, `{"code":"000000"}`, models.RoleAgent))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid two-factor code")
+}
+
+func TestTwoFactorDisable_ForbiddenForAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUC := new(mockTwoFactorUseCase)
+	mockUC.On("Disable", mock.Anything, "123456").
+		Return(fmt.Errorf("%w: two-factor authentication is mandatory for this role", models.ErrForbidden))
+
+	h := handler.NewTwoFactorHandler(mockUC)
+	w := httptest.NewRecorder()
+	h.Disable(newTwoFactorContext(w, `{"code":"123456"}`, models.RoleAdmin))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -3,9 +3,11 @@ package handler_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -13,6 +15,7 @@ import (
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/interface/api/handler"
+	"inmo-backend/middleware"
 )
 
 // MockUserUseCase is a mock implementation of UserUseCase
@@ -20,41 +23,128 @@ type MockUserUseCase struct {
 	mock.Mock
 }
 
-func (m *MockUserUseCase) Login(email, password string) error {
-	args := m.Called(email, password)
+func (m *MockUserUseCase) Login(email, password string, client models.ClientInfo) (*models.LoginResponse, error) {
+	args := m.Called(email, password, client)
+	if loginResp, ok := args.Get(0).(*models.LoginResponse); ok {
+		return loginResp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) RefreshSession(refreshToken string, client models.ClientInfo) (*models.LoginResponse, error) {
+	args := m.Called(refreshToken, client)
+	if loginResp, ok := args.Get(0).(*models.LoginResponse); ok {
+		return loginResp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) CompleteTwoFactorLogin(challengeToken, code, recoveryCode string, client models.ClientInfo) (*models.LoginResponse, error) {
+	args := m.Called(challengeToken, code, recoveryCode, client)
+	if loginResp, ok := args.Get(0).(*models.LoginResponse); ok {
+		return loginResp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) Logout(sessionID uint) error {
+	args := m.Called(sessionID)
+	return args.Error(0)
+}
+func (m *MockUserUseCase) GetSessions(userID uint, currentSessionID uint) ([]models.SessionResponse, error) {
+	args := m.Called(userID, currentSessionID)
+	if sessions, ok := args.Get(0).([]models.SessionResponse); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) RevokeSession(userID uint, sessionID uint) error {
+	args := m.Called(userID, sessionID)
 	return args.Error(0)
 }
-func (m *MockUserUseCase) GetAllUsers() ([]models.UserResponse, error) {
-	args := m.Called()
+func (m *MockUserUseCase) GetAllUsers(caller *models.Caller, branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error) {
+	args := m.Called(caller, branchID, page)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]models.UserResponse), args.Error(1)
+	return args.Get(0).(*models.Page[models.UserResponse]), args.Error(1)
 }
-func (m *MockUserUseCase) GetUserByID(id uint) (*models.UserResponse, error) {
-	args := m.Called(id)
+func (m *MockUserUseCase) GetUserByID(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	args := m.Called(caller, id)
 	if user, ok := args.Get(0).(*models.UserResponse); ok {
 		return user, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
-func (m *MockUserUseCase) CreateUser(user *models.User) (*models.UserResponse, error) {
-	args := m.Called(user)
+func (m *MockUserUseCase) UpdateUser(caller *models.Caller, user *models.User) (*models.UserResponse, error) {
+	args := m.Called(caller, user)
+	if userResp, ok := args.Get(0).(*models.UserResponse); ok {
+		return userResp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) PatchUser(caller *models.Caller, id uint, version uint, patch models.MergePatch) (*models.UserResponse, error) {
+	args := m.Called(caller, id, version, patch)
+	if userResp, ok := args.Get(0).(*models.UserResponse); ok {
+		return userResp, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockUserUseCase) UpdateUserRole(caller *models.Caller, id uint, version uint, role models.UserRole) (*models.UserResponse, error) {
+	args := m.Called(caller, id, version, role)
 	if userResp, ok := args.Get(0).(*models.UserResponse); ok {
 		return userResp, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
-func (m *MockUserUseCase) UpdateUser(user *models.User) (*models.UserResponse, error) {
-	args := m.Called(user)
+func (m *MockUserUseCase) UpdateProfile(userID uint, version uint, profile *models.UserProfile) (*models.UserResponse, error) {
+	args := m.Called(userID, version, profile)
 	if userResp, ok := args.Get(0).(*models.UserResponse); ok {
 		return userResp, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
-func (m *MockUserUseCase) DeleteUser(id uint) error {
-	args := m.Called(id)
+func (m *MockUserUseCase) UnlockUser(caller *models.Caller, id uint) error {
+	args := m.Called(caller, id)
+	return args.Error(0)
+}
+func (m *MockUserUseCase) VerifyEmail(token string) (*models.UserResponse, error) {
+	args := m.Called(token)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+func (m *MockUserUseCase) ResendEmailVerification(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+func (m *MockUserUseCase) MarkEmailVerified(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	args := m.Called(caller, id)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+func (m *MockUserUseCase) EnsureAdmin(admin *models.User) error {
+	args := m.Called(admin)
+	return args.Error(0)
+}
+func (m *MockUserUseCase) DeleteUser(caller *models.Caller, id uint, version uint) error {
+	args := m.Called(caller, id, version)
+	return args.Error(0)
+}
+func (m *MockUserUseCase) DeactivateUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	args := m.Called(caller, id)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+func (m *MockUserUseCase) GetDeletedUsers(caller *models.Caller, branchID uint) ([]models.UserResponse, error) {
+	args := m.Called(caller, branchID)
+	users, _ := args.Get(0).([]models.UserResponse)
+	return users, args.Error(1)
+}
+func (m *MockUserUseCase) RestoreUser(caller *models.Caller, id uint) (*models.UserResponse, error) {
+	args := m.Called(caller, id)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+func (m *MockUserUseCase) PurgeUser(caller *models.Caller, id uint) error {
+	args := m.Called(caller, id)
 	return args.Error(0)
 }
 
@@ -64,7 +154,12 @@ func TestUserLogin_Success(t *testing.T) {
 	handler := handler.NewUserHandler(mockUsecase)
 
 	loginData := `{"email":"test@example.com","password":"password123"}`
-	mockUsecase.On("Login", "test@example.com", "password123").Return(nil)
+	loginResp := &models.LoginResponse{
+		AccessToken: "signed.jwt.token",
+		TokenType:   "Bearer",
+		User:        &models.UserResponse{ID: 1, Email: "test@example.com"},
+	}
+	mockUsecase.On("Login", "test@example.com", "password123", mock.AnythingOfType("models.ClientInfo")).Return(loginResp, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -75,6 +170,7 @@ func TestUserLogin_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "Login successful")
+	assert.Contains(t, w.Body.String(), "signed.jwt.token")
 	mockUsecase.AssertExpectations(t)
 }
 
@@ -102,7 +198,7 @@ func TestUserLogin_LoginFailed(t *testing.T) {
 	handler := handler.NewUserHandler(mockUsecase)
 
 	loginData := `{"email":"test@example.com","password":"wrongpass"}`
-	mockUsecase.On("Login", "test@example.com", "wrongpass").Return(errors.New("invalid credentials"))
+	mockUsecase.On("Login", "test@example.com", "wrongpass", mock.AnythingOfType("models.ClientInfo")).Return(nil, errors.New("invalid credentials"))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -115,291 +211,367 @@ func TestUserLogin_LoginFailed(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "Invalid email or password")
 	mockUsecase.AssertExpectations(t)
 }
-func TestGetUsers_Success(t *testing.T) {
+
+func TestUserLogin_Throttled(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	users := []models.UserResponse{
-		{ID: 1, Email: "user1@example.com"},
-		{ID: 2, Email: "user2@example.com"},
-	}
-	mockUsecase.On("GetAllUsers").Return(users, nil)
+	loginData := `{"email":"test@example.com","password":"wrongpass"}`
+	mockUsecase.On("Login", "test@example.com", "wrongpass", mock.AnythingOfType("models.ClientInfo")).
+		Return(nil, &models.LoginLockedError{RetryAfter: 90*time.Second + 200*time.Millisecond})
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+	c.Request, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUsers(c)
+	handler.UserLogin(c)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "Users retrieved successfully")
-	assert.Contains(t, w.Body.String(), "user1@example.com")
-	assert.Contains(t, w.Body.String(), "user2@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "91", w.Header().Get("Retry-After"))
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestGetUsers_Failure(t *testing.T) {
+func TestUserLogin_EmailNotVerified(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	mockUsecase.On("GetAllUsers").Return(nil, errors.New("database error"))
+	loginData := `{"email":"test@example.com","password":"password123"}`
+	mockUsecase.On("Login", "test@example.com", "password123", mock.AnythingOfType("models.ClientInfo")).Return(nil, models.ErrEmailNotVerified)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+	c.Request, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUsers(c)
+	handler.UserLogin(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to retrieve users")
-	assert.Contains(t, w.Body.String(), "database error")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Email not verified")
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestGetUsers_NoUsers(t *testing.T) {
+func TestUserLogin_TwoFactorChallenge(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	mockUsecase.On("GetAllUsers").Return([]models.UserResponse{}, nil)
+	loginData := `{"email":"test@example.com","password":"password123"}`
+	mockUsecase.On("Login", "test@example.com", "password123", mock.AnythingOfType("models.ClientInfo")).
+		Return(&models.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge.jwt"}, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+	c.Request, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUsers(c)
+	handler.UserLogin(c)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Contains(t, w.Body.String(), "No users found")
-	assert.Contains(t, w.Body.String(), "No users available in the database")
-	mockUsecase.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Two-factor authentication required")
+	assert.Contains(t, w.Body.String(), "challenge.jwt")
+	assert.NotContains(t, w.Body.String(), "access_token")
 }
 
-func TestGetUserByID_Success(t *testing.T) {
+func TestVerifyTwoFactorLogin_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	userResp := &models.UserResponse{ID: 1, Email: "user1@example.com"}
-	mockUsecase.On("GetUserByID", uint(1)).Return(userResp, nil)
+	body := `{"challenge_token":"challenge.jwt","code":"123456"}`
+	mockUsecase.On("CompleteTwoFactorLogin", "challenge.jwt", "123456", "", mock.AnythingOfType("models.ClientInfo")).
+		Return(&models.LoginResponse{AccessToken: "signed.jwt.token", TokenType: "Bearer"}, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users/1", nil)
+	c.Request, _ = http.NewRequest("POST", "/login/2fa", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUserByID(c)
+	handler.VerifyTwoFactorLogin(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "User retrieved successfully")
-	assert.Contains(t, w.Body.String(), "user1@example.com")
+	assert.Contains(t, w.Body.String(), "signed.jwt.token")
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestGetUserByID_InvalidID(t *testing.T) {
+func TestVerifyTwoFactorLogin_MissingCode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = gin.Params{gin.Param{Key: "id", Value: "abc"}}
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users/abc", nil)
+	c.Request, _ = http.NewRequest("POST", "/login/2fa", bytes.NewBufferString(`{"challenge_token":"challenge.jwt"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUserByID(c)
+	handler.VerifyTwoFactorLogin(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "Invalid user ID")
-	assert.Contains(t, w.Body.String(), "User ID must be a valid number")
+	mockUsecase.AssertNotCalled(t, "CompleteTwoFactorLogin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
-func TestGetUserByID_NotFound(t *testing.T) {
+func TestVerifyTwoFactorLogin_InvalidCode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	mockUsecase.On("GetUserByID", uint(99)).Return((*models.UserResponse)(nil), errors.New("user not found"))
+	body := `{"challenge_token":"challenge.jwt","recovery_code":"nope"}`
+	mockUsecase.On("CompleteTwoFactorLogin", "challenge.jwt", "", "nope", mock.AnythingOfType("models.ClientInfo")).
+		Return(nil, errors.New("invalid two-factor code"))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = gin.Params{gin.Param{Key: "id", Value: "99"}}
-	c.Request, _ = http.NewRequest("GET", "/api/v1/users/99", nil)
+	c.Request, _ = http.NewRequest("POST", "/login/2fa", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.GetUserByID(c)
+	handler.VerifyTwoFactorLogin(c)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
-	assert.Contains(t, w.Body.String(), "User not found")
-	assert.Contains(t, w.Body.String(), "user not found")
-	mockUsecase.AssertExpectations(t)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
-func TestCreateUser_Success(t *testing.T) {
+
+func TestRefreshToken_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	user := models.User{
-		Email:    "newuser@example.com",
-		Password: "securepassword",
-	}
-	userJSON := `{"email":"newuser@example.com","password":"securepassword"}`
-
-	userResponse := &models.UserResponse{ID: 1, Email: "newuser@example.com", Username: "newuser"}
-
-	mockUsecase.On("CreateUser", mock.AnythingOfType("*models.User")).Return(userResponse, nil).Run(func(args mock.Arguments) {
-		argUser := args.Get(0).(*models.User)
-		assert.Equal(t, user.Email, argUser.Email)
-		assert.Equal(t, user.Password, argUser.Password)
-	})
+	loginResp := &models.LoginResponse{AccessToken: "new.access.token", RefreshToken: "new-refresh-token"}
+	mockUsecase.On("RefreshSession", "old-refresh-token", mock.AnythingOfType("models.ClientInfo")).Return(loginResp, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("POST", "/api/v1/users", bytes.NewBufferString(userJSON))
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/refresh", bytes.NewBufferString(`{"refresh_token":"old-refresh-token"}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.CreateUser(c)
+	handler.RefreshToken(c)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
-	assert.Contains(t, w.Body.String(), "User created successfully")
-	assert.Contains(t, w.Body.String(), "newuser@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "new-refresh-token")
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestCreateUser_InvalidJSON(t *testing.T) {
+func TestRefreshToken_MissingToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	invalidJSON := `{"email": "baduser@example.com", "password": 123}` // password should be string
-
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("POST", "/api/v1/users", bytes.NewBufferString(invalidJSON))
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/refresh", bytes.NewBufferString(`{}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.CreateUser(c)
+	handler.RefreshToken(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to parse user data")
+	assert.Contains(t, w.Body.String(), "Failed to parse refresh token")
 }
 
-func TestCreateUser_CreateUserFails(t *testing.T) {
+func TestRefreshToken_Invalid(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	userJSON := `{"email":"failuser@example.com","password":"failpass"}`
-
-	userResponse := &models.UserResponse{ID: 1, Email: "failuser@example.com", Username: "failuser"}
-	mockUsecase.On("CreateUser", mock.AnythingOfType("*models.User")).Return(userResponse, errors.New("db error"))
+	mockUsecase.On("RefreshSession", "reused-token", mock.AnythingOfType("models.ClientInfo")).Return(nil, errors.New("refresh token has already been used"))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("POST", "/api/v1/users", bytes.NewBufferString(userJSON))
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/refresh", bytes.NewBufferString(`{"refresh_token":"reused-token"}`))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler.CreateUser(c)
+	handler.RefreshToken(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to create user")
-	assert.Contains(t, w.Body.String(), "db error")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid or expired refresh token")
 	mockUsecase.AssertExpectations(t)
 }
-func TestUpdateUser_Success(t *testing.T) {
+
+func TestLogout_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	user := models.User{
-		ID:       1,
-		Email:    "updateuser@example.com",
-		Password: "newpassword",
-	}
-	userJSON := `{"id":1,"email":"updateuser@example.com","password":"newpassword"}`
+	mockUsecase.On("Logout", uint(3)).Return(nil)
 
-	userResponse := &models.UserResponse{ID: user.ID, Email: user.Email, Username: "updateuser"}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/logout", nil)
+	c.Set(middleware.ContextUserIDKey, uint(1))
+	c.Set(middleware.ContextSessionIDKey, uint(3))
 
-	mockUsecase.On("UpdateUser", mock.AnythingOfType("*models.User")).Return(userResponse, nil).Run(func(args mock.Arguments) {
-		argUser := args.Get(0).(*models.User)
-		assert.Equal(t, user.ID, argUser.ID)
-		assert.Equal(t, user.Email, argUser.Email)
-		assert.Equal(t, user.Password, argUser.Password)
-	})
+	handler.Logout(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Logout successful")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestLogout_NoSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(userJSON))
-	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/logout", nil)
 
-	handler.UpdateUser(c)
+	handler.Logout(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetSessions_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	sessions := []models.SessionResponse{
+		{ID: 3, UserAgent: "Shared tablet", Current: true},
+		{ID: 4, UserAgent: "Office phone"},
+	}
+	mockUsecase.On("GetSessions", uint(1), uint(3)).Return(sessions, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/sessions", nil)
+	c.Set(middleware.ContextUserIDKey, uint(1))
+	c.Set(middleware.ContextSessionIDKey, uint(3))
+
+	handler.GetSessions(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "User updated successfully")
-	assert.Contains(t, w.Body.String(), "updateuser@example.com")
+	assert.Contains(t, w.Body.String(), "Shared tablet")
+	assert.Contains(t, w.Body.String(), "Office phone")
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestUpdateUser_InvalidJSON(t *testing.T) {
+func TestRevokeSession_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	invalidJSON := `{"id":1,"email":123,"password":"pass"}` // email should be string
+	mockUsecase.On("RevokeSession", uint(1), uint(4)).Return(nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(invalidJSON))
-	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "4"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/sessions/4", nil)
+	c.Set(middleware.ContextUserIDKey, uint(1))
 
-	handler.UpdateUser(c)
+	handler.RevokeSession(c)
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to parse user data")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockUsecase.AssertExpectations(t)
 }
 
-func TestUpdateUser_UpdateFails(t *testing.T) {
+func TestRevokeSession_NotOwned(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	userJSON := `{"id":2,"email":"failupdate@example.com","password":"failpass"}`
+	mockUsecase.On("RevokeSession", uint(1), uint(9)).Return(errors.New("session not found"))
 
-	userResponse := &models.UserResponse{ID: 2, Email: "failupdate@example.com", Username: "failupdate"}
-	mockUsecase.On("UpdateUser", mock.AnythingOfType("*models.User")).Return(userResponse, errors.New("update error"))
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "9"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/sessions/9", nil)
+	c.Set(middleware.ContextUserIDKey, uint(1))
+
+	handler.RevokeSession(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Session not found")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetUsers_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	users := []models.UserResponse{
+		{ID: 1, Email: "user1@example.com"},
+		{ID: 2, Email: "user2@example.com"},
+	}
+	mockUsecase.On("GetAllUsers", mock.Anything, uint(0), mock.Anything).
+		Return(&models.Page[models.UserResponse]{Items: users, Pagination: models.Pagination{Total: 2, Limit: 20}}, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/2", bytes.NewBufferString(userJSON))
-	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
 
-	handler.UpdateUser(c)
+	handler.GetUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Users retrieved successfully")
+	assert.Contains(t, w.Body.String(), "user1@example.com")
+	assert.Contains(t, w.Body.String(), "user2@example.com")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetUsers_Failure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("GetAllUsers", mock.Anything, uint(0), mock.Anything).Return(nil, errors.New("database error"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+
+	handler.GetUsers(c)
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to update user")
-	assert.Contains(t, w.Body.String(), "update error")
+	assert.Contains(t, w.Body.String(), "Failed to retrieve users")
+	assert.Contains(t, w.Body.String(), "database error")
 	mockUsecase.AssertExpectations(t)
 }
-func TestDeleteUser_Success(t *testing.T) {
+
+func TestGetUsers_NoUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("GetAllUsers", mock.Anything, uint(0), mock.Anything).
+		Return(&models.Page[models.UserResponse]{Items: []models.UserResponse{}, Pagination: models.Pagination{Limit: 20}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+
+	handler.GetUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":[]`)
+	assert.Contains(t, w.Body.String(), `"total":0`)
+	assert.Equal(t, `</api/v1/users>; rel="first"`, w.Header().Get("Link"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetUserByID_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	mockUsecase.On("DeleteUser", uint(1)).Return(nil)
+	userResp := &models.UserResponse{ID: 1, Email: "user1@example.com", Version: 2}
+	mockUsecase.On("GetUserByID", mock.Anything, uint(1)).Return(userResp, nil)
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
-	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/1", nil)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/1", nil)
 
-	handler.DeleteUser(c)
+	handler.GetUserByID(c)
 
-	assert.Equal(t, http.StatusNoContent, w.Code)
-	assert.Empty(t, w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User retrieved successfully")
+	assert.Contains(t, w.Body.String(), "user1@example.com")
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
 	mockUsecase.AssertExpectations(t)
 }
 
-func TestDeleteUser_InvalidID(t *testing.T) {
+func TestGetUserByID_InvalidID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
@@ -407,31 +579,641 @@ func TestDeleteUser_InvalidID(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{gin.Param{Key: "id", Value: "abc"}}
-	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/abc", nil)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/abc", nil)
 
-	handler.DeleteUser(c)
+	handler.GetUserByID(c)
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "Invalid user ID")
 	assert.Contains(t, w.Body.String(), "User ID must be a valid number")
 }
 
-func TestDeleteUser_DeleteFails(t *testing.T) {
+func TestGetUserByID_NotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockUsecase := new(MockUserUseCase)
 	handler := handler.NewUserHandler(mockUsecase)
 
-	mockUsecase.On("DeleteUser", uint(2)).Return(errors.New("delete error"))
+	mockUsecase.On("GetUserByID", mock.Anything, uint(99)).Return((*models.UserResponse)(nil), errors.New("user not found"))
 
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Params = gin.Params{gin.Param{Key: "id", Value: "2"}}
-	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/2", nil)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "99"}}
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/99", nil)
 
-	handler.DeleteUser(c)
+	handler.GetUserByID(c)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-	assert.Contains(t, w.Body.String(), "Failed to delete user")
-	assert.Contains(t, w.Body.String(), "delete error")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "User not found")
+	assert.Contains(t, w.Body.String(), "user not found")
+	mockUsecase.AssertExpectations(t)
+}
+func TestUpdateUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	user := models.User{
+		ID:       1,
+		Email:    "updateuser@example.com",
+		Password: "newpassword",
+	}
+	userJSON := `{"id":1,"email":"updateuser@example.com","password":"newpassword"}`
+
+	userResponse := &models.UserResponse{ID: user.ID, Email: user.Email, Username: "updateuser"}
+
+	mockUsecase.On("UpdateUser", mock.Anything, mock.AnythingOfType("*models.User")).Return(userResponse, nil).Run(func(args mock.Arguments) {
+		argUser := args.Get(1).(*models.User)
+		assert.Equal(t, user.ID, argUser.ID)
+		assert.Equal(t, user.Email, argUser.Email)
+		assert.Equal(t, user.Password, argUser.Password)
+		assert.Equal(t, uint(3), argUser.Version)
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(userJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"3"`)
+
+	handler.UpdateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User updated successfully")
+	assert.Contains(t, w.Body.String(), "updateuser@example.com")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPatchUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	userResponse := &models.UserResponse{ID: 1, Username: "renamed", Email: "user@example.com"}
+	mockUsecase.On("PatchUser", mock.Anything, uint(1), uint(2), models.MergePatch{"username": []byte(`"renamed"`)}).Return(userResponse, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PATCH", "/api/v1/users/1", bytes.NewBufferString(`{"username":"renamed"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.PatchUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"username":"renamed"`)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestPatchUser_InvalidPatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	invalid := fmt.Errorf("%w: \"role\" cannot be patched", models.ErrInvalidPatch)
+	mockUsecase.On("PatchUser", mock.Anything, uint(1), uint(2), mock.Anything).Return(nil, invalid)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("PATCH", "/api/v1/users/1", bytes.NewBufferString(`{"role":"admin"}`))
+	c.Request.Header.Set("Content-Type", "application/merge-patch+json")
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.PatchUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cannot be patched")
+}
+
+func TestUpdateUser_InvalidJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	invalidJSON := `{"id":1,"email":123,"password":"pass"}` // email should be string
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(invalidJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.UpdateUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to parse user data")
+}
+
+func TestUpdateUser_UpdateFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	userJSON := `{"id":2,"email":"failupdate@example.com","password":"failpass"}`
+
+	userResponse := &models.UserResponse{ID: 2, Email: "failupdate@example.com", Username: "failupdate"}
+	mockUsecase.On("UpdateUser", mock.Anything, mock.AnythingOfType("*models.User")).Return(userResponse, errors.New("update error"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/2", bytes.NewBufferString(userJSON))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"1"`)
+
+	handler.UpdateUser(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to update user")
+	assert.Contains(t, w.Body.String(), "update error")
+	mockUsecase.AssertExpectations(t)
+}
+func TestUpdateUserRole_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	caller := &models.Caller{UserID: 1, SessionID: 2, Role: models.RoleAdmin}
+	userResponse := &models.UserResponse{ID: 5, Email: "agent@example.com", Role: models.RoleAgent, Version: 3}
+	mockUsecase.On("UpdateUserRole", caller, uint(5), uint(2), models.RoleAgent).Return(userResponse, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/5/role", bytes.NewBufferString(`{"role":"agent"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
+	c.Set(middleware.ContextUserIDKey, uint(1))
+	c.Set(middleware.ContextSessionIDKey, uint(2))
+	c.Set(middleware.ContextRoleKey, models.RoleAdmin)
+
+	handler.UpdateUserRole(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User role updated successfully")
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUpdateUserRole_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/5/role", bytes.NewBufferString(`{"role":"agent"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.UpdateUserRole(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockUsecase.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserRole_StaleVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("UpdateUserRole", mock.Anything, uint(5), uint(2), models.RoleAgent).
+		Return(nil, fmt.Errorf("%w: user 5 is at version 3", models.ErrStaleVersion))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/5/role", bytes.NewBufferString(`{"role":"agent"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.UpdateUserRole(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUpdateUserRole_InvalidRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "5"}}
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/5/role", bytes.NewBufferString(`{"role":"superuser"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.UpdateUserRole(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Role must be one of admin, agent or assistant")
+}
+
+func TestUnlockUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("UnlockUser", mock.Anything, uint(3)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/3/unlock", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.UnlockUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User unlocked successfully")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUnlockUser_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("UnlockUser", mock.Anything, uint(99)).Return(errors.New("user not found"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/99/unlock", nil)
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.UnlockUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("DeleteUser", mock.Anything, uint(1), uint(2)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/1", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeleteUser_InvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "abc"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/abc", nil)
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid user ID")
+	assert.Contains(t, w.Body.String(), "User ID must be a valid number")
+}
+
+func TestDeleteUser_DeleteFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("DeleteUser", mock.Anything, uint(2), uint(2)).Return(errors.New("delete error"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "2"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/2", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to delete user")
+	assert.Contains(t, w.Body.String(), "delete error")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeleteUser_OtherBranch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("DeleteUser", mock.Anything, uint(2), uint(2)).Return(models.ErrUserNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "2"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/2", nil)
+	c.Request.Header.Set("If-Match", `"2"`)
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "User not found")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestDeleteUser_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/1", nil)
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	assert.Contains(t, w.Body.String(), "If-Match")
+	mockUsecase.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateUser_StaleVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	stale := fmt.Errorf("%w: user 1 is at version 5", models.ErrStaleVersion)
+	mockUsecase.On("UpdateUser", mock.Anything, mock.AnythingOfType("*models.User")).Return(nil, stale)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/api/v1/users/1", bytes.NewBufferString(`{"id":1,"email":"late@example.com"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"4"`)
+
+	handler.UpdateUser(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), "user 1 is at version 5")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetMe_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("GetUserByID", mock.Anything, uint(4)).Return(&models.UserResponse{
+		ID: 4, Username: "ana", UserProfile: models.UserProfile{FullName: "Ana López", Phone: "+525512345678"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/users/me", nil)
+	c.Set(middleware.ContextUserIDKey, uint(4))
+
+	handler.GetMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"full_name":"Ana López"`)
+	assert.Contains(t, w.Body.String(), `"phone":"+525512345678"`)
+}
+
+func TestUpdateMe_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	body := `{"full_name":"Ana López","phone":"+52 55 1234 5678","commission_rate":3.5,"role":"admin"}`
+	mockUsecase.On("UpdateProfile", uint(4), uint(6), &models.UserProfile{FullName: "Ana López", Phone: "+52 55 1234 5678", CommissionRate: 3.5}).
+		Return(&models.UserResponse{ID: 4, Role: models.RoleAgent, Version: 7}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/users/me", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"6"`)
+	c.Set(middleware.ContextUserIDKey, uint(4))
+
+	handler.UpdateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Profile updated successfully")
+	assert.Equal(t, `"7"`, w.Header().Get("ETag"))
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUpdateMe_MissingIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/users/me", bytes.NewBufferString(`{"full_name":"Ana"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(middleware.ContextUserIDKey, uint(4))
+
+	handler.UpdateMe(c)
+
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	mockUsecase.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateMe_InvalidPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	mockUsecase.On("UpdateProfile", uint(4), uint(6), mock.Anything).
+		Return(nil, errors.New(`invalid phone number "5512", use the international format, e.g. +52 55 1234 5678`))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("PUT", "/users/me", bytes.NewBufferString(`{"phone":"5512"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("If-Match", `"6"`)
+	c.Set(middleware.ContextUserIDKey, uint(4))
+
+	handler.UpdateMe(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid phone number")
+}
+
+func TestVerifyEmail_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	verifiedAt := time.Now()
+	mockUsecase.On("VerifyEmail", "verification-token").Return(&models.UserResponse{ID: 3, Email: "ana@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+	w := httptest.NewRecorder()
+	handler.VerifyEmail(newPasswordContext(w, `{"token":"verification-token"}`))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Email verified successfully")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestVerifyEmail_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("VerifyEmail", "stale").Return(nil, errors.New("invalid or expired verification token"))
+
+	w := httptest.NewRecorder()
+	handler.VerifyEmail(newPasswordContext(w, `{"token":"stale"}`))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid or expired verification token")
+}
+
+func TestVerifyEmail_EmailTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("VerifyEmail", "verification-token").Return(nil, models.ErrEmailTaken)
+
+	w := httptest.NewRecorder()
+	handler.VerifyEmail(newPasswordContext(w, `{"token":"verification-token"}`))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "a user with this email already exists")
+}
+
+func TestResendEmailVerification_Accepted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("ResendEmailVerification", "ghost@example.com").Return(nil)
+
+	w := httptest.NewRecorder()
+	handler.ResendEmailVerification(newPasswordContext(w, `{"email":"ghost@example.com"}`))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestVerifyUserEmail_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	verifiedAt := time.Now()
+	mockUsecase.On("MarkEmailVerified", mock.Anything, uint(3)).Return(&models.UserResponse{ID: 3, EmailVerifiedAt: &verifiedAt}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/3/verify-email", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.VerifyUserEmail(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "email_verified_at")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestUserLogin_Deactivated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+
+	loginData := `{"email":"gone@example.com","password":"password123"}`
+	mockUsecase.On("Login", "gone@example.com", "password123", mock.AnythingOfType("models.ClientInfo")).Return(nil, models.ErrAccountDeactivated)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/login", bytes.NewBufferString(loginData))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.UserLogin(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Account deactivated")
+}
+
+func TestDeactivateUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	deactivatedAt := time.Now()
+	mockUsecase.On("DeactivateUser", mock.Anything, uint(6)).Return(&models.UserResponse{ID: 6, DeactivatedAt: &deactivatedAt}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/6/deactivate", nil)
+	c.Params = gin.Params{{Key: "id", Value: "6"}}
+
+	handler.DeactivateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "User deactivated successfully")
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestGetDeletedUsers_Empty(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("GetDeletedUsers", mock.Anything, uint(0)).Return([]models.UserResponse{}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/users/deleted", nil)
+
+	handler.GetDeletedUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+}
+
+func TestRestoreUser_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("RestoreUser", mock.Anything, uint(6)).Return(nil, errors.New("user not found or not deleted or deactivated"))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/api/v1/users/6/restore", nil)
+	c.Params = gin.Params{{Key: "id", Value: "6"}}
+
+	handler.RestoreUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPurgeUser_HasProperties(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("PurgeUser", mock.Anything, uint(6)).Return(fmt.Errorf("%w: reassign its 2 properties first", models.ErrUserHasProperties))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/6/purge", nil)
+	c.Params = gin.Params{{Key: "id", Value: "6"}}
+
+	handler.PurgeUser(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "reassign its 2 properties first")
+}
+
+func TestPurgeUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUserUseCase)
+	handler := handler.NewUserHandler(mockUsecase)
+	mockUsecase.On("PurgeUser", mock.Anything, uint(6)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/6/purge", nil)
+	c.Params = gin.Params{{Key: "id", Value: "6"}}
+
+	handler.PurgeUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
 	mockUsecase.AssertExpectations(t)
 }
@@ -0,0 +1,42 @@
+package mail_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/mail"
+)
+
+func TestOutboxMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer := mail.NewOutboxMailer(dir, "no-reply@example.com")
+
+	err := mailer.Send(&models.Email{To: "ana@example.com", Subject: "Reset your password", Body: "Hello ana"})
+	require.NoError(t, err)
+	err = mailer.Send(&models.Email{To: "ana@example.com", Subject: "Second", Body: "Again"})
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, strings.HasSuffix(entries[0].Name(), "ana@example.com.eml"))
+
+	content, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: no-reply@example.com")
+	assert.Contains(t, string(content), "To: ana@example.com")
+	assert.Contains(t, string(content), "Hello ana")
+}
+
+func TestOutboxMailer_RequiresRecipient(t *testing.T) {
+	mailer := mail.NewOutboxMailer(t.TempDir(), "no-reply@example.com")
+
+	err := mailer.Send(&models.Email{Subject: "No one"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,264 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+// stubSessionRepository serves sessions from a map for the auth middleware tests
+type stubSessionRepository struct {
+	sessions map[uint]*models.Session
+}
+
+func (s *stubSessionRepository) Create(session *models.Session) (*models.Session, error) {
+	return session, nil
+}
+func (s *stubSessionRepository) GetByID(id uint) (*models.Session, error) {
+	if session, ok := s.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, errors.New("session not found")
+}
+func (s *stubSessionRepository) GetByRefreshTokenHash(hash string) (*models.Session, error) {
+	return nil, errors.New("session not found")
+}
+func (s *stubSessionRepository) GetActiveByUserID(userID uint) ([]models.Session, error) {
+	return nil, nil
+}
+func (s *stubSessionRepository) Rotate(id uint, currentHash string, newHash string, expiresAt time.Time, client models.ClientInfo) error {
+	return nil
+}
+func (s *stubSessionRepository) Revoke(id uint) error {
+	return nil
+}
+func (s *stubSessionRepository) MarkTwoFactorVerified(id uint) error {
+	return nil
+}
+func (s *stubSessionRepository) RevokeAllByUserID(userID uint, exceptID uint) error {
+	return nil
+}
+
+func activeSessions() *stubSessionRepository {
+	return &stubSessionRepository{sessions: map[uint]*models.Session{
+		1: {ID: 1, UserID: 5, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+}
+
+// stubAPIKeyRepository serves API keys by hash and records their last use
+type stubAPIKeyRepository struct {
+	keys    map[string]*models.APIKey
+	touched []uint
+}
+
+func (s *stubAPIKeyRepository) Create(key *models.APIKey) (*models.APIKey, error) {
+	return key, nil
+}
+func (s *stubAPIKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	if key, ok := s.keys[hash]; ok {
+		return key, nil
+	}
+	return nil, errors.New("api key not found")
+}
+func (s *stubAPIKeyRepository) GetAll(userID uint) ([]models.APIKey, error) {
+	return nil, nil
+}
+func (s *stubAPIKeyRepository) Revoke(id uint, userID uint) error {
+	return nil
+}
+func (s *stubAPIKeyRepository) TouchLastUsed(id uint, now time.Time) error {
+	s.touched = append(s.touched, id)
+	return nil
+}
+
+func testAPIKeys() *stubAPIKeyRepository {
+	revokedAt := time.Now().Add(-time.Minute)
+	expiredAt := time.Now().Add(-time.Minute)
+	return &stubAPIKeyRepository{keys: map[string]*models.APIKey{
+		middleware.HashToken("inmo_reader"):  {ID: 1, UserID: 5, BranchID: 2, Scopes: "properties:read"},
+		middleware.HashToken("inmo_revoked"): {ID: 2, UserID: 5, Scopes: "properties:read", RevokedAt: &revokedAt},
+		middleware.HashToken("inmo_expired"): {ID: 3, UserID: 5, Scopes: "properties:read", ExpiresAt: &expiredAt},
+	}}
+}
+
+func newAuthRouter(tokens *middleware.TokenManager, sessions ports.SessionRepository) *gin.Engine {
+	return newAuthRouterWithKeys(tokens, sessions, testAPIKeys())
+}
+
+func newAuthRouterWithKeys(tokens *middleware.TokenManager, sessions ports.SessionRepository, apiKeys ports.APIKeyRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/protected", middleware.AuthMiddleware(tokens, sessions, apiKeys), func(c *gin.Context) {
+		caller, ok := middleware.GetCaller(c)
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": caller.UserID, "role": caller.Role, "branch_id": caller.BranchID})
+	})
+	return r
+}
+
+func TestAuthMiddleware_ValidToken(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	token, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 3}, 1)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	newAuthRouter(tokens, activeSessions()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"user_id":5`)
+	assert.Contains(t, w.Body.String(), `"role":"agent"`)
+	assert.Contains(t, w.Body.String(), `"branch_id":3`)
+}
+
+func TestAuthMiddleware_MissingHeader(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	newAuthRouter(tokens, activeSessions()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Missing or malformed Authorization header")
+}
+
+func TestAuthMiddleware_InvalidToken(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer invalid.token.value")
+	newAuthRouter(tokens, activeSessions()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid or expired access token")
+}
+
+func TestAuthMiddleware_RevokedSession(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	token, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 5, Role: models.RoleAgent}, 1)
+	require.NoError(t, err)
+
+	revokedAt := time.Now()
+	sessions := &stubSessionRepository{sessions: map[uint]*models.Session{
+		1: {ID: 1, UserID: 5, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+	}}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	newAuthRouter(tokens, sessions).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Session has been revoked or expired")
+}
+
+func TestAuthMiddleware_ExposesTwoFactorVerification(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	token, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 5, Role: models.RoleAdmin}, 1)
+	require.NoError(t, err)
+
+	sessions := &stubSessionRepository{sessions: map[uint]*models.Session{
+		1: {ID: 1, UserID: 5, ExpiresAt: time.Now().Add(time.Hour), TwoFactorVerified: true},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", middleware.AuthMiddleware(tokens, sessions, testAPIKeys()), middleware.RequireTwoFactor(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthMiddleware_RejectsTwoFactorChallenge(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	challenge, _, err := tokens.GenerateTwoFactorChallenge(5)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge)
+	newAuthRouter(tokens, activeSessions()).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	apiKeys := testAPIKeys()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	auth := middleware.AuthMiddleware(tokens, activeSessions(), apiKeys)
+	r.GET("/properties", auth, middleware.RequirePermission(models.PermPropertiesRead), func(c *gin.Context) {
+		caller, _ := middleware.GetCaller(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": caller.UserID, "api_key_id": caller.APIKeyID, "branch_id": caller.BranchID})
+	})
+	r.POST("/properties", auth, middleware.RequirePermission(models.PermPropertiesWrite), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	r.GET("/sessions", auth, middleware.RequireUserSession(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("acts as its creator within its scopes and branch", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/properties", nil)
+		req.Header.Set(middleware.APIKeyHeader, "inmo_reader")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":5,"api_key_id":1,"branch_id":2}`, w.Body.String())
+		assert.Contains(t, apiKeys.touched, uint(1))
+	})
+
+	t.Run("is denied outside its scopes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/properties", nil)
+		req.Header.Set(middleware.APIKeyHeader, "inmo_reader")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("cannot use account routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/sessions", nil)
+		req.Header.Set(middleware.APIKeyHeader, "inmo_reader")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	for _, rawKey := range []string{"inmo_revoked", "inmo_expired", "inmo_unknown"} {
+		t.Run("rejects "+rawKey, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/properties", nil)
+			req.Header.Set(middleware.APIKeyHeader, rawKey)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Body.String(), "Invalid, revoked or expired API key")
+		})
+	}
+}
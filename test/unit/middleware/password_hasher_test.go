@@ -0,0 +1,107 @@
+package middleware_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/middleware"
+)
+
+// Cheap parameters keep the tests fast; they are not a production policy.
+var testArgon2idParams = middleware.Argon2idParams{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func usePasswordHasher(t *testing.T, hasher ports.PasswordHasher) {
+	t.Helper()
+	middleware.SetPasswordHasher(hasher)
+	t.Cleanup(func() { middleware.SetPasswordHasher(middleware.NewBcryptHasher(middleware.COST)) })
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := middleware.NewArgon2idHasher(testArgon2idParams)
+
+	hash, err := hasher.Hash("testPassword123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.True(t, hasher.Identifies(hash))
+	assert.NoError(t, hasher.Verify(hash, "testPassword123"))
+	assert.Error(t, hasher.Verify(hash, "wrongPassword"))
+	assert.Error(t, hasher.Verify("$argon2id$v=19$broken", "testPassword123"))
+
+	other, err := hasher.Hash("testPassword123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "Every hash should use a fresh salt")
+}
+
+func TestArgon2idHasher_AllowsPasswordsLongerThanBcrypt(t *testing.T) {
+	usePasswordHasher(t, middleware.NewArgon2idHasher(testArgon2idParams))
+	password := strings.Repeat("a", 100)
+
+	hash, err := middleware.HashPassword(password)
+	require.NoError(t, err)
+	assert.NoError(t, middleware.VerifyPassword(hash, password))
+
+	_, err = middleware.HashPassword(strings.Repeat("a", middleware.MaxArgon2idPasswordLength+1))
+	assert.Error(t, err)
+}
+
+func TestVerifyPassword_IdentifiesAlgorithmFromPrefix(t *testing.T) {
+	bcryptHash, err := middleware.NewBcryptHasher(bcrypt.MinCost).Hash("testPassword123")
+	require.NoError(t, err)
+	argonHash, err := middleware.NewArgon2idHasher(testArgon2idParams).Hash("testPassword123")
+	require.NoError(t, err)
+
+	for _, current := range []struct {
+		name string
+		use  func(t *testing.T)
+	}{
+		{"bcrypt policy", func(t *testing.T) { usePasswordHasher(t, middleware.NewBcryptHasher(bcrypt.MinCost)) }},
+		{"argon2id policy", func(t *testing.T) { usePasswordHasher(t, middleware.NewArgon2idHasher(testArgon2idParams)) }},
+	} {
+		t.Run(current.name, func(t *testing.T) {
+			current.use(t)
+			assert.NoError(t, middleware.VerifyPassword(bcryptHash, "testPassword123"))
+			assert.NoError(t, middleware.VerifyPassword(argonHash, "testPassword123"))
+			assert.Error(t, middleware.VerifyPassword(argonHash, "wrongPassword"))
+			assert.Error(t, middleware.VerifyPassword("plaintext", "plaintext"))
+		})
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	weakBcrypt, err := middleware.NewBcryptHasher(bcrypt.MinCost).Hash("testPassword123")
+	require.NoError(t, err)
+	strongBcrypt, err := middleware.NewBcryptHasher(bcrypt.MinCost + 1).Hash("testPassword123")
+	require.NoError(t, err)
+	argonHash, err := middleware.NewArgon2idHasher(testArgon2idParams).Hash("testPassword123")
+	require.NoError(t, err)
+
+	t.Run("bcrypt policy", func(t *testing.T) {
+		usePasswordHasher(t, middleware.NewBcryptHasher(bcrypt.MinCost+1))
+		assert.True(t, middleware.PasswordNeedsRehash(weakBcrypt), "Lower cost should be rehashed")
+		assert.False(t, middleware.PasswordNeedsRehash(strongBcrypt))
+		assert.True(t, middleware.PasswordNeedsRehash(argonHash), "Other algorithm should be rehashed")
+	})
+
+	t.Run("argon2id policy", func(t *testing.T) {
+		stronger := testArgon2idParams
+		stronger.Iterations = 2
+		usePasswordHasher(t, middleware.NewArgon2idHasher(stronger))
+		assert.True(t, middleware.PasswordNeedsRehash(weakBcrypt))
+		assert.True(t, middleware.PasswordNeedsRehash(argonHash), "Fewer iterations should be rehashed")
+
+		current, err := middleware.HashPassword("testPassword123")
+		require.NoError(t, err)
+		assert.False(t, middleware.PasswordNeedsRehash(current))
+	})
+}
@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/middleware"
+)
+
+func TestRequirePermission_Allowed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/2", nil)
+	c.Set(middleware.ContextRoleKey, models.RoleAdmin)
+
+	middleware.RequirePermission(models.PermUsersManage)(c)
+
+	assert.False(t, c.IsAborted())
+}
+
+func TestRequirePermission_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/api/v1/users/2", nil)
+	c.Set(middleware.ContextRoleKey, models.RoleAgent)
+
+	middleware.RequirePermission(models.PermUsersManage)(c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error":"Forbidden","message":"You do not have permission to perform this action"}`, w.Body.String())
+}
+
+func TestRequirePermission_NoRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/api/v1/properties", nil)
+
+	middleware.RequirePermission(models.PermPropertiesRead)(c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name     string
+		role     models.UserRole
+		verified bool
+		aborted  bool
+	}{
+		{"admin without second factor", models.RoleAdmin, false, true},
+		{"admin with second factor", models.RoleAdmin, true, false},
+		{"agent without second factor", models.RoleAgent, false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/api/v1/users", nil)
+			c.Set(middleware.ContextRoleKey, tc.role)
+			c.Set(middleware.ContextTwoFactorKey, tc.verified)
+
+			middleware.RequireTwoFactor()(c)
+
+			assert.Equal(t, tc.aborted, c.IsAborted())
+			if tc.aborted {
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			}
+		})
+	}
+}
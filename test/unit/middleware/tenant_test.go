@@ -0,0 +1,164 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/middleware"
+)
+
+// stubTenantRepository serves the tenants acme (1) and globex (2)
+type stubTenantRepository struct{}
+
+var testTenants = []models.Tenant{{ID: 1, Slug: "acme", Name: "Acme"}, {ID: 2, Slug: "globex", Name: "Globex"}}
+
+func (s *stubTenantRepository) GetAll() ([]models.Tenant, error) {
+	return testTenants, nil
+}
+func (s *stubTenantRepository) GetByID(id uint) (*models.Tenant, error) {
+	for i := range testTenants {
+		if testTenants[i].ID == id {
+			return &testTenants[i], nil
+		}
+	}
+	return nil, models.ErrTenantNotFound
+}
+func (s *stubTenantRepository) GetBySlug(slug string) (*models.Tenant, error) {
+	for i := range testTenants {
+		if testTenants[i].Slug == slug {
+			return &testTenants[i], nil
+		}
+	}
+	return nil, models.ErrTenantNotFound
+}
+func (s *stubTenantRepository) Create(tenant *models.Tenant) error {
+	return nil
+}
+func (s *stubTenantRepository) Update(tenant *models.Tenant) error {
+	return nil
+}
+
+func TestTenantResolver_Resolve(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+	globexToken, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 5, TenantID: 2}, 1)
+	require.NoError(t, err)
+	expiredTokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", -time.Minute, time.Hour)
+	expiredToken, _, err := expiredTokens.GenerateAccessToken(&models.UserResponse{ID: 5, TenantID: 2}, 1)
+	require.NoError(t, err)
+	forgedToken, _, err := middleware.NewTokenManager("other-secret", "inmo-backend-test", time.Minute, time.Hour).
+		GenerateAccessToken(&models.UserResponse{ID: 5, TenantID: 2}, 1)
+	require.NoError(t, err)
+
+	resolver := middleware.NewTenantResolver(&stubTenantRepository{}, tokens, "inmo.example")
+
+	tests := []struct {
+		name       string
+		host       string
+		header     string
+		token      string
+		wantTenant uint
+		wantErr    error
+	}{
+		{name: "header", host: "inmo.example", header: "acme", wantTenant: 1},
+		{name: "header wins over subdomain and token", host: "globex.inmo.example", header: "ACME", token: globexToken, wantTenant: 1},
+		{name: "subdomain", host: "acme.inmo.example:8080", wantTenant: 1},
+		{name: "subdomain wins over token", host: "acme.inmo.example", token: globexToken, wantTenant: 1},
+		{name: "token claim", host: "inmo.example", token: globexToken, wantTenant: 2},
+		{name: "expired token still names its tenant", host: "inmo.example", token: expiredToken, wantTenant: 2},
+		{name: "forged token", host: "inmo.example", token: forgedToken, wantErr: models.ErrTenantRequired},
+		{name: "nested subdomain", host: "a.acme.inmo.example", wantErr: models.ErrTenantRequired},
+		{name: "other domain", host: "acme.example.com", wantErr: models.ErrTenantRequired},
+		{name: "unknown header", host: "inmo.example", header: "initech", wantErr: models.ErrTenantNotFound},
+		{name: "unknown subdomain", host: "initech.inmo.example", wantErr: models.ErrTenantNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/properties", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set(middleware.TenantHeader, tt.header)
+			}
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			tenant, err := resolver.Resolve(req)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTenant, tenant.ID)
+		})
+	}
+}
+
+func TestAuthMiddleware_RejectsTokenOfAnotherTenant(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.SetTenant(1))
+	r.GET("/protected", middleware.AuthMiddleware(tokens, activeSessions(), testAPIKeys()), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, tt := range []struct {
+		tenantID   uint
+		wantStatus int
+	}{{1, http.StatusOK}, {2, http.StatusUnauthorized}} {
+		token, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 5, Role: models.RoleAgent, TenantID: tt.tenantID}, 1)
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.wantStatus, w.Code, "token of tenant %d", tt.tenantID)
+	}
+}
+
+func TestRequirePlatformToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(token string) *gin.Engine {
+		r := gin.New()
+		r.GET("/tenants", middleware.RequirePlatformToken(token), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return r
+	}
+
+	tests := []struct {
+		name          string
+		platformToken string
+		header        string
+		wantStatus    int
+	}{
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusOK},
+		{"wrong token", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"missing token", "s3cret", "", http.StatusUnauthorized},
+		{"no platform token configured", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/tenants", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			newRouter(tt.platformToken).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
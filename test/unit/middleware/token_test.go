@@ -0,0 +1,115 @@
+package middleware_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/middleware"
+)
+
+func TestGenerateAccessToken(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", 15*time.Minute, time.Hour)
+
+	token, expiresAt, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 42, Role: models.RoleAdmin}, 1)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), expiresAt, 2*time.Second)
+
+	claims, err := tokens.ParseAccessToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.UserID)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, models.RoleAdmin, claims.Role)
+	assert.Equal(t, middleware.TokenTypeAccess, claims.TokenType)
+}
+
+func TestParseAccessToken_Expired(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", -time.Minute, time.Hour)
+
+	token, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 1}, 1)
+	require.NoError(t, err)
+
+	_, err = tokens.ParseAccessToken(token)
+	assert.Error(t, err, "Expired tokens must be rejected")
+}
+
+func TestParseAccessToken_WrongSecret(t *testing.T) {
+	issuer := middleware.NewTokenManager("secret-a", "inmo-backend-test", time.Minute, time.Hour)
+	verifier := middleware.NewTokenManager("secret-b", "inmo-backend-test", time.Minute, time.Hour)
+
+	token, _, err := issuer.GenerateAccessToken(&models.UserResponse{ID: 1}, 1)
+	require.NoError(t, err)
+
+	_, err = verifier.ParseAccessToken(token)
+	assert.Error(t, err, "Tokens signed with another secret must be rejected")
+}
+
+func TestParseAccessToken_Garbage(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	_, err := tokens.ParseAccessToken("not-a-jwt")
+	assert.Error(t, err)
+}
+
+func TestGenerateRefreshToken(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, 24*time.Hour)
+
+	raw, hash, expiresAt, err := tokens.GenerateRefreshToken()
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, raw)
+	assert.Equal(t, middleware.HashToken(raw), hash, "Stored hash must match the issued token")
+	assert.NotEqual(t, raw, hash)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), expiresAt, 2*time.Second)
+
+	other, _, _, err := tokens.GenerateRefreshToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, raw, other, "Refresh tokens must be unique")
+}
+
+func TestTwoFactorChallenge(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	challenge, expiresAt, err := tokens.GenerateTwoFactorChallenge(7)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, 2*time.Second)
+
+	userID, err := tokens.ParseTwoFactorChallenge(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+
+	_, err = tokens.ParseAccessToken(challenge)
+	assert.Error(t, err, "A challenge must not be accepted as an access token")
+
+	accessToken, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 7}, 1)
+	require.NoError(t, err)
+	_, err = tokens.ParseTwoFactorChallenge(accessToken)
+	assert.Error(t, err, "An access token must not be accepted as a challenge")
+}
+
+func TestInvitationToken(t *testing.T) {
+	tokens := middleware.NewTokenManager("test-secret", "inmo-backend-test", time.Minute, time.Hour)
+
+	first, err := tokens.GenerateInvitationToken("ana@example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	second, err := tokens.GenerateInvitationToken("ana@example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "Resending must produce a new token")
+
+	email, err := tokens.ParseInvitationToken(first)
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", email)
+
+	_, err = tokens.ParseAccessToken(first)
+	assert.Error(t, err, "An invitation must not be accepted as an access token")
+
+	expired, err := tokens.GenerateInvitationToken("ana@example.com", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = tokens.ParseInvitationToken(expired)
+	assert.Error(t, err, "Expired invitations must be rejected")
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/middleware"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA1), truncated to 6 digits.
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := middleware.TOTPCode(secret, middleware.TOTPStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, "T=%d", unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := middleware.GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	step := middleware.TOTPStep(now)
+
+	code, err := middleware.TOTPCode(secret, step)
+	require.NoError(t, err)
+	matched, ok := middleware.ValidateTOTP(secret, code, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	// One step of clock drift is tolerated, two are not
+	previous, err := middleware.TOTPCode(secret, step-1)
+	require.NoError(t, err)
+	_, ok = middleware.ValidateTOTP(secret, previous, now, 0)
+	assert.True(t, ok)
+	old, err := middleware.TOTPCode(secret, step-2)
+	require.NoError(t, err)
+	_, ok = middleware.ValidateTOTP(secret, old, now, 0)
+	assert.False(t, ok)
+
+	// A used step cannot be replayed
+	_, ok = middleware.ValidateTOTP(secret, code, now, step)
+	assert.False(t, ok)
+
+	_, ok = middleware.ValidateTOTP(secret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := middleware.TOTPURI("Inmo", "ana@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Inmo:ana@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Inmo")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
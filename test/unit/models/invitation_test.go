@@ -0,0 +1,31 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestInvitation_Status(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		invitation models.Invitation
+		want       models.InvitationStatus
+	}{
+		{"pending", models.Invitation{ExpiresAt: now.Add(time.Hour)}, models.InvitationPending},
+		{"expired", models.Invitation{ExpiresAt: earlier}, models.InvitationExpired},
+		{"accepted", models.Invitation{ExpiresAt: earlier, AcceptedAt: &earlier}, models.InvitationAccepted},
+		{"revoked", models.Invitation{ExpiresAt: now.Add(time.Hour), RevokedAt: &earlier}, models.InvitationRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.invitation.Status(now))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestLoginPolicy_LockFor(t *testing.T) {
+	policy := models.LoginPolicy{
+		LockoutDuration: 15 * time.Minute,
+		FreeAttempts:    2,
+		BackoffBase:     time.Second,
+		BackoffMax:      10 * time.Second,
+	}
+
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 7, want: 10 * time.Second},
+		{failures: 50, want: 10 * time.Second},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, policy.LockFor(tc.failures, 100), "failures=%d", tc.failures)
+	}
+
+	assert.Equal(t, 15*time.Minute, policy.LockFor(5, 5))
+}
+
+func TestLoginAttempt_IsLocked(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Minute)
+
+	assert.False(t, (&models.LoginAttempt{}).IsLocked(now))
+	assert.True(t, (&models.LoginAttempt{LockedUntil: &until}).IsLocked(now))
+	assert.False(t, (&models.LoginAttempt{LockedUntil: &until}).IsLocked(until))
+}
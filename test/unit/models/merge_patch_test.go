@@ -0,0 +1,93 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestParseMergePatch(t *testing.T) {
+	patch, err := models.ParseMergePatch([]byte(`{"price": 250000, "notes": null}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes", "price"}, patch.Fields())
+
+	for _, body := range []string{`[]`, `"price"`, `null`, `{"price":`} {
+		_, err := models.ParseMergePatch([]byte(body))
+		assert.ErrorIs(t, err, models.ErrInvalidPatch, body)
+	}
+}
+
+func TestMergePatch_Apply(t *testing.T) {
+	current := func() *models.Property {
+		return &models.Property{
+			ID:        1,
+			Title:     "Casa Centro",
+			Address:   "Av. Juarez 10",
+			Price:     100000,
+			Bedrooms:  3,
+			Amenities: models.StringArray{"pool", "gym"},
+			Notes:     "keys at the office",
+		}
+	}
+
+	t.Run("replaces the supplied fields only", func(t *testing.T) {
+		property := current()
+		patch, err := models.ParseMergePatch([]byte(`{"price": 250000, "amenities": ["garden"]}`))
+		require.NoError(t, err)
+
+		require.NoError(t, patch.Apply(property, models.PropertyPatchFields))
+		assert.Equal(t, 250000.0, property.Price)
+		assert.Equal(t, models.StringArray{"garden"}, property.Amenities, "Arrays are replaced, not merged")
+		assert.Equal(t, "Casa Centro", property.Title)
+		assert.Equal(t, 3, property.Bedrooms)
+		assert.Equal(t, uint(1), property.ID)
+	})
+
+	t.Run("null resets a field", func(t *testing.T) {
+		property := current()
+		patch, err := models.ParseMergePatch([]byte(`{"notes": null, "amenities": null}`))
+		require.NoError(t, err)
+
+		require.NoError(t, patch.Apply(property, models.PropertyPatchFields))
+		assert.Empty(t, property.Notes)
+		assert.Empty(t, property.Amenities)
+		assert.Equal(t, "Av. Juarez 10", property.Address)
+	})
+
+	t.Run("refuses fields that cannot be patched", func(t *testing.T) {
+		property := current()
+		patch, err := models.ParseMergePatch([]byte(`{"id": 7, "price": 1}`))
+		require.NoError(t, err)
+
+		err = patch.Apply(property, models.PropertyPatchFields)
+		assert.ErrorIs(t, err, models.ErrInvalidPatch)
+		assert.ErrorContains(t, err, `"id" cannot be patched`)
+		assert.Equal(t, current(), property)
+	})
+
+	t.Run("refuses values of the wrong type", func(t *testing.T) {
+		property := current()
+		patch, err := models.ParseMergePatch([]byte(`{"price": "cheap"}`))
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, patch.Apply(property, models.PropertyPatchFields), models.ErrInvalidPatch)
+		assert.Equal(t, current(), property)
+	})
+
+	t.Run("merges nested objects", func(t *testing.T) {
+		type settings struct {
+			Name    string            `json:"name"`
+			Labels  map[string]string `json:"labels"`
+			Enabled bool              `json:"enabled"`
+		}
+		target := &settings{Name: "a", Labels: map[string]string{"x": "1", "y": "2"}, Enabled: true}
+		patch, err := models.ParseMergePatch([]byte(`{"labels": {"x": null, "z": "3"}}`))
+		require.NoError(t, err)
+
+		require.NoError(t, patch.Apply(target, []string{"labels"}))
+		assert.Equal(t, &settings{Name: "a", Labels: map[string]string{"y": "2", "z": "3"}, Enabled: true}, target)
+	})
+}
@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestPageRequest_Normalize(t *testing.T) {
+	sortable := []string{"price", "created_at"}
+
+	t.Run("applies the defaults", func(t *testing.T) {
+		page := models.PageRequest{}
+		require.NoError(t, page.Normalize(sortable, "-created_at"))
+		assert.Equal(t, models.DefaultPageLimit, page.Limit)
+		assert.Equal(t, []models.SortField{{Name: "created_at", Desc: true}}, page.SortFields)
+	})
+
+	t.Run("parses multiple sort fields", func(t *testing.T) {
+		page := models.PageRequest{Sort: " price, -created_at"}
+		require.NoError(t, page.Normalize(sortable, "-created_at"))
+		assert.Equal(t, "price,-created_at", page.Sort)
+		assert.Equal(t, []models.SortField{{Name: "price"}, {Name: "created_at", Desc: true}}, page.SortFields)
+	})
+
+	t.Run("keeps the sort order of the cursor", func(t *testing.T) {
+		cursor := (&models.Cursor{Sort: "price", Values: []any{1500.0}, ID: 4}).Encode()
+		page := models.PageRequest{Cursor: cursor}
+		require.NoError(t, page.Normalize(sortable, "-created_at"))
+		assert.Equal(t, "price", page.Sort)
+		assert.Equal(t, uint(4), page.After.ID)
+	})
+
+	cursor := (&models.Cursor{Sort: "price", Values: []any{1500.0}, ID: 4}).Encode()
+	tests := []struct {
+		name string
+		page models.PageRequest
+	}{
+		{"limit too large", models.PageRequest{Limit: models.MaxPageLimit + 1}},
+		{"negative limit", models.PageRequest{Limit: -1}},
+		{"negative offset", models.PageRequest{Offset: -20}},
+		{"unknown sort field", models.PageRequest{Sort: "owner_id"}},
+		{"repeated sort field", models.PageRequest{Sort: "price,-price"}},
+		{"malformed cursor", models.PageRequest{Cursor: "not-a-cursor"}},
+		{"cursor with offset", models.PageRequest{Cursor: cursor, Offset: 20}},
+		{"cursor of another sort", models.PageRequest{Cursor: cursor, Sort: "-price"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.page.Normalize(sortable, "-created_at"), models.ErrInvalidPage)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestUserRole_IsValid(t *testing.T) {
+	assert.True(t, models.RoleAdmin.IsValid())
+	assert.True(t, models.RoleAgent.IsValid())
+	assert.True(t, models.RoleAssistant.IsValid())
+	assert.True(t, models.RoleRegionalAdmin.IsValid())
+	assert.False(t, models.UserRole("").IsValid())
+	assert.False(t, models.UserRole("owner").IsValid())
+}
+
+func TestUserRole_HasPermission(t *testing.T) {
+	tests := []struct {
+		role       models.UserRole
+		permission models.Permission
+		expected   bool
+	}{
+		{models.RoleAdmin, models.PermUsersManage, true},
+		{models.RoleAgent, models.PermUsersManage, false},
+		{models.RoleAssistant, models.PermUsersManage, false},
+		{models.RoleAgent, models.PermPropertiesWrite, true},
+		{models.RoleAssistant, models.PermPropertiesWrite, true},
+		{models.RoleAssistant, models.PermPropertiesDelete, false},
+		{models.RoleAgent, models.PermPropertiesDelete, true},
+		{models.RoleRegionalAdmin, models.PermBranchesAll, true},
+		{models.RoleAdmin, models.PermBranchesAll, false},
+		{models.RoleAdmin, models.PermBranchesManage, false},
+		{models.UserRole("owner"), models.PermPropertiesRead, false},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expected, tc.role.HasPermission(tc.permission), "%s / %s", tc.role, tc.permission)
+	}
+}
+
+func TestCaller_Can(t *testing.T) {
+	agent := &models.Caller{UserID: 4, SessionID: 1, Role: models.RoleAgent}
+	assert.True(t, agent.Can(models.PermPropertiesWrite))
+	assert.False(t, agent.Can(models.PermUsersManage))
+
+	// An API key only has its scopes, whatever its creator's role
+	apiKey := &models.Caller{UserID: 1, APIKeyID: 3, Scopes: []models.Permission{models.PermPropertiesRead}}
+	assert.True(t, apiKey.Can(models.PermPropertiesRead))
+	assert.False(t, apiKey.Can(models.PermPropertiesWrite))
+	assert.False(t, apiKey.IsAdmin())
+
+	var nobody *models.Caller
+	assert.False(t, nobody.Can(models.PermPropertiesRead))
+}
+
+func TestAPIKey_Scopes(t *testing.T) {
+	var key models.APIKey
+	key.SetScopes([]models.Permission{models.PermPropertiesRead, models.PermLeadsWrite})
+
+	assert.Equal(t, "properties:read,leads:write", key.Scopes)
+	assert.Equal(t, []models.Permission{models.PermPropertiesRead, models.PermLeadsWrite}, key.ScopeList())
+	assert.True(t, models.PermLeadsWrite.IsAPIKeyScope())
+	assert.False(t, models.PermAPIKeysManage.IsAPIKeyScope())
+}
+
+func TestCaller_BranchScope(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2}
+	regional := &models.Caller{UserID: 3, Role: models.RoleRegionalAdmin}
+
+	scope, err := admin.BranchScope(0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), scope, "Listings default to the caller's branch")
+	scope, err = admin.BranchScope(2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), scope)
+	_, err = admin.BranchScope(4)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+
+	scope, err = regional.BranchScope(0)
+	assert.NoError(t, err)
+	assert.Zero(t, scope, "Regional admins see every branch")
+	scope, err = regional.BranchScope(4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), scope)
+
+	// Branch permissions are never granted to API keys
+	apiKey := &models.Caller{UserID: 3, APIKeyID: 7, Scopes: []models.Permission{models.PermPropertiesRead}}
+	_, err = apiKey.BranchScope(0)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+
+	var nobody *models.Caller
+	_, err = nobody.BranchScope(0)
+	assert.ErrorIs(t, err, models.ErrForbidden)
+}
+
+func TestCaller_CanAccessBranch(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2}
+	assert.True(t, admin.CanAccessBranch(2))
+	assert.False(t, admin.CanAccessBranch(4))
+	assert.False(t, admin.CanAccessBranch(0))
+	assert.True(t, (&models.Caller{UserID: 3, Role: models.RoleRegionalAdmin}).CanAccessBranch(4))
+	assert.False(t, (&models.Caller{UserID: 5, Role: models.RoleAgent}).CanAccessBranch(0))
+}
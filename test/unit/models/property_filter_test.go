@@ -0,0 +1,56 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestPropertyFilter_Normalize(t *testing.T) {
+	price := func(v float64) *float64 { return &v }
+	size := func(v int) *int { return &v }
+
+	t.Run("trims text and splits amenities", func(t *testing.T) {
+		filter := models.PropertyFilter{
+			Query:     " casa  cerca de la\tiglesia ",
+			City:      "  Monterrey ",
+			Amenities: []string{"pool, gym", " ", "garden"},
+		}
+		require.NoError(t, filter.Normalize())
+		assert.Equal(t, "casa cerca de la iglesia", filter.Query)
+		assert.Equal(t, "Monterrey", filter.City)
+		assert.Equal(t, []string{"pool", "gym", "garden"}, filter.Amenities)
+	})
+
+	t.Run("accepts equal range bounds", func(t *testing.T) {
+		filter := models.PropertyFilter{MinPrice: price(100), MaxPrice: price(100), MinLandM2: size(0), MaxLandM2: size(0)}
+		assert.NoError(t, filter.Normalize())
+	})
+
+	tests := []struct {
+		name   string
+		filter models.PropertyFilter
+		want   string
+	}{
+		{"long query", models.PropertyFilter{Query: strings.Repeat("jardín ", 30)}, "q cannot exceed 200 characters"},
+		{"unknown property type", models.PropertyFilter{PropertyType: "castle"}, `unknown property_type "castle"`},
+		{"unknown transaction type", models.PropertyFilter{TransactionType: "lease"}, `unknown transaction_type "lease"`},
+		{"unknown status", models.PropertyFilter{Status: "gone"}, `unknown status "gone"`},
+		{"negative price", models.PropertyFilter{MinPrice: price(-1)}, "prices cannot be negative"},
+		{"inverted price range", models.PropertyFilter{MinPrice: price(200), MaxPrice: price(100)}, "min_price cannot exceed max_price"},
+		{"negative bedrooms", models.PropertyFilter{MinBedrooms: size(-2)}, "min_bedrooms cannot be negative"},
+		{"inverted construction range", models.PropertyFilter{MinConstructionM2: size(90), MaxConstructionM2: size(60)}, "min_construction_m2 cannot exceed max_construction_m2"},
+		{"inverted land range", models.PropertyFilter{MinLandM2: size(300), MaxLandM2: size(200)}, "min_land_m2 cannot exceed max_land_m2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Normalize()
+			assert.ErrorIs(t, err, models.ErrInvalidFilter)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestComparePrice(t *testing.T) {
+	assert.Equal(t, models.PriceComparison{OriginalPrice: 120000, PriceChangePercent: -12.5, PriceReduced: true}, models.ComparePrice(105000, 120000))
+	assert.Equal(t, models.PriceComparison{OriginalPrice: 90000, PriceChangePercent: 11.11}, models.ComparePrice(100000, 90000))
+	assert.Equal(t, models.PriceComparison{OriginalPrice: 100000}, models.ComparePrice(100000, 100000))
+	assert.Equal(t, models.PriceComparison{OriginalPrice: 100000}, models.ComparePrice(100000, 0), "Properties without an original price never changed")
+}
+
+func TestProperty_PriceComparison(t *testing.T) {
+	p := &models.Property{ID: 1, Price: 95000, OriginalPrice: 100000}
+
+	resp := p.ToResponse()
+	assert.Equal(t, 100000.0, resp.OriginalPrice)
+	assert.Equal(t, -5.0, resp.PriceChangePercent)
+	assert.True(t, resp.PriceReduced)
+	assert.Equal(t, 100000.0, resp.ToProperty().OriginalPrice)
+
+	assert.True(t, p.ToCard().PriceReduced)
+}
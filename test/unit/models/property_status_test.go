@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"inmo-backend/internal/domain/models"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	tests := []struct {
+		name            string
+		transactionType models.TransactionType
+		from, to        models.PropertyStatus
+		wantErr         string
+	}{
+		{"reserve a sale", models.TransactionSale, models.StatusAvailable, models.StatusReserved, ""},
+		{"sell a reserved property", models.TransactionSale, models.StatusReserved, models.StatusSold, ""},
+		{"release a reservation", models.TransactionRental, models.StatusReserved, models.StatusAvailable, ""},
+		{"end a lease", models.TransactionRental, models.StatusRented, models.StatusAvailable, ""},
+		{"no status counts as available", models.TransactionRental, "", models.StatusRented, ""},
+		{"rent a sale", models.TransactionSale, models.StatusAvailable, models.StatusRented, "a property for sale cannot be rented"},
+		{"sell a rental", models.TransactionRental, models.StatusReserved, models.StatusSold, "a property for rental cannot be sold"},
+		{"typo", models.TransactionSale, models.StatusAvailable, "sould", `unknown status "sould"`},
+		{"unknown transaction type", "lease", models.StatusAvailable, models.StatusRented, `unknown transaction_type "lease"`},
+		{"same status", models.TransactionSale, models.StatusReserved, models.StatusReserved, "the property is already reserved"},
+		{"a sale is final", models.TransactionSale, models.StatusSold, models.StatusAvailable, "a property for sale that is sold cannot change status"},
+		{"reserve a rented property", models.TransactionRental, models.StatusRented, models.StatusReserved, "cannot go from rented to reserved, only to available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.CheckStatusTransition(tt.transactionType, tt.from, tt.to)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckStatus(t *testing.T) {
+	assert.NoError(t, models.CheckStatus(models.TransactionSale, models.StatusSold))
+	assert.NoError(t, models.CheckStatus(models.TransactionRental, models.StatusRented))
+	assert.ErrorIs(t, models.CheckStatus(models.TransactionRental, models.StatusSold), models.ErrInvalidStatusTransition)
+	assert.ErrorIs(t, models.CheckStatus("", models.StatusAvailable), models.ErrInvalidStatusTransition)
+}
@@ -88,6 +88,7 @@ func TestProperty_ToResponse_NoUser(t *testing.T) {
 		PropertyType:   models.TypeHouse,
 		TransactionType: models.TransactionSale,
 		Status:         models.StatusAvailable,
+		UserID:         7,
 	}
 	resp := p.ToResponse()
 	assert.Equal(t, p.ID, resp.ID)
@@ -113,6 +114,7 @@ func TestProperty_ToResponse_NoUser(t *testing.T) {
 	assert.Equal(t, p.PropertyType, resp.PropertyType)
 	assert.Equal(t, p.TransactionType, resp.TransactionType)
 	assert.Equal(t, p.Status, resp.Status)
+	assert.Equal(t, p.UserID, resp.AgentID)
 	assert.Nil(t, resp.Agent)
 }
 
@@ -21,6 +21,7 @@ func TestToUserResponse_ValidUser(t *testing.T) {
 		ID:        1,
 		Username:  "testuser",
 		Email:     "test@example.com",
+		Role:      models.RoleAgent,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -29,6 +30,27 @@ func TestToUserResponse_ValidUser(t *testing.T) {
 	assert.Equal(t, user.ID, resp.ID)
 	assert.Equal(t, user.Username, resp.Username)
 	assert.Equal(t, user.Email, resp.Email)
+	assert.Equal(t, user.Role, resp.Role)
 	assert.Equal(t, user.CreatedAt, resp.CreatedAt)
 	assert.Equal(t, user.UpdatedAt, resp.UpdatedAt)
 }
+
+func TestNormalizePhone(t *testing.T) {
+	valid := map[string]string{
+		"+52 55 1234 5678":   "+525512345678",
+		"+52 (55) 1234-5678": "+525512345678",
+		"0052.55.1234.5678":  "+525512345678",
+		"+1 415 555 0100":    "+14155550100",
+		"":                   "",
+	}
+	for input, expected := range valid {
+		normalized, err := models.NormalizePhone(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, normalized, input)
+	}
+
+	for _, input := range []string{"55 1234 5678", "+0 55 1234 5678", "+52 55 12ab 5678", "+1234", "+1234567890123456"} {
+		_, err := models.NormalizePhone(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,144 @@
+package oidc_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/internal/infrastructure/oidc"
+	"inmo-backend/test/fakeidp"
+)
+
+const (
+	testClientID    = "inmo-backend"
+	testRedirectURL = "http://localhost:8080/api/v1/users/login/sso/callback"
+	testVerifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func testChallenge() string {
+	sum := sha256.Sum256([]byte(testVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func newTestProvider(idp *fakeidp.Server) ports.IdentityProvider {
+	return oidc.NewProvider(idp.URL, idp.ClientID, idp.ClientSecret, testRedirectURL, []string{"openid", "email"}, nil)
+}
+
+func signIn(t *testing.T, idp *fakeidp.Server, provider ports.IdentityProvider, nonce string) string {
+	authURL, err := provider.AuthCodeURL("state-1", nonce, testChallenge())
+	require.NoError(t, err)
+	code, state, err := idp.SignIn(authURL)
+	require.NoError(t, err)
+	require.Equal(t, "state-1", state)
+	return code
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	idp := fakeidp.New(t, testClientID, "")
+
+	authURL, err := newTestProvider(idp).AuthCodeURL("state-1", "nonce-1", testChallenge())
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email", query.Get("scope"))
+	assert.Equal(t, "state-1", query.Get("state"))
+	assert.Equal(t, "nonce-1", query.Get("nonce"))
+	assert.Equal(t, testChallenge(), query.Get("code_challenge"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	t.Run("returns the identity of a verified ID token", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "client-secret")
+		idp.Email = "Agent@Example.com"
+		provider := newTestProvider(idp)
+
+		identity, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), testVerifier, "nonce-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, idp.URL, identity.Issuer)
+		assert.Equal(t, "fakeidp-user-1", identity.Subject)
+		assert.Equal(t, "agent@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("rejects a wrong PKCE verifier", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "")
+		provider := newTestProvider(idp)
+
+		_, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), "not-the-verifier", "nonce-1")
+
+		assert.Error(t, err)
+		assert.Zero(t, idp.Exchanges)
+	})
+
+	t.Run("rejects a replayed code", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "")
+		provider := newTestProvider(idp)
+		code := signIn(t, idp, provider, "nonce-1")
+
+		_, err := provider.Exchange(code, testVerifier, "nonce-1")
+		require.NoError(t, err)
+		_, err = provider.Exchange(code, testVerifier, "nonce-1")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects a wrong client secret", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "client-secret")
+		provider := oidc.NewProvider(idp.URL, testClientID, "wrong-secret", testRedirectURL, []string{"openid"}, nil)
+
+		_, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), testVerifier, "nonce-1")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an ID token for another nonce", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "")
+		idp.Nonce = "someone-elses-nonce"
+		provider := newTestProvider(idp)
+
+		_, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), testVerifier, "nonce-1")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an ID token for another client", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "")
+		idp.Audience = "another-client"
+		provider := newTestProvider(idp)
+
+		_, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), testVerifier, "nonce-1")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an ID token with a forged signature", func(t *testing.T) {
+		idp := fakeidp.New(t, testClientID, "")
+		idp.SigningKey = fakeidp.GenerateKey(t)
+		provider := newTestProvider(idp)
+
+		_, err := provider.Exchange(signIn(t, idp, provider, "nonce-1"), testVerifier, "nonce-1")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestProvider_RequiresDiscoveryDocument(t *testing.T) {
+	idp := fakeidp.New(t, testClientID, "")
+	provider := oidc.NewProvider(idp.URL+"/other-tenant", testClientID, "", testRedirectURL, []string{"openid"}, nil)
+
+	_, err := provider.AuthCodeURL("state-1", "nonce-1", testChallenge())
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/repository"
+)
+
+func normalizedPage(t *testing.T, page models.PageRequest) *models.PageRequest {
+	t.Helper()
+	require.NoError(t, page.Normalize(models.UserSortFields, "-created_at"))
+	return &page
+}
+
+func TestUserRepository_GetPage(t *testing.T) {
+	t.Run("first page counts the rows and points at the next one", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM users WHERE tenant_id = \? AND deleted_at IS NULL AND branch_id = \?$`).
+			WithArgs(tenantID, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND deleted_at IS NULL AND branch_id = \? ORDER BY created_at DESC, id ASC LIMIT 3$`).
+			WithArgs(tenantID, 2).
+			WillReturnRows(userRow(userRow(userRow(sqlmock.NewRows(userColumnNames), 9, "a@example.com"), 8, "b@example.com"), 7, "c@example.com"))
+
+		page, err := repository.NewUserRepository(db, tenantID).GetPage(2, normalizedPage(t, models.PageRequest{Limit: 2}))
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, 3, page.Total)
+
+		cursor, err := models.DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, uint(8), cursor.ID)
+		assert.Equal(t, "-created_at", cursor.Sort)
+	})
+
+	t.Run("last page has no next cursor", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM users`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND deleted_at IS NULL ORDER BY username ASC, id ASC LIMIT 3 OFFSET 4$`).
+			WithArgs(tenantID).
+			WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 9, "a@example.com"))
+
+		page, err := repository.NewUserRepository(db, tenantID).GetPage(0, normalizedPage(t, models.PageRequest{Limit: 2, Offset: 4, Sort: "username"}))
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 1)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("cursor continues after the last row", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		cursor := (&models.Cursor{Sort: "email,-created_at", Values: []any{"b@example.com", createdAt}, ID: 8}).Encode()
+
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM users WHERE tenant_id = \? AND deleted_at IS NULL$`).
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND deleted_at IS NULL`+
+			` AND \(\(email > \?\) OR \(email = \? AND created_at < \?\) OR \(email = \? AND created_at = \? AND id > \?\)\)`+
+			` ORDER BY email ASC, created_at DESC, id ASC LIMIT 3$`).
+			WithArgs(tenantID, "b@example.com", "b@example.com", createdAt, "b@example.com", createdAt, 8).
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+		page, err := repository.NewUserRepository(db, tenantID).GetPage(0, normalizedPage(t, models.PageRequest{Limit: 2, Cursor: cursor}))
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+}
+
+func TestPropertyRepository_SearchPage(t *testing.T) {
+	db, mock := newMockDB(t)
+	page := &models.PageRequest{Limit: 5, Sort: "price,-created_at"}
+	require.NoError(t, page.Normalize(models.PropertySortFields, "-created_at"))
+
+	mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties LEFT JOIN branches .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL AND properties.city = \?$`).
+		WithArgs(tenantID, "Monterrey").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(selectProperties+`properties.deleted_at IS NULL AND properties.city = \? ORDER BY properties.price ASC, properties.created_at DESC, properties.id ASC LIMIT 6$`).
+		WithArgs(tenantID, "Monterrey").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	result, err := repository.NewPropertyRepository(db, tenantID).Search(&models.PropertyFilter{City: "Monterrey"}, page)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PropertyResponse{}, result.Items)
+	assert.Equal(t, 0, result.Total)
+}
+
+func TestPropertyRepository_SearchCards(t *testing.T) {
+	db, mock := newMockDB(t)
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := &models.PageRequest{Limit: 1, Sort: "land_m2"}
+	require.NoError(t, page.Normalize(models.PropertySortFields, "-created_at"))
+
+	cardColumns := []string{"id", "title", "price", "original_price", "bedrooms", "bathrooms", "construction_m2", "city",
+		"neighborhood", "property_type", "transaction_type", "status", "created_at", "land_m2", "updated_at"}
+	mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties`).
+		WithArgs(tenantID, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`^SELECT properties.id, properties.title, properties.price, .*, properties.created_at, properties.land_m2, properties.updated_at FROM properties`+
+		` .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL AND properties.branch_id = \? ORDER BY properties.land_m2 ASC, properties.id ASC LIMIT 2$`).
+		WithArgs(tenantID, 2).
+		WillReturnRows(sqlmock.NewRows(cardColumns).
+			AddRow(4, "Lot", 90000.0, 100000.0, 0, 0, 0, "Monterrey", "Centro", models.TypeLand, models.TransactionSale, models.StatusAvailable, createdAt, 250, createdAt).
+			AddRow(5, "House", 150000.0, 150000.0, 3, 2, 120, "Monterrey", "Centro", models.TypeHouse, models.TransactionSale, models.StatusAvailable, createdAt, 300, createdAt))
+
+	cards, err := repository.NewPropertyRepository(db, tenantID).SearchCards(&models.PropertyFilter{BranchID: 2}, page)
+	require.NoError(t, err)
+	require.Len(t, cards.Items, 1)
+	assert.Equal(t, models.PropertyCard{
+		ID: 4, Title: "Lot", Price: 90000, City: "Monterrey", Neighborhood: "Centro",
+		PropertyType: models.TypeLand, TransactionType: models.TransactionSale, Status: models.StatusAvailable, CreatedAt: createdAt,
+		PriceReduced: true,
+	}, cards.Items[0])
+	assert.Equal(t, 2, cards.Total)
+
+	cursor, err := models.DecodeCursor(cards.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []any{250.0}, cursor.Values, "land_m2 is read for the cursor although cards leave it out")
+}
+
+func TestPropertyRepository_TextSearch(t *testing.T) {
+	const match = `MATCH\(properties.title, properties.address, properties.neighborhood, properties.reference, properties.notes\) AGAINST \(\? IN NATURAL LANGUAGE MODE\)`
+	rankedColumns := append(append([]string{}, propertyColumnNames...), "relevance")
+	rankedRow := func(rows *sqlmock.Rows, id uint, relevance float64) *sqlmock.Rows {
+		now := time.Now()
+		return rows.AddRow(id, "Casa junto a la iglesia", nil, "Av. Juarez 10", "Colonia Centro", "Monterrey", "", "", 100000.0, 100000.0, 0, 0, false, false,
+			1, 3, 2, 0, 0, nil, nil, nil, nil, "", 1, 5, 1, models.TypeHouse, models.TransactionSale, models.StatusAvailable,
+			now, now, 1, nil, "Centro", relevance)
+	}
+
+	t.Run("ranks the best matches first", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		page := &models.PageRequest{Limit: 1}
+		require.NoError(t, page.Normalize(models.PropertyTextSortFields, "-relevance"))
+
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL AND `+match+` AND properties.status = \?$`).
+			WithArgs(tenantID, "iglesia centro", models.StatusAvailable).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(`^SELECT .*, `+match+` FROM properties .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL AND `+match+` AND properties.status = \?`+
+			` ORDER BY `+match+` DESC, properties.id ASC LIMIT 2$`).
+			WithArgs("iglesia centro", tenantID, "iglesia centro", models.StatusAvailable, "iglesia centro").
+			WillReturnRows(rankedRow(rankedRow(sqlmock.NewRows(rankedColumns), 4, 1.75), 9, 0.5))
+
+		filter := &models.PropertyFilter{Query: "iglesia centro", Status: models.StatusAvailable}
+		result, err := repository.NewPropertyRepository(db, tenantID).TextSearch(filter, page)
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, uint(4), result.Items[0].ID)
+		assert.Equal(t, 1.75, result.Items[0].Relevance)
+		assert.Equal(t, 2, result.Total)
+
+		cursor, err := models.DecodeCursor(result.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, []any{1.75}, cursor.Values)
+	})
+
+	t.Run("cursor continues below the relevance of the last match", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		cursor := (&models.Cursor{Sort: "-relevance", Values: []any{1.75}, ID: 4}).Encode()
+		page := &models.PageRequest{Limit: 1, Cursor: cursor}
+		require.NoError(t, page.Normalize(models.PropertyTextSortFields, "-relevance"))
+
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties`).
+			WithArgs(tenantID, "iglesia").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(`^SELECT .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL AND `+match+
+			` AND \(\(`+match+` < \?\) OR \(`+match+` = \? AND properties.id > \?\)\) ORDER BY `+match+` DESC, properties.id ASC LIMIT 2$`).
+			WithArgs("iglesia", tenantID, "iglesia", "iglesia", 1.75, "iglesia", 1.75, 4, "iglesia").
+			WillReturnRows(rankedRow(sqlmock.NewRows(rankedColumns), 9, 0.5))
+
+		result, err := repository.NewPropertyRepository(db, tenantID).TextSearch(&models.PropertyFilter{Query: "iglesia"}, page)
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		assert.Equal(t, uint(9), result.Items[0].ID)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("refuses an empty query", func(t *testing.T) {
+		db, _ := newMockDB(t)
+		_, err := repository.NewPropertyRepository(db, tenantID).TextSearch(&models.PropertyFilter{}, &models.PageRequest{Limit: 1})
+		assert.ErrorIs(t, err, models.ErrInvalidFilter)
+	})
+}
@@ -0,0 +1,336 @@
+package repository_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/repository"
+)
+
+const selectProperties = `^SELECT .* FROM properties LEFT JOIN branches ON branches.id = properties.branch_id AND branches.tenant_id = properties.tenant_id WHERE properties.tenant_id = \? AND `
+
+// anyArgs returns n placeholders matching any value, with the tenant at index
+func anyArgs(n int, tenantIndex int) []driver.Value {
+	args := make([]driver.Value, n)
+	for i := range args {
+		args[i] = sqlmock.AnyArg()
+	}
+	if tenantIndex >= 0 {
+		args[tenantIndex] = tenantID
+	}
+	return args
+}
+
+// propertyRow adds a property in the column order of the property reads
+func propertyRow(rows *sqlmock.Rows, id uint, version uint) *sqlmock.Rows {
+	now := time.Now()
+	return rows.AddRow(id, "House", nil, "Av. Juarez 10", "Centro", "Monterrey", "", "", 100000.0, 120000.0, 0, 0, false, false,
+		1, 3, 2, 0, 0, nil, nil, nil, nil, "", 1, 5, 1, models.TypeHouse, models.TransactionSale, models.StatusAvailable,
+		now, now, version, nil, "Centro")
+}
+
+var propertyColumnNames = []string{
+	"id", "title", "listing_date", "address", "neighborhood", "city", "zone", "reference", "price", "original_price",
+	"construction_m2", "land_m2", "is_occupied", "is_furnished", "floors", "bedrooms", "bathrooms",
+	"garage_size", "garden_m2", "gas_types", "amenities", "extras", "utilities", "notes", "owner_id",
+	"user_id", "branch_id", "property_type", "transaction_type", "status", "created_at", "updated_at",
+	"version", "deleted_at", "branch_name",
+}
+
+func TestPropertyRepository_ReadsAreScopedToTenant(t *testing.T) {
+	t.Run("GetByID misses properties of other tenants", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := repository.NewPropertyRepository(db, tenantID).GetByID(3)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestPropertyRepository_Search(t *testing.T) {
+	t.Run("adds a condition per criterion", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		minPrice, maxPrice, minBedrooms, maxLand := 1000.0, 2500.0, 2, 300
+		furnished := false
+		conditions := `properties.deleted_at IS NULL` +
+			` AND properties.branch_id = \? AND properties.city = \? AND properties.property_type = \?` +
+			` AND properties.price >= \? AND properties.price <= \? AND properties.bedrooms >= \?` +
+			` AND properties.land_m2 <= \? AND properties.is_furnished = \?` +
+			` AND JSON_CONTAINS\(properties.amenities, JSON_QUOTE\(\?\)\) AND JSON_CONTAINS\(properties.amenities, JSON_QUOTE\(\?\)\) ORDER BY`
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties`).
+			WithArgs(tenantID, 2, "Monterrey", models.TypeHouse, minPrice, maxPrice, minBedrooms, maxLand, furnished, "pool", "gym").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(selectProperties+conditions).
+			WithArgs(tenantID, 2, "Monterrey", models.TypeHouse, minPrice, maxPrice, minBedrooms, maxLand, furnished, "pool", "gym").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		properties, err := repository.NewPropertyRepository(db, tenantID).Search(&models.PropertyFilter{
+			BranchID:     2,
+			City:         "Monterrey",
+			PropertyType: models.TypeHouse,
+			MinPrice:     &minPrice,
+			MaxPrice:     &maxPrice,
+			MinBedrooms:  &minBedrooms,
+			MaxLandM2:    &maxLand,
+			IsFurnished:  &furnished,
+			Amenities:    []string{"pool", "gym"},
+		}, searchPageRequest(t))
+		require.NoError(t, err)
+		assert.Empty(t, properties.Items)
+	})
+
+	t.Run("an empty filter lists the tenant's properties", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties .* WHERE properties.tenant_id = \? AND properties.deleted_at IS NULL$`).
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(selectProperties + `properties.deleted_at IS NULL ORDER BY`).
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := repository.NewPropertyRepository(db, tenantID).Search(&models.PropertyFilter{}, searchPageRequest(t))
+		assert.NoError(t, err)
+	})
+
+	t.Run("every search requires a page", func(t *testing.T) {
+		db, _ := newMockDB(t)
+		properties := repository.NewPropertyRepository(db, tenantID)
+
+		_, err := properties.Search(&models.PropertyFilter{}, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidPage)
+		_, err = properties.SearchCards(&models.PropertyFilter{}, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidPage)
+		_, err = properties.TextSearch(&models.PropertyFilter{Query: "iglesia"}, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidPage)
+	})
+}
+
+// searchPageRequest is the first page of a property search with the defaults
+func searchPageRequest(t *testing.T) *models.PageRequest {
+	t.Helper()
+	page := &models.PageRequest{}
+	require.NoError(t, page.Normalize(models.PropertySortFields, "-created_at"))
+	return page
+}
+
+func TestPropertyRepository_CreateStoresTenant(t *testing.T) {
+	db, mock := newMockDB(t)
+	// tenant_id follows the 26 columns up to branch_id
+	mock.ExpectExec(`^INSERT INTO properties \(.*price,original_price,.*branch_id,tenant_id,property_type.*\) VALUES`).
+		WithArgs(anyArgs(30, 26)...).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+
+	property := &models.Property{Title: "House", BranchID: 1, Price: 100000}
+	created, err := repository.NewPropertyRepository(db, tenantID).Create(property)
+	require.NoError(t, err)
+	assert.Equal(t, tenantID, property.TenantID)
+	assert.Equal(t, 100000.0, property.OriginalPrice, "The first price is the original one")
+	assert.False(t, created.PriceReduced)
+}
+
+func TestPropertyRepository_WritesAreScopedToTenant(t *testing.T) {
+	t.Run("Update", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET .*, updated_at = NOW\(\), version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs(append(anyArgs(28, -1), tenantID, 3, 4)...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		property, err := repository.NewPropertyRepository(db, tenantID).Update(&models.Property{ID: 3, Title: "House", Price: 100000, Version: 4})
+		require.NoError(t, err)
+		assert.Equal(t, uint(5), property.Version)
+		assert.Equal(t, "Centro", property.BranchName)
+		assert.Equal(t, 120000.0, property.OriginalPrice, "The stored original price is kept")
+		assert.True(t, property.PriceReduced)
+	})
+
+	t.Run("Update records a price change with the write", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE properties SET .* WHERE tenant_id = \? AND id = \? AND version = \?`).
+			WithArgs(append(anyArgs(28, -1), tenantID, 3, 4)...).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO property_price_changes \(property_id,tenant_id,old_price,new_price,reason,changed_by,changed_at\) VALUES`).
+			WithArgs(3, tenantID, 100000.0, 95000.0, "slow season", 5, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(8, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		change := &models.PropertyPriceChange{PropertyID: 3, OldPrice: 100000, NewPrice: 95000, Reason: "slow season", ChangedBy: 5}
+		_, err := repository.NewPropertyRepository(db, tenantID).Update(&models.Property{ID: 3, Price: 95000, Version: 4, PriceChange: change})
+		require.NoError(t, err)
+		assert.Equal(t, uint(8), change.ID)
+	})
+
+	t.Run("a stale update records no price change", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE properties SET .* WHERE tenant_id = \? AND id = \? AND version = \?`).
+			WithArgs(append(anyArgs(28, -1), tenantID, 3, 4)...).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		change := &models.PropertyPriceChange{PropertyID: 3, OldPrice: 100000, NewPrice: 95000, ChangedBy: 5}
+		_, err := repository.NewPropertyRepository(db, tenantID).Update(&models.Property{ID: 3, Price: 95000, Version: 4, PriceChange: change})
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+	})
+
+	t.Run("Update misses properties of other tenants", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET .* WHERE tenant_id = \? AND id = \?`).
+			WithArgs(append(anyArgs(28, -1), tenantID, 3, 4)...).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(sqlmock.NewRows(propertyColumnNames))
+
+		_, err := repository.NewPropertyRepository(db, tenantID).Update(&models.Property{ID: 3, Title: "House", Version: 4})
+		assert.EqualError(t, err, "property not found or already deleted")
+	})
+
+	t.Run("Update of a stale version", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET .* WHERE tenant_id = \? AND id = \? AND version = \?`).
+			WithArgs(append(anyArgs(28, -1), tenantID, 3, 4)...).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		_, err := repository.NewPropertyRepository(db, tenantID).Update(&models.Property{ID: 3, Title: "House", Version: 4})
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		assert.ErrorContains(t, err, "property 3 is at version 5")
+	})
+
+	t.Run("Patch writes only the given fields", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET notes = \?, price = \?, updated_at = NOW\(\), version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs("price drop", 95000.0, tenantID, 3, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		property := &models.Property{ID: 3, Title: "House", Price: 95000, Notes: "price drop", Version: 4}
+		patched, err := repository.NewPropertyRepository(db, tenantID).Patch(property, []string{"notes", "price"})
+		require.NoError(t, err)
+		assert.Equal(t, uint(5), patched.Version)
+	})
+
+	t.Run("Patch refuses unknown fields", func(t *testing.T) {
+		db, _ := newMockDB(t)
+
+		_, err := repository.NewPropertyRepository(db, tenantID).Patch(&models.Property{ID: 3}, []string{"tenant_id"})
+		assert.ErrorIs(t, err, models.ErrInvalidPatch)
+	})
+
+	t.Run("UpdateAgent", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET user_id = \?, updated_at = NOW\(\), version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs(9, tenantID, 3, 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repository.NewPropertyRepository(db, tenantID).UpdateAgent(3, 4, 9))
+	})
+
+	t.Run("UpdateAgent of a stale version", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET user_id = \?`).
+			WithArgs(9, tenantID, 3, 4).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		err := repository.NewPropertyRepository(db, tenantID).UpdateAgent(3, 4, 9)
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE properties SET deleted_at = NOW\(\), version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs(tenantID, 3, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(sqlmock.NewRows(propertyColumnNames))
+
+		err := repository.NewPropertyRepository(db, tenantID).Delete(3, 2)
+		assert.EqualError(t, err, "property not found or already deleted")
+	})
+}
+
+func TestPropertyRepository_ChangeStatus(t *testing.T) {
+	const changeStatus = `^UPDATE properties SET status = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \? AND status = \? AND deleted_at IS NULL$`
+
+	t.Run("moves the property and records the change", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(changeStatus).
+			WithArgs(models.StatusReserved, sqlmock.AnyArg(), tenantID, 3, models.StatusAvailable).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^INSERT INTO property_status_changes \(property_id,tenant_id,from_status,to_status,reason,changed_by,changed_at\) VALUES`).
+			WithArgs(3, tenantID, models.StatusAvailable, models.StatusReserved, "deposit received", 5, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+
+		change := &models.PropertyStatusChange{PropertyID: 3, FromStatus: models.StatusAvailable, ToStatus: models.StatusReserved, Reason: "deposit received", ChangedBy: 5}
+		_, err := repository.NewPropertyRepository(db, tenantID).ChangeStatus(change)
+		require.NoError(t, err)
+		assert.Equal(t, uint(11), change.ID)
+		assert.Equal(t, tenantID, change.TenantID)
+		assert.False(t, change.ChangedAt.IsZero())
+	})
+
+	t.Run("refuses a property that changed status meanwhile", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(changeStatus).
+			WithArgs(models.StatusSold, sqlmock.AnyArg(), tenantID, 3, models.StatusReserved).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(selectProperties+`\(properties.id = \? AND properties.deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 3).
+			WillReturnRows(propertyRow(sqlmock.NewRows(propertyColumnNames), 3, 5))
+		mock.ExpectRollback()
+
+		change := &models.PropertyStatusChange{PropertyID: 3, FromStatus: models.StatusReserved, ToStatus: models.StatusSold, Reason: "closed", ChangedBy: 5}
+		_, err := repository.NewPropertyRepository(db, tenantID).ChangeStatus(change)
+		assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+		assert.ErrorContains(t, err, "the property is now available")
+	})
+}
+
+func TestPropertyRepository_GetPriceHistory(t *testing.T) {
+	db, mock := newMockDB(t)
+	changedAt := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`^SELECT id, property_id, tenant_id, old_price, new_price, reason, changed_by, changed_at FROM property_price_changes WHERE property_id = \? AND tenant_id = \? ORDER BY changed_at ASC, id ASC$`).
+		WithArgs(3, tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "property_id", "tenant_id", "old_price", "new_price", "reason", "changed_by", "changed_at"}).
+			AddRow(1, 3, tenantID, 120000.0, 110000.0, "", 5, changedAt).
+			AddRow(2, 3, tenantID, 110000.0, 105000.0, "offer expected", 5, changedAt.Add(time.Hour)))
+
+	changes, err := repository.NewPropertyRepository(db, tenantID).GetPriceHistory(3)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, 120000.0, changes[0].OldPrice)
+	assert.Equal(t, "offer expected", changes[1].Reason)
+}
@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/repository"
+)
+
+func TestUserIdentityRepository_ScopedToTenant(t *testing.T) {
+	t.Run("looks up subjects linked at the tenant", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT id, user_id, issuer, subject, email, created_at, last_login_at FROM user_identities WHERE tenant_id = \? AND issuer = \? AND subject = \?$`).
+			WithArgs(tenantID, "https://idp.example.com", "sub-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "issuer", "subject", "email", "created_at", "last_login_at"}))
+
+		identity, err := repository.NewUserIdentityRepository(db, tenantID).GetBySubject("https://idp.example.com", "sub-1")
+		require.NoError(t, err)
+		assert.Nil(t, identity, "A subject linked at another tenant is not linked here")
+	})
+
+	t.Run("links the identity at the tenant", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^INSERT INTO user_identities \(tenant_id,user_id,issuer,subject,email,created_at,last_login_at\)`).
+			WithArgs(tenantID, 4, "https://idp.example.com", "sub-1", "ana@example.com", sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(9, 1))
+
+		identity := &models.UserIdentity{UserID: 4, Issuer: "https://idp.example.com", Subject: "sub-1", Email: "ana@example.com"}
+		require.NoError(t, repository.NewUserIdentityRepository(db, tenantID).Create(identity))
+		assert.Equal(t, uint(9), identity.ID)
+		assert.Equal(t, tenantID, identity.TenantID)
+	})
+
+	t.Run("records logins of the tenant's links", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE user_identities SET last_login_at = \? WHERE id = \? AND tenant_id = \?$`).
+			WithArgs(sqlmock.AnyArg(), 9, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repository.NewUserIdentityRepository(db, tenantID).TouchLastLogin(9, time.Now()))
+	})
+}
+
+func TestSSOStateRepository_ScopedToTenant(t *testing.T) {
+	t.Run("stores the attempt at the tenant", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^INSERT INTO sso_login_states \(tenant_id,state_hash,code_verifier,nonce,expires_at,created_at\)`).
+			WithArgs(tenantID, "hash", "verifier", "nonce", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+
+		state := &models.SSOLoginState{StateHash: "hash", CodeVerifier: "verifier", Nonce: "nonce", ExpiresAt: time.Now().Add(time.Minute)}
+		require.NoError(t, repository.NewSSOStateRepository(db, tenantID).Create(state))
+		assert.Equal(t, tenantID, state.TenantID)
+	})
+
+	t.Run("does not find attempts started at another tenant", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM sso_login_states WHERE tenant_id = \? AND state_hash = \?$`).
+			WithArgs(tenantID, "hash").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "state_hash", "code_verifier", "nonce", "expires_at", "used_at", "created_at"}))
+
+		_, err := repository.NewSSOStateRepository(db, tenantID).GetByStateHash("hash")
+		assert.EqualError(t, err, "single sign-on state not found")
+	})
+
+	t.Run("consumes the tenant's attempt once", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE sso_login_states SET used_at = \? WHERE id = \? AND tenant_id = \? AND used_at IS NULL$`).
+			WithArgs(sqlmock.AnyArg(), 3, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.EqualError(t, repository.NewSSOStateRepository(db, tenantID).Consume(3), "single sign-on state already used")
+	})
+}
@@ -0,0 +1,417 @@
+package repository_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/domain/ports"
+	"inmo-backend/internal/infrastructure/repository"
+)
+
+const tenantID uint = 7
+
+// tenantTables are the tables whose statements must always carry tenant_id
+var tenantTables = regexp.MustCompile(`\b(FROM|UPDATE|INTO|JOIN)\s+(users|properties|user_identities|sso_login_states)\b`)
+
+// tenantMatcher matches like the default regexp matcher of sqlmock, but
+// first fails any statement on a tenant table that does not mention the
+// tenant, so a query that could leak across tenants breaks every test.
+var tenantMatcher = sqlmock.QueryMatcherFunc(func(expectedSQL string, actualSQL string) error {
+	if tenantTables.MatchString(actualSQL) && !regexp.MustCompile(`\btenant_id\b`).MatchString(actualSQL) {
+		return fmt.Errorf("statement is not scoped to a tenant: %s", actualSQL)
+	}
+	return sqlmock.QueryMatcherRegexp.Match(expectedSQL, actualSQL)
+})
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(tenantMatcher))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+	return db, mock
+}
+
+var userColumnNames = []string{
+	"id", "username", "email", "email_verified_at", "role", "branch_id", "tenant_id",
+	"full_name", "phone", "whatsapp", "license_number", "bio", "avatar_url", "commission_rate",
+	"created_at", "updated_at", "version", "deactivated_at", "deleted_at",
+}
+
+func userRow(rows *sqlmock.Rows, id uint, email string) *sqlmock.Rows {
+	now := time.Now()
+	return rows.AddRow(id, "agent", email, now, models.RoleAgent, 1, tenantID,
+		"", "", "", "", "", "", 0.0, now, now, 7, nil, nil)
+}
+
+func TestUserRepository_ReadsAreScopedToTenant(t *testing.T) {
+	t.Run("GetByID", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \? AND deleted_at IS NULL\)$`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 5, "agent@example.com"))
+
+		user, err := repository.NewUserRepository(db, tenantID).GetByID(5)
+		require.NoError(t, err)
+		assert.Equal(t, tenantID, user.TenantID)
+	})
+
+	t.Run("GetByID misses users of other tenants", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		// The row exists in tenant 8, the database filters it out
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+		_, err := repository.NewUserRepository(db, tenantID).GetByID(5)
+		assert.EqualError(t, err, "user not found")
+	})
+
+	t.Run("GetByEmail", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND email = \? AND deleted_at IS NULL$`).
+			WithArgs(tenantID, "agent@example.com").
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+		_, err := repository.NewUserRepository(db, tenantID).GetByEmail("agent@example.com")
+		assert.EqualError(t, err, "user not found")
+	})
+
+	t.Run("ConsultPassword", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT password FROM users WHERE tenant_id = \? AND email = \? AND deleted_at IS NULL$`).
+			WithArgs(tenantID, "agent@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"password"}))
+
+		_, err := repository.NewUserRepository(db, tenantID).ConsultPassword("agent@example.com")
+		assert.EqualError(t, err, "user not found")
+	})
+
+	t.Run("GetDeleted", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND deleted_at IS NOT NULL ORDER BY deleted_at DESC$`).
+			WithArgs(tenantID).
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+		users, err := repository.NewUserRepository(db, tenantID).GetDeleted(0)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("GetDeleted of a branch", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND deleted_at IS NOT NULL AND branch_id = \? ORDER BY deleted_at DESC$`).
+			WithArgs(tenantID, 2).
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+
+		users, err := repository.NewUserRepository(db, tenantID).GetDeleted(2)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("GetByIDIncludingDeleted", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND id = \?$`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 5, "agent@example.com"))
+
+		user, err := repository.NewUserRepository(db, tenantID).GetByIDIncludingDeleted(5)
+		require.NoError(t, err)
+		assert.Equal(t, uint(5), user.ID)
+	})
+}
+
+func TestUserRepository_WritesAreScopedToTenant(t *testing.T) {
+	anyArg := sqlmock.AnyArg()
+	tests := []struct {
+		name string
+		sql  string
+		args []driver.Value
+		call func(repo ports.UserRepository) error
+	}{
+		{
+			"Create", `^INSERT INTO users \(.*tenant_id.*\) VALUES`,
+			[]driver.Value{"agent", "agent@example.com", anyArg, "hash", models.RoleAgent, 1, tenantID, anyArg, anyArg},
+			func(repo ports.UserRepository) error {
+				user, err := repo.Create(&models.User{Username: "agent", Email: "agent@example.com", Password: "hash", Role: models.RoleAgent, BranchID: 1})
+				if err == nil && user.TenantID != tenantID {
+					return fmt.Errorf("user created in tenant %d", user.TenantID)
+				}
+				return err
+			},
+		},
+		{
+			"Update", `^UPDATE users SET username = \?, email = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`,
+			[]driver.Value{"agent", "agent@example.com", anyArg, tenantID, 5, 2},
+			func(repo ports.UserRepository) error {
+				user, err := repo.Update(&models.User{ID: 5, Username: "agent", Email: "agent@example.com", Version: 2})
+				if err == nil && user.Version != 3 {
+					return fmt.Errorf("user is at version %d after the update", user.Version)
+				}
+				return err
+			},
+		},
+		{
+			"UpdateProfile", `^UPDATE users SET .* WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`,
+			[]driver.Value{anyArg, anyArg, anyArg, anyArg, anyArg, anyArg, anyArg, anyArg, tenantID, 5, 2},
+			func(repo ports.UserRepository) error {
+				return repo.UpdateProfile(5, 2, &models.UserProfile{FullName: "Ana"})
+			},
+		},
+		{
+			"UpdatePassword", `^UPDATE users SET password = \?, updated_at = \? WHERE tenant_id = \? AND id = \?`,
+			[]driver.Value{"hash", anyArg, tenantID, 5},
+			func(repo ports.UserRepository) error { return repo.UpdatePassword(5, "hash") },
+		},
+		{
+			"MarkEmailVerified", `^UPDATE users SET email_verified_at = COALESCE\(email_verified_at, \?\), updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \?`,
+			[]driver.Value{anyArg, anyArg, tenantID, 5},
+			func(repo ports.UserRepository) error { return repo.MarkEmailVerified(5) },
+		},
+		{
+			"Restore", `^UPDATE users SET deleted_at = \?, deactivated_at = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \?`,
+			[]driver.Value{nil, nil, anyArg, tenantID, 5},
+			func(repo ports.UserRepository) error { return repo.Restore(5) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			mock.ExpectExec(tt.sql).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(5, 1))
+
+			assert.NoError(t, tt.call(repository.NewUserRepository(db, tenantID)))
+		})
+	}
+}
+
+func TestUserRepository_ReportsTakenUsernamesAndEmails(t *testing.T) {
+	t.Run("Create", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^INSERT INTO users`).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '7-agent@example.com' for key 'users.idx_users_tenant_email'"})
+
+		_, err := repository.NewUserRepository(db, tenantID).Create(&models.User{Username: "agent", Email: "agent@example.com", Password: "hash"})
+		assert.ErrorIs(t, err, models.ErrEmailTaken)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^UPDATE users SET username = \?`).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '7-agent' for key 'users.idx_users_tenant_username'"})
+
+		_, err := repository.NewUserRepository(db, tenantID).Update(&models.User{ID: 5, Username: "agent", Email: "agent@example.com", Version: 2})
+		assert.ErrorIs(t, err, models.ErrUsernameTaken)
+	})
+
+	t.Run("other errors pass through", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectExec(`^INSERT INTO users`).WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"})
+
+		_, err := repository.NewUserRepository(db, tenantID).Create(&models.User{Username: "agent", Email: "agent@example.com", Password: "hash"})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, models.ErrEmailTaken)
+	})
+}
+
+func TestUserRepository_UpdateRoleRevokesAccess(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE users SET role = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+		WithArgs(models.RoleAdmin, sqlmock.AnyArg(), tenantID, 5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`^UPDATE sessions SET revoked_at = \? WHERE user_id = \? AND revoked_at IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`^UPDATE api_keys SET revoked_at = \? WHERE user_id = \? AND revoked_at IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repository.NewUserRepository(db, tenantID).UpdateRole(5, 2, models.RoleAdmin))
+}
+
+func TestUserRepository_UpdateBranch(t *testing.T) {
+	t.Run("moves the user and revokes access", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE users SET branch_id = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs(3, sqlmock.AnyArg(), tenantID, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE sessions SET revoked_at = \? WHERE user_id = \? AND revoked_at IS NULL$`).
+			WithArgs(sqlmock.AnyArg(), 5).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`^UPDATE api_keys SET revoked_at = \? WHERE user_id = \? AND revoked_at IS NULL$`).
+			WithArgs(sqlmock.AnyArg(), 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repository.NewUserRepository(db, tenantID).UpdateBranch(5, 2, 3))
+	})
+
+	t.Run("refuses a stale version", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE users SET branch_id = \?`).
+			WithArgs(3, sqlmock.AnyArg(), tenantID, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \?`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 5, "agent@example.com"))
+		mock.ExpectRollback()
+
+		err := repository.NewUserRepository(db, tenantID).UpdateBranch(5, 2, 3)
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+	})
+}
+
+func TestUserRepository_UpdateMissesUsersOfOtherTenants(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE users SET role = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \?`).
+		WithArgs(models.RoleAdmin, sqlmock.AnyArg(), tenantID, 5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \?`).
+		WithArgs(tenantID, 5).
+		WillReturnRows(sqlmock.NewRows(userColumnNames))
+	mock.ExpectRollback()
+
+	err := repository.NewUserRepository(db, tenantID).UpdateRole(5, 2, models.RoleAdmin)
+	assert.EqualError(t, err, "user not found or already deleted")
+}
+
+func TestUserRepository_UpdateRoleRefusesStaleVersion(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE users SET role = \?`).
+		WithArgs(models.RoleAdmin, sqlmock.AnyArg(), tenantID, 5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \?`).
+		WithArgs(tenantID, 5).
+		WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 5, "agent@example.com"))
+	mock.ExpectRollback()
+
+	err := repository.NewUserRepository(db, tenantID).UpdateRole(5, 2, models.RoleAdmin)
+	assert.ErrorIs(t, err, models.ErrStaleVersion)
+}
+
+func TestUserRepository_DeleteIsScopedToTenant(t *testing.T) {
+	t.Run("deletes and revokes access", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE users SET deleted_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \? AND version = \? AND deleted_at IS NULL$`).
+			WithArgs(sqlmock.AnyArg(), tenantID, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`^UPDATE sessions SET revoked_at`).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(`^UPDATE api_keys SET revoked_at`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repository.NewUserRepository(db, tenantID).Delete(5, 2))
+	})
+
+	t.Run("leaves the sessions of other tenants alone", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE users SET deleted_at = \?, version = version \+ 1 WHERE tenant_id = \?`).
+			WithArgs(sqlmock.AnyArg(), tenantID, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \?`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(sqlmock.NewRows(userColumnNames))
+		mock.ExpectRollback()
+
+		err := repository.NewUserRepository(db, tenantID).Delete(5, 2)
+		assert.EqualError(t, err, "user not found or already deleted")
+	})
+
+	t.Run("refuses a stale version", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`^UPDATE users SET deleted_at = \?, version = version \+ 1 WHERE tenant_id = \?`).
+			WithArgs(sqlmock.AnyArg(), tenantID, 5, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`^SELECT .* FROM users WHERE tenant_id = \? AND \(id = \?`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(userRow(sqlmock.NewRows(userColumnNames), 5, "agent@example.com"))
+		mock.ExpectRollback()
+
+		err := repository.NewUserRepository(db, tenantID).Delete(5, 2)
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+	})
+}
+
+func TestUserRepository_DeactivateIsScopedToTenant(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`^UPDATE users SET deactivated_at = \?, updated_at = \?, version = version \+ 1 WHERE tenant_id = \? AND id = \?`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), tenantID, 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repository.NewUserRepository(db, tenantID).Deactivate(5)
+	assert.EqualError(t, err, "user not found or already deactivated")
+}
+
+func TestUserRepository_PurgeIsScopedToTenant(t *testing.T) {
+	t.Run("purges a deleted user of the tenant", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^SELECT deleted_at FROM users WHERE tenant_id = \? AND id = \? FOR UPDATE$`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}).AddRow(time.Now()))
+		mock.ExpectQuery(`^SELECT COUNT\(\*\) FROM properties WHERE tenant_id = \? AND \(user_id = \? OR owner_id = \?\)$`).
+			WithArgs(tenantID, 5, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`^UPDATE invitations SET user_id = \? WHERE tenant_id = \? AND user_id = \?$`).
+			WithArgs(nil, tenantID, 5).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`^UPDATE invitations SET invited_by = \? WHERE invited_by = \? AND tenant_id = \?$`).
+			WithArgs(nil, 5, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`^DELETE FROM user_identities WHERE tenant_id = \? AND user_id = \?$`).
+			WithArgs(tenantID, 5).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, table := range []string{"sessions", "password_reset_tokens", "email_verification_tokens", "recovery_codes", "two_factor_credentials", "api_keys"} {
+			mock.ExpectExec(`^DELETE FROM ` + table + ` WHERE user_id = \?$`).
+				WithArgs(5).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(`^DELETE FROM users WHERE id = \? AND tenant_id = \?$`).
+			WithArgs(5, tenantID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repository.NewUserRepository(db, tenantID).Purge(5))
+	})
+
+	t.Run("does not see users of other tenants", func(t *testing.T) {
+		db, mock := newMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`^SELECT deleted_at FROM users WHERE tenant_id = \? AND id = \? FOR UPDATE$`).
+			WithArgs(tenantID, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"deleted_at"}))
+		mock.ExpectRollback()
+
+		err := repository.NewUserRepository(db, tenantID).Purge(5)
+		assert.EqualError(t, err, "user not found")
+	})
+}
+
+func TestTenantMatcher_RejectsUnscopedStatements(t *testing.T) {
+	assert.Error(t, tenantMatcher.Match(`.*`, "SELECT id FROM users WHERE id = ?"))
+	assert.Error(t, tenantMatcher.Match(`.*`, "UPDATE properties SET status = ? WHERE id = ?"))
+	assert.NoError(t, tenantMatcher.Match(`.*`, "SELECT id FROM users WHERE tenant_id = ? AND id = ?"))
+	assert.NoError(t, tenantMatcher.Match(`.*`, "DELETE FROM sessions WHERE user_id = ?"))
+}
@@ -0,0 +1,184 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(key *models.APIKey) (*models.APIKey, error) {
+	args := m.Called(key)
+	created, _ := args.Get(0).(*models.APIKey)
+	return created, args.Error(1)
+}
+func (m *MockAPIKeyRepository) GetByHash(hash string) (*models.APIKey, error) {
+	args := m.Called(hash)
+	key, _ := args.Get(0).(*models.APIKey)
+	return key, args.Error(1)
+}
+func (m *MockAPIKeyRepository) GetAll(userID uint) ([]models.APIKey, error) {
+	args := m.Called(userID)
+	keys, _ := args.Get(0).([]models.APIKey)
+	return keys, args.Error(1)
+}
+func (m *MockAPIKeyRepository) Revoke(id uint, userID uint) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+func (m *MockAPIKeyRepository) TouchLastUsed(id uint, now time.Time) error {
+	args := m.Called(id, now)
+	return args.Error(0)
+}
+
+// createdKey makes the mock repository return the key it was given, with an ID
+func createdKey(repo *MockAPIKeyRepository) *models.APIKey {
+	saved := &models.APIKey{}
+	repo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		*saved = *args.Get(0).(*models.APIKey)
+		saved.ID = 7
+	}).Return(saved, nil)
+	return saved
+}
+
+func TestAPIKeyUseCase_CreateAPIKey(t *testing.T) {
+	admin := &models.Caller{UserID: 1, SessionID: 2, Role: models.RoleAdmin}
+
+	t.Run("returns the raw key once and stores only its hash", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+		saved := createdKey(repo)
+
+		created, err := uc.CreateAPIKey(admin, &models.CreateAPIKeyData{
+			Name:   "  Website  ",
+			Scopes: []models.Permission{models.PermPropertiesRead, models.PermLeadsWrite, models.PermPropertiesRead},
+		})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Key, "inmo_"))
+		assert.Equal(t, uint(7), created.ID)
+		assert.Equal(t, "Website", created.Name)
+		assert.Equal(t, created.Key[:12], created.Prefix)
+		assert.Equal(t, []models.Permission{models.PermPropertiesRead, models.PermLeadsWrite}, created.Scopes)
+
+		assert.Equal(t, uint(1), saved.UserID)
+		assert.Equal(t, middleware.HashToken(created.Key), saved.KeyHash)
+		assert.NotContains(t, saved.KeyHash, created.Key)
+		assert.Equal(t, "properties:read,leads:write", saved.Scopes)
+	})
+
+	t.Run("rejects unknown scopes and scopes reserved for people", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		for _, scope := range []models.Permission{"properties:everything", models.PermUsersManage, models.PermAPIKeysManage} {
+			_, err := uc.CreateAPIKey(admin, &models.CreateAPIKeyData{Name: "Portal", Scopes: []models.Permission{scope}})
+			assert.EqualError(t, err, `invalid scope "`+string(scope)+`"`)
+		}
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("cannot grant more than the creator has", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		agent := &models.Caller{UserID: 4, SessionID: 5, Role: models.RoleAgent}
+		_, err := uc.CreateAPIKey(agent, &models.CreateAPIKeyData{Name: "Portal", Scopes: []models.Permission{models.PermPropertiesAssign}})
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("requires a name, a scope and a future expiry", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		past := time.Now().Add(-time.Hour)
+		_, err := uc.CreateAPIKey(admin, &models.CreateAPIKeyData{Scopes: []models.Permission{models.PermPropertiesRead}})
+		assert.EqualError(t, err, "name cannot be empty")
+		_, err = uc.CreateAPIKey(admin, &models.CreateAPIKeyData{Name: "Portal"})
+		assert.EqualError(t, err, "at least one scope is required")
+		_, err = uc.CreateAPIKey(admin, &models.CreateAPIKeyData{Name: "Portal", Scopes: []models.Permission{models.PermPropertiesRead}, ExpiresAt: &past})
+		assert.EqualError(t, err, "expiry must be in the future")
+		repo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestAPIKeyUseCase_GetAPIKeys(t *testing.T) {
+	t.Run("lists the caller's own keys", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		lastUsed := time.Now().Add(-time.Hour)
+		repo.On("GetAll", uint(1)).Return([]models.APIKey{
+			{ID: 1, UserID: 1, Name: "Website", Prefix: "inmo_abcdefg", KeyHash: "secret-hash", Scopes: "properties:read", LastUsedAt: &lastUsed},
+		}, nil)
+
+		keys, err := uc.GetAPIKeys(&models.Caller{UserID: 1, SessionID: 2, Role: models.RoleAdmin, BranchID: 1})
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, []models.Permission{models.PermPropertiesRead}, keys[0].Scopes)
+		assert.Equal(t, &lastUsed, keys[0].LastUsedAt)
+	})
+
+	t.Run("lists every key to a regional admin", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		repo.On("GetAll", uint(0)).Return([]models.APIKey{{ID: 1, UserID: 4}, {ID: 2, UserID: 5}}, nil)
+
+		keys, err := uc.GetAPIKeys(&models.Caller{UserID: 1, SessionID: 2, Role: models.RoleRegionalAdmin})
+		require.NoError(t, err)
+		assert.Len(t, keys, 2)
+	})
+
+	t.Run("requires a caller", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		_, err := uc.GetAPIKeys(nil)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		repo.AssertNotCalled(t, "GetAll", mock.Anything)
+	})
+}
+
+func TestAPIKeyUseCase_RevokeAPIKey(t *testing.T) {
+	t.Run("revokes only the caller's own keys", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		repo.On("Revoke", uint(3), uint(1)).Return(nil)
+
+		admin := &models.Caller{UserID: 1, SessionID: 2, Role: models.RoleAdmin, BranchID: 1}
+		require.NoError(t, uc.RevokeAPIKey(admin, 3))
+		assert.Error(t, uc.RevokeAPIKey(admin, 0))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("a regional admin revokes any key", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		repo.On("Revoke", uint(3), uint(0)).Return(nil)
+
+		require.NoError(t, uc.RevokeAPIKey(&models.Caller{UserID: 1, SessionID: 2, Role: models.RoleRegionalAdmin}, 3))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("requires a caller", func(t *testing.T) {
+		repo := new(MockAPIKeyRepository)
+		uc := usecase.NewAPIKeyUseCase(repo)
+
+		assert.ErrorIs(t, uc.RevokeAPIKey(nil, 3), models.ErrForbidden)
+		repo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,112 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+)
+
+type MockBranchRepository struct {
+	mock.Mock
+}
+
+func (m *MockBranchRepository) GetAll() ([]models.Branch, error) {
+	args := m.Called()
+	branches, _ := args.Get(0).([]models.Branch)
+	return branches, args.Error(1)
+}
+func (m *MockBranchRepository) GetByID(id uint) (*models.Branch, error) {
+	args := m.Called(id)
+	branch, _ := args.Get(0).(*models.Branch)
+	return branch, args.Error(1)
+}
+func (m *MockBranchRepository) Create(branch *models.Branch) error {
+	args := m.Called(branch)
+	return args.Error(0)
+}
+func (m *MockBranchRepository) Update(branch *models.Branch) error {
+	args := m.Called(branch)
+	return args.Error(0)
+}
+
+func TestBranchUseCase_CreateBranch(t *testing.T) {
+	t.Run("trims the fields", func(t *testing.T) {
+		branchRepo := new(MockBranchRepository)
+		uc := usecase.NewBranchUseCase(branchRepo, new(MockUserRepository))
+
+		branchRepo.On("Create", mock.MatchedBy(func(branch *models.Branch) bool {
+			return branch.Name == "Guadalajara" && branch.City == "Guadalajara"
+		})).Return(nil)
+
+		branch, err := uc.CreateBranch(&models.BranchData{Name: " Guadalajara ", City: "Guadalajara "})
+		require.NoError(t, err)
+		assert.Equal(t, "Guadalajara", branch.Name)
+		branchRepo.AssertExpectations(t)
+	})
+
+	t.Run("requires a name", func(t *testing.T) {
+		branchRepo := new(MockBranchRepository)
+		uc := usecase.NewBranchUseCase(branchRepo, new(MockUserRepository))
+
+		_, err := uc.CreateBranch(&models.BranchData{Name: "  ", City: "Monterrey"})
+		assert.EqualError(t, err, "name cannot be empty")
+		branchRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestBranchUseCase_AssignUser(t *testing.T) {
+	regional := &models.Caller{UserID: 1, Role: models.RoleRegionalAdmin}
+
+	t.Run("moves the user to the branch", func(t *testing.T) {
+		branchRepo, userRepo := new(MockBranchRepository), new(MockUserRepository)
+		uc := usecase.NewBranchUseCase(branchRepo, userRepo)
+
+		branchRepo.On("GetByID", uint(3)).Return(&models.Branch{ID: 3, Name: "Guadalajara"}, nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, BranchID: 1, Version: 2}, nil).Once()
+		userRepo.On("UpdateBranch", uint(5), uint(2), uint(3)).Return(nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, BranchID: 3, Version: 3}, nil)
+
+		user, err := uc.AssignUser(regional, 5, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), user.BranchID)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("refuses a stale version", func(t *testing.T) {
+		branchRepo, userRepo := new(MockBranchRepository), new(MockUserRepository)
+		uc := usecase.NewBranchUseCase(branchRepo, userRepo)
+
+		branchRepo.On("GetByID", uint(3)).Return(&models.Branch{ID: 3, Name: "Guadalajara"}, nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, BranchID: 1, Version: 4}, nil)
+
+		_, err := uc.AssignUser(regional, 5, 2, 3)
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		userRepo.AssertNotCalled(t, "UpdateBranch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses an unknown branch", func(t *testing.T) {
+		branchRepo, userRepo := new(MockBranchRepository), new(MockUserRepository)
+		uc := usecase.NewBranchUseCase(branchRepo, userRepo)
+
+		branchRepo.On("GetByID", uint(9)).Return(nil, errors.New("branch not found"))
+
+		_, err := uc.AssignUser(regional, 5, 2, 9)
+		assert.EqualError(t, err, "branch not found")
+		userRepo.AssertNotCalled(t, "UpdateBranch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a branch", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		uc := usecase.NewBranchUseCase(new(MockBranchRepository), userRepo)
+
+		_, err := uc.AssignUser(regional, 5, 2, 0)
+		assert.Error(t, err)
+		userRepo.AssertNotCalled(t, "UpdateBranch", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,260 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
+)
+
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) Create(token *models.EmailVerificationToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+func (m *MockEmailVerificationRepository) GetByTokenHash(hash string) (*models.EmailVerificationToken, error) {
+	args := m.Called(hash)
+	token, _ := args.Get(0).(*models.EmailVerificationToken)
+	return token, args.Error(1)
+}
+func (m *MockEmailVerificationRepository) InvalidateByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+func (m *MockEmailVerificationRepository) Consume(token *models.EmailVerificationToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func verifiedNow() *time.Time {
+	now := time.Now()
+	return &now
+}
+
+func newTestEmailVerifier(verifications *MockEmailVerificationRepository, userRepo *MockUserRepository, mailer *MockMailer) *usecase.EmailVerifier {
+	return usecase.NewEmailVerifier(verifications, userRepo, mailer, time.Hour, "http://localhost:3000/verify-email")
+}
+
+// unusedEmailVerifier is for tests that must not send verification emails;
+// the mocks fail the test if one is sent.
+func unusedEmailVerifier() *usecase.EmailVerifier {
+	return newTestEmailVerifier(new(MockEmailVerificationRepository), new(MockUserRepository), new(MockMailer))
+}
+
+// acceptingEmailVerifier lets any verification email through.
+func acceptingEmailVerifier() *usecase.EmailVerifier {
+	verifications, mailer := new(MockEmailVerificationRepository), new(MockMailer)
+	verifications.On("InvalidateByUserID", mock.Anything).Return(nil).Maybe()
+	verifications.On("Create", mock.Anything).Return(nil).Maybe()
+	mailer.On("Send", mock.Anything).Return(nil).Maybe()
+	return newTestEmailVerifier(verifications, new(MockUserRepository), mailer)
+}
+
+// expectVerificationEmail records the stored token and the sent email.
+func expectVerificationEmail(verifications *MockEmailVerificationRepository, mailer *MockMailer, userID uint) (*models.EmailVerificationToken, *models.Email) {
+	stored, sent := new(models.EmailVerificationToken), new(models.Email)
+	verifications.On("InvalidateByUserID", userID).Return(nil)
+	verifications.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		*stored = *args.Get(0).(*models.EmailVerificationToken)
+	}).Return(nil)
+	mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+		*sent = *args.Get(0).(*models.Email)
+	}).Return(nil)
+	return stored, sent
+}
+
+func mailedVerificationToken(t *testing.T, sent *models.Email) string {
+	t.Helper()
+	_, after, found := strings.Cut(sent.Body, "verify-email?token=")
+	require.True(t, found)
+	return strings.Fields(after)[0]
+}
+
+func TestUserUseCase_Login_UnverifiedEmail(t *testing.T) {
+	mockRepo, mockSessions := new(MockUserRepository), new(MockSessionRepository)
+	uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	hash, err := middleware.HashPassword("mypassword123")
+	require.NoError(t, err)
+	mockRepo.On("ConsultPassword", "new@example.com").Return(hash, nil)
+	mockRepo.On("GetByEmail", "new@example.com").Return(&models.UserResponse{ID: 7, Email: "new@example.com"}, nil)
+
+	_, err = uc.Login("new@example.com", "mypassword123", models.ClientInfo{})
+	assert.ErrorIs(t, err, models.ErrEmailNotVerified)
+	mockSessions.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUserUseCase_CreateUser_SendsVerification(t *testing.T) {
+	mockRepo, verifications, mailer := new(MockUserRepository), new(MockEmailVerificationRepository), new(MockMailer)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, mailer))
+
+	mockRepo.On("Create", mock.Anything).Return(&models.UserResponse{ID: 3, Username: "ana", Email: "ana@example.com"}, nil)
+	stored, sent := expectVerificationEmail(verifications, mailer, 3)
+
+	_, err := uc.CreateUser(&models.User{Username: "ana", Email: "ana@example.com", Password: "testpassword"})
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", sent.To)
+	assert.Equal(t, "ana@example.com", stored.Email)
+	assert.Equal(t, middleware.HashToken(mailedVerificationToken(t, sent)), stored.TokenHash)
+}
+
+func TestUserUseCase_EnsureAdmin_CreatesVerifiedAdmin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	mockRepo.On("GetByEmail", "boss@example.com").Return(nil, errors.New("user not found"))
+	mockRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Role == models.RoleRegionalAdmin && u.EmailVerifiedAt != nil
+	})).Return(&models.UserResponse{ID: 1, Role: models.RoleRegionalAdmin, EmailVerifiedAt: verifiedNow()}, nil)
+
+	require.NoError(t, uc.EnsureAdmin(&models.User{Username: "boss", Email: "boss@example.com", Password: "testpassword"}))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserUseCase_UpdateUser_EmailChange(t *testing.T) {
+	current := &models.UserResponse{ID: 3, Username: "ana", Email: "ana@example.com", EmailVerifiedAt: verifiedNow(), BranchID: 1}
+
+	t.Run("keeps the current email until the new one is verified", func(t *testing.T) {
+		mockRepo, verifications, mailer := new(MockUserRepository), new(MockEmailVerificationRepository), new(MockMailer)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, mailer))
+
+		mockRepo.On("GetByID", uint(3)).Return(current, nil)
+		mockRepo.On("GetByEmail", "ana.new@example.com").Return(nil, errors.New("user not found"))
+		mockRepo.On("Update", mock.MatchedBy(func(u *models.User) bool {
+			return u.Email == "ana@example.com" && u.Username == "ana.r"
+		})).Return(&models.UserResponse{ID: 3, Username: "ana.r", Email: "ana@example.com"}, nil)
+		stored, sent := expectVerificationEmail(verifications, mailer, 3)
+
+		user, err := uc.UpdateUser(adminCaller, &models.User{ID: 3, Username: "ana.r", Email: "ana.new@example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "ana@example.com", user.Email)
+		assert.Equal(t, "ana.new@example.com", sent.To)
+		assert.Equal(t, "ana.new@example.com", stored.Email)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an email used by another account", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByID", uint(3)).Return(current, nil)
+		mockRepo.On("GetByEmail", "bob@example.com").Return(&models.UserResponse{ID: 4, Email: "bob@example.com"}, nil)
+
+		_, err := uc.UpdateUser(adminCaller, &models.User{ID: 3, Username: "ana", Email: "bob@example.com"})
+		assert.ErrorIs(t, err, models.ErrEmailTaken)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestUserUseCase_VerifyEmail(t *testing.T) {
+	const rawToken = "verification-token"
+	hash := middleware.HashToken(rawToken)
+
+	t.Run("verifies the address on the token", func(t *testing.T) {
+		mockRepo, verifications := new(MockUserRepository), new(MockEmailVerificationRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, new(MockMailer)))
+
+		token := &models.EmailVerificationToken{ID: 9, UserID: 3, Email: "ana.new@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+		verifications.On("GetByTokenHash", hash).Return(token, nil)
+		mockRepo.On("GetByEmail", "ana.new@example.com").Return(nil, errors.New("user not found"))
+		verifications.On("Consume", token).Return(nil)
+		mockRepo.On("GetByID", uint(3)).Return(&models.UserResponse{ID: 3, Email: "ana.new@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+
+		user, err := uc.VerifyEmail(rawToken)
+		require.NoError(t, err)
+		assert.Equal(t, "ana.new@example.com", user.Email)
+		assert.True(t, user.IsEmailVerified())
+		verifications.AssertExpectations(t)
+	})
+
+	t.Run("rejects used or expired tokens", func(t *testing.T) {
+		usedAt := time.Now()
+		for _, token := range []*models.EmailVerificationToken{
+			{ID: 9, UserID: 3, Email: "ana@example.com", ExpiresAt: time.Now().Add(-time.Minute)},
+			{ID: 9, UserID: 3, Email: "ana@example.com", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt},
+		} {
+			mockRepo, verifications := new(MockUserRepository), new(MockEmailVerificationRepository)
+			uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, new(MockMailer)))
+
+			verifications.On("GetByTokenHash", hash).Return(token, nil)
+
+			_, err := uc.VerifyEmail(rawToken)
+			assert.EqualError(t, err, "invalid or expired verification token")
+			verifications.AssertNotCalled(t, "Consume", mock.Anything)
+		}
+	})
+
+	t.Run("rejects an address taken meanwhile", func(t *testing.T) {
+		mockRepo, verifications := new(MockUserRepository), new(MockEmailVerificationRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, new(MockMailer)))
+
+		verifications.On("GetByTokenHash", hash).Return(&models.EmailVerificationToken{ID: 9, UserID: 3, Email: "bob@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		mockRepo.On("GetByEmail", "bob@example.com").Return(&models.UserResponse{ID: 4, Email: "bob@example.com"}, nil)
+
+		_, err := uc.VerifyEmail(rawToken)
+		assert.ErrorIs(t, err, models.ErrEmailTaken)
+		verifications.AssertNotCalled(t, "Consume", mock.Anything)
+	})
+
+	t.Run("rejects an address taken while verifying", func(t *testing.T) {
+		mockRepo, verifications := new(MockUserRepository), new(MockEmailVerificationRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, new(MockMailer)))
+
+		token := &models.EmailVerificationToken{ID: 9, UserID: 3, Email: "bob@example.com", ExpiresAt: time.Now().Add(time.Hour)}
+		verifications.On("GetByTokenHash", hash).Return(token, nil)
+		mockRepo.On("GetByEmail", "bob@example.com").Return(nil, errors.New("user not found"))
+		verifications.On("Consume", token).Return(models.ErrEmailTaken)
+
+		_, err := uc.VerifyEmail(rawToken)
+		assert.ErrorIs(t, err, models.ErrEmailTaken)
+	})
+}
+
+func TestUserUseCase_ResendEmailVerification(t *testing.T) {
+	t.Run("unknown or verified emails succeed without sending mail", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByEmail", "ghost@example.com").Return(nil, errors.New("user not found"))
+		mockRepo.On("GetByEmail", "ana@example.com").Return(&models.UserResponse{ID: 3, Email: "ana@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+
+		assert.NoError(t, uc.ResendEmailVerification("ghost@example.com"))
+		assert.NoError(t, uc.ResendEmailVerification("ana@example.com"))
+	})
+
+	t.Run("mails a new link to unverified accounts", func(t *testing.T) {
+		mockRepo, verifications, mailer := new(MockUserRepository), new(MockEmailVerificationRepository), new(MockMailer)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), newTestEmailVerifier(verifications, mockRepo, mailer))
+
+		mockRepo.On("GetByEmail", "ana@example.com").Return(&models.UserResponse{ID: 3, Email: "ana@example.com"}, nil)
+		_, sent := expectVerificationEmail(verifications, mailer, 3)
+
+		require.NoError(t, uc.ResendEmailVerification("ana@example.com"))
+		assert.Equal(t, "ana@example.com", sent.To)
+		verifications.AssertExpectations(t)
+	})
+}
+
+func TestUserUseCase_MarkEmailVerified(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	mockRepo.On("MarkEmailVerified", uint(3)).Return(nil)
+	mockRepo.On("GetByID", uint(3)).Return(&models.UserResponse{ID: 3, BranchID: 1, EmailVerifiedAt: verifiedNow()}, nil)
+
+	user, err := uc.MarkEmailVerified(adminCaller, 3)
+	require.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,375 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
+)
+
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvitationRepository) Create(invitation *models.Invitation) error {
+	args := m.Called(invitation)
+	return args.Error(0)
+}
+func (m *MockInvitationRepository) GetByID(id uint) (*models.Invitation, error) {
+	args := m.Called(id)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+func (m *MockInvitationRepository) GetByTokenHash(hash string) (*models.Invitation, error) {
+	args := m.Called(hash)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+func (m *MockInvitationRepository) GetOpenByEmail(email string) (*models.Invitation, error) {
+	args := m.Called(email)
+	invitation, _ := args.Get(0).(*models.Invitation)
+	return invitation, args.Error(1)
+}
+func (m *MockInvitationRepository) GetAll(branchID uint) ([]models.Invitation, error) {
+	args := m.Called(branchID)
+	invitations, _ := args.Get(0).([]models.Invitation)
+	return invitations, args.Error(1)
+}
+func (m *MockInvitationRepository) Renew(id uint, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(id, tokenHash, expiresAt)
+	return args.Error(0)
+}
+func (m *MockInvitationRepository) Revoke(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockInvitationRepository) Accept(id uint, tokenHash string, user *models.User) (*models.UserResponse, error) {
+	args := m.Called(id, tokenHash, user)
+	created, _ := args.Get(0).(*models.UserResponse)
+	return created, args.Error(1)
+}
+
+func newInvitationUseCase(invitationRepo *MockInvitationRepository, userRepo *MockUserRepository, mailer *MockMailer) *usecase.InvitationUseCase {
+	return usecase.NewInvitationUseCase(invitationRepo, userRepo, new(MockBranchRepository), newTestTokenManager(), mailer, time.Hour, "http://localhost:3000/accept-invitation")
+}
+
+// mailedInvitationToken extracts the raw token from the link in an invitation email
+func mailedInvitationToken(t *testing.T, sent *models.Email) string {
+	t.Helper()
+	require.NotNil(t, sent)
+	_, after, found := strings.Cut(sent.Body, "accept-invitation?token=")
+	require.True(t, found)
+	return strings.Fields(after)[0]
+}
+
+func TestInvitationUseCase_CreateInvitation(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2}
+
+	t.Run("mails a link whose token matches the stored hash", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		var stored *models.Invitation
+		var sent *models.Email
+		userRepo.On("GetByEmail", "ana@example.com").Return(nil, errors.New("user not found"))
+		invitationRepo.On("GetOpenByEmail", "ana@example.com").Return(nil, nil)
+		invitationRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*models.Invitation)
+		}).Return(nil)
+		mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(0).(*models.Email)
+		}).Return(nil)
+
+		invitation, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: " Ana@Example.com ", Role: models.RoleAgent})
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, "ana@example.com", stored.Email)
+		assert.Equal(t, models.RoleAgent, stored.Role)
+		require.NotNil(t, stored.InvitedBy)
+		assert.Equal(t, uint(1), *stored.InvitedBy)
+		assert.Equal(t, uint(2), stored.BranchID, "Invitees join the branch of the admin by default")
+		assert.Equal(t, models.InvitationPending, invitation.Status)
+		assert.Equal(t, "ana@example.com", sent.To)
+		assert.Equal(t, middleware.HashToken(mailedInvitationToken(t, sent)), stored.TokenHash)
+	})
+
+	t.Run("defaults to the assistant role", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		userRepo.On("GetByEmail", "ana@example.com").Return(nil, errors.New("user not found"))
+		invitationRepo.On("GetOpenByEmail", "ana@example.com").Return(nil, nil)
+		invitationRepo.On("Create", mock.MatchedBy(func(invitation *models.Invitation) bool {
+			return invitation.Role == models.RoleAssistant
+		})).Return(nil)
+		mailer.On("Send", mock.Anything).Return(nil)
+
+		_, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com"})
+		require.NoError(t, err)
+		invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an email that already has an account", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		userRepo.On("GetByEmail", "ana@example.com").Return(&models.UserResponse{ID: 3, Email: "ana@example.com"}, nil)
+
+		_, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com"})
+		assert.ErrorIs(t, err, models.ErrEmailTaken)
+		invitationRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("rejects an email with an open invitation", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		userRepo.On("GetByEmail", "ana@example.com").Return(nil, errors.New("user not found"))
+		invitationRepo.On("GetOpenByEmail", "ana@example.com").Return(&models.Invitation{ID: 4, Email: "ana@example.com"}, nil)
+
+		_, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com"})
+		assert.ErrorContains(t, err, "resend it instead")
+		invitationRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("rejects an invalid email or role", func(t *testing.T) {
+		uc := newInvitationUseCase(new(MockInvitationRepository), new(MockUserRepository), new(MockMailer))
+
+		_, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "not-an-email"})
+		assert.Error(t, err)
+		_, err = uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com", Role: "owner"})
+		assert.EqualError(t, err, "invalid role")
+	})
+
+	t.Run("keeps branch admins to their own branch", func(t *testing.T) {
+		invitationRepo, userRepo := new(MockInvitationRepository), new(MockUserRepository)
+		uc := newInvitationUseCase(invitationRepo, userRepo, new(MockMailer))
+
+		_, err := uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com", BranchID: 3})
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = uc.CreateInvitation(admin, &models.CreateInvitationData{Email: "ana@example.com", Role: models.RoleRegionalAdmin})
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		invitationRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("lets regional admins invite to another branch", func(t *testing.T) {
+		invitationRepo, userRepo, branchRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockBranchRepository), new(MockMailer)
+		uc := usecase.NewInvitationUseCase(invitationRepo, userRepo, branchRepo, newTestTokenManager(), mailer, time.Hour, "http://localhost:3000/accept-invitation")
+		regional := &models.Caller{UserID: 1, Role: models.RoleRegionalAdmin}
+
+		branchRepo.On("GetByID", uint(3)).Return(&models.Branch{ID: 3, Name: "Guadalajara"}, nil)
+		userRepo.On("GetByEmail", "ana@example.com").Return(nil, errors.New("user not found"))
+		invitationRepo.On("GetOpenByEmail", "ana@example.com").Return(nil, nil)
+		invitationRepo.On("Create", mock.MatchedBy(func(invitation *models.Invitation) bool {
+			return invitation.BranchID == 3
+		})).Return(nil)
+		mailer.On("Send", mock.Anything).Return(nil)
+
+		_, err := uc.CreateInvitation(regional, &models.CreateInvitationData{Email: "ana@example.com", BranchID: 3})
+		require.NoError(t, err)
+		invitationRepo.AssertExpectations(t)
+	})
+}
+
+func TestInvitationUseCase_GetInvitations(t *testing.T) {
+	t.Run("lists the invitations to the caller's branch", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetAll", uint(2)).Return([]models.Invitation{{ID: 4, BranchID: 2, ExpiresAt: time.Now().Add(time.Hour)}}, nil)
+
+		invitations, err := uc.GetInvitations(&models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2})
+		require.NoError(t, err)
+		assert.Len(t, invitations, 1)
+		invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("lists every branch for regional admins", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetAll", uint(0)).Return([]models.Invitation{}, nil)
+
+		_, err := uc.GetInvitations(&models.Caller{UserID: 1, Role: models.RoleRegionalAdmin, BranchID: 2})
+		require.NoError(t, err)
+		invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("requires a caller", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		_, err := uc.GetInvitations(nil)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		invitationRepo.AssertNotCalled(t, "GetAll", mock.Anything)
+	})
+}
+
+func TestInvitationUseCase_ResendInvitation(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2}
+
+	t.Run("renews the token and expiry", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		var renewedHash string
+		var sent *models.Email
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{
+			ID: 4, Email: "ana@example.com", Role: models.RoleAgent, BranchID: 2, TokenHash: "old-hash", ExpiresAt: time.Now().Add(-time.Minute),
+		}, nil)
+		invitationRepo.On("Renew", uint(4), mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			renewedHash = args.String(1)
+		}).Return(nil)
+		mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(0).(*models.Email)
+		}).Return(nil)
+
+		invitation, err := uc.ResendInvitation(admin, 4)
+		require.NoError(t, err)
+		assert.Equal(t, models.InvitationPending, invitation.Status)
+		assert.NotEqual(t, "old-hash", renewedHash)
+		assert.Equal(t, middleware.HashToken(mailedInvitationToken(t, sent)), renewedHash)
+	})
+
+	t.Run("refuses accepted invitations", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		acceptedAt := time.Now()
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{ID: 4, BranchID: 2, ExpiresAt: time.Now().Add(time.Hour), AcceptedAt: &acceptedAt}, nil)
+
+		_, err := uc.ResendInvitation(admin, 4)
+		assert.EqualError(t, err, "invitation is already accepted")
+		invitationRepo.AssertNotCalled(t, "Renew", mock.Anything, mock.Anything, mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("hides invitations to other branches", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{ID: 4, BranchID: 3, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		_, err := uc.ResendInvitation(admin, 4)
+		assert.EqualError(t, err, "invitation not found")
+		invitationRepo.AssertNotCalled(t, "Renew", mock.Anything, mock.Anything, mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+}
+
+func TestInvitationUseCase_RevokeInvitation(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 2}
+
+	t.Run("revokes an invitation to the caller's branch", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{ID: 4, Role: models.RoleAgent, BranchID: 2}, nil)
+		invitationRepo.On("Revoke", uint(4)).Return(nil)
+
+		require.NoError(t, uc.RevokeInvitation(admin, 4))
+		invitationRepo.AssertExpectations(t)
+	})
+
+	t.Run("hides invitations to other branches", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{ID: 4, Role: models.RoleAgent, BranchID: 3}, nil)
+
+		assert.EqualError(t, uc.RevokeInvitation(admin, 4), "invitation not found")
+		invitationRepo.AssertNotCalled(t, "Revoke", mock.Anything)
+	})
+
+	t.Run("keeps regional admin invitations to regional admins", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetByID", uint(4)).Return(&models.Invitation{ID: 4, Role: models.RoleRegionalAdmin, BranchID: 2}, nil)
+
+		assert.ErrorIs(t, uc.RevokeInvitation(admin, 4), models.ErrForbidden)
+		invitationRepo.AssertNotCalled(t, "Revoke", mock.Anything)
+	})
+}
+
+func TestInvitationUseCase_AcceptInvitation(t *testing.T) {
+	tokens := newTestTokenManager()
+	token, err := tokens.GenerateInvitationToken("ana@example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	hash := middleware.HashToken(token)
+
+	t.Run("creates the user with the invited email and role", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		var created *models.User
+		invitationRepo.On("GetByTokenHash", hash).Return(&models.Invitation{
+			ID: 4, Email: "ana@example.com", Role: models.RoleAgent, BranchID: 2, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+		invitationRepo.On("Accept", uint(4), hash, mock.Anything).Run(func(args mock.Arguments) {
+			created = args.Get(2).(*models.User)
+		}).Return(&models.UserResponse{ID: 9, Username: "ana", Email: "ana@example.com", Role: models.RoleAgent}, nil)
+
+		user, err := uc.AcceptInvitation(&models.AcceptInvitationData{Token: token, Username: " ana ", Password: "Str0ng!Passw0rd"})
+		require.NoError(t, err)
+		assert.Equal(t, uint(9), user.ID)
+		require.NotNil(t, created)
+		assert.Equal(t, "ana", created.Username)
+		assert.Equal(t, "ana@example.com", created.Email)
+		assert.Equal(t, models.RoleAgent, created.Role)
+		assert.Equal(t, uint(2), created.BranchID)
+		assert.NotNil(t, created.EmailVerifiedAt, "The invitation link proves the invitee owns the address")
+		assert.NotEqual(t, "Str0ng!Passw0rd", created.Password, "The password must be stored hashed")
+	})
+
+	t.Run("reports a username taken in the tenant", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		invitationRepo.On("GetByTokenHash", hash).Return(&models.Invitation{
+			ID: 4, Email: "ana@example.com", Role: models.RoleAgent, BranchID: 2, TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+		invitationRepo.On("Accept", uint(4), hash, mock.Anything).Return(nil, models.ErrUsernameTaken)
+
+		_, err := uc.AcceptInvitation(&models.AcceptInvitationData{Token: token, Username: "ana", Password: "Str0ng!Passw0rd"})
+		assert.ErrorIs(t, err, models.ErrUsernameTaken)
+	})
+
+	for name, invitation := range map[string]*models.Invitation{
+		"revoked":           {ID: 4, Email: "ana@example.com", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: func() *time.Time { now := time.Now(); return &now }()},
+		"expired":           {ID: 4, Email: "ana@example.com", ExpiresAt: time.Now().Add(-time.Minute)},
+		"for another email": {ID: 4, Email: "bob@example.com", ExpiresAt: time.Now().Add(time.Hour)},
+	} {
+		t.Run("rejects an invitation "+name, func(t *testing.T) {
+			invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+			uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+			invitationRepo.On("GetByTokenHash", hash).Return(invitation, nil)
+
+			_, err := uc.AcceptInvitation(&models.AcceptInvitationData{Token: token, Username: "ana", Password: "Str0ng!Passw0rd"})
+			assert.EqualError(t, err, "invalid or expired invitation")
+			invitationRepo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("rejects a token that is not an invitation", func(t *testing.T) {
+		invitationRepo, userRepo, mailer := new(MockInvitationRepository), new(MockUserRepository), new(MockMailer)
+		uc := newInvitationUseCase(invitationRepo, userRepo, mailer)
+
+		accessToken, _, err := tokens.GenerateAccessToken(&models.UserResponse{ID: 1}, 1)
+		require.NoError(t, err)
+
+		_, err = uc.AcceptInvitation(&models.AcceptInvitationData{Token: accessToken, Username: "ana", Password: "Str0ng!Passw0rd"})
+		assert.EqualError(t, err, "invalid or expired invitation")
+		invitationRepo.AssertNotCalled(t, "GetByTokenHash", mock.Anything)
+	})
+}
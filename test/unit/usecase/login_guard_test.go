@@ -0,0 +1,132 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/repository"
+	"inmo-backend/internal/usecase"
+)
+
+var testLoginPolicy = models.LoginPolicy{
+	MaxAttempts:     4,
+	IPMaxAttempts:   10,
+	LockoutDuration: 15 * time.Minute,
+	FreeAttempts:    1,
+	BackoffBase:     time.Second,
+	BackoffMax:      time.Minute,
+	Window:          15 * time.Minute,
+}
+
+func requireLockedFor(t *testing.T, err error, wait time.Duration) {
+	t.Helper()
+	var locked *models.LoginLockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, wait, locked.RetryAfter)
+}
+
+func TestLoginGuard_Backoff(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first failure is free
+	require.NoError(t, guard.RecordFailure("ana@example.com", "10.0.0.1", now))
+	require.NoError(t, guard.Check("ana@example.com", "10.0.0.1", now))
+
+	// Then the wait doubles with every failure
+	require.NoError(t, guard.RecordFailure("ana@example.com", "10.0.0.1", now))
+	requireLockedFor(t, guard.Check("ana@example.com", "10.0.0.1", now), time.Second)
+
+	now = now.Add(time.Second)
+	require.NoError(t, guard.Check("ana@example.com", "10.0.0.1", now))
+	require.NoError(t, guard.RecordFailure("ana@example.com", "10.0.0.1", now))
+	requireLockedFor(t, guard.Check("ana@example.com", "10.0.0.1", now), 2*time.Second)
+
+	// Reaching MaxAttempts locks the account from any IP
+	now = now.Add(2 * time.Second)
+	require.NoError(t, guard.RecordFailure("ana@example.com", "10.0.0.1", now))
+	requireLockedFor(t, guard.Check("ANA@example.com", "10.9.9.9", now), testLoginPolicy.LockoutDuration)
+
+	now = now.Add(testLoginPolicy.LockoutDuration)
+	assert.NoError(t, guard.Check("ana@example.com", "10.9.9.9", now))
+}
+
+func TestLoginGuard_IPLockout(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Spread over many accounts so only the IP crosses its threshold
+	for i := 0; i < testLoginPolicy.IPMaxAttempts; i++ {
+		email := string(rune('a'+i)) + "@example.com"
+		require.NoError(t, guard.RecordFailure(email, "10.0.0.1", now))
+	}
+
+	requireLockedFor(t, guard.Check("new@example.com", "10.0.0.1", now), testLoginPolicy.LockoutDuration)
+	assert.NoError(t, guard.Check("new@example.com", "10.0.0.2", now))
+}
+
+func TestLoginGuard_SuccessAndUnlockReset(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < testLoginPolicy.MaxAttempts; i++ {
+		require.NoError(t, guard.RecordFailure("ana@example.com", "", now))
+	}
+	require.ErrorIs(t, guard.Check("ana@example.com", "", now), models.ErrTooManyAttempts)
+
+	require.NoError(t, guard.Unlock("ana@example.com"))
+	require.NoError(t, guard.Check("ana@example.com", "", now))
+
+	require.NoError(t, guard.RecordFailure("ana@example.com", "", now))
+	require.NoError(t, guard.RecordFailure("ana@example.com", "", now))
+	require.Error(t, guard.Check("ana@example.com", "", now))
+	require.NoError(t, guard.RecordSuccess("ana@example.com"))
+	assert.NoError(t, guard.Check("ana@example.com", "", now))
+}
+
+func TestLoginGuard_FailuresExpireAfterWindow(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < testLoginPolicy.MaxAttempts-1; i++ {
+		require.NoError(t, guard.RecordFailure("ana@example.com", "", now))
+	}
+
+	now = now.Add(testLoginPolicy.Window + time.Second)
+	require.NoError(t, guard.RecordFailure("ana@example.com", "", now))
+	assert.NoError(t, guard.Check("ana@example.com", "", now))
+}
+
+func TestLoginGuard_AccountsLockPerTenant(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	agency, other := guard.ForTenant(1), guard.ForTenant(2)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < testLoginPolicy.MaxAttempts; i++ {
+		require.NoError(t, agency.RecordFailure("ana@example.com", "10.0.0.1", now))
+	}
+	require.ErrorIs(t, agency.Check("ana@example.com", "10.0.0.2", now), models.ErrTooManyAttempts)
+
+	// The same email at another agency is a different account
+	assert.NoError(t, other.Check("ana@example.com", "10.0.0.2", now))
+
+	// Unlocking at another agency leaves the lockout in place
+	require.NoError(t, other.Unlock("ana@example.com"))
+	assert.ErrorIs(t, agency.Check("ana@example.com", "10.0.0.2", now), models.ErrTooManyAttempts)
+}
+
+func TestLoginGuard_IPLockoutSpansTenants(t *testing.T) {
+	guard := usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < testLoginPolicy.IPMaxAttempts; i++ {
+		tenant := guard.ForTenant(uint(i + 1))
+		require.NoError(t, tenant.RecordFailure("ana@example.com", "10.0.0.1", now))
+	}
+
+	requireLockedFor(t, guard.ForTenant(99).Check("ana@example.com", "10.0.0.1", now), testLoginPolicy.LockoutDuration)
+}
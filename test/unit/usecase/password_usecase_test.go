@@ -0,0 +1,211 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
+)
+
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+func (m *MockPasswordResetRepository) GetByTokenHash(hash string) (*models.PasswordResetToken, error) {
+	args := m.Called(hash)
+	token, _ := args.Get(0).(*models.PasswordResetToken)
+	return token, args.Error(1)
+}
+func (m *MockPasswordResetRepository) MarkUsed(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockPasswordResetRepository) InvalidateByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+type MockMailer struct {
+	mock.Mock
+}
+
+func (m *MockMailer) Send(email *models.Email) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func newPasswordUseCase(userRepo *MockUserRepository, resetRepo *MockPasswordResetRepository, sessions *MockSessionRepository, mailer *MockMailer) *usecase.PasswordUseCase {
+	return usecase.NewPasswordUseCase(userRepo, resetRepo, sessions, mailer, time.Hour, "http://localhost:3000/reset-password")
+}
+
+func TestPasswordUseCase_ForgotPassword(t *testing.T) {
+	t.Run("unknown email succeeds without sending mail", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		userRepo.On("GetByEmail", "ghost@example.com").Return(nil, errors.New("user not found"))
+
+		err := uc.ForgotPassword("ghost@example.com")
+		require.NoError(t, err)
+		resetRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mailer.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("mails a link whose token matches the stored hash", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		var stored *models.PasswordResetToken
+		var sent *models.Email
+		userRepo.On("GetByEmail", "ana@example.com").Return(&models.UserResponse{ID: 3, Username: "ana", Email: "ana@example.com"}, nil)
+		resetRepo.On("InvalidateByUserID", uint(3)).Return(nil)
+		resetRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*models.PasswordResetToken)
+		}).Return(nil)
+		mailer.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(0).(*models.Email)
+		}).Return(nil)
+
+		err := uc.ForgotPassword("ana@example.com")
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		require.NotNil(t, sent)
+		assert.Equal(t, uint(3), stored.UserID)
+		assert.True(t, stored.ExpiresAt.After(time.Now()))
+		assert.Equal(t, "ana@example.com", sent.To)
+
+		_, after, found := strings.Cut(sent.Body, "reset-password?token=")
+		require.True(t, found)
+		rawToken := strings.Fields(after)[0]
+		assert.Equal(t, middleware.HashToken(rawToken), stored.TokenHash)
+		resetRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports a mailer failure as mail not sent", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		userRepo.On("GetByEmail", "ana@example.com").Return(&models.UserResponse{ID: 3, Username: "ana", Email: "ana@example.com"}, nil)
+		resetRepo.On("InvalidateByUserID", uint(3)).Return(nil)
+		resetRepo.On("Create", mock.Anything).Return(nil)
+		mailer.On("Send", mock.Anything).Return(errors.New("smtp timeout"))
+
+		err := uc.ForgotPassword("ana@example.com")
+		assert.ErrorIs(t, err, models.ErrMailNotSent)
+	})
+}
+
+func TestPasswordUseCase_ResetPassword(t *testing.T) {
+	const rawToken = "reset-token"
+	hash := middleware.HashToken(rawToken)
+
+	t.Run("stores the new password and revokes every session", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		resetRepo.On("GetByTokenHash", hash).Return(&models.PasswordResetToken{ID: 9, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		resetRepo.On("MarkUsed", uint(9)).Return(nil)
+		userRepo.On("UpdatePassword", uint(3), mock.MatchedBy(func(hashed string) bool {
+			return middleware.VerifyPassword(hashed, "new-password") == nil
+		})).Return(nil)
+		sessions.On("RevokeAllByUserID", uint(3), uint(0)).Return(nil)
+
+		err := uc.ResetPassword(rawToken, "new-password")
+		require.NoError(t, err)
+		resetRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("rejects an expired token", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		resetRepo.On("GetByTokenHash", hash).Return(&models.PasswordResetToken{ID: 9, UserID: 3, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		err := uc.ResetPassword(rawToken, "new-password")
+		assert.EqualError(t, err, "invalid or expired reset token")
+		userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects a token that was already used", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		usedAt := time.Now().Add(-time.Minute)
+		resetRepo.On("GetByTokenHash", hash).Return(&models.PasswordResetToken{ID: 9, UserID: 3, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}, nil)
+
+		err := uc.ResetPassword(rawToken, "new-password")
+		assert.EqualError(t, err, "invalid or expired reset token")
+		resetRepo.AssertNotCalled(t, "MarkUsed", mock.Anything)
+	})
+
+	t.Run("rejects a short password without consuming the token", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		resetRepo.On("GetByTokenHash", hash).Return(&models.PasswordResetToken{ID: 9, UserID: 3, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+
+		err := uc.ResetPassword(rawToken, "short")
+		assert.Error(t, err)
+		resetRepo.AssertNotCalled(t, "MarkUsed", mock.Anything)
+	})
+}
+
+func TestPasswordUseCase_ChangePassword(t *testing.T) {
+	caller := &models.Caller{UserID: 4, SessionID: 12, Role: models.RoleAgent}
+	currentHash, err := middleware.HashPassword("old-password")
+	require.NoError(t, err)
+
+	t.Run("stores the new password and revokes the other sessions", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		userRepo.On("GetByID", uint(4)).Return(&models.UserResponse{ID: 4, Email: "ana@example.com"}, nil)
+		userRepo.On("ConsultPassword", "ana@example.com").Return(currentHash, nil)
+		userRepo.On("UpdatePassword", uint(4), mock.MatchedBy(func(hashed string) bool {
+			return middleware.VerifyPassword(hashed, "new-password") == nil
+		})).Return(nil)
+		sessions.On("RevokeAllByUserID", uint(4), uint(12)).Return(nil)
+
+		err := uc.ChangePassword(caller, 4, "old-password", "new-password")
+		require.NoError(t, err)
+		userRepo.AssertExpectations(t)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("rejects a wrong current password", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		userRepo.On("GetByID", uint(4)).Return(&models.UserResponse{ID: 4, Email: "ana@example.com"}, nil)
+		userRepo.On("ConsultPassword", "ana@example.com").Return(currentHash, nil)
+
+		err := uc.ChangePassword(caller, 4, "wrong-password", "new-password")
+		assert.EqualError(t, err, "current password is incorrect")
+		userRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+		sessions.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects changing another user's password", func(t *testing.T) {
+		userRepo, resetRepo, sessions, mailer := new(MockUserRepository), new(MockPasswordResetRepository), new(MockSessionRepository), new(MockMailer)
+		uc := newPasswordUseCase(userRepo, resetRepo, sessions, mailer)
+
+		admin := &models.Caller{UserID: 1, SessionID: 2, Role: models.RoleAdmin}
+		err := uc.ChangePassword(admin, 4, "old-password", "new-password")
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		userRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+}
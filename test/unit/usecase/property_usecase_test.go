@@ -1,11 +1,15 @@
 package usecase_test
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"inmo-backend/internal/domain/models"
 	"inmo-backend/internal/usecase"
@@ -16,13 +20,23 @@ type MockPropertyRepository struct {
 	mock.Mock
 }
 
-func (m *MockPropertyRepository) GetAll() ([]models.PropertyResponse, error) {
-	args := m.Called()
-	if properties, ok := args.Get(0).([]models.PropertyResponse); ok {
+func (m *MockPropertyRepository) Search(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	args := m.Called(filter, page)
+	if properties, ok := args.Get(0).(*models.Page[models.PropertyResponse]); ok {
 		return properties, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
+func (m *MockPropertyRepository) SearchCards(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyCard], error) {
+	args := m.Called(filter, page)
+	cards, _ := args.Get(0).(*models.Page[models.PropertyCard])
+	return cards, args.Error(1)
+}
+func (m *MockPropertyRepository) TextSearch(filter *models.PropertyFilter, page *models.PageRequest) (*models.Page[models.PropertyResponse], error) {
+	args := m.Called(filter, page)
+	properties, _ := args.Get(0).(*models.Page[models.PropertyResponse])
+	return properties, args.Error(1)
+}
 func (m *MockPropertyRepository) GetByID(id uint) (*models.PropertyResponse, error) {
 	args := m.Called(id)
 	if property, ok := args.Get(0).(*models.PropertyResponse); ok {
@@ -44,66 +58,105 @@ func (m *MockPropertyRepository) Update(property *models.Property) (*models.Prop
 	}
 	return nil, args.Error(1)
 }
-func (m *MockPropertyRepository) Delete(id uint) error {
+func (m *MockPropertyRepository) Patch(property *models.Property, fields []string) (*models.PropertyResponse, error) {
+	args := m.Called(property, fields)
+	if propertyResponse, ok := args.Get(0).(*models.PropertyResponse); ok {
+		return propertyResponse, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockPropertyRepository) UpdateAgent(id uint, version uint, agentID uint) error {
+	args := m.Called(id, version, agentID)
+	return args.Error(0)
+}
+func (m *MockPropertyRepository) ChangeStatus(change *models.PropertyStatusChange) (*models.PropertyResponse, error) {
+	args := m.Called(change)
+	if propertyResponse, ok := args.Get(0).(*models.PropertyResponse); ok {
+		return propertyResponse, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockPropertyRepository) GetPriceHistory(id uint) ([]models.PropertyPriceChange, error) {
 	args := m.Called(id)
+	changes, _ := args.Get(0).([]models.PropertyPriceChange)
+	return changes, args.Error(1)
+}
+func (m *MockPropertyRepository) Delete(id uint, version uint) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
 
 
+// pageOf wraps properties in a single page
+func pageOf(properties []models.PropertyResponse) *models.Page[models.PropertyResponse] {
+	return &models.Page[models.PropertyResponse]{
+		Items:      properties,
+		Pagination: models.Pagination{Total: len(properties), Limit: models.DefaultPageLimit},
+	}
+}
+
+// searchOfBranch matches a search scoped to the branch
+func searchOfBranch(branchID uint) any {
+	return mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+		return filter.BranchID == branchID
+	})
+}
+
+var adminCaller = &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}
 
 func TestPropertyUseCase_GetAllProperties(t *testing.T) {
 	t.Run("should return all properties successfully", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedProperties := []models.PropertyResponse{
 			{ID: 1, Address: "123 Main St", Price: 100000},
 			{ID: 2, Address: "456 Oak Ave", Price: 200000},
 		}
 		
-		mockRepo.On("GetAll").Return(expectedProperties, nil)
+		mockRepo.On("Search", searchOfBranch(1), mock.Anything).Return(pageOf(expectedProperties), nil)
 		
 		// Act
-		result, err := propertyUseCase.GetAllProperties()
+		result, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{}, nil)
 		
 		// Assert
 		assert.NoError(t, err)
-		assert.Equal(t, expectedProperties, result)
-		assert.Len(t, result, 2)
+		assert.Equal(t, expectedProperties, result.Items)
+		assert.Len(t, result.Items, 2)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return empty slice when no properties exist", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedProperties := []models.PropertyResponse{}
 		
-		mockRepo.On("GetAll").Return(expectedProperties, nil)
+		mockRepo.On("Search", searchOfBranch(1), mock.Anything).Return(pageOf(expectedProperties), nil)
 		
 		// Act
-		result, err := propertyUseCase.GetAllProperties()
+		result, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{}, nil)
 		
 		// Assert
 		assert.NoError(t, err)
-		assert.Equal(t, expectedProperties, result)
-		assert.Len(t, result, 0)
+		assert.Equal(t, expectedProperties, result.Items)
+		assert.Len(t, result.Items, 0)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedError := errors.New("database connection failed")
 		
-		mockRepo.On("GetAll").Return([]models.PropertyResponse(nil), expectedError)
+		mockRepo.On("Search", searchOfBranch(1), mock.Anything).Return(nil, expectedError)
 		
 		// Act
-		result, err := propertyUseCase.GetAllProperties()
+		result, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{}, nil)
 		
 		// Assert
 		assert.Error(t, err)
@@ -112,22 +165,150 @@ func TestPropertyUseCase_GetAllProperties(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+func TestPropertyUseCase_SearchProperties(t *testing.T) {
+	t.Run("passes the normalized filter to the repository", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		minBedrooms := 3
+
+		mockRepo.On("Search", &models.PropertyFilter{
+			BranchID:    1,
+			City:        "Monterrey",
+			MinBedrooms: &minBedrooms,
+			Amenities:   []string{"pool", "gym"},
+		}, mock.Anything).Return(pageOf([]models.PropertyResponse{{ID: 1}}), nil)
+
+		result, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{
+			City:        " Monterrey",
+			MinBedrooms: &minBedrooms,
+			Amenities:   []string{"pool,gym"},
+		}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid filter without searching", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{Status: "gone"}, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidFilter)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_PaginateProperties(t *testing.T) {
+	t.Run("defaults to the newest properties first", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("Search", searchOfBranch(1), mock.MatchedBy(func(page *models.PageRequest) bool {
+			return page.Limit == models.DefaultPageLimit && page.Sort == "-created_at" &&
+				assert.ObjectsAreEqual([]models.SortField{{Name: "created_at", Desc: true}}, page.SortFields)
+		})).Return(pageOf(nil), nil)
+
+		_, err := propertyUseCase.GetAllProperties(adminCaller, nil, nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects an invalid page without searching", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		for _, page := range []*models.PageRequest{
+			{Limit: 500},
+			{Sort: "owner_id"},
+			{Offset: 20, Cursor: (&models.Cursor{Sort: "-created_at", Values: []any{"2026-01-01T00:00:00Z"}, ID: 3}).Encode()},
+		} {
+			_, err := propertyUseCase.GetAllProperties(adminCaller, nil, page)
+			assert.ErrorIs(t, err, models.ErrInvalidPage)
+		}
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_TextSearch(t *testing.T) {
+	t.Run("ranks the matches by relevance", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		matches := pageOf([]models.PropertyResponse{{ID: 4, Title: "Casa junto a la iglesia", BranchID: 1, Relevance: 1.5}})
+
+		mockRepo.On("TextSearch", mock.MatchedBy(func(filter *models.PropertyFilter) bool {
+			return filter.Query == "iglesia Colonia Centro" && filter.BranchID == 1
+		}), mock.MatchedBy(func(page *models.PageRequest) bool {
+			return page.Sort == "-relevance"
+		})).Return(matches, nil)
+
+		result, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{Query: "  iglesia   Colonia Centro "}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, matches, result)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sorts by relevance only with a query", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.GetAllProperties(adminCaller, nil, &models.PageRequest{Sort: "-relevance"})
+		assert.ErrorIs(t, err, models.ErrInvalidPage)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("is not offered for cards", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.GetPropertyCards(adminCaller, &models.PropertyFilter{Query: "iglesia"}, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidFilter)
+		mockRepo.AssertNotCalled(t, "SearchCards", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_GetPropertyCards(t *testing.T) {
+	t.Run("searches cards of the caller's branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		cards := &models.Page[models.PropertyCard]{Items: []models.PropertyCard{{ID: 1, Title: "House"}}}
+
+		mockRepo.On("SearchCards", searchOfBranch(1), mock.MatchedBy(func(page *models.PageRequest) bool {
+			return page.Sort == "price"
+		})).Return(cards, nil)
+
+		result, err := propertyUseCase.GetPropertyCards(adminCaller, nil, &models.PageRequest{Sort: "price"})
+		assert.NoError(t, err)
+		assert.Equal(t, cards, result)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses another branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.GetPropertyCards(adminCaller, &models.PropertyFilter{BranchID: 2}, nil)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "SearchCards", mock.Anything, mock.Anything)
+	})
+}
+
 func TestPropertyUseCase_GetPropertyByID(t *testing.T) {
 	t.Run("should return property successfully when found", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedProperty := &models.PropertyResponse{
-			ID:      1,
-			Address: "123 Main St",
-			Price:   100000,
+			ID:       1,
+			Address:  "123 Main St",
+			Price:    100000,
+			BranchID: 1,
 		}
 		
 		mockRepo.On("GetByID", uint(1)).Return(expectedProperty, nil)
 		
 		// Act
-		result, err := propertyUseCase.GetPropertyByID(1)
+		result, err := propertyUseCase.GetPropertyByID(adminCaller, 1)
 		
 		// Assert
 		assert.NoError(t, err)
@@ -138,12 +319,12 @@ func TestPropertyUseCase_GetPropertyByID(t *testing.T) {
 	t.Run("should return error when property not found", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		mockRepo.On("GetByID", uint(999)).Return((*models.PropertyResponse)(nil), nil)
 		
 		// Act
-		result, err := propertyUseCase.GetPropertyByID(999)
+		result, err := propertyUseCase.GetPropertyByID(adminCaller, 999)
 		
 		// Assert
 		assert.Error(t, err)
@@ -155,14 +336,14 @@ func TestPropertyUseCase_GetPropertyByID(t *testing.T) {
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedError := errors.New("database connection failed")
 		
 		mockRepo.On("GetByID", uint(1)).Return((*models.PropertyResponse)(nil), expectedError)
 		
 		// Act
-		result, err := propertyUseCase.GetPropertyByID(1)
+		result, err := propertyUseCase.GetPropertyByID(adminCaller, 1)
 		
 		// Assert
 		assert.Error(t, err)
@@ -174,13 +355,16 @@ func TestPropertyUseCase_GetPropertyByID(t *testing.T) {
 func TestPropertyUseCase_CreateProperty(t *testing.T) {
 	t.Run("should create property successfully", func(t *testing.T) {
 		// Arrange
-		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
 		
 		inputProperty := &models.Property{
-			Address: "123 Main St",
-			Price:   100000,
+			Address:         "123 Main St",
+			Price:           100000,
+			TransactionType: models.TransactionSale,
+			UserID:          5,
 		}
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 1}, nil)
 		
 		expectedResponse := &models.PropertyResponse{
 			ID:      1,
@@ -191,7 +375,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 		mockRepo.On("Create", inputProperty).Return(expectedResponse, nil)
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(inputProperty)
+		result, err := propertyUseCase.CreateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.NoError(t, err)
@@ -202,10 +386,10 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 	t.Run("should return error when property is nil", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(nil)
+		result, err := propertyUseCase.CreateProperty(adminCaller, nil)
 		
 		// Assert
 		assert.Error(t, err)
@@ -217,7 +401,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 	t.Run("should return error when address is empty", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			Address: "",
@@ -225,7 +409,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(inputProperty)
+		result, err := propertyUseCase.CreateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -237,7 +421,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 	t.Run("should return error when price is zero", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			Address: "123 Main St",
@@ -245,7 +429,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(inputProperty)
+		result, err := propertyUseCase.CreateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -257,7 +441,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 	t.Run("should return error when price is negative", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			Address: "123 Main St",
@@ -265,7 +449,7 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(inputProperty)
+		result, err := propertyUseCase.CreateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -276,20 +460,23 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Arrange
-		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
 		
 		inputProperty := &models.Property{
-			Address: "123 Main St",
-			Price:   100000,
+			Address:         "123 Main St",
+			Price:           100000,
+			TransactionType: models.TransactionSale,
+			UserID:          5,
 		}
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 1}, nil)
 		
 		expectedError := errors.New("database connection failed")
 		
 		mockRepo.On("Create", inputProperty).Return((*models.PropertyResponse)(nil), expectedError)
 		
 		// Act
-		result, err := propertyUseCase.CreateProperty(inputProperty)
+		result, err := propertyUseCase.CreateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -297,12 +484,39 @@ func TestPropertyUseCase_CreateProperty(t *testing.T) {
 		assert.Equal(t, expectedError, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("an agent lists the property as their own", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("Create", mock.MatchedBy(func(p *models.Property) bool { return p.UserID == 5 })).Return(&models.PropertyResponse{ID: 1, AgentID: 5}, nil)
+
+		agent := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+		_, err := propertyUseCase.CreateProperty(agent, &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale, UserID: 6})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("an admin must assign an agent of the branch", func(t *testing.T) {
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
+
+		userRepo.On("GetByID", uint(6)).Return(&models.UserResponse{ID: 6, Role: models.RoleAgent, BranchID: 2}, nil)
+		userRepo.On("GetByID", uint(7)).Return(nil, models.ErrUserNotFound)
+
+		for _, agentID := range []uint{0, 6, 7} {
+			_, err := propertyUseCase.CreateProperty(adminCaller, &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale, UserID: agentID})
+			assert.ErrorIs(t, err, models.ErrInvalidAgent, "agent %d", agentID)
+		}
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
 }
+
 func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should update property successfully", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      1,
@@ -316,10 +530,11 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 			Price:   150000,
 		}
 		
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}, nil)
 		mockRepo.On("Update", inputProperty).Return(expectedResponse, nil)
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.NoError(t, err)
@@ -330,10 +545,10 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when property is nil", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(nil)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, nil)
 		
 		// Assert
 		assert.Error(t, err)
@@ -345,7 +560,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when property ID is zero", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      0,
@@ -354,7 +569,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -366,7 +581,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when address is empty", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      1,
@@ -375,7 +590,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -387,7 +602,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when price is zero", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      1,
@@ -396,7 +611,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -408,7 +623,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when price is negative", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      1,
@@ -417,7 +632,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 		}
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -429,7 +644,7 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		inputProperty := &models.Property{
 			ID:      1,
@@ -439,10 +654,11 @@ func TestPropertyUseCase_UpdateProperty(t *testing.T) {
 		
 		expectedError := errors.New("database connection failed")
 		
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}, nil)
 		mockRepo.On("Update", inputProperty).Return((*models.PropertyResponse)(nil), expectedError)
 		
 		// Act
-		result, err := propertyUseCase.UpdateProperty(inputProperty)
+		result, err := propertyUseCase.UpdateProperty(adminCaller, inputProperty)
 		
 		// Assert
 		assert.Error(t, err)
@@ -455,12 +671,13 @@ func TestPropertyUseCase_DeleteProperty(t *testing.T) {
 	t.Run("should delete property successfully", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
-		mockRepo.On("Delete", uint(1)).Return(nil)
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1, Version: 2}, nil)
+		mockRepo.On("Delete", uint(1), uint(2)).Return(nil)
 		
 		// Act
-		err := propertyUseCase.DeleteProperty(1)
+		err := propertyUseCase.DeleteProperty(adminCaller, 1, 2)
 		
 		// Assert
 		assert.NoError(t, err)
@@ -470,10 +687,10 @@ func TestPropertyUseCase_DeleteProperty(t *testing.T) {
 	t.Run("should return error when property ID is zero", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		// Act
-		err := propertyUseCase.DeleteProperty(0)
+		err := propertyUseCase.DeleteProperty(adminCaller, 0, 1)
 		
 		// Assert
 		assert.Error(t, err)
@@ -484,14 +701,15 @@ func TestPropertyUseCase_DeleteProperty(t *testing.T) {
 	t.Run("should return error when repository fails", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockPropertyRepository)
-		propertyUseCase := usecase.NewPropertyUseCase(mockRepo)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 		
 		expectedError := errors.New("database connection failed")
 		
-		mockRepo.On("Delete", uint(1)).Return(expectedError)
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1, Version: 2}, nil)
+		mockRepo.On("Delete", uint(1), uint(2)).Return(expectedError)
 		
 		// Act
-		err := propertyUseCase.DeleteProperty(1)
+		err := propertyUseCase.DeleteProperty(adminCaller, 1, 2)
 		
 		// Assert
 		assert.Error(t, err)
@@ -500,6 +718,609 @@ func TestPropertyUseCase_DeleteProperty(t *testing.T) {
 	})
 }
 
+func TestPropertyUseCase_UpdateProperty_Ownership(t *testing.T) {
+	existing := &models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}
+
+	t.Run("assigned agent can update", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+		inputProperty := &models.Property{ID: 1, Address: "123 Main St", Price: 100000}
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", inputProperty).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}, nil)
+
+		_, err := propertyUseCase.UpdateProperty(caller, inputProperty)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), inputProperty.UserID, "Omitted user_id keeps the assigned agent")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("other agent is forbidden", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 8, Role: models.RoleAgent, BranchID: 1}
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(caller, &models.Property{ID: 1, Address: "123 Main St", Price: 100000})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("assistant is forbidden", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 9, Role: models.RoleAssistant, BranchID: 1}
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(caller, &models.Property{ID: 1, Address: "123 Main St", Price: 100000})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+	})
+
+	t.Run("agent cannot hand over through update", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(caller, &models.Property{ID: 1, Address: "123 Main St", Price: 100000, UserID: 8})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("admin cannot reassign through update either", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(adminCaller, &models.Property{ID: 1, Address: "123 Main St", Price: 100000, UserID: 8})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+	})
+
+	t.Run("missing caller is forbidden", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(nil, &models.Property{ID: 1, Address: "123 Main St", Price: 100000})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+	})
+}
+
+func TestPropertyUseCase_PatchProperty(t *testing.T) {
+	existing := &models.PropertyResponse{ID: 1, Title: "Casa Centro", Address: "Av. Juarez 10", Price: 100000, AgentID: 5, BranchID: 1, Version: 4}
+	patchOf := func(body string) models.MergePatch {
+		patch, err := models.ParseMergePatch([]byte(body))
+		require.NoError(t, err)
+		return patch
+	}
+
+	t.Run("writes only the patched fields", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+		patched := &models.PropertyResponse{ID: 1, Title: "Casa Centro", Address: "Av. Juarez 10", Price: 95000, AgentID: 5, BranchID: 1, Version: 5}
+
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Patch", mock.MatchedBy(func(p *models.Property) bool {
+			return p.ID == 1 && p.Version == 4 && p.Price == 95000 && p.Notes == "price drop" && p.Title == "Casa Centro"
+		}), []string{"notes", "price"}).Return(patched, nil)
+
+		result, err := propertyUseCase.PatchProperty(caller, 1, 4, patchOf(`{"price": 95000, "notes": "price drop"}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, patched, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("applies the update validation to the merged property", func(t *testing.T) {
+		for _, body := range []string{`{"address": null}`, `{"address": ""}`, `{"price": 0}`, `{"price": -5}`} {
+			mockRepo := new(MockPropertyRepository)
+			propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+			mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+			_, err := propertyUseCase.PatchProperty(adminCaller, 1, 4, patchOf(body))
+
+			assert.ErrorIs(t, err, models.ErrInvalidPatch, body)
+			mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("refuses fields that cannot be patched", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.PatchProperty(adminCaller, 1, 4, patchOf(`{"created_at": "2020-01-01T00:00:00Z"}`))
+
+		assert.ErrorIs(t, err, models.ErrInvalidPatch)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("agent and branch follow the update rules", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.PatchProperty(adminCaller, 1, 4, patchOf(`{"user_id": 8}`))
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = propertyUseCase.PatchProperty(adminCaller, 1, 4, patchOf(`{"branch_id": 2}`))
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("other agent is forbidden", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 8, Role: models.RoleAgent, BranchID: 1}
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.PatchProperty(caller, 1, 4, patchOf(`{"price": 1}`))
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("an empty patch changes nothing", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		result, err := propertyUseCase.PatchProperty(adminCaller, 1, 4, patchOf(`{}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, existing, result)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_RefusesStaleVersions(t *testing.T) {
+	mockRepo := new(MockPropertyRepository)
+	propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+	mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, Address: "Av. Juarez 10", Price: 100000, AgentID: 5, BranchID: 1, Version: 4}, nil)
+
+	_, err := propertyUseCase.UpdateProperty(adminCaller, &models.Property{ID: 1, Address: "Av. Juarez 10", Price: 90000, Version: 3})
+	assert.ErrorIs(t, err, models.ErrStaleVersion)
+	assert.ErrorContains(t, err, "property 1 is at version 4")
+
+	patch, err := models.ParseMergePatch([]byte(`{"price": 90000}`))
+	require.NoError(t, err)
+	_, err = propertyUseCase.PatchProperty(adminCaller, 1, 3, patch)
+	assert.ErrorIs(t, err, models.ErrStaleVersion)
+
+	assert.ErrorIs(t, propertyUseCase.DeleteProperty(adminCaller, 1, 3), models.ErrStaleVersion)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestPropertyUseCase_ChangePropertyStatus(t *testing.T) {
+	existing := &models.PropertyResponse{ID: 1, Address: "Av. Juarez 10", Price: 100000, AgentID: 5, BranchID: 1, TransactionType: models.TransactionSale, Status: models.StatusAvailable, Version: 4}
+	agent := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+
+	t.Run("records who changed the status and why", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		reserved := &models.PropertyResponse{ID: 1, Status: models.StatusReserved, Version: 5}
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("ChangeStatus", &models.PropertyStatusChange{
+			PropertyID: 1,
+			FromStatus: models.StatusAvailable,
+			ToStatus:   models.StatusReserved,
+			Reason:     "deposit received",
+			ChangedBy:  5,
+		}).Return(reserved, nil)
+
+		result, err := propertyUseCase.ChangePropertyStatus(agent, 1, &models.PropertyStatusRequest{Status: models.StatusReserved, Reason: " deposit received "})
+
+		require.NoError(t, err)
+		assert.Equal(t, reserved, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("refuses transitions the transaction type does not allow", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		for _, status := range []models.PropertyStatus{models.StatusRented, "sould", models.StatusAvailable} {
+			_, err := propertyUseCase.ChangePropertyStatus(agent, 1, &models.PropertyStatusRequest{Status: status, Reason: "typo"})
+			assert.ErrorIs(t, err, models.ErrInvalidStatusTransition, status)
+		}
+		mockRepo.AssertNotCalled(t, "ChangeStatus", mock.Anything)
+	})
+
+	t.Run("requires a reason", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.ChangePropertyStatus(agent, 1, &models.PropertyStatusRequest{Status: models.StatusSold, Reason: " "})
+
+		assert.EqualError(t, err, "a reason for the status change must be provided")
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything)
+	})
+
+	t.Run("other agent is forbidden", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		caller := &models.Caller{UserID: 8, Role: models.RoleAgent, BranchID: 1}
+
+		_, err := propertyUseCase.ChangePropertyStatus(caller, 1, &models.PropertyStatusRequest{Status: models.StatusSold, Reason: "closed"})
+
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "ChangeStatus", mock.Anything)
+	})
+
+	t.Run("updates cannot change the status", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(adminCaller, &models.Property{ID: 1, Address: "Av. Juarez 10", Price: 100000, Status: models.StatusSold, Version: 4})
+		assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+		patch, err := models.ParseMergePatch([]byte(`{"status": "sold"}`))
+		require.NoError(t, err)
+		_, err = propertyUseCase.PatchProperty(adminCaller, 1, 4, patch)
+		assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a new transaction type must allow the status", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		sold := &models.PropertyResponse{ID: 1, Address: "Av. Juarez 10", Price: 100000, AgentID: 5, BranchID: 1, TransactionType: models.TransactionSale, Status: models.StatusSold, Version: 4}
+		mockRepo.On("GetByID", uint(1)).Return(sold, nil)
+
+		patch, err := models.ParseMergePatch([]byte(`{"transaction_type": "rental"}`))
+		require.NoError(t, err)
+		_, err = propertyUseCase.PatchProperty(adminCaller, 1, 4, patch)
+
+		assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+		assert.ErrorContains(t, err, "a property for rental cannot be sold")
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("new properties need a status of their transaction type", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.CreateProperty(adminCaller, &models.Property{Address: "Av. Juarez 10", Price: 100000, TransactionType: models.TransactionSale, Status: models.StatusRented})
+
+		assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_PriceHistory(t *testing.T) {
+	existing := &models.PropertyResponse{ID: 1, Address: "Av. Juarez 10", Price: 100000, AgentID: 5, BranchID: 1, Version: 4}
+	agent := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+
+	t.Run("an update records the old and new price", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *models.Property) bool {
+			return assert.ObjectsAreEqual(&models.PropertyPriceChange{PropertyID: 1, OldPrice: 100000, NewPrice: 95000, Reason: "slow season", ChangedBy: 5}, p.PriceChange)
+		})).Return(&models.PropertyResponse{ID: 1, Price: 95000}, nil)
+
+		_, err := propertyUseCase.UpdateProperty(agent, &models.Property{ID: 1, Address: "Av. Juarez 10", Price: 95000, Version: 4, PriceChangeReason: " slow season "})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("an update keeping the price records nothing", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *models.Property) bool { return p.PriceChange == nil })).Return(existing, nil)
+
+		_, err := propertyUseCase.UpdateProperty(agent, &models.Property{ID: 1, Title: "Renamed", Address: "Av. Juarez 10", Price: 100000, Version: 4})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("a patch passes the reason to the history only", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("Patch", mock.MatchedBy(func(p *models.Property) bool {
+			return p.PriceChange != nil && p.PriceChange.OldPrice == 100000 && p.PriceChange.NewPrice == 90000 && p.PriceChange.Reason == "owner agreed"
+		}), []string{"price"}).Return(&models.PropertyResponse{ID: 1, Price: 90000}, nil)
+
+		patch, err := models.ParseMergePatch([]byte(`{"price": 90000, "price_change_reason": "owner agreed"}`))
+		require.NoError(t, err)
+		_, err = propertyUseCase.PatchProperty(agent, 1, 4, patch)
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("lists the history of visible properties only", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		history := []models.PropertyPriceChange{{ID: 1, PropertyID: 1, OldPrice: 120000, NewPrice: 100000, ChangedBy: 5}}
+		mockRepo.On("GetByID", uint(1)).Return(existing, nil)
+		mockRepo.On("GetPriceHistory", uint(1)).Return(history, nil)
+
+		result, err := propertyUseCase.GetPriceHistory(agent, 1)
+		require.NoError(t, err)
+		assert.Equal(t, history, result)
+
+		_, err = propertyUseCase.GetPriceHistory(&models.Caller{UserID: 9, Role: models.RoleAgent, BranchID: 2}, 1)
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		mockRepo.AssertNumberOfCalls(t, "GetPriceHistory", 1)
+	})
+
+	t.Run("reports a missing property", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(9)).Return(nil, fmt.Errorf("%w: %w", models.ErrPropertyNotFound, sql.ErrNoRows))
+
+		_, err := propertyUseCase.GetPriceHistory(adminCaller, 9)
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		mockRepo.AssertNotCalled(t, "GetPriceHistory", mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_DeleteProperty_Ownership(t *testing.T) {
+	mockRepo := new(MockPropertyRepository)
+	propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+	caller := &models.Caller{UserID: 8, Role: models.RoleAgent, BranchID: 1}
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}, nil)
+
+	err := propertyUseCase.DeleteProperty(caller, 1, 1)
+
+	assert.ErrorIs(t, err, models.ErrForbidden)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestPropertyUseCase_ReassignProperty(t *testing.T) {
+	t.Run("should reassign property", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		mockUsers := new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, mockUsers)
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 8, BranchID: 1, Version: 4}, nil)
+		mockUsers.On("GetByID", uint(8)).Return(&models.UserResponse{ID: 8, Role: models.RoleAgent, BranchID: 1}, nil)
+		mockRepo.On("UpdateAgent", uint(1), uint(4), uint(8)).Return(nil)
+
+		result, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(8), result.AgentID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should require an agent", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 0)
+
+		assert.ErrorIs(t, err, models.ErrInvalidAgent)
+		mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should report a missing property", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(1)).Return(nil, fmt.Errorf("%w: %w", models.ErrPropertyNotFound, sql.ErrNoRows))
+
+		_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse a stale version", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 3, BranchID: 1, Version: 5}, nil)
+
+		_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse a property of another branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 3, BranchID: 2, Version: 4}, nil)
+
+		_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	deactivatedAt := time.Now()
+	invalid := map[string]*models.UserResponse{
+		"deactivated user":        {ID: 8, Role: models.RoleAgent, BranchID: 1, DeactivatedAt: &deactivatedAt},
+		"user without agent role": {ID: 8, Role: models.RoleAssistant, BranchID: 1},
+		"agent of another branch": {ID: 8, Role: models.RoleAgent, BranchID: 2},
+	}
+	for name, agent := range invalid {
+		t.Run("should refuse a "+name, func(t *testing.T) {
+			mockRepo := new(MockPropertyRepository)
+			mockUsers := new(MockUserRepository)
+			propertyUseCase := usecase.NewPropertyUseCase(mockRepo, mockUsers)
+
+			mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 3, BranchID: 1, Version: 4}, nil)
+			mockUsers.On("GetByID", uint(8)).Return(agent, nil)
+
+			_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+			assert.ErrorIs(t, err, models.ErrInvalidAgent)
+			mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("should refuse a missing or deleted user", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		mockUsers := new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, mockUsers)
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 3, BranchID: 1, Version: 4}, nil)
+		mockUsers.On("GetByID", uint(8)).Return(nil, models.ErrUserNotFound)
+
+		_, err := propertyUseCase.ReassignProperty(adminCaller, 1, 4, 8)
+
+		assert.ErrorIs(t, err, models.ErrInvalidAgent)
+		mockRepo.AssertNotCalled(t, "UpdateAgent", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPropertyUseCase_BranchScope(t *testing.T) {
+	regional := &models.Caller{UserID: 2, Role: models.RoleRegionalAdmin}
+
+	t.Run("lists the caller's branch by default", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+		caller := &models.Caller{UserID: 5, Role: models.RoleAgent, BranchID: 1}
+
+		mockRepo.On("Search", searchOfBranch(1), mock.Anything).Return(pageOf([]models.PropertyResponse{{ID: 1, BranchID: 1}}), nil)
 
+		_, err := propertyUseCase.GetAllProperties(caller, &models.PropertyFilter{}, nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
 
+	t.Run("refuses to list another branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
 
+		_, err := propertyUseCase.GetAllProperties(adminCaller, &models.PropertyFilter{BranchID: 2}, nil)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+	})
+
+	t.Run("regional admins list every branch or filter by one", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("Search", searchOfBranch(0), mock.Anything).Return(pageOf([]models.PropertyResponse{}), nil)
+		mockRepo.On("Search", searchOfBranch(2), mock.Anything).Return(pageOf([]models.PropertyResponse{}), nil)
+
+		_, err := propertyUseCase.GetAllProperties(regional, &models.PropertyFilter{}, nil)
+		assert.NoError(t, err)
+		_, err = propertyUseCase.GetAllProperties(regional, &models.PropertyFilter{BranchID: 2}, nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("hides a property of another branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(7)).Return(&models.PropertyResponse{ID: 7, BranchID: 2}, nil)
+
+		_, err := propertyUseCase.GetPropertyByID(adminCaller, 7)
+		assert.EqualError(t, err, "property not found")
+		_, err = propertyUseCase.GetPropertyByID(regional, 7)
+		assert.NoError(t, err)
+	})
+
+	t.Run("admin cannot change a property of another branch", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(7)).Return(&models.PropertyResponse{ID: 7, AgentID: 5, BranchID: 2}, nil)
+
+		// Reported as missing, so the property's existence does not leak
+		_, err := propertyUseCase.UpdateProperty(adminCaller, &models.Property{ID: 7, Address: "123 Main St", Price: 100000})
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		assert.ErrorIs(t, propertyUseCase.DeleteProperty(adminCaller, 7, 1), models.ErrPropertyNotFound)
+		_, err = propertyUseCase.ChangePropertyStatus(adminCaller, 7, &models.PropertyStatusRequest{Status: models.StatusSold, Reason: "signed"})
+		assert.ErrorIs(t, err, models.ErrPropertyNotFound)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("an agent of the branch cannot change a colleague's property", func(t *testing.T) {
+		mockRepo := new(MockPropertyRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, new(MockUserRepository))
+
+		mockRepo.On("GetByID", uint(7)).Return(&models.PropertyResponse{ID: 7, AgentID: 5, BranchID: 1}, nil)
+
+		agent := &models.Caller{UserID: 6, Role: models.RoleAgent, BranchID: 1}
+		assert.ErrorIs(t, propertyUseCase.DeleteProperty(agent, 7, 1), models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("only regional admins move a property to another branch", func(t *testing.T) {
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(p *models.Property) bool { return p.BranchID == 2 })).Return(&models.PropertyResponse{ID: 1, BranchID: 2}, nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 2}, nil)
+
+		_, err := propertyUseCase.UpdateProperty(adminCaller, &models.Property{ID: 1, Address: "123 Main St", Price: 100000, BranchID: 2})
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = propertyUseCase.UpdateProperty(regional, &models.Property{ID: 1, Address: "123 Main St", Price: 100000, BranchID: 2})
+		assert.NoError(t, err)
+	})
+
+	t.Run("a property only moves to a branch its agent works at", func(t *testing.T) {
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
+
+		mockRepo.On("GetByID", uint(1)).Return(&models.PropertyResponse{ID: 1, AgentID: 5, BranchID: 1, Address: "123 Main St", Price: 100000, Version: 4}, nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 1}, nil)
+
+		_, err := propertyUseCase.UpdateProperty(regional, &models.Property{ID: 1, Address: "123 Main St", Price: 100000, BranchID: 2, Version: 4})
+		assert.ErrorIs(t, err, models.ErrInvalidAgent)
+		patch, err := models.ParseMergePatch([]byte(`{"branch_id": 2}`))
+		require.NoError(t, err)
+		_, err = propertyUseCase.PatchProperty(regional, 1, 4, patch)
+		assert.ErrorIs(t, err, models.ErrInvalidAgent)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("creates in the caller's branch unless a regional admin picks one", func(t *testing.T) {
+		mockRepo, userRepo := new(MockPropertyRepository), new(MockUserRepository)
+		propertyUseCase := usecase.NewPropertyUseCase(mockRepo, userRepo)
+
+		mockRepo.On("Create", mock.Anything).Return(&models.PropertyResponse{ID: 1}, nil)
+		userRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 1}, nil)
+		userRepo.On("GetByID", uint(6)).Return(&models.UserResponse{ID: 6, Role: models.RoleAgent, BranchID: 2}, nil)
+
+		property := &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale, UserID: 5}
+		_, err := propertyUseCase.CreateProperty(adminCaller, property)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), property.BranchID)
+
+		_, err = propertyUseCase.CreateProperty(adminCaller, &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale, BranchID: 2})
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = propertyUseCase.CreateProperty(regional, &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale})
+		assert.EqualError(t, err, "branch_id is required")
+		_, err = propertyUseCase.CreateProperty(regional, &models.Property{Address: "123 Main St", Price: 100000, TransactionType: models.TransactionSale, BranchID: 2, UserID: 6})
+		assert.NoError(t, err)
+		mockRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+}
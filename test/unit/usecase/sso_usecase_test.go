@@ -0,0 +1,241 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/oidc"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/test/fakeidp"
+)
+
+// memorySSOStates keeps single sign-on states in a map for the flow tests
+type memorySSOStates struct {
+	states map[string]*models.SSOLoginState
+}
+
+func (m *memorySSOStates) Create(state *models.SSOLoginState) error {
+	state.ID = uint(len(m.states) + 1)
+	m.states[state.StateHash] = state
+	return nil
+}
+func (m *memorySSOStates) GetByStateHash(hash string) (*models.SSOLoginState, error) {
+	if state, ok := m.states[hash]; ok {
+		return state, nil
+	}
+	return nil, errors.New("single sign-on state not found")
+}
+func (m *memorySSOStates) Consume(id uint) error {
+	for _, state := range m.states {
+		if state.ID == id && state.UsedAt == nil {
+			now := time.Now()
+			state.UsedAt = &now
+			return nil
+		}
+	}
+	return errors.New("single sign-on state already used")
+}
+
+// memoryUserIdentities keeps identity links in a slice for the flow tests
+type memoryUserIdentities struct {
+	identities []models.UserIdentity
+}
+
+func (m *memoryUserIdentities) GetBySubject(issuer string, subject string) (*models.UserIdentity, error) {
+	for i := range m.identities {
+		if m.identities[i].Issuer == issuer && m.identities[i].Subject == subject {
+			return &m.identities[i], nil
+		}
+	}
+	return nil, nil
+}
+func (m *memoryUserIdentities) Create(identity *models.UserIdentity) error {
+	identity.ID = uint(len(m.identities) + 1)
+	m.identities = append(m.identities, *identity)
+	return nil
+}
+func (m *memoryUserIdentities) TouchLastLogin(id uint, now time.Time) error {
+	return nil
+}
+
+type ssoFixture struct {
+	idp        *fakeidp.Server
+	userRepo   *MockUserRepository
+	sessions   *MockSessionRepository
+	identities *memoryUserIdentities
+	uc         *usecase.SSOUseCase
+}
+
+func newSSOFixture(t *testing.T, twoFactorRepo *MockTwoFactorRepository) *ssoFixture {
+	idp := fakeidp.New(t, "inmo-backend", "client-secret")
+	f := &ssoFixture{
+		idp:        idp,
+		userRepo:   new(MockUserRepository),
+		sessions:   new(MockSessionRepository),
+		identities: &memoryUserIdentities{},
+	}
+	provider := oidc.NewProvider(idp.URL, idp.ClientID, idp.ClientSecret, "http://localhost:3000/sso/callback", []string{"openid", "email"}, nil)
+	logins := usecase.NewUserUseCase(f.userRepo, f.sessions, twoFactorRepo, newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+	f.uc = usecase.NewSSOUseCase(provider, &memorySSOStates{states: map[string]*models.SSOLoginState{}}, f.identities, f.userRepo, logins, 10*time.Minute)
+	return f
+}
+
+// signIn runs the browser part of the flow and returns the callback parameters
+func (f *ssoFixture) signIn(t *testing.T) (string, string) {
+	t.Helper()
+	authorization, err := f.uc.StartLogin()
+	require.NoError(t, err)
+	code, state, err := f.idp.SignIn(authorization.AuthorizationURL)
+	require.NoError(t, err)
+	return state, code
+}
+
+func TestSSOUseCase_LinksUserByVerifiedEmail(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "agent@example.com"
+	user := &models.UserResponse{ID: 4, Email: "agent@example.com", Role: models.RoleAgent, EmailVerifiedAt: verifiedNow()}
+	f.userRepo.On("GetByEmail", "agent@example.com").Return(user, nil)
+	f.sessions.On("Create", mock.MatchedBy(func(session *models.Session) bool {
+		return session.UserID == 4
+	})).Return(&models.Session{ID: 30, UserID: 4}, nil)
+
+	state, code := f.signIn(t)
+	loginResponse, err := f.uc.CompleteLogin(state, code, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResponse.AccessToken)
+	assert.Equal(t, uint(4), loginResponse.User.ID)
+	require.Len(t, f.identities.identities, 1)
+	assert.Equal(t, f.idp.URL, f.identities.identities[0].Issuer)
+	assert.Equal(t, "fakeidp-user-1", f.identities.identities[0].Subject)
+	assert.Equal(t, uint(4), f.identities.identities[0].UserID)
+	f.userRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything)
+}
+
+func TestSSOUseCase_FollowsExistingLink(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	// The email changed at the provider since the link was made
+	f.idp.Email = "renamed@example.com"
+	f.identities.identities = []models.UserIdentity{{ID: 1, UserID: 4, Issuer: f.idp.URL, Subject: "fakeidp-user-1"}}
+	f.userRepo.On("GetByID", uint(4)).Return(&models.UserResponse{ID: 4, Email: "agent@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+	f.sessions.On("Create", mock.Anything).Return(&models.Session{ID: 30, UserID: 4}, nil)
+
+	state, code := f.signIn(t)
+	loginResponse, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), loginResponse.User.ID)
+	assert.Len(t, f.identities.identities, 1)
+	f.userRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+}
+
+func TestSSOUseCase_VerifiesLinkedEmail(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "new.agent@example.com"
+	f.userRepo.On("GetByEmail", "new.agent@example.com").Return(&models.UserResponse{ID: 9, Email: "new.agent@example.com"}, nil)
+	f.userRepo.On("MarkEmailVerified", uint(9)).Return(nil)
+	f.userRepo.On("GetByID", uint(9)).Return(&models.UserResponse{ID: 9, Email: "new.agent@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+	f.sessions.On("Create", mock.Anything).Return(&models.Session{ID: 31, UserID: 9}, nil)
+
+	state, code := f.signIn(t)
+	_, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	require.NoError(t, err)
+	f.userRepo.AssertCalled(t, "MarkEmailVerified", uint(9))
+}
+
+func TestSSOUseCase_RefusesUnverifiedProviderEmail(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "agent@example.com"
+	f.idp.EmailVerified = false
+
+	state, code := f.signIn(t)
+	_, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	assert.Error(t, err)
+	assert.Empty(t, f.identities.identities)
+	f.userRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+	f.sessions.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestSSOUseCase_RefusesUnknownEmail(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "stranger@example.com"
+	f.userRepo.On("GetByEmail", "stranger@example.com").Return(nil, errors.New("user not found"))
+
+	state, code := f.signIn(t)
+	_, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invitation")
+	assert.Empty(t, f.identities.identities)
+}
+
+func TestSSOUseCase_RefusesReplayedCallback(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "agent@example.com"
+	f.userRepo.On("GetByEmail", "agent@example.com").Return(&models.UserResponse{ID: 4, Email: "agent@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+	f.sessions.On("Create", mock.Anything).Return(&models.Session{ID: 30, UserID: 4}, nil)
+
+	state, code := f.signIn(t)
+	_, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+	require.NoError(t, err)
+
+	_, err = f.uc.CompleteLogin(state, code, models.ClientInfo{})
+	assert.Error(t, err)
+	f.sessions.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestSSOUseCase_RefusesUnknownState(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	_, code := f.signIn(t)
+
+	_, err := f.uc.CompleteLogin("forged-state", code, models.ClientInfo{})
+
+	assert.Error(t, err)
+	assert.Zero(t, f.idp.Exchanges)
+}
+
+func TestSSOUseCase_RefusesDeactivatedUser(t *testing.T) {
+	f := newSSOFixture(t, withoutTwoFactor())
+	f.idp.Email = "gone@example.com"
+	deactivatedAt := time.Now()
+	f.userRepo.On("GetByEmail", "gone@example.com").Return(&models.UserResponse{ID: 6, Email: "gone@example.com", EmailVerifiedAt: verifiedNow(), DeactivatedAt: &deactivatedAt}, nil)
+
+	state, code := f.signIn(t)
+	_, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	assert.ErrorIs(t, err, models.ErrAccountDeactivated)
+	f.sessions.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestSSOUseCase_AsksForSecondFactor(t *testing.T) {
+	twoFactorRepo := new(MockTwoFactorRepository)
+	twoFactorRepo.On("GetByUserID", uint(4)).Return(enabledCredential(t, 4), nil)
+	f := newSSOFixture(t, twoFactorRepo)
+	f.idp.Email = "agent@example.com"
+	f.userRepo.On("GetByEmail", "agent@example.com").Return(&models.UserResponse{ID: 4, Email: "agent@example.com", EmailVerifiedAt: verifiedNow()}, nil)
+
+	state, code := f.signIn(t)
+	loginResponse, err := f.uc.CompleteLogin(state, code, models.ClientInfo{})
+
+	require.NoError(t, err)
+	assert.True(t, loginResponse.TwoFactorRequired)
+	assert.NotEmpty(t, loginResponse.ChallengeToken)
+	f.sessions.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestSSOUseCase_NotConfigured(t *testing.T) {
+	uc := usecase.NewSSOUseCase(nil, nil, nil, nil, nil, time.Minute)
+
+	_, err := uc.StartLogin()
+	assert.ErrorIs(t, err, models.ErrSSONotConfigured)
+	_, err = uc.CompleteLogin("state", "code", models.ClientInfo{})
+	assert.ErrorIs(t, err, models.ErrSSONotConfigured)
+}
@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+)
+
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) GetAll() ([]models.Tenant, error) {
+	args := m.Called()
+	tenants, _ := args.Get(0).([]models.Tenant)
+	return tenants, args.Error(1)
+}
+func (m *MockTenantRepository) GetByID(id uint) (*models.Tenant, error) {
+	args := m.Called(id)
+	tenant, _ := args.Get(0).(*models.Tenant)
+	return tenant, args.Error(1)
+}
+func (m *MockTenantRepository) GetBySlug(slug string) (*models.Tenant, error) {
+	args := m.Called(slug)
+	tenant, _ := args.Get(0).(*models.Tenant)
+	return tenant, args.Error(1)
+}
+func (m *MockTenantRepository) Create(tenant *models.Tenant) error {
+	args := m.Called(tenant)
+	return args.Error(0)
+}
+func (m *MockTenantRepository) Update(tenant *models.Tenant) error {
+	args := m.Called(tenant)
+	return args.Error(0)
+}
+
+type MockInvitationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockInvitationUseCase) CreateInvitation(caller *models.Caller, invitationData *models.CreateInvitationData) (*models.InvitationResponse, error) {
+	args := m.Called(caller, invitationData)
+	invitation, _ := args.Get(0).(*models.InvitationResponse)
+	return invitation, args.Error(1)
+}
+func (m *MockInvitationUseCase) GetInvitations(caller *models.Caller) ([]models.InvitationResponse, error) {
+	args := m.Called(caller)
+	invitations, _ := args.Get(0).([]models.InvitationResponse)
+	return invitations, args.Error(1)
+}
+func (m *MockInvitationUseCase) ResendInvitation(caller *models.Caller, id uint) (*models.InvitationResponse, error) {
+	args := m.Called(caller, id)
+	invitation, _ := args.Get(0).(*models.InvitationResponse)
+	return invitation, args.Error(1)
+}
+func (m *MockInvitationUseCase) RevokeInvitation(caller *models.Caller, id uint) error {
+	args := m.Called(caller, id)
+	return args.Error(0)
+}
+func (m *MockInvitationUseCase) AcceptInvitation(acceptData *models.AcceptInvitationData) (*models.UserResponse, error) {
+	args := m.Called(acceptData)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
+
+// newTenantUseCase binds every tenant to the same mocked services and records
+// which tenants they were requested for
+func newTenantUseCase(tenantRepo *MockTenantRepository, branchRepo *MockBranchRepository, invitations *MockInvitationUseCase) (*usecase.TenantUseCase, *[]uint) {
+	var boundTo []uint
+	uc := usecase.NewTenantUseCase(tenantRepo, func(tenant *models.Tenant) usecase.TenantServices {
+		boundTo = append(boundTo, tenant.ID)
+		return usecase.TenantServices{Branches: branchRepo, Invitations: invitations}
+	})
+	return uc, &boundTo
+}
+
+func TestTenantUseCase_CreateTenant(t *testing.T) {
+	t.Run("provisions the tenant, its first branch and admin invitation", func(t *testing.T) {
+		tenantRepo, branchRepo, invitations := new(MockTenantRepository), new(MockBranchRepository), new(MockInvitationUseCase)
+		uc, boundTo := newTenantUseCase(tenantRepo, branchRepo, invitations)
+
+		tenantRepo.On("GetBySlug", "acme").Return(nil, models.ErrTenantNotFound)
+		tenantRepo.On("Create", mock.MatchedBy(func(tenant *models.Tenant) bool {
+			return tenant.Slug == "acme" && tenant.Name == "Acme Realty"
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.Tenant).ID = 4
+		}).Return(nil)
+		branchRepo.On("Create", mock.MatchedBy(func(branch *models.Branch) bool {
+			return branch.Name == models.DefaultBranchName
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.Branch).ID = 11
+		}).Return(nil)
+		invitations.On("CreateInvitation",
+			&models.Caller{Role: models.RoleRegionalAdmin, BranchID: 11},
+			&models.CreateInvitationData{Email: "owner@acme.com", Role: models.RoleRegionalAdmin, BranchID: 11},
+		).Return(&models.InvitationResponse{ID: 1, Email: "owner@acme.com"}, nil)
+
+		tenant, err := uc.CreateTenant(&models.TenantData{Slug: " Acme ", Name: "Acme Realty", AdminEmail: "Owner@Acme.com"})
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), tenant.ID)
+		assert.Equal(t, []uint{4}, *boundTo)
+		tenantRepo.AssertExpectations(t)
+		branchRepo.AssertExpectations(t)
+		invitations.AssertExpectations(t)
+	})
+
+	t.Run("rejects a taken slug", func(t *testing.T) {
+		tenantRepo := new(MockTenantRepository)
+		uc, _ := newTenantUseCase(tenantRepo, new(MockBranchRepository), new(MockInvitationUseCase))
+
+		tenantRepo.On("GetBySlug", "acme").Return(&models.Tenant{ID: 2, Slug: "acme"}, nil)
+
+		_, err := uc.CreateTenant(&models.TenantData{Slug: "acme", Name: "Acme", AdminEmail: "owner@acme.com"})
+		assert.EqualError(t, err, "a tenant with this slug already exists")
+		tenantRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("rejects invalid data before storing anything", func(t *testing.T) {
+		for _, tenantData := range []*models.TenantData{
+			{Slug: "-acme", Name: "Acme", AdminEmail: "owner@acme.com"},
+			{Slug: "api", Name: "Acme", AdminEmail: "owner@acme.com"},
+			{Slug: "acme", Name: " ", AdminEmail: "owner@acme.com"},
+			{Slug: "acme", Name: "Acme", AdminEmail: "owner"},
+		} {
+			tenantRepo := new(MockTenantRepository)
+			uc, _ := newTenantUseCase(tenantRepo, new(MockBranchRepository), new(MockInvitationUseCase))
+			tenantRepo.On("GetBySlug", mock.Anything).Return(nil, models.ErrTenantNotFound)
+
+			_, err := uc.CreateTenant(tenantData)
+			assert.Error(t, err, "%+v", *tenantData)
+			tenantRepo.AssertNotCalled(t, "Create", mock.Anything)
+		}
+	})
+}
+
+func TestTenantUseCase_UpdateTenant(t *testing.T) {
+	t.Run("renames a tenant", func(t *testing.T) {
+		tenantRepo := new(MockTenantRepository)
+		uc, _ := newTenantUseCase(tenantRepo, new(MockBranchRepository), new(MockInvitationUseCase))
+
+		tenantRepo.On("GetByID", uint(4)).Return(&models.Tenant{ID: 4, Slug: "acme", Name: "Acme"}, nil)
+		tenantRepo.On("GetBySlug", "acme-realty").Return(nil, models.ErrTenantNotFound)
+		tenantRepo.On("Update", &models.Tenant{ID: 4, Slug: "acme-realty", Name: "Acme Realty"}).Return(nil)
+
+		_, err := uc.UpdateTenant(4, &models.TenantData{Slug: "acme-realty", Name: "Acme Realty"})
+		require.NoError(t, err)
+		tenantRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the slug of the default tenant", func(t *testing.T) {
+		tenantRepo := new(MockTenantRepository)
+		uc, _ := newTenantUseCase(tenantRepo, new(MockBranchRepository), new(MockInvitationUseCase))
+
+		tenantRepo.On("GetByID", uint(1)).Return(&models.Tenant{ID: 1, Slug: models.DefaultTenantSlug}, nil)
+		tenantRepo.On("GetBySlug", "acme").Return(nil, models.ErrTenantNotFound)
+
+		_, err := uc.UpdateTenant(1, &models.TenantData{Slug: "acme", Name: "Acme"})
+		assert.EqualError(t, err, "the slug of the default tenant cannot change")
+		tenantRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestTenantUseCase_InviteAdmin(t *testing.T) {
+	t.Run("invites to the first branch of the tenant", func(t *testing.T) {
+		tenantRepo, branchRepo, invitations := new(MockTenantRepository), new(MockBranchRepository), new(MockInvitationUseCase)
+		uc, boundTo := newTenantUseCase(tenantRepo, branchRepo, invitations)
+
+		tenantRepo.On("GetByID", uint(4)).Return(&models.Tenant{ID: 4, Slug: "acme"}, nil)
+		branchRepo.On("GetAll").Return([]models.Branch{{ID: 11}, {ID: 12}}, nil)
+		invitations.On("CreateInvitation", mock.Anything, &models.CreateInvitationData{
+			Email: "second@acme.com", Role: models.RoleRegionalAdmin, BranchID: 11,
+		}).Return(&models.InvitationResponse{ID: 2}, nil)
+
+		invitation, err := uc.InviteAdmin(4, &models.TenantAdminData{Email: "second@acme.com"})
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), invitation.ID)
+		assert.Equal(t, []uint{4}, *boundTo)
+	})
+
+	t.Run("unknown tenant", func(t *testing.T) {
+		tenantRepo := new(MockTenantRepository)
+		uc, _ := newTenantUseCase(tenantRepo, new(MockBranchRepository), new(MockInvitationUseCase))
+
+		tenantRepo.On("GetByID", uint(9)).Return(nil, models.ErrTenantNotFound)
+
+		_, err := uc.InviteAdmin(9, &models.TenantAdminData{Email: "second@acme.com"})
+		assert.ErrorIs(t, err, models.ErrTenantNotFound)
+	})
+}
@@ -0,0 +1,284 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/usecase"
+	"inmo-backend/middleware"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) GetByUserID(userID uint) (*models.TwoFactorCredential, error) {
+	args := m.Called(userID)
+	credential, _ := args.Get(0).(*models.TwoFactorCredential)
+	return credential, args.Error(1)
+}
+func (m *MockTwoFactorRepository) SavePending(userID uint, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+func (m *MockTwoFactorRepository) Confirm(userID uint, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+func (m *MockTwoFactorRepository) UseStep(userID uint, step int64) error {
+	args := m.Called(userID, step)
+	return args.Error(0)
+}
+func (m *MockTwoFactorRepository) Delete(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	args := m.Called(userID, codeHashes)
+	return args.Error(0)
+}
+func (m *MockTwoFactorRepository) UseRecoveryCode(userID uint, codeHash string) error {
+	args := m.Called(userID, codeHash)
+	return args.Error(0)
+}
+
+// withoutTwoFactor is the repository of users that never enrolled.
+func withoutTwoFactor() *MockTwoFactorRepository {
+	repo := new(MockTwoFactorRepository)
+	repo.On("GetByUserID", mock.Anything).Return(nil, nil).Maybe()
+	return repo
+}
+
+func enabledCredential(t *testing.T, userID uint) *models.TwoFactorCredential {
+	t.Helper()
+	secret, err := middleware.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().Add(-time.Hour)
+	return &models.TwoFactorCredential{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := middleware.TOTPCode(secret, middleware.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestTwoFactorUseCase_Enroll(t *testing.T) {
+	agent := &models.Caller{UserID: 4, SessionID: 12, Role: models.RoleAgent}
+
+	t.Run("returns an otpauth URI for a new pending secret", func(t *testing.T) {
+		userRepo, twoFactorRepo := new(MockUserRepository), new(MockTwoFactorRepository)
+		uc := usecase.NewTwoFactorUseCase(userRepo, twoFactorRepo, new(MockSessionRepository), "Inmo")
+
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(nil, nil)
+		userRepo.On("GetByID", uint(4)).Return(&models.UserResponse{ID: 4, Email: "ana@example.com"}, nil)
+		twoFactorRepo.On("SavePending", uint(4), mock.AnythingOfType("string")).Return(nil)
+
+		enrollment, err := uc.Enroll(agent)
+		require.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/Inmo:ana@example.com?"))
+		assert.Contains(t, enrollment.OTPAuthURI, "secret="+enrollment.Secret)
+		twoFactorRepo.AssertCalled(t, "SavePending", uint(4), enrollment.Secret)
+	})
+
+	t.Run("rejects a second enrollment", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		uc := usecase.NewTwoFactorUseCase(new(MockUserRepository), twoFactorRepo, new(MockSessionRepository), "Inmo")
+
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(enabledCredential(t, 4), nil)
+
+		_, err := uc.Enroll(agent)
+		assert.EqualError(t, err, "two-factor authentication is already enabled")
+		twoFactorRepo.AssertNotCalled(t, "SavePending", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTwoFactorUseCase_Confirm(t *testing.T) {
+	admin := &models.Caller{UserID: 1, SessionID: 3, Role: models.RoleAdmin}
+	secret, err := middleware.GenerateTOTPSecret()
+	require.NoError(t, err)
+	pending := &models.TwoFactorCredential{UserID: 1, Secret: secret}
+
+	t.Run("enables 2FA, stores hashed recovery codes and verifies the session", func(t *testing.T) {
+		twoFactorRepo, sessions := new(MockTwoFactorRepository), new(MockSessionRepository)
+		uc := usecase.NewTwoFactorUseCase(new(MockUserRepository), twoFactorRepo, sessions, "Inmo")
+
+		var storedHashes []string
+		twoFactorRepo.On("GetByUserID", uint(1)).Return(pending, nil)
+		twoFactorRepo.On("Confirm", uint(1), middleware.TOTPStep(time.Now())).Return(nil)
+		twoFactorRepo.On("ReplaceRecoveryCodes", uint(1), mock.Anything).Run(func(args mock.Arguments) {
+			storedHashes = args.Get(1).([]string)
+		}).Return(nil)
+		sessions.On("MarkTwoFactorVerified", uint(3)).Return(nil)
+
+		response, err := uc.Confirm(admin, currentCode(t, secret))
+		require.NoError(t, err)
+		require.Len(t, response.RecoveryCodes, 10)
+		require.Len(t, storedHashes, 10)
+		for i, code := range response.RecoveryCodes {
+			assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+			assert.NotContains(t, storedHashes, code, "Recovery codes must not be stored in plain text")
+			assert.Equal(t, middleware.HashToken(strings.ReplaceAll(code, "-", "")), storedHashes[i])
+		}
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("rejects a wrong code", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		uc := usecase.NewTwoFactorUseCase(new(MockUserRepository), twoFactorRepo, new(MockSessionRepository), "Inmo")
+
+		twoFactorRepo.On("GetByUserID", uint(1)).Return(pending, nil)
+
+		_, err := uc.Confirm(admin, "000000x")
+		assert.EqualError(t, err, "invalid two-factor code")
+		twoFactorRepo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTwoFactorUseCase_Disable(t *testing.T) {
+	t.Run("is mandatory for admins", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		uc := usecase.NewTwoFactorUseCase(new(MockUserRepository), twoFactorRepo, new(MockSessionRepository), "Inmo")
+
+		err := uc.Disable(&models.Caller{UserID: 1, Role: models.RoleAdmin}, "123456")
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		twoFactorRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("removes the credential after checking a code", func(t *testing.T) {
+		twoFactorRepo := new(MockTwoFactorRepository)
+		uc := usecase.NewTwoFactorUseCase(new(MockUserRepository), twoFactorRepo, new(MockSessionRepository), "Inmo")
+
+		credential := enabledCredential(t, 4)
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(credential, nil)
+		twoFactorRepo.On("UseStep", uint(4), middleware.TOTPStep(time.Now())).Return(nil)
+		twoFactorRepo.On("Delete", uint(4)).Return(nil)
+
+		err := uc.Disable(&models.Caller{UserID: 4, Role: models.RoleAgent}, currentCode(t, credential.Secret))
+		require.NoError(t, err)
+		twoFactorRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUseCase_TwoFactorLogin(t *testing.T) {
+	const email = "ana@example.com"
+	const password = "mypassword123"
+	hash, err := middleware.HashPassword(password)
+	require.NoError(t, err)
+	user := &models.UserResponse{ID: 4, Email: email, Role: models.RoleAgent, EmailVerifiedAt: verifiedNow()}
+
+	t.Run("password step returns a challenge instead of a session", func(t *testing.T) {
+		userRepo, sessions, twoFactorRepo := new(MockUserRepository), new(MockSessionRepository), new(MockTwoFactorRepository)
+		uc := usecase.NewUserUseCase(userRepo, sessions, twoFactorRepo, newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		userRepo.On("ConsultPassword", email).Return(hash, nil)
+		userRepo.On("GetByEmail", email).Return(user, nil)
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(enabledCredential(t, 4), nil)
+
+		response, err := uc.Login(email, password, models.ClientInfo{})
+		require.NoError(t, err)
+		assert.True(t, response.TwoFactorRequired)
+		assert.NotEmpty(t, response.ChallengeToken)
+		assert.Empty(t, response.AccessToken)
+		assert.Empty(t, response.RefreshToken)
+		sessions.AssertNotCalled(t, "Create", mock.Anything)
+
+		userID, err := newTestTokenManager().ParseTwoFactorChallenge(response.ChallengeToken)
+		require.NoError(t, err)
+		assert.Equal(t, uint(4), userID)
+	})
+
+	t.Run("a valid code completes the login with a verified session", func(t *testing.T) {
+		userRepo, sessions, twoFactorRepo := new(MockUserRepository), new(MockSessionRepository), new(MockTwoFactorRepository)
+		uc := usecase.NewUserUseCase(userRepo, sessions, twoFactorRepo, newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		credential := enabledCredential(t, 4)
+		challenge, _, err := newTestTokenManager().GenerateTwoFactorChallenge(4)
+		require.NoError(t, err)
+
+		userRepo.On("GetByID", uint(4)).Return(user, nil)
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(credential, nil)
+		twoFactorRepo.On("UseStep", uint(4), middleware.TOTPStep(time.Now())).Return(nil)
+		sessions.On("Create", mock.MatchedBy(func(session *models.Session) bool {
+			return session.UserID == 4 && session.TwoFactorVerified
+		})).Return(&models.Session{ID: 20, UserID: 4, TwoFactorVerified: true}, nil)
+
+		response, err := uc.CompleteTwoFactorLogin(challenge, currentCode(t, credential.Secret), "", models.ClientInfo{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.AccessToken)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("a recovery code completes the login", func(t *testing.T) {
+		userRepo, sessions, twoFactorRepo := new(MockUserRepository), new(MockSessionRepository), new(MockTwoFactorRepository)
+		uc := usecase.NewUserUseCase(userRepo, sessions, twoFactorRepo, newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		challenge, _, err := newTestTokenManager().GenerateTwoFactorChallenge(4)
+		require.NoError(t, err)
+
+		userRepo.On("GetByID", uint(4)).Return(user, nil)
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(enabledCredential(t, 4), nil)
+		twoFactorRepo.On("UseRecoveryCode", uint(4), middleware.HashToken("abcdefghijklmnop")).Return(nil)
+		sessions.On("Create", mock.Anything).Return(&models.Session{ID: 21, UserID: 4, TwoFactorVerified: true}, nil)
+
+		_, err = uc.CompleteTwoFactorLogin(challenge, "", "ABCD-EFGH-IJKL-MNOP", models.ClientInfo{})
+		require.NoError(t, err)
+		twoFactorRepo.AssertExpectations(t)
+	})
+
+	t.Run("wrong codes count as failed logins", func(t *testing.T) {
+		userRepo, sessions, twoFactorRepo := new(MockUserRepository), new(MockSessionRepository), new(MockTwoFactorRepository)
+		uc := usecase.NewUserUseCase(userRepo, sessions, twoFactorRepo, newTestTokenManager(), newLockoutOnlyLoginGuard(), unusedEmailVerifier())
+
+		challenge, _, err := newTestTokenManager().GenerateTwoFactorChallenge(4)
+		require.NoError(t, err)
+
+		userRepo.On("GetByID", uint(4)).Return(user, nil)
+		twoFactorRepo.On("GetByUserID", uint(4)).Return(enabledCredential(t, 4), nil)
+		twoFactorRepo.On("UseRecoveryCode", uint(4), mock.Anything).Return(errors.New("recovery code not found or already used"))
+
+		for i := 0; i < testLoginPolicy.MaxAttempts; i++ {
+			_, err := uc.CompleteTwoFactorLogin(challenge, "", "wrong-code", models.ClientInfo{})
+			require.EqualError(t, err, "invalid two-factor code")
+		}
+
+		_, err = uc.CompleteTwoFactorLogin(challenge, "", "wrong-code", models.ClientInfo{})
+		assert.ErrorIs(t, err, models.ErrTooManyAttempts)
+		sessions.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("rejects an access token used as challenge", func(t *testing.T) {
+		uc := usecase.NewUserUseCase(new(MockUserRepository), new(MockSessionRepository), new(MockTwoFactorRepository), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		accessToken, _, err := newTestTokenManager().GenerateAccessToken(user, 1)
+		require.NoError(t, err)
+
+		_, err = uc.CompleteTwoFactorLogin(accessToken, "123456", "", models.ClientInfo{})
+		assert.EqualError(t, err, "invalid or expired two-factor challenge")
+	})
+
+	t.Run("admins without 2FA get a session restricted to enrollment", func(t *testing.T) {
+		userRepo, sessions := new(MockUserRepository), new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(userRepo, sessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		userRepo.On("ConsultPassword", "boss@example.com").Return(hash, nil)
+		userRepo.On("GetByEmail", "boss@example.com").Return(&models.UserResponse{ID: 1, Email: "boss@example.com", Role: models.RoleAdmin, EmailVerifiedAt: verifiedNow()}, nil)
+		sessions.On("Create", mock.MatchedBy(func(session *models.Session) bool {
+			return !session.TwoFactorVerified
+		})).Return(&models.Session{ID: 22, UserID: 1}, nil)
+
+		response, err := uc.Login("boss@example.com", password, models.ClientInfo{})
+		require.NoError(t, err)
+		assert.True(t, response.TwoFactorSetupRequired)
+		assert.NotEmpty(t, response.AccessToken)
+	})
+}
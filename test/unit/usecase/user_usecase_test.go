@@ -2,13 +2,18 @@ package usecase_test
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 
 	"inmo-backend/internal/domain/models"
+	"inmo-backend/internal/infrastructure/repository"
 	"inmo-backend/internal/usecase"
 	"inmo-backend/middleware"
 )
@@ -31,13 +36,23 @@ func (m *MockUserRepository) GetByID(id uint) (*models.UserResponse, error) {
 	}
 	return nil, args.Error(1)
 }
+func (m *MockUserRepository) GetByIDIncludingDeleted(id uint) (*models.UserResponse, error) {
+	args := m.Called(id)
+	user, _ := args.Get(0).(*models.UserResponse)
+	return user, args.Error(1)
+}
 func (m *MockUserRepository) ConsultPassword(username string) (string, error) {
 	args := m.Called(username)
 	return args.String(0), args.Error(1)
 }
-func (m *MockUserRepository) GetAll() ([]models.UserResponse, error) {
-	args := m.Called()
-	return args.Get(0).([]models.UserResponse), args.Error(1)
+func (m *MockUserRepository) GetPage(branchID uint, page *models.PageRequest) (*models.Page[models.UserResponse], error) {
+	args := m.Called(branchID, page)
+	users, _ := args.Get(0).(*models.Page[models.UserResponse])
+	return users, args.Error(1)
+}
+func (m *MockUserRepository) UpdateBranch(id uint, version uint, branchID uint) error {
+	args := m.Called(id, version, branchID)
+	return args.Error(0)
 }
 func (m *MockUserRepository) Update(user *models.User) (*models.UserResponse, error) {
 	args := m.Called(user)
@@ -46,8 +61,41 @@ func (m *MockUserRepository) Update(user *models.User) (*models.UserResponse, er
 	}
 	return nil, args.Error(1)
 }
-func (m *MockUserRepository) Delete(userID uint) error {
-	args := m.Called(userID)
+func (m *MockUserRepository) UpdateRole(id uint, version uint, role models.UserRole) error {
+	args := m.Called(id, version, role)
+	return args.Error(0)
+}
+func (m *MockUserRepository) UpdateProfile(id uint, version uint, profile *models.UserProfile) error {
+	args := m.Called(id, version, profile)
+	return args.Error(0)
+}
+func (m *MockUserRepository) UpdatePassword(id uint, hashedPassword string) error {
+	args := m.Called(id, hashedPassword)
+	return args.Error(0)
+}
+func (m *MockUserRepository) MarkEmailVerified(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockUserRepository) Delete(userID uint, version uint) error {
+	args := m.Called(userID, version)
+	return args.Error(0)
+}
+func (m *MockUserRepository) Deactivate(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockUserRepository) GetDeleted(branchID uint) ([]models.UserResponse, error) {
+	args := m.Called(branchID)
+	users, _ := args.Get(0).([]models.UserResponse)
+	return users, args.Error(1)
+}
+func (m *MockUserRepository) Restore(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockUserRepository) Purge(id uint) error {
+	args := m.Called(id)
 	return args.Error(0)
 }
 func (m *MockUserRepository) GetByEmail(email string) (*models.UserResponse, error) {
@@ -56,6 +104,71 @@ func (m *MockUserRepository) GetByEmail(email string) (*models.UserResponse, err
 	return user, args.Error(1)
 }
 
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Create(session *models.Session) (*models.Session, error) {
+	args := m.Called(session)
+	if created, ok := args.Get(0).(*models.Session); ok {
+		return created, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockSessionRepository) GetByID(id uint) (*models.Session, error) {
+	args := m.Called(id)
+	if session, ok := args.Get(0).(*models.Session); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockSessionRepository) GetByRefreshTokenHash(hash string) (*models.Session, error) {
+	args := m.Called(hash)
+	if session, ok := args.Get(0).(*models.Session); ok {
+		return session, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockSessionRepository) GetActiveByUserID(userID uint) ([]models.Session, error) {
+	args := m.Called(userID)
+	if sessions, ok := args.Get(0).([]models.Session); ok {
+		return sessions, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (m *MockSessionRepository) Rotate(id uint, currentHash string, newHash string, expiresAt time.Time, client models.ClientInfo) error {
+	args := m.Called(id, currentHash, newHash, expiresAt, client)
+	return args.Error(0)
+}
+func (m *MockSessionRepository) Revoke(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockSessionRepository) MarkTwoFactorVerified(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockSessionRepository) RevokeAllByUserID(userID uint, exceptID uint) error {
+	args := m.Called(userID, exceptID)
+	return args.Error(0)
+}
+
+func newTestTokenManager() *middleware.TokenManager {
+	return middleware.NewTokenManager("test-secret", "inmo-backend-test", 15*time.Minute, 24*time.Hour)
+}
+
+func newTestLoginGuard() *usecase.LoginGuard {
+	return usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), testLoginPolicy)
+}
+
+// newLockoutOnlyLoginGuard skips the backoff so tests using the real clock
+// can reach the lockout threshold without waiting.
+func newLockoutOnlyLoginGuard() *usecase.LoginGuard {
+	policy := testLoginPolicy
+	policy.BackoffBase = 0
+	return usecase.NewLoginGuard(repository.NewMemoryLoginAttemptStore(), policy)
+}
+
 func TestUserUsecase_CreateUser(t *testing.T) {
 	type testCase struct {
 		name           string
@@ -122,7 +235,7 @@ func TestUserUsecase_CreateUser(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(MockUserRepository)
-			usecase := usecase.NewUserUseCase(mockRepo)
+			usecase := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), acceptingEmailVerifier())
 			userResponse := &models.UserResponse{
 				ID:        1,
 				Username:  tc.user.Username,
@@ -147,23 +260,40 @@ func TestUserUsecase_CreateUser(t *testing.T) {
 func TestUserUsecase_Login(t *testing.T) {
 	t.Run("successful login", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		uc := usecase.NewUserUseCase(mockRepo)
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 		email := "test@example.com"
 		password := "mypassword123"
+		client := models.ClientInfo{IPAddress: "10.0.0.1", UserAgent: "Shared tablet"}
 
 		hash, err := middleware.HashPassword(password)
 		require.NoError(t, err)
 
 		mockRepo.On("ConsultPassword", email).Return(hash, nil)
+		mockRepo.On("GetByEmail", email).Return(&models.UserResponse{ID: 7, Email: email, EmailVerifiedAt: verifiedNow()}, nil)
+		mockSessions.On("Create", mock.MatchedBy(func(session *models.Session) bool {
+			return session.UserID == 7 && session.UserAgent == "Shared tablet" && session.RefreshTokenHash != ""
+		})).Return(&models.Session{ID: 11, UserID: 7}, nil)
 
-		err = uc.Login(email, password)
-		assert.NoError(t, err)
+		loginResponse, err := uc.Login(email, password, client)
+		require.NoError(t, err)
+		assert.NotEmpty(t, loginResponse.AccessToken)
+		assert.NotEmpty(t, loginResponse.RefreshToken)
+		assert.Equal(t, "Bearer", loginResponse.TokenType)
+		assert.Equal(t, uint(7), loginResponse.User.ID)
+
+		claims, err := newTestTokenManager().ParseAccessToken(loginResponse.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, uint(7), claims.UserID)
+		assert.Equal(t, uint(11), claims.SessionID)
+		assert.WithinDuration(t, loginResponse.ExpiresAt, claims.ExpiresAt.Time, time.Second)
+		mockSessions.AssertExpectations(t)
 	})
 
 	t.Run("wrong password", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		uc := usecase.NewUserUseCase(mockRepo)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 		email := "test@example.com"
 		correctPassword := "mypassword123"
@@ -176,55 +306,227 @@ func TestUserUsecase_Login(t *testing.T) {
 		mockRepo.On("ConsultPassword", email).Return(hash, nil)
 
 		// Try to login with wrong password
-		err = uc.Login(email, wrongPassword)
+		_, err = uc.Login(email, wrongPassword, models.ClientInfo{})
 		assert.Error(t, err)
 	})
 
 	t.Run("user not found", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		uc := usecase.NewUserUseCase(mockRepo)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 		mockRepo.On("ConsultPassword", "notfound@test.com").Return("", errors.New("user not found"))
 
-		err := uc.Login("notfound@test.com", "anypassword")
+		_, err := uc.Login("notfound@test.com", "anypassword", models.ClientInfo{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "user not found")
 	})
 
 	t.Run("hashing error", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		uc := usecase.NewUserUseCase(mockRepo)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 		mockRepo.On("ConsultPassword", "hashingerror@test.com").Return("", errors.New("hashing error"))
 
-		err := uc.Login("hashingerror@test.com", "anyPassword")
+		_, err := uc.Login("hashingerror@test.com", "anyPassword", models.ClientInfo{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "hashing error")
 	})
+
+	t.Run("rehashes a password weaker than the current policy", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		email := "legacy@example.com"
+		password := "mypassword123"
+		weakHash, err := middleware.NewBcryptHasher(bcrypt.MinCost).Hash(password)
+		require.NoError(t, err)
+
+		mockRepo.On("ConsultPassword", email).Return(weakHash, nil)
+		mockRepo.On("GetByEmail", email).Return(&models.UserResponse{ID: 8, Email: email, EmailVerifiedAt: verifiedNow()}, nil)
+		mockRepo.On("UpdatePassword", uint(8), mock.MatchedBy(func(hashed string) bool {
+			return !middleware.PasswordNeedsRehash(hashed) && middleware.VerifyPassword(hashed, password) == nil
+		})).Return(nil)
+		mockSessions.On("Create", mock.Anything).Return(&models.Session{ID: 12, UserID: 8}, nil)
+
+		_, err = uc.Login(email, password, models.ClientInfo{})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("locks the account after repeated failures", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newLockoutOnlyLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("ConsultPassword", "locked@test.com").Return("", errors.New("user not found"))
+
+		for i := 0; i < testLoginPolicy.MaxAttempts; i++ {
+			_, err := uc.Login("locked@test.com", "anyPassword", models.ClientInfo{IPAddress: "10.0.0.2"})
+			require.NotErrorIs(t, err, models.ErrTooManyAttempts)
+		}
+
+		_, err := uc.Login("locked@test.com", "anyPassword", models.ClientInfo{IPAddress: "10.0.0.3"})
+		var locked *models.LoginLockedError
+		require.ErrorAs(t, err, &locked)
+		assert.InDelta(t, testLoginPolicy.LockoutDuration.Seconds(), locked.RetryAfter.Seconds(), 1)
+		mockRepo.AssertNumberOfCalls(t, "ConsultPassword", testLoginPolicy.MaxAttempts)
+	})
+}
+
+func TestUserUseCase_UnlockUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newLockoutOnlyLoginGuard(), unusedEmailVerifier())
+
+	mockRepo.On("ConsultPassword", "ana@test.com").Return("", errors.New("user not found")).Times(testLoginPolicy.MaxAttempts)
+	mockRepo.On("GetByID", uint(3)).Return(&models.UserResponse{ID: 3, Email: "ana@test.com", BranchID: 1}, nil)
+
+	for i := 0; i < testLoginPolicy.MaxAttempts; i++ {
+		_, _ = uc.Login("ana@test.com", "anyPassword", models.ClientInfo{})
+	}
+	_, err := uc.Login("ana@test.com", "anyPassword", models.ClientInfo{})
+	require.ErrorIs(t, err, models.ErrTooManyAttempts)
+
+	require.NoError(t, uc.UnlockUser(adminCaller, 3))
+
+	mockRepo.On("ConsultPassword", "ana@test.com").Return("", errors.New("user not found")).Once()
+	_, err = uc.Login("ana@test.com", "anyPassword", models.ClientInfo{})
+	assert.NotErrorIs(t, err, models.ErrTooManyAttempts)
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestUserUseCase_RefreshSession(t *testing.T) {
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		oldHash := middleware.HashToken("old-token")
+		session := &models.Session{ID: 3, UserID: 7, RefreshTokenHash: oldHash, ExpiresAt: time.Now().Add(time.Hour)}
+		mockSessions.On("GetByRefreshTokenHash", oldHash).Return(session, nil)
+		mockRepo.On("GetByID", uint(7)).Return(&models.UserResponse{ID: 7}, nil)
+		mockSessions.On("Rotate", uint(3), oldHash, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time"), models.ClientInfo{}).Return(nil)
+
+		loginResponse, err := uc.RefreshSession("old-token", models.ClientInfo{})
+		require.NoError(t, err)
+		assert.NotEqual(t, "old-token", loginResponse.RefreshToken)
+
+		claims, err := newTestTokenManager().ParseAccessToken(loginResponse.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, uint(3), claims.SessionID)
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("reused token revokes the session", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		reusedHash := middleware.HashToken("stolen-token")
+		session := &models.Session{ID: 3, UserID: 7, RefreshTokenHash: "current-hash", PreviousTokenHash: &reusedHash, ExpiresAt: time.Now().Add(time.Hour)}
+		mockSessions.On("GetByRefreshTokenHash", reusedHash).Return(session, nil)
+		mockSessions.On("Revoke", uint(3)).Return(nil)
+
+		_, err := uc.RefreshSession("stolen-token", models.ClientInfo{})
+		assert.Error(t, err)
+		mockSessions.AssertExpectations(t)
+		mockSessions.AssertNotCalled(t, "Rotate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked session", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		hash := middleware.HashToken("token")
+		revokedAt := time.Now()
+		session := &models.Session{ID: 3, UserID: 7, RefreshTokenHash: hash, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		mockSessions.On("GetByRefreshTokenHash", hash).Return(session, nil)
+
+		_, err := uc.RefreshSession("token", models.ClientInfo{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "revoked or expired")
+	})
+
+	t.Run("empty token", func(t *testing.T) {
+		uc := usecase.NewUserUseCase(new(MockUserRepository), new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.RefreshSession("", models.ClientInfo{})
+		assert.Error(t, err)
+	})
+}
+
+func TestUserUseCase_GetSessions(t *testing.T) {
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUseCase(new(MockUserRepository), mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	mockSessions.On("GetActiveByUserID", uint(7)).Return([]models.Session{
+		{ID: 3, UserID: 7, UserAgent: "Phone"},
+		{ID: 4, UserID: 7, UserAgent: "Tablet"},
+	}, nil)
+
+	sessions, err := uc.GetSessions(7, 4)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestUserUseCase_RevokeSession(t *testing.T) {
+	t.Run("own session", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(new(MockUserRepository), mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockSessions.On("GetByID", uint(4)).Return(&models.Session{ID: 4, UserID: 7}, nil)
+		mockSessions.On("Revoke", uint(4)).Return(nil)
+
+		assert.NoError(t, uc.RevokeSession(7, 4))
+		mockSessions.AssertExpectations(t)
+	})
+
+	t.Run("session of another user", func(t *testing.T) {
+		mockSessions := new(MockSessionRepository)
+		uc := usecase.NewUserUseCase(new(MockUserRepository), mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockSessions.On("GetByID", uint(4)).Return(&models.Session{ID: 4, UserID: 8}, nil)
+
+		assert.Error(t, uc.RevokeSession(7, 4))
+		mockSessions.AssertNotCalled(t, "Revoke", uint(4))
+	})
 }
 
 func TestUserUseCase_GetAllUsers(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 	expectedUsers := []models.UserResponse{
 		{ID: 1, Username: "user1", Email: "user1@email.com"},
 		{ID: 2, Username: "user2", Email: "user2@email.com"},
 	}
-	mockRepo.On("GetAll").Return(expectedUsers, nil)
-	users, err := uc.GetAllUsers()
+	mockRepo.On("GetPage", uint(1), mock.MatchedBy(func(page *models.PageRequest) bool {
+		return page.Limit == models.DefaultPageLimit && page.Sort == "-created_at"
+	})).Return(&models.Page[models.UserResponse]{Items: expectedUsers, Pagination: models.Pagination{Total: 2}}, nil)
+	users, err := uc.GetAllUsers(&models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}, 0, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, expectedUsers, users)
+	assert.Equal(t, expectedUsers, users.Items)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserUseCase_GetAllUsers_InvalidPage(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	_, err := uc.GetAllUsers(adminCaller, 0, &models.PageRequest{Sort: "password"})
+	assert.ErrorIs(t, err, models.ErrInvalidPage)
+	mockRepo.AssertNotCalled(t, "GetPage", mock.Anything, mock.Anything)
+}
+
 func TestUserUseCase_GetUserByID(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
-	expectedUser := &models.UserResponse{ID: 1, Username: "user1", Email: "user1@email.com"}
+	expectedUser := &models.UserResponse{ID: 1, Username: "user1", Email: "user1@email.com", BranchID: 1}
 	mockRepo.On("GetByID", uint(1)).Return(expectedUser, nil)
-	user, err := uc.GetUserByID(1)
+	user, err := uc.GetUserByID(&models.Caller{UserID: 2, Role: models.RoleAdmin, BranchID: 1}, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedUser, user)
 	mockRepo.AssertExpectations(t)
@@ -232,18 +534,119 @@ func TestUserUseCase_GetUserByID(t *testing.T) {
 
 func TestUserUseCase_GetUserByID_NotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 	mockRepo.On("GetByID", uint(999)).Return(nil, errors.New("user not found"))
-	user, err := uc.GetUserByID(999)
+	user, err := uc.GetUserByID(&models.Caller{UserID: 2, Role: models.RoleAdmin, BranchID: 1}, 999)
 	assert.Error(t, err)
 	assert.Nil(t, user)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserUseCase_BranchScope(t *testing.T) {
+	admin := &models.Caller{UserID: 2, Role: models.RoleAdmin, BranchID: 1}
+	regional := &models.Caller{UserID: 3, Role: models.RoleRegionalAdmin}
+
+	t.Run("refuses to list another branch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.GetAllUsers(admin, 2, nil)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = uc.GetAllUsers(&models.Caller{UserID: 4, Role: models.RoleAgent}, 0, nil)
+		assert.ErrorIs(t, err, models.ErrForbidden, "Callers without a branch see nothing")
+		mockRepo.AssertNotCalled(t, "GetPage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("regional admins list everyone", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetPage", uint(0), mock.Anything).
+			Return(&models.Page[models.UserResponse]{Items: []models.UserResponse{{ID: 1, BranchID: 1}, {ID: 5, BranchID: 2}}}, nil)
+
+		users, err := uc.GetAllUsers(regional, 0, nil)
+		require.NoError(t, err)
+		assert.Len(t, users.Items, 2)
+	})
+
+	t.Run("hides a user of another branch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, BranchID: 2}, nil)
+
+		_, err := uc.GetUserByID(admin, 5)
+		assert.EqualError(t, err, "user not found")
+		_, err = uc.GetUserByID(regional, 5)
+		assert.NoError(t, err)
+		_, err = uc.GetUserByID(&models.Caller{UserID: 5, Role: models.RoleAgent}, 5)
+		assert.NoError(t, err, "Everyone can read their own account")
+	})
+
+	t.Run("refuses to manage a user of another branch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		other := &models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 2}
+		mockRepo.On("GetByID", uint(5)).Return(other, nil)
+		mockRepo.On("GetByIDIncludingDeleted", uint(5)).Return(other, nil)
+
+		_, err := uc.UpdateUser(admin, &models.User{ID: 5, Email: "other@test.com", Username: "other"})
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+		_, err = uc.UpdateUserRole(admin, 5, 1, models.RoleAdmin)
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+		_, err = uc.DeactivateUser(admin, 5)
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+		assert.ErrorIs(t, uc.DeleteUser(admin, 5, 1), models.ErrUserNotFound)
+		_, err = uc.RestoreUser(admin, 5)
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+		assert.ErrorIs(t, uc.PurgeUser(admin, 5), models.ErrUserNotFound)
+		assert.ErrorIs(t, uc.UnlockUser(admin, 5), models.ErrUserNotFound)
+		_, err = uc.MarkEmailVerified(admin, 5)
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Deactivate", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Restore", mock.Anything)
+		mockRepo.AssertNotCalled(t, "Purge", mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkEmailVerified", mock.Anything)
+	})
+
+	t.Run("only regional admins change a regional admin", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByID", uint(7)).Return(&models.UserResponse{ID: 7, Role: models.RoleRegionalAdmin, BranchID: 1}, nil)
+
+		_, err := uc.UpdateUserRole(admin, 7, 1, models.RoleAgent)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		_, err = uc.DeactivateUser(admin, 7)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "Deactivate", mock.Anything)
+	})
+
+	t.Run("lists deleted users of the caller's branch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetDeleted", uint(1)).Return([]models.UserResponse{{ID: 4, BranchID: 1}}, nil)
+
+		users, err := uc.GetDeletedUsers(admin, 0)
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+		_, err = uc.GetDeletedUsers(admin, 2)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "GetDeleted", uint(2))
+	})
+}
+
 func TestUserUseCase_UpdateUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 	userToUpdate := &models.User{
 		ID:       1,
@@ -258,31 +661,330 @@ func TestUserUseCase_UpdateUser(t *testing.T) {
 		Email:    userToUpdate.Email,
 	}
 
+	mockRepo.On("GetByID", uint(1)).Return(&models.UserResponse{ID: 1, Username: "user", Email: "update@update.com", BranchID: 1}, nil)
 	mockRepo.On("Update", userToUpdate).Return(userResponse, nil)
-	_, err := uc.UpdateUser(userToUpdate)
+	_, err := uc.UpdateUser(adminCaller, userToUpdate)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserUseCase_PatchUser(t *testing.T) {
+	current := &models.UserResponse{ID: 3, Username: "ana", Email: "ana@example.com", Role: models.RoleAgent, BranchID: 1, Version: 2}
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}
+	patchOf := func(body string) models.MergePatch {
+		patch, err := models.ParseMergePatch([]byte(body))
+		require.NoError(t, err)
+		return patch
+	}
+
+	t.Run("changes the username and keeps the email", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+		updated := &models.UserResponse{ID: 3, Username: "ana.r", Email: "ana@example.com", Role: models.RoleAgent, BranchID: 1, Version: 3}
+
+		mockRepo.On("GetByID", uint(3)).Return(current, nil).Twice()
+		mockRepo.On("Update", &models.User{ID: 3, Username: "ana.r", Email: "ana@example.com", Version: 2}).Return(updated, nil)
+		mockRepo.On("GetByID", uint(3)).Return(updated, nil).Once()
+
+		user, err := uc.PatchUser(admin, 3, 2, patchOf(`{"username": "ana.r"}`))
+		require.NoError(t, err)
+		assert.Equal(t, updated, user)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("refuses to remove required fields", func(t *testing.T) {
+		for _, body := range []string{`{"username": null}`, `{"username": " "}`, `{"email": null}`} {
+			mockRepo := new(MockUserRepository)
+			uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+			mockRepo.On("GetByID", uint(3)).Return(current, nil)
+
+			_, err := uc.PatchUser(admin, 3, 2, patchOf(body))
+			assert.ErrorIs(t, err, models.ErrInvalidPatch, body)
+			mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+		}
+	})
+
+	t.Run("refuses fields PUT cannot change either", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+		mockRepo.On("GetByID", uint(3)).Return(current, nil)
+
+		_, err := uc.PatchUser(admin, 3, 2, patchOf(`{"role": "admin"}`))
+		assert.ErrorIs(t, err, models.ErrInvalidPatch)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("refuses a stale version", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+		mockRepo.On("GetByID", uint(3)).Return(current, nil)
+
+		_, err := uc.PatchUser(admin, 3, 1, patchOf(`{"username": "ana.r"}`))
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		_, err = uc.UpdateUser(admin, &models.User{ID: 3, Username: "ana.r", Email: "ana@example.com", Version: 1})
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+
+	t.Run("users of other branches are not found", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+		mockRepo.On("GetByID", uint(3)).Return(current, nil)
+
+		_, err := uc.PatchUser(&models.Caller{UserID: 2, Role: models.RoleAdmin, BranchID: 2}, 3, 2, patchOf(`{"username": "x"}`))
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestUserUseCase_CreateUser_DefaultRole(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), acceptingEmailVerifier())
+
+	user := &models.User{Username: "assistant", Email: "assistant@example.com", Password: "testpassword"}
+	mockRepo.On("Create", mock.MatchedBy(func(u *models.User) bool {
+		return u.Role == models.RoleAssistant
+	})).Return(&models.UserResponse{ID: 1, Role: models.RoleAssistant}, nil)
+
+	_, err := uc.CreateUser(user)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserUseCase_UpdateUserRole(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}
+
+	t.Run("promotes another user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("UpdateRole", uint(5), uint(2), models.RoleAgent).Return(nil)
+		mockRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAgent, BranchID: 1, Version: 2}, nil)
+
+		user, err := uc.UpdateUserRole(admin, 5, 2, models.RoleAgent)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleAgent, user.Role)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a stale version", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByID", uint(5)).Return(&models.UserResponse{ID: 5, Role: models.RoleAssistant, BranchID: 1, Version: 3}, nil)
+
+		_, err := uc.UpdateUserRole(admin, 5, 2, models.RoleAgent)
+		assert.ErrorIs(t, err, models.ErrStaleVersion)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.UpdateUserRole(admin, 5, 2, models.UserRole("owner"))
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("only regional admins grant the regional admin role", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.UpdateUserRole(admin, 5, 2, models.RoleRegionalAdmin)
+		assert.ErrorIs(t, err, models.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects changing own role", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.UpdateUserRole(admin, 1, 2, models.RoleAssistant)
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserUseCase_EnsureAdmin(t *testing.T) {
+	t.Run("promotes an existing user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByEmail", "boss@example.com").Return(&models.UserResponse{ID: 3, Role: models.RoleAssistant, Version: 4}, nil)
+		mockRepo.On("UpdateRole", uint(3), uint(4), models.RoleRegionalAdmin).Return(nil)
+
+		assert.NoError(t, uc.EnsureAdmin(&models.User{Email: "boss@example.com"}))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("leaves an existing admin alone", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByEmail", "boss@example.com").Return(&models.UserResponse{ID: 3, Role: models.RoleRegionalAdmin}, nil)
+
+		assert.NoError(t, uc.EnsureAdmin(&models.User{Email: "boss@example.com"}))
+		mockRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("reports a username taken in the tenant", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByEmail", "boss@example.com").Return(nil, errors.New("user not found"))
+		mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil, models.ErrUsernameTaken)
+
+		err := uc.EnsureAdmin(&models.User{Username: "admin", Email: "boss@example.com", Password: "secret123"})
+		assert.ErrorIs(t, err, models.ErrUsernameTaken)
+	})
+}
+
 func TestUserUseCase_DeleteUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
-	userID := uint(1)
-	mockRepo.On("Delete", userID).Return(nil)
-	err := uc.DeleteUser(userID)
+	userID := uint(4)
+	mockRepo.On("GetByID", userID).Return(&models.UserResponse{ID: userID, BranchID: 1, Version: 3}, nil)
+	mockRepo.On("Delete", userID, uint(3)).Return(nil)
+	err := uc.DeleteUser(adminCaller, userID, 3)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestUserUseCase_DeleteUser_NotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	uc := usecase.NewUserUseCase(mockRepo)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
 
 	userID := uint(999)
-	mockRepo.On("Delete", userID).Return(errors.New("user not found"))
-	err := uc.DeleteUser(userID)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "user not found")
+	mockRepo.On("GetByID", userID).Return(nil, models.ErrUserNotFound)
+	err := uc.DeleteUser(adminCaller, userID, 3)
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserUseCase_UpdateProfile(t *testing.T) {
+	t.Run("normalizes phone numbers before saving", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		profile := &models.UserProfile{
+			FullName:       "  Ana López ",
+			Phone:          "+52 (55) 1234-5678",
+			WhatsApp:       "0052 55 1234 5678",
+			LicenseNumber:  "AMPI-1234",
+			AvatarURL:      "https://cdn.example.com/ana.jpg",
+			CommissionRate: 3.5,
+		}
+		mockRepo.On("UpdateProfile", uint(4), uint(2), mock.MatchedBy(func(saved *models.UserProfile) bool {
+			return saved.FullName == "Ana López" && saved.Phone == "+525512345678" && saved.WhatsApp == "+525512345678"
+		})).Return(nil)
+		mockRepo.On("GetByID", uint(4)).Return(&models.UserResponse{ID: 4, UserProfile: models.UserProfile{Phone: "+525512345678"}}, nil)
+
+		user, err := uc.UpdateProfile(4, 2, profile)
+		require.NoError(t, err)
+		assert.Equal(t, "+525512345678", user.Phone)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid fields without saving", func(t *testing.T) {
+		invalid := []models.UserProfile{
+			{Phone: "55 1234 5678"},
+			{WhatsApp: "+52 55 12ab 5678"},
+			{Phone: "+1234"},
+			{AvatarURL: "javascript:alert(1)"},
+			{CommissionRate: 101},
+			{CommissionRate: -1},
+			{Bio: strings.Repeat("a", 1001)},
+		}
+		for _, profile := range invalid {
+			mockRepo := new(MockUserRepository)
+			uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+			_, err := uc.UpdateProfile(4, 2, &profile)
+			assert.Error(t, err, "%+v", profile)
+			mockRepo.AssertNotCalled(t, "UpdateProfile", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestUserUseCase_Login_Deactivated(t *testing.T) {
+	mockRepo, mockSessions := new(MockUserRepository), new(MockSessionRepository)
+	uc := usecase.NewUserUseCase(mockRepo, mockSessions, withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	hash, err := middleware.HashPassword("mypassword123")
+	require.NoError(t, err)
+	deactivatedAt := time.Now()
+	mockRepo.On("ConsultPassword", "gone@example.com").Return(hash, nil)
+	mockRepo.On("GetByEmail", "gone@example.com").Return(&models.UserResponse{ID: 6, Email: "gone@example.com", EmailVerifiedAt: verifiedNow(), DeactivatedAt: &deactivatedAt}, nil)
+
+	_, err = uc.Login("gone@example.com", "mypassword123", models.ClientInfo{})
+	assert.ErrorIs(t, err, models.ErrAccountDeactivated)
+	mockSessions.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestUserUseCase_DeactivateUser(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}
+
+	t.Run("deactivates another user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		deactivatedAt := time.Now()
+		mockRepo.On("Deactivate", uint(6)).Return(nil)
+		mockRepo.On("GetByID", uint(6)).Return(&models.UserResponse{ID: 6, BranchID: 1, DeactivatedAt: &deactivatedAt}, nil)
+
+		user, err := uc.DeactivateUser(admin, 6)
+		require.NoError(t, err)
+		assert.True(t, user.IsDeactivated())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects deactivating oneself", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		_, err := uc.DeactivateUser(admin, 1)
+		assert.Error(t, err)
+		mockRepo.AssertNotCalled(t, "Deactivate", mock.Anything)
+	})
+}
+
+func TestUserUseCase_RestoreUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+	deletedAt := time.Now()
+	mockRepo.On("GetByIDIncludingDeleted", uint(6)).Return(&models.UserResponse{ID: 6, BranchID: 1, DeletedAt: &deletedAt}, nil)
+	mockRepo.On("Restore", uint(6)).Return(nil)
+	mockRepo.On("GetByID", uint(6)).Return(&models.UserResponse{ID: 6, BranchID: 1}, nil)
+
+	user, err := uc.RestoreUser(adminCaller, 6)
+	require.NoError(t, err)
+	assert.False(t, user.IsDeactivated())
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserUseCase_PurgeUser(t *testing.T) {
+	admin := &models.Caller{UserID: 1, Role: models.RoleAdmin, BranchID: 1}
+
+	t.Run("refuses while properties reference the user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		mockRepo.On("GetByIDIncludingDeleted", uint(6)).Return(&models.UserResponse{ID: 6, BranchID: 1}, nil)
+		mockRepo.On("Purge", uint(6)).Return(fmt.Errorf("%w: reassign its 2 properties first", models.ErrUserHasProperties))
+
+		err := uc.PurgeUser(admin, 6)
+		assert.ErrorIs(t, err, models.ErrUserHasProperties)
+	})
+
+	t.Run("rejects purging oneself", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		uc := usecase.NewUserUseCase(mockRepo, new(MockSessionRepository), withoutTwoFactor(), newTestTokenManager(), newTestLoginGuard(), unusedEmailVerifier())
+
+		assert.Error(t, uc.PurgeUser(admin, 1))
+		mockRepo.AssertNotCalled(t, "Purge", mock.Anything)
+	})
+}